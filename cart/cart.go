@@ -0,0 +1,278 @@
+// Package cart implements the Cart component, which keeps the shopping carts
+// of the bookstore.
+//
+// A cart is identified by an ID picked by its owner, e.g. the product page
+// keeps a random one in a cookie of the browser. Carts are kept in memory,
+// and the component routes the calls for a cart to the same replica, so a
+// cart is seen whole with more than one.
+package cart
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sync"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Limits of a cart, which keep the carts kept in memory small.
+const (
+	MaxQuantity = 99 // units of a product
+	MaxItems    = 50 // different products
+)
+
+// Item is a number of units of a product in a cart.
+type Item struct {
+	weaver.AutoMarshal
+	ProductID int    `json:"product_id"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Contents are the items in a cart.
+type Contents struct {
+	weaver.AutoMarshal
+	ID       string `json:"id"`
+	Items    []Item `json:"items"`    // by product ID
+	Quantity int    `json:"quantity"` // the units of every item
+}
+
+// Cart keeps the shopping carts. A cart exists, empty, before anything is
+// added to it.
+type Cart interface {
+	// Get returns the contents of the cart with the given ID.
+	Get(ctx context.Context, cartID string) (Contents, error)
+	// Add adds quantity units of a product to a cart and returns its
+	// contents. It returns a catalog.NotFoundError for a product that
+	// isn't in the catalog.
+	Add(ctx context.Context, cartID string, productID int, quantity int) (Contents, error)
+	// Set sets the units of a product in a cart, removing it for 0, and
+	// returns its contents.
+	Set(ctx context.Context, cartID string, productID int, quantity int) (Contents, error)
+	// Clear empties a cart.
+	Clear(ctx context.Context, cartID string) error
+	// RemoveOrdered takes the units of the items of an order out of a cart,
+	// removing the items left with none, and returns its contents. Units
+	// added after the order was taken from the cart stay in it.
+	RemoveOrdered(ctx context.Context, cartID string, items []Item) (Contents, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// Add and RemoveOrdered change the units again on every call, so they aren't
+// retried.
+var (
+	_ weaver.NotRetriable = Cart.Add
+	_ weaver.NotRetriable = Cart.RemoveOrdered
+)
+
+// InvalidError is returned for a cart ID or quantity that isn't valid.
+type InvalidError struct {
+	weaver.AutoMarshal
+	Reason string
+}
+
+func (e InvalidError) Error() string {
+	return "invalid cart: " + e.Reason
+}
+
+// validID matches the valid cart IDs.
+var validID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// ValidateID returns an InvalidError unless id is a valid cart ID: 1 to 64
+// letters, digits, underscores and hyphens.
+func ValidateID(id string) error {
+	if !validID.MatchString(id) {
+		return InvalidError{Reason: fmt.Sprintf("invalid cart ID %q; want 1 to 64 letters, digits, '_' and '-'", id)}
+	}
+	return nil
+}
+
+type cart struct {
+	weaver.Implements[Cart]
+	weaver.WithConfig[logging.Config]
+	weaver.WithRouter[router]
+	catalog weaver.Ref[catalog.Catalog]
+	level   slog.Level // minimum level logged
+
+	mu    sync.Mutex
+	carts map[string]map[int]Item // items by product ID, by cart ID
+}
+
+// router sends the calls for a cart to the same replica, which keeps it.
+type router struct{}
+
+func (router) Get(_ context.Context, cartID string) string           { return cartID }
+func (router) Add(_ context.Context, cartID string, _, _ int) string { return cartID }
+func (router) Set(_ context.Context, cartID string, _, _ int) string { return cartID }
+func (router) Clear(_ context.Context, cartID string) string         { return cartID }
+
+func (router) RemoveOrdered(_ context.Context, cartID string, _ []Item) string { return cartID }
+
+func (c *cart) Init(ctx context.Context) error {
+	level, err := c.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	c.level = level
+	c.carts = map[string]map[int]Item{}
+	c.logger(ctx).Info("Cart component initialized")
+	return nil
+}
+
+// logger returns the component's logger for the request in ctx.
+func (c *cart) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, c.Logger(ctx), c.level)
+}
+
+func (c *cart) Get(_ context.Context, cartID string) (Contents, error) {
+	if err := ValidateID(cartID); err != nil {
+		return Contents{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.contentsLocked(cartID), nil
+}
+
+func (c *cart) Add(ctx context.Context, cartID string, productID int, quantity int) (Contents, error) {
+	if err := ValidateID(cartID); err != nil {
+		return Contents{}, err
+	}
+	if quantity < 1 {
+		return Contents{}, InvalidError{Reason: fmt.Sprintf("invalid quantity %d; want at least 1", quantity)}
+	}
+	c.mu.Lock()
+	item, ok := c.carts[cartID][productID]
+	c.mu.Unlock()
+	if !ok {
+		var err error
+		if item, err = c.item(ctx, productID); err != nil {
+			return Contents{}, err
+		}
+	}
+	return c.set(ctx, cartID, item, func(current int) int { return current + quantity })
+}
+
+func (c *cart) Set(ctx context.Context, cartID string, productID int, quantity int) (Contents, error) {
+	if err := ValidateID(cartID); err != nil {
+		return Contents{}, err
+	}
+	if quantity < 0 {
+		return Contents{}, InvalidError{Reason: fmt.Sprintf("invalid quantity %d; want at least 0", quantity)}
+	}
+	c.mu.Lock()
+	item, ok := c.carts[cartID][productID]
+	c.mu.Unlock()
+	if !ok && quantity > 0 {
+		var err error
+		if item, err = c.item(ctx, productID); err != nil {
+			return Contents{}, err
+		}
+	}
+	item.ProductID = productID
+	return c.set(ctx, cartID, item, func(int) int { return quantity })
+}
+
+// item returns an item of product id, with its title, and no units.
+func (c *cart) item(ctx context.Context, id int) (Item, error) {
+	p, err := c.catalog.Get().Get(ctx, id)
+	if err != nil {
+		return Item{}, err
+	}
+	return Item{ProductID: p.ID, Title: p.Title}, nil
+}
+
+// set sets the units of item in a cart to quantity applied to the current
+// ones, and returns the contents of the cart.
+func (c *cart) set(ctx context.Context, cartID string, item Item, quantity func(current int) int) (Contents, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items := c.carts[cartID]
+	current, ok := items[item.ProductID]
+	item.Quantity = quantity(current.Quantity)
+	switch {
+	case item.Quantity > MaxQuantity:
+		return Contents{}, InvalidError{Reason: fmt.Sprintf("at most %d units of a product fit in a cart", MaxQuantity)}
+	case !ok && item.Quantity > 0 && len(items) >= MaxItems:
+		return Contents{}, InvalidError{Reason: fmt.Sprintf("at most %d products fit in a cart", MaxItems)}
+	case item.Quantity == 0:
+		delete(items, item.ProductID)
+		if len(items) == 0 {
+			delete(c.carts, cartID)
+		}
+	default:
+		if items == nil {
+			items = map[int]Item{}
+			c.carts[cartID] = items
+		}
+		items[item.ProductID] = item
+	}
+	c.logger(ctx).Debug("Set cart item", "cart_id", cartID, "product_id", item.ProductID, "quantity", item.Quantity)
+	return c.contentsLocked(cartID), nil
+}
+
+// contentsLocked returns the contents of cart id.
+//
+// REQUIRES: c.mu is held.
+func (c *cart) contentsLocked(id string) Contents {
+	contents := Contents{ID: id, Items: []Item{}}
+	for _, item := range c.carts[id] {
+		contents.Items = append(contents.Items, item)
+		contents.Quantity += item.Quantity
+	}
+	slices.SortFunc(contents.Items, func(a, b Item) int { return a.ProductID - b.ProductID })
+	return contents
+}
+
+func (c *cart) Clear(ctx context.Context, cartID string) error {
+	if err := ValidateID(cartID); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.carts, cartID)
+	c.logger(ctx).Debug("Cleared cart", "cart_id", cartID)
+	return nil
+}
+
+func (c *cart) RemoveOrdered(ctx context.Context, cartID string, ordered []Item) (Contents, error) {
+	if err := ValidateID(cartID); err != nil {
+		return Contents{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	items := c.carts[cartID]
+	for _, o := range ordered {
+		item, ok := items[o.ProductID]
+		if !ok {
+			continue
+		}
+		item.Quantity -= o.Quantity
+		if item.Quantity > 0 {
+			items[o.ProductID] = item
+		} else {
+			delete(items, o.ProductID)
+		}
+	}
+	if len(items) == 0 {
+		delete(c.carts, cartID)
+	}
+	c.logger(ctx).Debug("Removed ordered items", "cart_id", cartID, "items", len(ordered))
+	return c.contentsLocked(cartID), nil
+}
+
+// Health reports whether the cart component is able to serve requests.
+func (c *cart) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the cart component serving the call.
+func (c *cart) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Cart](), nil
+}
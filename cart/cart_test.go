@@ -0,0 +1,112 @@
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+// quantities returns the product IDs and units of the items in c.
+func quantities(c Contents) string {
+	s := ""
+	for _, item := range c.Items {
+		s += fmt.Sprintf("%d:%d ", item.ProductID, item.Quantity)
+	}
+	return fmt.Sprintf("[%s] %d", s, c.Quantity)
+}
+
+func TestCart(t *testing.T) {
+	// The router sends the calls for a cart to the same replica when the
+	// deployer assigns routes, as "weaver multi" does, but the Multi runner
+	// balances routed calls over its two replicas, so it isn't tested here.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, c Cart) {
+			ctx := context.Background()
+			if got, err := c.Get(ctx, "alice"); err != nil || quantities(got) != "[] 0" {
+				t.Errorf("Get of a new cart = %+v, %v, want it empty", got, err)
+			}
+
+			// Add adds units, Set sets them.
+			if _, err := c.Add(ctx, "alice", 3, 1); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Add(ctx, "alice", 3, 2); err != nil {
+				t.Fatal(err)
+			}
+			got, err := c.Set(ctx, "alice", 1, 4)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := "[1:4 3:3 ] 7"; quantities(got) != want {
+				t.Errorf("cart after adding = %s, want %s", quantities(got), want)
+			}
+			if got.ID != "alice" || got.Items[0].Title == "" {
+				t.Errorf("cart = %+v, want ID alice and titled items", got)
+			}
+			if got, err := c.Set(ctx, "alice", 1, 0); err != nil || quantities(got) != "[3:3 ] 3" {
+				t.Errorf("Set(1, 0) = %s, %v, want product 1 removed", quantities(got), err)
+			}
+			if got, err := c.Get(ctx, "bob"); err != nil || len(got.Items) != 0 {
+				t.Errorf("Get of another cart = %+v, %v, want it empty", got, err)
+			}
+
+			if err := c.Clear(ctx, "alice"); err != nil {
+				t.Fatal(err)
+			}
+			if got, err := c.Get(ctx, "alice"); err != nil || len(got.Items) != 0 {
+				t.Errorf("Get after Clear = %+v, %v, want it empty", got, err)
+			}
+		})
+	}
+}
+
+func TestRemoveOrdered(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, c Cart) {
+		ctx := context.Background()
+		for _, item := range []Item{{ProductID: 1, Quantity: 3}, {ProductID: 2, Quantity: 1}} {
+			if _, err := c.Add(ctx, "alice", item.ProductID, item.Quantity); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The units added after the order stay, and products that
+		// aren't in the cart anymore are skipped.
+		ordered := []Item{{ProductID: 1, Quantity: 2}, {ProductID: 2, Quantity: 1}, {ProductID: 5, Quantity: 1}}
+		if got, err := c.RemoveOrdered(ctx, "alice", ordered); err != nil || quantities(got) != "[1:1 ] 1" {
+			t.Errorf("RemoveOrdered(%v) = %s, %v, want 1 unit of product 1 left", ordered, quantities(got), err)
+		}
+		if got, err := c.RemoveOrdered(ctx, "alice", ordered); err != nil || quantities(got) != "[] 0" {
+			t.Errorf("RemoveOrdered(%v) again = %s, %v, want the cart empty", ordered, quantities(got), err)
+		}
+	})
+}
+
+func TestCartErrors(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, c Cart) {
+		ctx := context.Background()
+		var notFound catalog.NotFoundError
+		if _, err := c.Add(ctx, "alice", 1000, 1); !errors.As(err, &notFound) {
+			t.Errorf("Add of a missing product: err = %v, want a catalog.NotFoundError", err)
+		}
+		if _, err := c.Add(ctx, "alice", 1, MaxQuantity); err != nil {
+			t.Fatal(err)
+		}
+		for name, call := range map[string]func() error{
+			"Add(0)":                      func() error { _, err := c.Add(ctx, "alice", 2, 0); return err },
+			"Set(-1)":                     func() error { _, err := c.Set(ctx, "alice", 2, -1); return err },
+			"Add over the max":            func() error { _, err := c.Add(ctx, "alice", 1, 1); return err },
+			"Get with a bad ID":           func() error { _, err := c.Get(ctx, "a/b"); return err },
+			"Clear with a bad ID":         func() error { return c.Clear(ctx, "") },
+			"RemoveOrdered with a bad ID": func() error { _, err := c.RemoveOrdered(ctx, "a b", nil); return err },
+		} {
+			var invalid InvalidError
+			if err := call(); !errors.As(err, &invalid) {
+				t.Errorf("%s: err = %v, want an InvalidError", name, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,1033 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package cart
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:    "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart",
+		Iface:   reflect.TypeOf((*Cart)(nil)).Elem(),
+		Impl:    reflect.TypeOf(cart{}),
+		Routed:  true,
+		NoRetry: []int{0, 5},
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return cart_local_stub{impl: impl.(Cart), tracer: tracer, addMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Add", Remote: false, Generated: true}), clearMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Clear", Remote: false, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Describe", Remote: false, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Get", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Health", Remote: false, Generated: true}), removeOrderedMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "RemoveOrdered", Remote: false, Generated: true}), setMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Set", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return cart_client_stub{stub: stub, addMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Add", Remote: true, Generated: true}), clearMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Clear", Remote: true, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Describe", Remote: true, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Get", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Health", Remote: true, Generated: true}), removeOrderedMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "RemoveOrdered", Remote: true, Generated: true}), setMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart", Method: "Set", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return cart_server_stub{impl: impl.(Cart), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return cart_reflect_stub{caller: caller}
+		},
+		RefData: "⟦cf115059:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart→github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog⟧\n",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Cart] = (*cart)(nil)
+
+// weaver.Router checks.
+var _ weaver.RoutedBy[router] = (*cart)(nil)
+
+// Component "cart", router "router" checks.
+type __cart_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate struct {
+	router
+	__cart_router_embedding
+}
+
+type __cart_router_embedding struct{}
+
+func (__cart_router_embedding) Describe() {}
+func (__cart_router_embedding) Health()   {}
+
+var _ func(_ context.Context, cartID string) string = (&router{}).Get                               // routed
+var _ func(_ context.Context, cartID string, _ int, _ int) string = (&router{}).Add                 // routed
+var _ func(_ context.Context, cartID string, _ int, _ int) string = (&router{}).Set                 // routed
+var _ func(_ context.Context, cartID string) string = (&router{}).Clear                             // routed
+var _ func(_ context.Context, cartID string, _ []Item) string = (&router{}).RemoveOrdered           // routed
+var _ = (&__cart_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Describe // unrouted
+var _ = (&__cart_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Health   // unrouted
+
+// Local stub implementations.
+
+type cart_local_stub struct {
+	impl                 Cart
+	tracer               trace.Tracer
+	addMetrics           *codegen.MethodMetrics
+	clearMetrics         *codegen.MethodMetrics
+	describeMetrics      *codegen.MethodMetrics
+	getMetrics           *codegen.MethodMetrics
+	healthMetrics        *codegen.MethodMetrics
+	removeOrderedMetrics *codegen.MethodMetrics
+	setMetrics           *codegen.MethodMetrics
+}
+
+// Check that cart_local_stub implements the Cart interface.
+var _ Cart = (*cart_local_stub)(nil)
+
+func (s cart_local_stub) Add(ctx context.Context, a0 string, a1 int, a2 int) (r0 Contents, err error) {
+	// Update metrics.
+	begin := s.addMetrics.Begin()
+	defer func() { s.addMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.Add", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Add(ctx, a0, a1, a2)
+}
+
+func (s cart_local_stub) Clear(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.clearMetrics.Begin()
+	defer func() { s.clearMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.Clear", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Clear(ctx, a0)
+}
+
+func (s cart_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s cart_local_stub) Get(ctx context.Context, a0 string) (r0 Contents, err error) {
+	// Update metrics.
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Get(ctx, a0)
+}
+
+func (s cart_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s cart_local_stub) RemoveOrdered(ctx context.Context, a0 string, a1 []Item) (r0 Contents, err error) {
+	// Update metrics.
+	begin := s.removeOrderedMetrics.Begin()
+	defer func() { s.removeOrderedMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.RemoveOrdered", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.RemoveOrdered(ctx, a0, a1)
+}
+
+func (s cart_local_stub) Set(ctx context.Context, a0 string, a1 int, a2 int) (r0 Contents, err error) {
+	// Update metrics.
+	begin := s.setMetrics.Begin()
+	defer func() { s.setMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "cart.Cart.Set", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Set(ctx, a0, a1, a2)
+}
+
+// Client stub implementations.
+
+type cart_client_stub struct {
+	stub                 codegen.Stub
+	addMetrics           *codegen.MethodMetrics
+	clearMetrics         *codegen.MethodMetrics
+	describeMetrics      *codegen.MethodMetrics
+	getMetrics           *codegen.MethodMetrics
+	healthMetrics        *codegen.MethodMetrics
+	removeOrderedMetrics *codegen.MethodMetrics
+	setMetrics           *codegen.MethodMetrics
+}
+
+// Check that cart_client_stub implements the Cart interface.
+var _ Cart = (*cart_client_stub)(nil)
+
+func (s cart_client_stub) Add(ctx context.Context, a0 string, a1 int, a2 int) (r0 Contents, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.addMetrics.Begin()
+	defer func() { s.addMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.Add", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += 8
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.Int(a1)
+	enc.Int(a2)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCart(r.Add(ctx, a0, a1, a2))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s cart_client_stub) Clear(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.clearMetrics.Begin()
+	defer func() { s.clearMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.Clear", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCart(r.Clear(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s cart_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s cart_client_stub) Get(ctx context.Context, a0 string) (r0 Contents, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.Get", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCart(r.Get(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s cart_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 4, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s cart_client_stub) RemoveOrdered(ctx context.Context, a0 string, a1 []Item) (r0 Contents, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.removeOrderedMetrics.Begin()
+	defer func() { s.removeOrderedMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.RemoveOrdered", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	enc.String(a0)
+	serviceweaver_enc_slice_Item_ec799a90(enc, a1)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCart(r.RemoveOrdered(ctx, a0, a1))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 5, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s cart_client_stub) Set(ctx context.Context, a0 string, a1 int, a2 int) (r0 Contents, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.setMetrics.Begin()
+	defer func() { s.setMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "cart.Cart.Set", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += 8
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.Int(a1)
+	enc.Int(a2)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCart(r.Set(ctx, a0, a1, a2))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 6, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type cart_server_stub struct {
+	impl    Cart
+	addLoad func(key uint64, load float64)
+}
+
+// Check that cart_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*cart_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s cart_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Add":
+		return s.add
+	case "Clear":
+		return s.clear
+	case "Describe":
+		return s.describe
+	case "Get":
+		return s.get
+	case "Health":
+		return s.health
+	case "RemoveOrdered":
+		return s.removeOrdered
+	case "Set":
+		return s.set
+	default:
+		return nil
+	}
+}
+
+func (s cart_server_stub) add(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 int
+	a1 = dec.Int()
+	var a2 int
+	a2 = dec.Int()
+	var r router
+	s.addLoad(_hashCart(r.Add(ctx, a0, a1, a2)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Add(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s cart_server_stub) clear(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var r router
+	s.addLoad(_hashCart(r.Clear(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Clear(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s cart_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s cart_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var r router
+	s.addLoad(_hashCart(r.Get(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Get(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s cart_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s cart_server_stub) removeOrdered(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []Item
+	a1 = serviceweaver_dec_slice_Item_ec799a90(dec)
+	var r router
+	s.addLoad(_hashCart(r.RemoveOrdered(ctx, a0, a1)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.RemoveOrdered(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s cart_server_stub) set(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 int
+	a1 = dec.Int()
+	var a2 int
+	a2 = dec.Int()
+	var r router
+	s.addLoad(_hashCart(r.Set(ctx, a0, a1, a2)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Set(ctx, a0, a1, a2)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type cart_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that cart_reflect_stub implements the Cart interface.
+var _ Cart = (*cart_reflect_stub)(nil)
+
+func (s cart_reflect_stub) Add(ctx context.Context, a0 string, a1 int, a2 int) (r0 Contents, err error) {
+	err = s.caller("Add", ctx, []any{a0, a1, a2}, []any{&r0})
+	return
+}
+
+func (s cart_reflect_stub) Clear(ctx context.Context, a0 string) (err error) {
+	err = s.caller("Clear", ctx, []any{a0}, []any{})
+	return
+}
+
+func (s cart_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s cart_reflect_stub) Get(ctx context.Context, a0 string) (r0 Contents, err error) {
+	err = s.caller("Get", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s cart_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s cart_reflect_stub) RemoveOrdered(ctx context.Context, a0 string, a1 []Item) (r0 Contents, err error) {
+	err = s.caller("RemoveOrdered", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
+func (s cart_reflect_stub) Set(ctx context.Context, a0 string, a1 int, a2 int) (r0 Contents, err error) {
+	err = s.caller("Set", ctx, []any{a0, a1, a2}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*Contents)(nil)
+
+type __is_Contents[T ~struct {
+	weaver.AutoMarshal
+	ID       string "json:\"id\""
+	Items    []Item "json:\"items\""
+	Quantity int    "json:\"quantity\""
+}] struct{}
+
+var _ __is_Contents[Contents]
+
+func (x *Contents) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Contents.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ID)
+	serviceweaver_enc_slice_Item_ec799a90(enc, x.Items)
+	enc.Int(x.Quantity)
+}
+
+func (x *Contents) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Contents.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.String()
+	x.Items = serviceweaver_dec_slice_Item_ec799a90(dec)
+	x.Quantity = dec.Int()
+}
+
+func serviceweaver_enc_slice_Item_ec799a90(enc *codegen.Encoder, arg []Item) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Item_ec799a90(dec *codegen.Decoder) []Item {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]Item, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*InvalidError)(nil)
+
+type __is_InvalidError[T ~struct {
+	weaver.AutoMarshal
+	Reason string
+}] struct{}
+
+var _ __is_InvalidError[InvalidError]
+
+func (x *InvalidError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Reason)
+}
+
+func (x *InvalidError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverUnmarshal: nil receiver"))
+	}
+	x.Reason = dec.String()
+}
+func init() { codegen.RegisterSerializable[*InvalidError]() }
+
+var _ codegen.AutoMarshal = (*Item)(nil)
+
+type __is_Item[T ~struct {
+	weaver.AutoMarshal
+	ProductID int    "json:\"product_id\""
+	Title     string "json:\"title\""
+	Quantity  int    "json:\"quantity\""
+}] struct{}
+
+var _ __is_Item[Item]
+
+func (x *Item) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Item.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+	enc.String(x.Title)
+	enc.Int(x.Quantity)
+}
+
+func (x *Item) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Item.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+	x.Title = dec.String()
+	x.Quantity = dec.Int()
+}
+
+// Router methods.
+
+// _hashCart returns a 64 bit hash of the provided value.
+func _hashCart(r string) uint64 {
+	var h codegen.Hasher
+	h.WriteString(string(r))
+	return h.Sum64()
+}
+
+// _orderedCodeCart returns an order-preserving serialization of the provided value.
+func _orderedCodeCart(r string) codegen.OrderedCode {
+	var enc codegen.OrderedEncoder
+	enc.WriteString(string(r))
+	return enc.Encode()
+}
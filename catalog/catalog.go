@@ -0,0 +1,372 @@
+// Package catalog implements the Catalog component, which owns the products
+// of the bookstore: their titles, descriptions and book metadata.
+//
+// The product page lists, searches and administers the products through it,
+// and the details component builds the book details from it. Products are
+// kept in a Store, picked in the component's config:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog"]
+//	store = "file"
+//	path = "/var/lib/bookinfo/catalog.json"
+//
+// Every store starts with the products embedded in data/products.json.
+//
+// Both stores are private to a replica, so the component routes every call
+// that reads or changes the products to the same replica, like the
+// inventory does. Routing is best effort: while replicas start or stop, a
+// call can reach another replica, which doesn't see the changes made through
+// the first one. The file store doesn't help with that: it's read once, when
+// the replica starts, and replicas sharing its file would overwrite each
+// other's changes.
+package catalog
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+//go:embed data/products.json
+var productsJSON []byte
+
+// Product is a product of the catalog: a book.
+type Product struct {
+	weaver.AutoMarshal
+	ID              int    `json:"id"`
+	Title           string `json:"title"`
+	DescriptionHTML string `json:"description_html"`
+	Author          string `json:"author"`
+	Year            int    `json:"year"`
+	Type            string `json:"type"`
+	Pages           int    `json:"pages"`
+	Publisher       string `json:"publisher"`
+	Language        string `json:"language"`
+	ISBN10          string `json:"ISBN-10"`
+	ISBN13          string `json:"ISBN-13"`
+}
+
+// Catalog owns the products.
+type Catalog interface {
+	// Get returns the product with the given ID, or a NotFoundError.
+	Get(ctx context.Context, id int) (Product, error)
+	// List returns every product, by ID.
+	List(ctx context.Context) ([]Product, error)
+	// Search returns the products, by ID, whose title, author or
+	// description contain every word of query, ignoring case.
+	Search(ctx context.Context, query string) ([]Product, error)
+	// Create adds a product with a new ID, which it returns with the
+	// product. The ID of p is ignored. IDs aren't reused, even those of
+	// deleted products, unless the store forgets them: the memory store
+	// starts over with the default products on restart.
+	Create(ctx context.Context, p Product) (Product, error)
+	// Update replaces the product with ID p.ID, or returns a NotFoundError.
+	Update(ctx context.Context, p Product) (Product, error)
+	// Delete deletes the product with the given ID, or returns a
+	// NotFoundError.
+	Delete(ctx context.Context, id int) error
+	// Changes returns the changes made since version since, which is the
+	// Version of the previous Changes, or 0 for every product.
+	Changes(ctx context.Context, since int64) (Changes, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// Create assigns a new ID on every call and Delete fails when repeated, so
+// they aren't retried.
+var (
+	_ weaver.NotRetriable = Catalog.Create
+	_ weaver.NotRetriable = Catalog.Delete
+)
+
+// Changes are the changes made to the products of a catalog replica since a
+// version.
+type Changes struct {
+	weaver.AutoMarshal
+	// Version is the version with the changes applied, to pass to the next
+	// call to Changes.
+	Version int64
+	// Reset is set if the changes since the version asked for aren't known,
+	// e.g. because it's from another replica or from before a restart.
+	// Products then holds every product, and Deleted is empty.
+	Reset    bool
+	Products []Product // the added and updated products, by ID
+	Deleted  []int     // the IDs of the deleted products, in order
+}
+
+// NotFoundError is returned for a product that doesn't exist.
+type NotFoundError struct {
+	weaver.AutoMarshal
+	ID int
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("product %d not found", e.ID)
+}
+
+// InvalidError is returned for a product that can't be stored.
+type InvalidError struct {
+	weaver.AutoMarshal
+	Reason string
+}
+
+func (e InvalidError) Error() string {
+	return "invalid product: " + e.Reason
+}
+
+// config is the configuration of the catalog component, read from its
+// section of the app config.
+type config struct {
+	logging.Config
+
+	// Store is the name of the store of the products: "memory" (the
+	// default) or "file".
+	Store string `toml:"store"`
+
+	// Path is the JSON file of the "file" store.
+	Path string `toml:"path"`
+}
+
+type catalog struct {
+	weaver.Implements[Catalog]
+	weaver.WithConfig[config]
+	weaver.WithRouter[router]
+	store Store
+	level slog.Level // minimum level logged
+
+	mu      sync.Mutex    // serializes the changes, so IDs are assigned once
+	first   int64         // version at Init
+	version int64         // version of the last change
+	changed map[int]int64 // version of the last change, by product ID
+}
+
+// router sends every call to the same replica, whose store holds the
+// products.
+type router struct{}
+
+func (router) Get(context.Context, int) string        { return "" }
+func (router) List(context.Context) string            { return "" }
+func (router) Search(context.Context, string) string  { return "" }
+func (router) Create(context.Context, Product) string { return "" }
+func (router) Update(context.Context, Product) string { return "" }
+func (router) Delete(context.Context, int) string     { return "" }
+func (router) Changes(context.Context, int64) string  { return "" }
+
+// Init opens the configured store.
+func (c *catalog) Init(ctx context.Context) error {
+	level, err := c.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	c.level = level
+
+	name := c.Config().Store
+	if name == "" {
+		name = "memory"
+	}
+	open, ok := stores[name]
+	if !ok {
+		return fmt.Errorf("unknown catalog store %q", name)
+	}
+	if c.store, err = open(*c.Config()); err != nil {
+		return fmt.Errorf("failed to open the %s catalog store: %w", name, err)
+	}
+
+	// Versions start at the time of Init, so that the versions of a
+	// restarted replica, or of another one, are rarely mistaken for ours.
+	c.first = time.Now().UnixNano()
+	c.version = c.first
+	c.changed = map[int]int64{}
+	c.logger(ctx).Info("Catalog component initialized", "store", name)
+	return nil
+}
+
+// logger returns the logger of the component for the request in ctx.
+func (c *catalog) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, c.Logger(ctx), c.level)
+}
+
+func (c *catalog) Get(ctx context.Context, id int) (Product, error) {
+	p, ok, err := c.store.Get(ctx, id)
+	if err != nil {
+		return Product{}, err
+	}
+	if !ok {
+		return Product{}, NotFoundError{ID: id}
+	}
+	return p, nil
+}
+
+func (c *catalog) List(ctx context.Context) ([]Product, error) {
+	return c.store.List(ctx)
+}
+
+func (c *catalog) Search(ctx context.Context, query string) ([]Product, error) {
+	products, err := c.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Match(products, query), nil
+}
+
+func (c *catalog) Create(ctx context.Context, p Product) (Product, error) {
+	if err := validate(p); err != nil {
+		return Product{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	id, err := c.store.NextID(ctx)
+	if err != nil {
+		return Product{}, err
+	}
+	p.ID = id
+	if err := c.store.Put(ctx, p); err != nil {
+		return Product{}, err
+	}
+	c.changedLocked(p.ID)
+	c.logger(ctx).Info("Created product", "product_id", p.ID, "title", p.Title)
+	return p, nil
+}
+
+func (c *catalog) Update(ctx context.Context, p Product) (Product, error) {
+	if err := validate(p); err != nil {
+		return Product{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok, err := c.store.Get(ctx, p.ID); err != nil {
+		return Product{}, err
+	} else if !ok {
+		return Product{}, NotFoundError{ID: p.ID}
+	}
+	if err := c.store.Put(ctx, p); err != nil {
+		return Product{}, err
+	}
+	c.changedLocked(p.ID)
+	c.logger(ctx).Info("Updated product", "product_id", p.ID)
+	return p, nil
+}
+
+func (c *catalog) Delete(ctx context.Context, id int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ok, err := c.store.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return NotFoundError{ID: id}
+	}
+	c.changedLocked(id)
+	c.logger(ctx).Info("Deleted product", "product_id", id)
+	return nil
+}
+
+func (c *catalog) Changes(ctx context.Context, since int64) (Changes, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	products, err := c.store.List(ctx)
+	if err != nil {
+		return Changes{}, err
+	}
+	if since < c.first || since > c.version {
+		return Changes{Version: c.version, Reset: true, Products: products}, nil
+	}
+	changes := Changes{Version: c.version, Products: []Product{}, Deleted: []int{}}
+	listed := map[int]bool{}
+	for _, p := range products {
+		listed[p.ID] = true
+		if c.changed[p.ID] > since {
+			changes.Products = append(changes.Products, p)
+		}
+	}
+	for id, version := range c.changed {
+		if version > since && !listed[id] {
+			changes.Deleted = append(changes.Deleted, id)
+		}
+	}
+	slices.Sort(changes.Deleted)
+	return changes, nil
+}
+
+// changedLocked records a change to product id.
+//
+// REQUIRES: c.mu is held.
+func (c *catalog) changedLocked(id int) {
+	c.version++
+	c.changed[id] = c.version
+}
+
+// Health reports whether the catalog component is able to serve requests.
+func (c *catalog) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the catalog component serving the call.
+func (c *catalog) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Catalog](), nil
+}
+
+// validate checks that p can be stored.
+func validate(p Product) error {
+	switch {
+	case p.ID < 0:
+		return InvalidError{Reason: "negative ID"}
+	case strings.TrimSpace(p.Title) == "":
+		return InvalidError{Reason: "no title"}
+	case p.Year < 0:
+		return InvalidError{Reason: "negative year"}
+	case p.Pages < 0:
+		return InvalidError{Reason: "negative page count"}
+	}
+	return nil
+}
+
+// DefaultProducts returns the products every store starts with.
+func DefaultProducts() ([]Product, error) {
+	var products []Product
+	if err := json.Unmarshal(productsJSON, &products); err != nil {
+		return nil, fmt.Errorf("failed to parse the embedded products: %w", err)
+	}
+	return products, nil
+}
+
+// tags matches HTML tags, which are left out of searches.
+var tags = regexp.MustCompile(`<[^>]*>`)
+
+// DescriptionText returns the description of p as plain text.
+func (p Product) DescriptionText() string {
+	return html.UnescapeString(tags.ReplaceAllString(p.DescriptionHTML, ""))
+}
+
+// Match returns the products whose title, author or description contain
+// every word of query, ignoring case. An empty query matches every product.
+func Match(products []Product, query string) []Product {
+	words := strings.Fields(strings.ToLower(query))
+	matches := []Product{}
+	for _, p := range products {
+		text := strings.ToLower(p.Title + " " + p.Author + " " + p.DescriptionText())
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, p)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,225 @@
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestGet(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, c Catalog) {
+			ctx := context.Background()
+			p, err := c.Get(ctx, 0)
+			if err != nil || p.ID != 0 || p.Title != "The Comedy of Errors" || p.ISBN10 != "1234567890" {
+				t.Errorf("Get(0) = %+v, %v, want The Comedy of Errors", p, err)
+			}
+
+			var notFound NotFoundError
+			if _, err := c.Get(ctx, 1000); !errors.As(err, &notFound) || notFound.ID != 1000 {
+				t.Errorf("Get(1000) = %v, want a NotFoundError", err)
+			}
+
+			products, err := c.List(ctx)
+			if err != nil || len(products) != 100 || products[99].ID != 99 {
+				t.Errorf("List() = %d products, %v, want 100 by ID", len(products), err)
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	// The Multi runner has more than one replica of the catalog, each with a
+	// memory store of its own, and balances routed calls over them, so it
+	// isn't tested; "weaver multi" routes the calls to one replica.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, c Catalog) {
+			ctx := context.Background()
+			created, err := c.Create(ctx, Product{ID: 7, Title: "Venus and Adonis", Year: 1593})
+			if err != nil || created.ID != 100 {
+				t.Fatalf("Create() = %+v, %v, want product 100", created, err)
+			}
+			if got, err := c.Get(ctx, 100); err != nil || got != created {
+				t.Errorf("Get(100) = %+v, %v, want %+v", got, err, created)
+			}
+
+			created.Year = 1594
+			if got, err := c.Update(ctx, created); err != nil || got != created {
+				t.Errorf("Update(100) = %+v, %v, want %+v", got, err, created)
+			}
+			if err := c.Delete(ctx, 100); err != nil {
+				t.Errorf("Delete(100) = %v", err)
+			}
+
+			var notFound NotFoundError
+			if err := c.Delete(ctx, 100); !errors.As(err, &notFound) {
+				t.Errorf("Delete(100) twice = %v, want a NotFoundError", err)
+			}
+			if _, err := c.Update(ctx, created); !errors.As(err, &notFound) {
+				t.Errorf("Update(100) after Delete = %v, want a NotFoundError", err)
+			}
+			// The ID of the deleted product isn't reused.
+			if again, err := c.Create(ctx, created); err != nil || again.ID != 101 {
+				t.Errorf("Create() after Delete(100) = %+v, %v, want product 101", again, err)
+			}
+			var invalid InvalidError
+			if _, err := c.Create(ctx, Product{Title: "Venus and Adonis", Pages: -1}); !errors.As(err, &invalid) {
+				t.Errorf("Create() with negative pages = %v, want an InvalidError", err)
+			}
+		})
+	}
+}
+
+func TestSearch(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, c Catalog) {
+		for _, test := range []struct {
+			query string
+			want  []int
+		}{
+			{"comedy errors", []int{0}},
+			{"  ERRORS ", []int{0}},
+			{"https", nil}, // only in the links of the descriptions
+			{"errors tempest", nil},
+		} {
+			products, err := c.Search(context.Background(), test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []int
+			for _, p := range products {
+				got = append(got, p.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+			}
+		}
+	})
+}
+
+func TestFileStoreConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	runner := weavertest.Local
+	runner.Config = fmt.Sprintf(`
+["github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog"]
+store = "file"
+path = %q
+`, path)
+	runner.Test(t, func(t *testing.T, c Catalog) {
+		if _, err := c.Create(context.Background(), Product{Title: "Venus and Adonis"}); err != nil {
+			t.Fatal(err)
+		}
+	})
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok, _ := s.Get(context.Background(), 100); !ok || p.Title != "Venus and Adonis" {
+		t.Errorf("product 100 in %s = %+v, %v, want the created product", path, p, ok)
+	}
+
+	// Delete the product with the highest ID and restart: its ID isn't
+	// reused.
+	runner.Test(t, func(t *testing.T, c Catalog) {
+		if err := c.Delete(context.Background(), 100); err != nil {
+			t.Fatal(err)
+		}
+	})
+	runner.Test(t, func(t *testing.T, c Catalog) {
+		p, err := c.Create(context.Background(), Product{Title: "The Rape of Lucrece"})
+		if err != nil || p.ID != 101 {
+			t.Errorf("Create() after deleting product 100 and restarting = %+v, %v, want product 101", p, err)
+		}
+	})
+}
+
+func TestFileStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "catalog.json")
+	s, err := NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("the default products weren't written: %v", err)
+	}
+	if err := s.Put(ctx, Product{ID: 100, Title: "Venus and Adonis"}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Delete(ctx, 0); !ok || err != nil {
+		t.Fatalf("Delete(0) = %v, %v", ok, err)
+	}
+
+	// The changes survive reopening the file.
+	s, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, ok, _ := s.Get(ctx, 100); !ok || p.Title != "Venus and Adonis" {
+		t.Errorf("Get(100) = %+v, %v, want the stored product", p, ok)
+	}
+	if _, ok, _ := s.Get(ctx, 0); ok {
+		t.Error("Get(0) found a deleted product")
+	}
+	if ok, err := s.Delete(ctx, 100); !ok || err != nil {
+		t.Fatalf("Delete(100) = %v, %v", ok, err)
+	}
+	s, err = NewFileStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next, err := s.NextID(ctx); next != 101 || err != nil {
+		t.Errorf("NextID() after deleting product 100 = %d, %v, want 101", next, err)
+	}
+
+	// A file holding just the products, without the next ID, is read too.
+	os.WriteFile(path, []byte(`[{"id": 7, "title": "Venus and Adonis"}]`), 0o644)
+	if s, err = NewFileStore(path); err != nil {
+		t.Fatal(err)
+	}
+	if next, _ := s.NextID(ctx); next != 8 {
+		t.Errorf("NextID() of a file without it = %d, want 8", next)
+	}
+
+	if _, err := NewFileStore(""); err == nil {
+		t.Error("NewFileStore(\"\") succeeded")
+	}
+	os.WriteFile(path, []byte("{"), 0o644)
+	if _, err := NewFileStore(path); err == nil {
+		t.Error("NewFileStore of a malformed file succeeded")
+	}
+}
+
+func TestChangeFeed(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, c Catalog) {
+		ctx := context.Background()
+		all, err := c.Changes(ctx, 0)
+		if err != nil || !all.Reset || len(all.Products) != 100 {
+			t.Fatalf("Changes(0) = reset %v, %d products, %v, want a reset with every product", all.Reset, len(all.Products), err)
+		}
+		if none, err := c.Changes(ctx, all.Version); err != nil || none.Reset || len(none.Products)+len(none.Deleted) != 0 || none.Version != all.Version {
+			t.Errorf("Changes(current) = %+v, %v, want no changes", none, err)
+		}
+
+		created, err := c.Create(ctx, Product{Title: "Venus and Adonis"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := c.Delete(ctx, 3); err != nil {
+			t.Fatal(err)
+		}
+		changes, err := c.Changes(ctx, all.Version)
+		if err != nil || changes.Reset || len(changes.Products) != 1 || changes.Products[0] != created || fmt.Sprint(changes.Deleted) != "[3]" {
+			t.Errorf("Changes() after a create and a delete = %+v, %v, want product 100 and a deletion of 3", changes, err)
+		}
+
+		// Versions of other replicas, or from the future, reset.
+		if got, err := c.Changes(ctx, changes.Version+1); err != nil || !got.Reset || len(got.Products) != 100 {
+			t.Errorf("Changes(unknown version) = reset %v, %d products, %v, want a reset with every product", got.Reset, len(got.Products), err)
+		}
+	})
+}
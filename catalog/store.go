@@ -0,0 +1,226 @@
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+)
+
+// Store stores the products of a catalog replica.
+//
+// The memory and file stores are private to a replica, so with more than one
+// replica the changes made through one aren't seen by the others, which is
+// why the component routes its calls to one replica; a store shared by the
+// replicas, like a database, avoids that.
+type Store interface {
+	// Get returns the product with the given ID, and whether it exists.
+	Get(ctx context.Context, id int) (Product, bool, error)
+	// List returns every product, by ID.
+	List(ctx context.Context) ([]Product, error)
+	// Put adds or replaces the product with ID p.ID.
+	Put(ctx context.Context, p Product) error
+	// Delete deletes the product with the given ID, and reports whether it
+	// existed.
+	Delete(ctx context.Context, id int) (bool, error)
+	// NextID returns the ID of the next product created: one more than the
+	// highest ID ever stored, even if that product was deleted.
+	NextID(ctx context.Context) (int, error)
+}
+
+// stores open the stores, by name.
+var stores = map[string]func(config) (Store, error){
+	"memory": func(config) (Store, error) {
+		products, err := DefaultProducts()
+		if err != nil {
+			return nil, err
+		}
+		return NewMemoryStore(products), nil
+	},
+	"file": func(c config) (Store, error) {
+		return NewFileStore(c.Path)
+	},
+}
+
+// MemoryStore is a Store that keeps the products in memory. It starts over
+// on restart, forgetting the products created and the IDs used.
+type MemoryStore struct {
+	mu       sync.Mutex
+	products map[int]Product
+	next     int // one more than the highest ID stored
+}
+
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a store holding products.
+func NewMemoryStore(products []Product) *MemoryStore {
+	s := &MemoryStore{products: map[int]Product{}}
+	for _, p := range products {
+		s.products[p.ID] = p
+		s.next = max(s.next, p.ID+1)
+	}
+	return s
+}
+
+func (s *MemoryStore) Get(_ context.Context, id int) (Product, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	p, ok := s.products[id]
+	return p, ok, nil
+}
+
+func (s *MemoryStore) List(context.Context) ([]Product, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.list(), nil
+}
+
+// list returns the products, by ID.
+//
+// REQUIRES: s.mu is held.
+func (s *MemoryStore) list() []Product {
+	products := make([]Product, 0, len(s.products))
+	for _, p := range s.products {
+		products = append(products, p)
+	}
+	slices.SortFunc(products, func(a, b Product) int { return a.ID - b.ID })
+	return products
+}
+
+func (s *MemoryStore) Put(_ context.Context, p Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.products[p.ID] = p
+	s.next = max(s.next, p.ID+1)
+	return nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.products[id]
+	delete(s.products, id)
+	return ok, nil
+}
+
+func (s *MemoryStore) NextID(context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.next, nil
+}
+
+// FileStore is a Store that keeps the products in memory and writes them to
+// a JSON file on every change, so they survive restarts. The file also holds
+// the next ID, so the IDs of deleted products aren't reused after a restart.
+//
+// The file is read once, when the store is opened, and rewritten from memory
+// on every change, so it must have a single writer: replicas sharing the file
+// would overwrite each other's changes without seeing them.
+type FileStore struct {
+	path string
+	mu   sync.Mutex // held while writing the file
+	mem  *MemoryStore
+}
+
+var _ Store = (*FileStore)(nil)
+
+// storeFile is the content of the file of a FileStore.
+type storeFile struct {
+	NextID   int       `json:"next_id"`
+	Products []Product `json:"products"`
+}
+
+// NewFileStore returns a store backed by the JSON file at path. A missing
+// file is created with the default products. A file holding just an array
+// of products, as written before the next ID was stored, is read too, with
+// the next ID following the highest stored one.
+func NewFileStore(path string) (*FileStore, error) {
+	if path == "" {
+		return nil, errors.New("no path")
+	}
+	s := &FileStore{path: path}
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		products, err := DefaultProducts()
+		if err != nil {
+			return nil, err
+		}
+		s.mem = NewMemoryStore(products)
+		if err := s.write(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	default:
+		var file storeFile
+		if err := json.Unmarshal(data, &file.Products); err != nil {
+			if err := json.Unmarshal(data, &file); err != nil {
+				return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+		}
+		s.mem = NewMemoryStore(file.Products)
+		s.mem.next = max(s.mem.next, file.NextID)
+	}
+	return s, nil
+}
+
+func (s *FileStore) Get(ctx context.Context, id int) (Product, bool, error) {
+	return s.mem.Get(ctx, id)
+}
+
+func (s *FileStore) List(ctx context.Context) ([]Product, error) {
+	return s.mem.List(ctx)
+}
+
+func (s *FileStore) Put(ctx context.Context, p Product) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mem.Put(ctx, p)
+	return s.write()
+}
+
+func (s *FileStore) Delete(ctx context.Context, id int) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ok, _ := s.mem.Delete(ctx, id)
+	if !ok {
+		return false, nil
+	}
+	return true, s.write()
+}
+
+func (s *FileStore) NextID(ctx context.Context) (int, error) {
+	return s.mem.NextID(ctx)
+}
+
+// write replaces the file with the products and the next ID. The file is replaced with a
+// rename, so readers never see it half written.
+//
+// REQUIRES: s.mu is held, or s isn't shared yet.
+func (s *FileStore) write() error {
+	products, _ := s.mem.List(context.Background())
+	next, _ := s.mem.NextID(context.Background())
+	data, err := json.MarshalIndent(storeFile{NextID: next, Products: products}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
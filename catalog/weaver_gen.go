@@ -0,0 +1,1331 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:    "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog",
+		Iface:   reflect.TypeOf((*Catalog)(nil)).Elem(),
+		Impl:    reflect.TypeOf(catalog{}),
+		Routed:  true,
+		NoRetry: []int{1, 2},
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return catalog_local_stub{impl: impl.(Catalog), tracer: tracer, changesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Changes", Remote: false, Generated: true}), createMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Create", Remote: false, Generated: true}), deleteMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Delete", Remote: false, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Describe", Remote: false, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Get", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Health", Remote: false, Generated: true}), listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "List", Remote: false, Generated: true}), searchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Search", Remote: false, Generated: true}), updateMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Update", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return catalog_client_stub{stub: stub, changesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Changes", Remote: true, Generated: true}), createMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Create", Remote: true, Generated: true}), deleteMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Delete", Remote: true, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Describe", Remote: true, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Get", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Health", Remote: true, Generated: true}), listMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "List", Remote: true, Generated: true}), searchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Search", Remote: true, Generated: true}), updateMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog", Method: "Update", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return catalog_server_stub{impl: impl.(Catalog), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return catalog_reflect_stub{caller: caller}
+		},
+		RefData: "",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Catalog] = (*catalog)(nil)
+
+// weaver.Router checks.
+var _ weaver.RoutedBy[router] = (*catalog)(nil)
+
+// Component "catalog", router "router" checks.
+type __catalog_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate struct {
+	router
+	__catalog_router_embedding
+}
+
+type __catalog_router_embedding struct{}
+
+func (__catalog_router_embedding) Describe() {}
+func (__catalog_router_embedding) Health()   {}
+
+var _ func(context.Context, int) string = (&router{}).Get                                              // routed
+var _ func(context.Context) string = (&router{}).List                                                  // routed
+var _ func(context.Context, string) string = (&router{}).Search                                        // routed
+var _ func(context.Context, Product) string = (&router{}).Create                                       // routed
+var _ func(context.Context, Product) string = (&router{}).Update                                       // routed
+var _ func(context.Context, int) string = (&router{}).Delete                                           // routed
+var _ func(context.Context, int64) string = (&router{}).Changes                                        // routed
+var _ = (&__catalog_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Describe // unrouted
+var _ = (&__catalog_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Health   // unrouted
+
+// Local stub implementations.
+
+type catalog_local_stub struct {
+	impl            Catalog
+	tracer          trace.Tracer
+	changesMetrics  *codegen.MethodMetrics
+	createMetrics   *codegen.MethodMetrics
+	deleteMetrics   *codegen.MethodMetrics
+	describeMetrics *codegen.MethodMetrics
+	getMetrics      *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+	listMetrics     *codegen.MethodMetrics
+	searchMetrics   *codegen.MethodMetrics
+	updateMetrics   *codegen.MethodMetrics
+}
+
+// Check that catalog_local_stub implements the Catalog interface.
+var _ Catalog = (*catalog_local_stub)(nil)
+
+func (s catalog_local_stub) Changes(ctx context.Context, a0 int64) (r0 Changes, err error) {
+	// Update metrics.
+	begin := s.changesMetrics.Begin()
+	defer func() { s.changesMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Changes", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Changes(ctx, a0)
+}
+
+func (s catalog_local_stub) Create(ctx context.Context, a0 Product) (r0 Product, err error) {
+	// Update metrics.
+	begin := s.createMetrics.Begin()
+	defer func() { s.createMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Create", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Create(ctx, a0)
+}
+
+func (s catalog_local_stub) Delete(ctx context.Context, a0 int) (err error) {
+	// Update metrics.
+	begin := s.deleteMetrics.Begin()
+	defer func() { s.deleteMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Delete", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Delete(ctx, a0)
+}
+
+func (s catalog_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s catalog_local_stub) Get(ctx context.Context, a0 int) (r0 Product, err error) {
+	// Update metrics.
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Get(ctx, a0)
+}
+
+func (s catalog_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s catalog_local_stub) List(ctx context.Context) (r0 []Product, err error) {
+	// Update metrics.
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.List", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.List(ctx)
+}
+
+func (s catalog_local_stub) Search(ctx context.Context, a0 string) (r0 []Product, err error) {
+	// Update metrics.
+	begin := s.searchMetrics.Begin()
+	defer func() { s.searchMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Search", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Search(ctx, a0)
+}
+
+func (s catalog_local_stub) Update(ctx context.Context, a0 Product) (r0 Product, err error) {
+	// Update metrics.
+	begin := s.updateMetrics.Begin()
+	defer func() { s.updateMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "catalog.Catalog.Update", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Update(ctx, a0)
+}
+
+// Client stub implementations.
+
+type catalog_client_stub struct {
+	stub            codegen.Stub
+	changesMetrics  *codegen.MethodMetrics
+	createMetrics   *codegen.MethodMetrics
+	deleteMetrics   *codegen.MethodMetrics
+	describeMetrics *codegen.MethodMetrics
+	getMetrics      *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+	listMetrics     *codegen.MethodMetrics
+	searchMetrics   *codegen.MethodMetrics
+	updateMetrics   *codegen.MethodMetrics
+}
+
+// Check that catalog_client_stub implements the Catalog interface.
+var _ Catalog = (*catalog_client_stub)(nil)
+
+func (s catalog_client_stub) Changes(ctx context.Context, a0 int64) (r0 Changes, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.changesMetrics.Begin()
+	defer func() { s.changesMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Changes", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.Int64(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.Changes(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Create(ctx context.Context, a0 Product) (r0 Product, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.createMetrics.Begin()
+	defer func() { s.createMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Create", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += serviceweaver_size_Product_e8b084be(&a0)
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	(a0).WeaverMarshal(enc)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.Create(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Delete(ctx context.Context, a0 int) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.deleteMetrics.Begin()
+	defer func() { s.deleteMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Delete", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.Int(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.Delete(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Get(ctx context.Context, a0 int) (r0 Product, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Get", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.Int(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.Get(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 5, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) List(ctx context.Context) (r0 []Product, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.listMetrics.Begin()
+	defer func() { s.listMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.List", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.List(ctx))
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 6, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_Product_3e36542a(dec)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Search(ctx context.Context, a0 string) (r0 []Product, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.searchMetrics.Begin()
+	defer func() { s.searchMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Search", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.Search(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 7, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_Product_3e36542a(dec)
+	err = dec.Error()
+	return
+}
+
+func (s catalog_client_stub) Update(ctx context.Context, a0 Product) (r0 Product, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.updateMetrics.Begin()
+	defer func() { s.updateMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "catalog.Catalog.Update", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += serviceweaver_size_Product_e8b084be(&a0)
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	(a0).WeaverMarshal(enc)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashCatalog(r.Update(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 8, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type catalog_server_stub struct {
+	impl    Catalog
+	addLoad func(key uint64, load float64)
+}
+
+// Check that catalog_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*catalog_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s catalog_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Changes":
+		return s.changes
+	case "Create":
+		return s.create
+	case "Delete":
+		return s.delete
+	case "Describe":
+		return s.describe
+	case "Get":
+		return s.get
+	case "Health":
+		return s.health
+	case "List":
+		return s.list
+	case "Search":
+		return s.search
+	case "Update":
+		return s.update
+	default:
+		return nil
+	}
+}
+
+func (s catalog_server_stub) changes(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 int64
+	a0 = dec.Int64()
+	var r router
+	s.addLoad(_hashCatalog(r.Changes(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Changes(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) create(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 Product
+	(&a0).WeaverUnmarshal(dec)
+	var r router
+	s.addLoad(_hashCatalog(r.Create(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Create(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) delete(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 int
+	a0 = dec.Int()
+	var r router
+	s.addLoad(_hashCatalog(r.Delete(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Delete(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 int
+	a0 = dec.Int()
+	var r router
+	s.addLoad(_hashCatalog(r.Get(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Get(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) list(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+	var r router
+	s.addLoad(_hashCatalog(r.List(ctx)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.List(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_Product_3e36542a(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) search(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var r router
+	s.addLoad(_hashCatalog(r.Search(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Search(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_Product_3e36542a(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s catalog_server_stub) update(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 Product
+	(&a0).WeaverUnmarshal(dec)
+	var r router
+	s.addLoad(_hashCatalog(r.Update(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Update(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type catalog_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that catalog_reflect_stub implements the Catalog interface.
+var _ Catalog = (*catalog_reflect_stub)(nil)
+
+func (s catalog_reflect_stub) Changes(ctx context.Context, a0 int64) (r0 Changes, err error) {
+	err = s.caller("Changes", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) Create(ctx context.Context, a0 Product) (r0 Product, err error) {
+	err = s.caller("Create", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) Delete(ctx context.Context, a0 int) (err error) {
+	err = s.caller("Delete", ctx, []any{a0}, []any{})
+	return
+}
+
+func (s catalog_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) Get(ctx context.Context, a0 int) (r0 Product, err error) {
+	err = s.caller("Get", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s catalog_reflect_stub) List(ctx context.Context) (r0 []Product, err error) {
+	err = s.caller("List", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) Search(ctx context.Context, a0 string) (r0 []Product, err error) {
+	err = s.caller("Search", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s catalog_reflect_stub) Update(ctx context.Context, a0 Product) (r0 Product, err error) {
+	err = s.caller("Update", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*Changes)(nil)
+
+type __is_Changes[T ~struct {
+	weaver.AutoMarshal
+	Version  int64
+	Reset    bool
+	Products []Product
+	Deleted  []int
+}] struct{}
+
+var _ __is_Changes[Changes]
+
+func (x *Changes) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Changes.WeaverMarshal: nil receiver"))
+	}
+	enc.Int64(x.Version)
+	enc.Bool(x.Reset)
+	serviceweaver_enc_slice_Product_3e36542a(enc, x.Products)
+	serviceweaver_enc_slice_int_7c8c8866(enc, x.Deleted)
+}
+
+func (x *Changes) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Changes.WeaverUnmarshal: nil receiver"))
+	}
+	x.Version = dec.Int64()
+	x.Reset = dec.Bool()
+	x.Products = serviceweaver_dec_slice_Product_3e36542a(dec)
+	x.Deleted = serviceweaver_dec_slice_int_7c8c8866(dec)
+}
+
+func serviceweaver_enc_slice_Product_3e36542a(enc *codegen.Encoder, arg []Product) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Product_3e36542a(dec *codegen.Decoder) []Product {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]Product, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_int_7c8c8866(enc *codegen.Encoder, arg []int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Int(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]int, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Int()
+	}
+	return res
+}
+
+var _ codegen.AutoMarshal = (*InvalidError)(nil)
+
+type __is_InvalidError[T ~struct {
+	weaver.AutoMarshal
+	Reason string
+}] struct{}
+
+var _ __is_InvalidError[InvalidError]
+
+func (x *InvalidError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Reason)
+}
+
+func (x *InvalidError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverUnmarshal: nil receiver"))
+	}
+	x.Reason = dec.String()
+}
+func init() { codegen.RegisterSerializable[*InvalidError]() }
+
+var _ codegen.AutoMarshal = (*NotFoundError)(nil)
+
+type __is_NotFoundError[T ~struct {
+	weaver.AutoMarshal
+	ID int
+}] struct{}
+
+var _ __is_NotFoundError[NotFoundError]
+
+func (x *NotFoundError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ID)
+}
+
+func (x *NotFoundError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.Int()
+}
+func init() { codegen.RegisterSerializable[*NotFoundError]() }
+
+var _ codegen.AutoMarshal = (*Product)(nil)
+
+type __is_Product[T ~struct {
+	weaver.AutoMarshal
+	ID              int    "json:\"id\""
+	Title           string "json:\"title\""
+	DescriptionHTML string "json:\"description_html\""
+	Author          string "json:\"author\""
+	Year            int    "json:\"year\""
+	Type            string "json:\"type\""
+	Pages           int    "json:\"pages\""
+	Publisher       string "json:\"publisher\""
+	Language        string "json:\"language\""
+	ISBN10          string "json:\"ISBN-10\""
+	ISBN13          string "json:\"ISBN-13\""
+}] struct{}
+
+var _ __is_Product[Product]
+
+func (x *Product) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Product.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ID)
+	enc.String(x.Title)
+	enc.String(x.DescriptionHTML)
+	enc.String(x.Author)
+	enc.Int(x.Year)
+	enc.String(x.Type)
+	enc.Int(x.Pages)
+	enc.String(x.Publisher)
+	enc.String(x.Language)
+	enc.String(x.ISBN10)
+	enc.String(x.ISBN13)
+}
+
+func (x *Product) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Product.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.Int()
+	x.Title = dec.String()
+	x.DescriptionHTML = dec.String()
+	x.Author = dec.String()
+	x.Year = dec.Int()
+	x.Type = dec.String()
+	x.Pages = dec.Int()
+	x.Publisher = dec.String()
+	x.Language = dec.String()
+	x.ISBN10 = dec.String()
+	x.ISBN13 = dec.String()
+}
+
+// Router methods.
+
+// _hashCatalog returns a 64 bit hash of the provided value.
+func _hashCatalog(r string) uint64 {
+	var h codegen.Hasher
+	h.WriteString(string(r))
+	return h.Sum64()
+}
+
+// _orderedCodeCatalog returns an order-preserving serialization of the provided value.
+func _orderedCodeCatalog(r string) codegen.OrderedCode {
+	var enc codegen.OrderedEncoder
+	enc.WriteString(string(r))
+	return enc.Encode()
+}
+
+// Size implementations.
+
+// serviceweaver_size_Product_e8b084be returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_Product_e8b084be(x *Product) int {
+	size := 0
+	size += 0
+	size += 8
+	size += (4 + len(x.Title))
+	size += (4 + len(x.DescriptionHTML))
+	size += (4 + len(x.Author))
+	size += 8
+	size += (4 + len(x.Type))
+	size += 8
+	size += (4 + len(x.Publisher))
+	size += (4 + len(x.Language))
+	size += (4 + len(x.ISBN10))
+	size += (4 + len(x.ISBN13))
+	return size
+}
@@ -0,0 +1,435 @@
+// Package apiv1 is a Go client for the /api/v1 REST API of the Bookinfo
+// product page.
+//
+//	c, err := apiv1.New("http://localhost:12345", apiv1.WithToken(token))
+//	if err != nil {
+//		...
+//	}
+//	book, err := c.Details(ctx, 1)
+//
+// The package follows the API version: incompatible changes to the API get a
+// new version, and a new package. It doesn't depend on the rest of the
+// application, so it can be used from anywhere.
+//
+// Idempotent requests, which are all of them but CreateProduct, DeleteProduct
+// and AddToCart, are retried when the product page can't be reached or
+// answers 429, 502, 503 or 504. Error responses are returned as *Error.
+package apiv1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BasePath is the path of the API on the product page.
+const BasePath = "/api/v1"
+
+// Defaults of the options.
+const (
+	DefaultRetries = 2
+	DefaultBackoff = 100 * time.Millisecond
+	DefaultTimeout = 10 * time.Second
+)
+
+// userAgent identifies the client to the product page.
+const userAgent = "bookinfo-apiv1-go"
+
+// Client is a client of the API. It's safe for concurrent use.
+type Client struct {
+	base    *url.URL // the product page, e.g. http://localhost:12345
+	http    *http.Client
+	header  http.Header // added to every request
+	retries int
+	backoff time.Duration
+	timeout time.Duration
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient makes the client send requests with hc instead of
+// http.DefaultClient.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithToken sends token as a bearer token in the Authorization header.
+func WithToken(token string) Option {
+	return WithHeader("Authorization", "Bearer "+token)
+}
+
+// WithHeader adds a header to every request, e.g. for other authorization
+// schemes.
+func WithHeader(name, value string) Option {
+	return func(c *Client) { c.header.Set(name, value) }
+}
+
+// WithRetries makes the client retry a failed request up to n times, waiting
+// backoff before the first retry and twice as long before every next one.
+func WithRetries(n int, backoff time.Duration) Option {
+	return func(c *Client) { c.retries, c.backoff = n, backoff }
+}
+
+// WithTimeout bounds every attempt of a request by d. The context passed to
+// a method bounds the request as a whole, retries included.
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.timeout = d }
+}
+
+// New returns a client of the API of the product page at baseURL, e.g.
+// "http://localhost:12345".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	base, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if base.Scheme != "http" && base.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL %q: want an http or https URL", baseURL)
+	}
+	c := &Client{
+		base:    base,
+		http:    http.DefaultClient,
+		header:  http.Header{"User-Agent": {userAgent}},
+		retries: DefaultRetries,
+		backoff: DefaultBackoff,
+		timeout: DefaultTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// Error is an error response of the API.
+type Error struct {
+	Method     string
+	Path       string
+	StatusCode int
+	Problem    Problem // the body of the response, if it was a problem
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s %s: %d %s", e.Method, e.Path, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.Problem.Detail != "" {
+		msg += ": " + e.Problem.Detail
+	}
+	return msg
+}
+
+// Products returns the products.
+func (c *Client) Products(ctx context.Context) ([]Product, error) {
+	var products []Product
+	err := c.do(ctx, http.MethodGet, BasePath+"/products", nil, &products)
+	return products, err
+}
+
+// SearchProducts returns the products whose title, author or description
+// contain every word of query, ignoring case.
+func (c *Client) SearchProducts(ctx context.Context, query string) ([]Product, error) {
+	var products []Product
+	err := c.do(ctx, http.MethodGet, BasePath+"/products?q="+url.QueryEscape(query), nil, &products)
+	return products, err
+}
+
+// Search returns the products matching every word of query, best first, and
+// the first limit of them as hits; the API serves 10 hits if limit is 0. A
+// word matches the words it's equal to, a prefix of, or a typo or two away
+// from.
+func (c *Client) Search(ctx context.Context, query string, limit int) (SearchResults, error) {
+	params := url.Values{"q": {query}}
+	if limit != 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+	var results SearchResults
+	err := c.do(ctx, http.MethodGet, BasePath+"/search?"+params.Encode(), nil, &results)
+	return results, err
+}
+
+// Details returns the book details of product id.
+func (c *Client) Details(ctx context.Context, id int) (BookDetails, error) {
+	var details BookDetails
+	err := c.do(ctx, http.MethodGet, productPath(id, ""), nil, &details)
+	return details, err
+}
+
+// Reviews returns the reviews of product id.
+func (c *Client) Reviews(ctx context.Context, id int) (Reviews, error) {
+	var reviews Reviews
+	err := c.do(ctx, http.MethodGet, productPath(id, "/reviews"), nil, &reviews)
+	return reviews, err
+}
+
+// Ratings returns the ratings of product id.
+func (c *Client) Ratings(ctx context.Context, id int) (Ratings, error) {
+	var ratings Ratings
+	err := c.do(ctx, http.MethodGet, productPath(id, "/ratings"), nil, &ratings)
+	return ratings, err
+}
+
+// Recommendations returns up to n products that the readers of product id
+// also liked, most similar first; the API serves 4 if n is 0.
+func (c *Client) Recommendations(ctx context.Context, id, n int) ([]Recommendation, error) {
+	path := productPath(id, "/recommendations")
+	if n != 0 {
+		path += "?n=" + strconv.Itoa(n)
+	}
+	var recs []Recommendation
+	err := c.do(ctx, http.MethodGet, path, nil, &recs)
+	return recs, err
+}
+
+// PostRatings sets the ratings of product id, stars by reviewer, and
+// returns the stored ratings. Posting the same ratings twice stores them
+// once, so it's retried like the other requests.
+func (c *Client) PostRatings(ctx context.Context, id int, ratings map[string]int) (Ratings, error) {
+	body, err := json.Marshal(ratings)
+	if err != nil {
+		return Ratings{}, err
+	}
+	var stored Ratings
+	err = c.do(ctx, http.MethodPost, productPath(id, "/ratings"), body, &stored)
+	return stored, err
+}
+
+// CatalogProduct returns product id of the catalog. Like the other catalog
+// methods, it needs the admin token of the product page, see WithToken.
+func (c *Client) CatalogProduct(ctx context.Context, id int) (CatalogProduct, error) {
+	var product CatalogProduct
+	err := c.do(ctx, http.MethodGet, adminProductPath(id), nil, &product)
+	return product, err
+}
+
+// CreateProduct adds p to the catalog and returns it with its new ID. The ID
+// of p is ignored. It isn't retried, as every call adds a product.
+func (c *Client) CreateProduct(ctx context.Context, p CatalogProduct) (CatalogProduct, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return CatalogProduct{}, err
+	}
+	var created CatalogProduct
+	err = c.attempt(ctx, http.MethodPost, BasePath+"/admin/products", nil, body, &created, []int{http.StatusCreated})
+	return created, err
+}
+
+// UpdateProduct replaces product p.ID of the catalog with p and returns it.
+func (c *Client) UpdateProduct(ctx context.Context, p CatalogProduct) (CatalogProduct, error) {
+	body, err := json.Marshal(p)
+	if err != nil {
+		return CatalogProduct{}, err
+	}
+	var updated CatalogProduct
+	err = c.do(ctx, http.MethodPut, adminProductPath(p.ID), body, &updated)
+	return updated, err
+}
+
+// DeleteProduct deletes product id from the catalog. It isn't retried, as a
+// repeated call fails with a 404.
+func (c *Client) DeleteProduct(ctx context.Context, id int) error {
+	return c.attempt(ctx, http.MethodDelete, adminProductPath(id), nil, nil, nil, []int{http.StatusNoContent})
+}
+
+// ProductStock returns the units of product id that can be ordered.
+func (c *Client) ProductStock(ctx context.Context, id int) (ProductStock, error) {
+	var stock ProductStock
+	err := c.do(ctx, http.MethodGet, productPath(id, "/stock"), nil, &stock)
+	return stock, err
+}
+
+// Price returns the price of product id in currency, an ISO 4217 code such
+// as "EUR". If currency is empty, the API picks it from the Accept-Language
+// header set with WithHeader, if any, or else serves it in US dollars.
+func (c *Client) Price(ctx context.Context, id int, currency string) (Price, error) {
+	path := productPath(id, "/price")
+	if currency != "" {
+		path += "?currency=" + url.QueryEscape(currency)
+	}
+	var price Price
+	err := c.do(ctx, http.MethodGet, path, nil, &price)
+	return price, err
+}
+
+// Cart returns the contents of cart id. Carts are created by adding to them,
+// with any ID of 1 to 64 letters, digits, '-' and '_'.
+func (c *Client) Cart(ctx context.Context, id string) (Cart, error) {
+	var cart Cart
+	err := c.do(ctx, http.MethodGet, cartPath(id, ""), nil, &cart)
+	return cart, err
+}
+
+// AddToCart adds quantity units of a product to cart id and returns its
+// contents. It isn't retried, as every call adds units.
+func (c *Client) AddToCart(ctx context.Context, id string, productID, quantity int) (Cart, error) {
+	body, err := json.Marshal(map[string]int{"product_id": productID, "quantity": quantity})
+	if err != nil {
+		return Cart{}, err
+	}
+	var cart Cart
+	err = c.attempt(ctx, http.MethodPost, cartPath(id, "/items"), nil, body, &cart, nil)
+	return cart, err
+}
+
+// SetCartItem sets the units of a product in cart id, removing it for 0, and
+// returns its contents.
+func (c *Client) SetCartItem(ctx context.Context, id string, productID, quantity int) (Cart, error) {
+	body, err := json.Marshal(map[string]int{"quantity": quantity})
+	if err != nil {
+		return Cart{}, err
+	}
+	var cart Cart
+	err = c.do(ctx, http.MethodPut, cartPath(id, "/items/"+strconv.Itoa(productID)), body, &cart)
+	return cart, err
+}
+
+// RemoveFromCart removes a product from cart id and returns its contents.
+func (c *Client) RemoveFromCart(ctx context.Context, id string, productID int) (Cart, error) {
+	var cart Cart
+	err := c.do(ctx, http.MethodDelete, cartPath(id, "/items/"+strconv.Itoa(productID)), nil, &cart)
+	return cart, err
+}
+
+// Checkout orders the items of cart id and empties it. The idempotency key
+// identifies the purchase: pick a new one, e.g. a random one, for every
+// purchase, and the same one to retry it. A checkout with the key of a
+// previous one returns the same order without ordering again, so it's
+// retried like the other requests. It fails with a 409 *Error if the cart is
+// empty, an item is out of stock or the order failed.
+func (c *Client) Checkout(ctx context.Context, id, idempotencyKey string) (Order, error) {
+	header := http.Header{"Idempotency-Key": {idempotencyKey}}
+	var order Order
+	err := c.retry(ctx, func() error {
+		return c.attempt(ctx, http.MethodPost, cartPath(id, "/checkout"), header, nil, &order, []int{http.StatusCreated})
+	})
+	return order, err
+}
+
+// Order returns order id.
+func (c *Client) Order(ctx context.Context, id string) (Order, error) {
+	var order Order
+	err := c.do(ctx, http.MethodGet, BasePath+"/orders/"+url.PathEscape(id), nil, &order)
+	return order, err
+}
+
+// Readiness returns the readiness of the product page and the components it
+// calls. A product page that isn't ready isn't an error: check Ready.
+func (c *Client) Readiness(ctx context.Context) (Readiness, error) {
+	var readiness Readiness
+	err := c.do(ctx, http.MethodGet, "/readyz", nil, &readiness, http.StatusServiceUnavailable)
+	return readiness, err
+}
+
+// productPath returns the API path of product id, followed by suffix.
+func productPath(id int, suffix string) string {
+	return BasePath + "/products/" + strconv.Itoa(id) + suffix
+}
+
+// adminProductPath returns the admin API path of product id.
+func adminProductPath(id int) string {
+	return BasePath + "/admin/products/" + strconv.Itoa(id)
+}
+
+// cartPath returns the API path of cart id, followed by suffix.
+func cartPath(id, suffix string) string {
+	return BasePath + "/carts/" + url.PathEscape(id) + suffix
+}
+
+// do sends a request, retrying it if needed, and decodes the JSON response
+// into out. Responses with status 200 or one of accept are decoded; others
+// are returned as *Error.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out any, accept ...int) error {
+	return c.retry(ctx, func() error {
+		return c.attempt(ctx, method, path, nil, body, out, accept)
+	})
+}
+
+// retry calls attempt, which sends a request once, until it succeeds or
+// fails with an error that isn't retriable, up to 1+c.retries times.
+func (c *Client) retry(ctx context.Context, attempt func() error) error {
+	backoff := c.backoff
+	for n := 0; ; n++ {
+		err := attempt()
+		if err == nil || n >= c.retries || !retriable(err) || ctx.Err() != nil {
+			return err
+		}
+		// Wait a random fraction of the backoff at most, so that clients
+		// don't retry in lockstep.
+		timer := time.NewTimer(backoff/2 + rand.N(backoff/2+1))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// attempt sends a request once, with header on top of the client's. A nil
+// out discards the response.
+func (c *Client) attempt(ctx context.Context, method, path string, header http.Header, body []byte, out any, accept []int) error {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base.String()+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && !slices.Contains(accept, resp.StatusCode) {
+		e := &Error{Method: method, Path: path, StatusCode: resp.StatusCode}
+		// The body is a problem, unless something other than the product
+		// page answered.
+		json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&e.Problem)
+		return e
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, path, err)
+	}
+	return nil
+}
+
+// retriable reports whether a request that failed with err may succeed if
+// retried.
+func retriable(err error) bool {
+	var e *Error
+	if errors.As(err, &e) {
+		switch e.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	// The product page couldn't be reached, or the attempt timed out.
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
@@ -0,0 +1,199 @@
+package apiv1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newClient returns a client of srv that retries without waiting.
+func newClient(t *testing.T, srv *httptest.Server, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(srv.URL, append([]Option{WithRetries(DefaultRetries, time.Millisecond)}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// problem writes a problem response.
+func problem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `{"type": "about:blank", "status": %d, "detail": %q}`, status, detail)
+}
+
+func TestNew(t *testing.T) {
+	for _, base := range []string{"", "localhost:12345", "ftp://localhost", "http://%"} {
+		if _, err := New(base); err == nil {
+			t.Errorf("New(%q) succeeded, want error", base)
+		}
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/products/3/ratings" || r.Method != http.MethodPost {
+			t.Errorf("got %s %s, want POST /api/v1/products/3/ratings", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("Authorization = %q, want %q", got, "Bearer secret")
+		}
+		if got := r.Header.Get("X-Tenant"); got != "books" {
+			t.Errorf("X-Tenant = %q, want %q", got, "books")
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+		w.Write([]byte(`{"id": 3, "ratings": {"Reviewer1": 2}}`))
+	}))
+	defer srv.Close()
+
+	c := newClient(t, srv, WithToken("secret"), WithHeader("X-Tenant", "books"))
+	got, err := c.PostRatings(context.Background(), 3, map[string]int{"Reviewer1": 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.ID != 3 || got.Ratings["Reviewer1"] != 2 {
+		t.Errorf("PostRatings() = %+v, want Reviewer1's 2 stars for product 3", got)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		retries  int
+		failures int // responses that fail before one succeeds
+		status   int // of the failures
+		attempts int32
+		wantErr  bool
+	}{
+		{"success", 2, 0, 0, 1, false},
+		{"retried", 2, 2, http.StatusServiceUnavailable, 3, false},
+		{"bad gateway", 2, 1, http.StatusBadGateway, 2, false},
+		{"out of retries", 1, 2, http.StatusServiceUnavailable, 2, true},
+		{"not retriable", 2, 1, http.StatusBadRequest, 1, true},
+		{"no retries", 0, 1, http.StatusTooManyRequests, 1, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if int(attempts.Add(1)) <= test.failures {
+					problem(w, test.status, "try again")
+					return
+				}
+				w.Write([]byte(`[{"id": 0, "title": "The Comedy of Errors"}]`))
+			}))
+			defer srv.Close()
+
+			c := newClient(t, srv, WithRetries(test.retries, time.Millisecond))
+			products, err := c.Products(context.Background())
+			if got := attempts.Load(); got != test.attempts {
+				t.Errorf("%d attempts, want %d", got, test.attempts)
+			}
+			if test.wantErr {
+				var e *Error
+				if !errors.As(err, &e) || e.StatusCode != test.status || e.Problem.Detail != "try again" {
+					t.Errorf("Products() error = %v, want a %d problem", err, test.status)
+				}
+				return
+			}
+			if err != nil || len(products) != 1 || products[0].Title != "The Comedy of Errors" {
+				t.Errorf("Products() = %+v, %v, want The Comedy of Errors", products, err)
+			}
+		})
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) == 1 {
+			// The first attempt times out.
+			<-r.Context().Done()
+			return
+		}
+		w.Write([]byte(`{"id": 1, "author": "William Shakespeare"}`))
+	}))
+	defer srv.Close()
+
+	c := newClient(t, srv, WithTimeout(50*time.Millisecond))
+	got, err := c.Details(context.Background(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Author != "William Shakespeare" || attempts.Load() != 2 {
+		t.Errorf("Details() = %+v after %d attempts, want the details after 2", got, attempts.Load())
+	}
+
+	// A cancelled context isn't retried.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	attempts.Store(0)
+	if _, err := c.Details(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Details() with a cancelled context: %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestReadiness(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status": "not ready", "components": {"details": {"status": "unavailable", "error": "details are down"}}}`))
+	}))
+	defer srv.Close()
+
+	got, err := newClient(t, srv).Readiness(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Ready() || got.Components["details"].Error != "details are down" {
+		t.Errorf("Readiness() = %+v, want details unavailable", got)
+	}
+}
+
+func TestCheckoutRetries(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/carts/alice/checkout" || r.Method != http.MethodPost {
+			t.Errorf("got %s %s, want POST /api/v1/carts/alice/checkout", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Idempotency-Key"); got != "key-1" {
+			t.Errorf("Idempotency-Key = %q, want key-1", got)
+		}
+		if attempts.Add(1) == 1 {
+			problem(w, http.StatusBadGateway, "orders are down")
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": "0123456789abcdef", "cart_id": "alice", "status": "placed"}`))
+	}))
+	defer srv.Close()
+
+	// Every attempt carries the same key, so a retry places a single order.
+	c := newClient(t, srv)
+	order, err := c.Checkout(context.Background(), "alice", "key-1")
+	if err != nil || order.Status != "placed" || attempts.Load() != 2 {
+		t.Errorf("Checkout() = %+v, %v after %d attempts, want it placed after 2", order, err, attempts.Load())
+	}
+
+}
+
+func TestAddToCartIsNotRetried(t *testing.T) {
+	var attempts atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		problem(w, http.StatusBadGateway, "cart is down")
+	}))
+	defer srv.Close()
+
+	// A retry would add the units twice if the first attempt did.
+	if _, err := newClient(t, srv).AddToCart(context.Background(), "alice", 1, 1); err == nil || attempts.Load() != 1 {
+		t.Errorf("AddToCart() = %v after %d attempts, want an error after 1", err, attempts.Load())
+	}
+}
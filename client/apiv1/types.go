@@ -0,0 +1,181 @@
+package apiv1
+
+import "time"
+
+// Product is a product of the bookstore.
+type Product struct {
+	ID              int    `json:"id"`
+	Title           string `json:"title"`
+	DescriptionHTML string `json:"description_html"`
+}
+
+// CatalogProduct is a product as the catalog stores it, with its book
+// details.
+type CatalogProduct struct {
+	ID              int    `json:"id"`
+	Title           string `json:"title"`
+	DescriptionHTML string `json:"description_html"`
+	Author          string `json:"author"`
+	Year            int    `json:"year"`
+	Type            string `json:"type"`
+	Pages           int    `json:"pages"`
+	Publisher       string `json:"publisher"`
+	Language        string `json:"language"`
+	ISBN10          string `json:"ISBN-10"`
+	ISBN13          string `json:"ISBN-13"`
+}
+
+// SearchResults are the results of a search.
+type SearchResults struct {
+	Query string      `json:"query"`
+	Total int         `json:"total"` // the number of matching products
+	Hits  []SearchHit `json:"hits"`  // the best ones, best first
+}
+
+// SearchHit is a product matching a search. TitleHTML and SnippetHTML, an
+// excerpt of the description, are HTML with the words matching the query in
+// <mark> elements.
+type SearchHit struct {
+	ProductID   int     `json:"product_id"`
+	Title       string  `json:"title"`
+	Author      string  `json:"author"`
+	Score       float64 `json:"score"` // relevance; higher is better
+	TitleHTML   string  `json:"title_html"`
+	SnippetHTML string  `json:"snippet_html"`
+}
+
+// Recommendation is a product that the readers of another product also
+// liked.
+type Recommendation struct {
+	ProductID int     `json:"product_id"`
+	Title     string  `json:"title"`
+	Author    string  `json:"author"`
+	Score     float64 `json:"score"`   // similarity, from 0 to 1; higher is better
+	Readers   int     `json:"readers"` // the number who rated both products
+}
+
+// BookDetails are the details of the book of a product.
+type BookDetails struct {
+	ID        int    `json:"id"`
+	Author    string `json:"author"`
+	Year      int    `json:"year"`
+	Type      string `json:"type"`
+	Pages     int    `json:"pages"`
+	Publisher string `json:"publisher"`
+	Language  string `json:"language"`
+	ISBN10    string `json:"ISBN-10"`
+	ISBN13    string `json:"ISBN-13"`
+}
+
+// Reviews are the reviews of a product.
+type Reviews struct {
+	ID      string   `json:"id"` // product ID
+	Reviews []Review `json:"reviews"`
+
+	// Deprecated: PodName and ClusterName tell where the reviews were
+	// served, and are only kept for compatibility with the original
+	// Bookinfo.
+	PodName     string `json:"podname"`
+	ClusterName string `json:"clustername"`
+}
+
+// Review is a review of a product.
+type Review struct {
+	Reviewer string `json:"reviewer"`
+	Text     string `json:"text"`
+	Rating   Rating `json:"rating,omitempty"` // zero if ratings are disabled
+}
+
+// Rating is the rating of a review. Stars is -1 if ratings are unavailable,
+// and Color then holds the reason.
+type Rating struct {
+	Stars int    `json:"stars"`
+	Color string `json:"color"`
+}
+
+// Ratings are the ratings of a product: stars, from 0 to MaxStars, by
+// reviewer.
+type Ratings struct {
+	ID      int            `json:"id"`
+	Ratings map[string]int `json:"ratings"`
+}
+
+// MaxStars is the highest rating.
+const MaxStars = 5
+
+// ProductStock is the stock of a product.
+type ProductStock struct {
+	ProductID int `json:"product_id"`
+	Available int `json:"available"` // units that can be ordered
+}
+
+// Price is the price of a product in a currency.
+type Price struct {
+	ProductID int    `json:"product_id"`
+	Currency  string `json:"currency"` // ISO 4217 code
+	// List is the list price, as a decimal with the number of decimals of
+	// the currency, e.g. "12.99" for USD and "1938" for JPY, and Discounted
+	// the price to pay, the list price less the discount.
+	List            string  `json:"list"`
+	Discounted      string  `json:"discounted"`
+	DiscountPercent int     `json:"discount_percent"` // 0 if none
+	Rate            float64 `json:"rate"`             // units of Currency per US dollar
+}
+
+// Cart is a shopping cart.
+type Cart struct {
+	ID       string     `json:"id"`
+	Items    []CartItem `json:"items"`    // by product ID
+	Quantity int        `json:"quantity"` // the units of every item
+}
+
+// CartItem is a product in a cart, or in an order.
+type CartItem struct {
+	ProductID int    `json:"product_id"`
+	Title     string `json:"title"`
+	Quantity  int    `json:"quantity"`
+}
+
+// MaxQuantity is the most units of a product a cart may hold.
+const MaxQuantity = 99
+
+// Order is an order of the items of a cart.
+type Order struct {
+	ID       string     `json:"id"`
+	CartID   string     `json:"cart_id"`
+	Items    []CartItem `json:"items"`
+	Quantity int        `json:"quantity"`         // the units of every item
+	Status   string     `json:"status"`           // "placed" or "failed"
+	Reason   string     `json:"reason,omitempty"` // why it failed
+	Created  time.Time  `json:"created"`
+}
+
+// Readiness is the readiness of the product page and the components it
+// calls.
+type Readiness struct {
+	Status     string                     `json:"status"` // "ready" or "not ready"
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// Ready reports whether the product page and its components are ready.
+func (r Readiness) Ready() bool {
+	return r.Status == "ready"
+}
+
+// ComponentHealth is the readiness of a component.
+type ComponentHealth struct {
+	Status   string `json:"status"` // "ok", "unhealthy" or "unavailable"
+	Error    string `json:"error,omitempty"`
+	Healthy  *bool  `json:"healthy,omitempty"`
+	Database string `json:"database,omitempty"`
+}
+
+// Problem is the RFC 7807 problem details body of an error response.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
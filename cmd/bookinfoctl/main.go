@@ -0,0 +1,521 @@
+// Command bookinfoctl talks to the REST API of a Bookinfo product page.
+//
+// Usage:
+//
+//	bookinfoctl [flags] products                          list the products
+//	bookinfoctl [flags] search <word>...                  search the products
+//	bookinfoctl [flags] product <id>                      show a product's details, price, reviews, ratings and recommendations
+//	bookinfoctl [flags] price <id> [currency]             show a product's price
+//	bookinfoctl [flags] rate <id> <reviewer>=<stars>...   set a product's ratings
+//	bookinfoctl [flags] cart <cart>                       show a cart
+//	bookinfoctl [flags] add <cart> <id> [quantity]        add a product to a cart
+//	bookinfoctl [flags] remove <cart> <id>                remove a product from a cart
+//	bookinfoctl [flags] checkout [-key k] <cart>          order the items of a cart
+//	bookinfoctl [flags] order <order>                     show an order
+//	bookinfoctl [flags] health [-interval d] [-count n]   watch the readiness
+//
+// The product page is taken from -addr or $BOOKINFO_ADDR, and the bearer
+// token, if any, from -token or $BOOKINFO_TOKEN.
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/client/apiv1"
+)
+
+var (
+	addr    = flag.String("addr", envOr("BOOKINFO_ADDR", "http://localhost:12345"), "Base URL of the product page")
+	token   = flag.String("token", os.Getenv("BOOKINFO_TOKEN"), "Bearer token sent with every request")
+	timeout = flag.Duration("timeout", apiv1.DefaultTimeout, "Timeout of every attempt of a request")
+	retries = flag.Int("retries", apiv1.DefaultRetries, "Retries of a failed request")
+	jsonOut = flag.Bool("json", false, "Print JSON instead of tables")
+)
+
+// commands are the subcommands, by name.
+var commands = map[string]func(ctx context.Context, c *apiv1.Client, args []string) error{
+	"products": products,
+	"search":   search,
+	"product":  product,
+	"price":    price,
+	"rate":     rate,
+	"cart":     showCart,
+	"add":      add,
+	"remove":   remove,
+	"checkout": checkout,
+	"order":    showOrder,
+	"health":   health,
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flag.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "bookinfoctl: unknown command %q\n", flag.Arg(0))
+		usage()
+		os.Exit(2)
+	}
+
+	opts := []apiv1.Option{apiv1.WithTimeout(*timeout), apiv1.WithRetries(*retries, apiv1.DefaultBackoff)}
+	if *token != "" {
+		opts = append(opts, apiv1.WithToken(*token))
+	}
+	c, err := apiv1.New(*addr, opts...)
+	if err != nil {
+		fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := cmd(ctx, c, flag.Args()[1:]); err != nil && !errors.Is(err, context.Canceled) {
+		fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, `Usage:
+  bookinfoctl [flags] products
+  bookinfoctl [flags] search <word>...
+  bookinfoctl [flags] product <id>
+  bookinfoctl [flags] price <id> [currency]
+  bookinfoctl [flags] rate <id> <reviewer>=<stars>...
+  bookinfoctl [flags] cart <cart>
+  bookinfoctl [flags] add <cart> <id> [quantity]
+  bookinfoctl [flags] remove <cart> <id>
+  bookinfoctl [flags] checkout [-key k] <cart>
+  bookinfoctl [flags] order <order>
+  bookinfoctl [flags] health [-interval d] [-count n]
+
+Flags:
+`)
+	flag.PrintDefaults()
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "bookinfoctl: %v\n", err)
+	os.Exit(1)
+}
+
+// products lists the products.
+func products(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 0 {
+		return errors.New("usage: products")
+	}
+	products, err := c.Products(ctx)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(os.Stdout, products)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTITLE")
+	for _, p := range products {
+		fmt.Fprintf(tw, "%d\t%s\n", p.ID, p.Title)
+	}
+	return tw.Flush()
+}
+
+// search lists the best products matching the words of a query.
+func search(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: search <word>...")
+	}
+	results, err := c.Search(ctx, strings.Join(args, " "), 0)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(os.Stdout, results)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSCORE\tTITLE\tAUTHOR")
+	for _, hit := range results.Hits {
+		fmt.Fprintf(tw, "%d\t%.3f\t%s\t%s\n", hit.ProductID, hit.Score, hit.Title, hit.Author)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	if results.Total > len(results.Hits) {
+		fmt.Printf("%d of %d matching products\n", len(results.Hits), results.Total)
+	}
+	return nil
+}
+
+// product shows the details, price, reviews, ratings and recommendations of
+// a product.
+func product(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: product <id>")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	book, err := c.Details(ctx, id)
+	if err != nil {
+		return err
+	}
+	// Products added to the catalog have no price until the price list
+	// gets one.
+	var bookPrice *apiv1.Price
+	p, err := c.Price(ctx, id, "")
+	var e *apiv1.Error
+	switch {
+	case err == nil:
+		bookPrice = &p
+	case !errors.As(err, &e) || e.StatusCode != http.StatusNotFound:
+		return err
+	}
+	bookReviews, err := c.Reviews(ctx, id)
+	if err != nil {
+		return err
+	}
+	bookRatings, err := c.Ratings(ctx, id)
+	if err != nil {
+		return err
+	}
+	recs, err := c.Recommendations(ctx, id, 0)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(os.Stdout, map[string]any{"details": book, "price": bookPrice, "reviews": bookReviews.Reviews, "ratings": bookRatings.Ratings, "recommendations": recs})
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Author:\t%s\n", book.Author)
+	fmt.Fprintf(tw, "Year:\t%d\n", book.Year)
+	fmt.Fprintf(tw, "Type:\t%s\n", book.Type)
+	fmt.Fprintf(tw, "Pages:\t%d\n", book.Pages)
+	fmt.Fprintf(tw, "Publisher:\t%s\n", book.Publisher)
+	fmt.Fprintf(tw, "Language:\t%s\n", book.Language)
+	fmt.Fprintf(tw, "ISBN-10:\t%s\n", book.ISBN10)
+	fmt.Fprintf(tw, "ISBN-13:\t%s\n", book.ISBN13)
+	if bookPrice != nil {
+		fmt.Fprintf(tw, "Price:\t%s\n", formatPrice(*bookPrice))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Println()
+	for _, review := range bookReviews.Reviews {
+		fmt.Printf("%s %s\n  %s\n", review.Reviewer, stars(review.Rating), review.Text)
+	}
+	fmt.Println()
+	fmt.Println("Ratings:")
+	for _, reviewer := range sortedKeys(bookRatings.Ratings) {
+		fmt.Printf("  %s: %d\n", reviewer, bookRatings.Ratings[reviewer])
+	}
+	if len(recs) > 0 {
+		fmt.Println()
+		fmt.Println("You might also like:")
+		for _, rec := range recs {
+			fmt.Printf("  %d %s, by %s\n", rec.ProductID, rec.Title, rec.Author)
+		}
+	}
+	return nil
+}
+
+// stars renders a rating.
+func stars(r apiv1.Rating) string {
+	switch {
+	case r == apiv1.Rating{}:
+		return ""
+	case r.Stars < 0:
+		return "(" + r.Color + ")"
+	}
+	n := min(r.Stars, apiv1.MaxStars)
+	return strings.Repeat("★", n) + strings.Repeat("☆", apiv1.MaxStars-n)
+}
+
+// price shows the price of a product, in US dollars unless told otherwise.
+func price(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 1 && len(args) != 2 {
+		return errors.New("usage: price <id> [currency]")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	var currency string
+	if len(args) == 2 {
+		currency = args[1]
+	}
+	p, err := c.Price(ctx, id, currency)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(os.Stdout, p)
+	}
+	fmt.Println(formatPrice(p))
+	return nil
+}
+
+// formatPrice renders a price, with its discount if any.
+func formatPrice(p apiv1.Price) string {
+	if p.DiscountPercent == 0 {
+		return p.Discounted + " " + p.Currency
+	}
+	return fmt.Sprintf("%s %s (%d%% off %s)", p.Discounted, p.Currency, p.DiscountPercent, p.List)
+}
+
+// rate sets the ratings of a product.
+func rate(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) < 2 {
+		return errors.New("usage: rate <id> <reviewer>=<stars>...")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	ratings := map[string]int{}
+	for _, arg := range args[1:] {
+		reviewer, s, ok := strings.Cut(arg, "=")
+		n, err := strconv.Atoi(s)
+		if !ok || reviewer == "" || err != nil {
+			return fmt.Errorf("invalid rating %q: want <reviewer>=<stars>", arg)
+		}
+		ratings[reviewer] = n
+	}
+	stored, err := c.PostRatings(ctx, id, ratings)
+	if err != nil {
+		return err
+	}
+	if *jsonOut {
+		return printJSON(os.Stdout, stored)
+	}
+	for _, reviewer := range sortedKeys(stored.Ratings) {
+		fmt.Printf("%s: %d\n", reviewer, stored.Ratings[reviewer])
+	}
+	return nil
+}
+
+// showCart shows the contents of a cart.
+func showCart(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: cart <cart>")
+	}
+	cart, err := c.Cart(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printCart(cart)
+}
+
+// add adds units of a product to a cart, 1 unless told otherwise.
+func add(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 2 && len(args) != 3 {
+		return errors.New("usage: add <cart> <id> [quantity]")
+	}
+	id, err := parseID(args[1])
+	if err != nil {
+		return err
+	}
+	quantity := 1
+	if len(args) == 3 {
+		quantity, err = strconv.Atoi(args[2])
+		if err != nil || quantity < 1 || quantity > apiv1.MaxQuantity {
+			return fmt.Errorf("invalid quantity %q: want 1 to %d", args[2], apiv1.MaxQuantity)
+		}
+	}
+	cart, err := c.AddToCart(ctx, args[0], id, quantity)
+	if err != nil {
+		return err
+	}
+	return printCart(cart)
+}
+
+// remove removes a product from a cart.
+func remove(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 2 {
+		return errors.New("usage: remove <cart> <id>")
+	}
+	id, err := parseID(args[1])
+	if err != nil {
+		return err
+	}
+	cart, err := c.RemoveFromCart(ctx, args[0], id)
+	if err != nil {
+		return err
+	}
+	return printCart(cart)
+}
+
+// printCart prints the contents of a cart.
+func printCart(cart apiv1.Cart) error {
+	if *jsonOut {
+		return printJSON(os.Stdout, cart)
+	}
+	if len(cart.Items) == 0 {
+		fmt.Printf("Cart %s is empty\n", cart.ID)
+		return nil
+	}
+	return printItems(cart.Items, cart.Quantity)
+}
+
+// checkout orders the items of a cart. Without -key, the checkout gets a new
+// idempotency key, which is printed so that it can be retried.
+func checkout(ctx context.Context, c *apiv1.Client, args []string) error {
+	fs := flag.NewFlagSet("checkout", flag.ContinueOnError)
+	key := fs.String("key", "", "Idempotency key of the purchase; a new one if empty")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("usage: checkout [-key k] <cart>")
+	}
+	if *key == "" {
+		b := make([]byte, 16)
+		if _, err := rand.Read(b); err != nil {
+			return err
+		}
+		*key = hex.EncodeToString(b)
+		fmt.Fprintf(os.Stderr, "Idempotency key: %s\n", *key)
+	}
+	order, err := c.Checkout(ctx, fs.Arg(0), *key)
+	if err != nil {
+		return err
+	}
+	return printOrder(order)
+}
+
+// showOrder shows an order.
+func showOrder(ctx context.Context, c *apiv1.Client, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: order <order>")
+	}
+	order, err := c.Order(ctx, args[0])
+	if err != nil {
+		return err
+	}
+	return printOrder(order)
+}
+
+// printOrder prints an order.
+func printOrder(order apiv1.Order) error {
+	if *jsonOut {
+		return printJSON(os.Stdout, order)
+	}
+	fmt.Printf("Order %s %s on %s\n", order.ID, order.Status, order.Created.Local().Format(time.DateTime))
+	if order.Reason != "" {
+		fmt.Printf("  %s\n", order.Reason)
+	}
+	fmt.Println()
+	return printItems(order.Items, order.Quantity)
+}
+
+// printItems prints the items of a cart or order, and their units.
+func printItems(items []apiv1.CartItem, quantity int) error {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tQUANTITY\tTITLE")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%d\t%d\t%s\n", item.ProductID, item.Quantity, item.Title)
+	}
+	fmt.Fprintf(tw, "\t%d\t\n", quantity)
+	return tw.Flush()
+}
+
+// health prints the readiness of the product page every interval, until
+// interrupted or count times.
+func health(ctx context.Context, c *apiv1.Client, args []string) error {
+	fs := flag.NewFlagSet("health", flag.ContinueOnError)
+	interval := fs.Duration("interval", 5*time.Second, "Time between checks")
+	count := fs.Int("count", 0, "Number of checks; 0 checks until interrupted")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for i := 0; *count == 0 || i < *count; i++ {
+		if i > 0 {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		readiness, err := c.Readiness(ctx)
+		now := time.Now().Format(time.TimeOnly)
+		switch {
+		case errors.Is(err, context.Canceled):
+			return err
+		case err != nil:
+			// Keep watching: the product page may come back.
+			fmt.Printf("%s error: %v\n", now, err)
+		case *jsonOut:
+			if err := printJSON(os.Stdout, readiness); err != nil {
+				return err
+			}
+		default:
+			var components []string
+			for _, name := range sortedKeys(readiness.Components) {
+				h := readiness.Components[name]
+				component := name + "=" + h.Status
+				if h.Error != "" {
+					component += " (" + h.Error + ")"
+				}
+				components = append(components, component)
+			}
+			fmt.Printf("%s %s %s\n", now, readiness.Status, strings.Join(components, " "))
+		}
+	}
+	return nil
+}
+
+// parseID parses a product ID.
+func parseID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 {
+		return 0, fmt.Errorf("invalid product ID %q", s)
+	}
+	return id, nil
+}
+
+// sortedKeys returns the keys of m, sorted.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// printJSON writes v as indented JSON.
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// envOr returns the value of the environment variable key, or def if it's
+// unset.
+func envOr(key, def string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,66 @@
+// Command bookinfostub serves stubs of the original Bookinfo details, reviews
+// and ratings services, for trying the remote adapters locally.
+//
+// Usage:
+//
+//	go run ./cmd/bookinfostub -details localhost:9091 -reviews localhost:9092 -ratings localhost:9093 -delay 2ms
+//
+// An empty address leaves the service out.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote/stubs"
+)
+
+var (
+	detailsAddr = flag.String("details", "localhost:9091", "Address of the details stub")
+	reviewsAddr = flag.String("reviews", "localhost:9092", "Address of the reviews stub")
+	ratingsAddr = flag.String("ratings", "localhost:9093", "Address of the ratings stub")
+	delay       = flag.Duration("delay", 0, "Delay added to every response, e.g. to model a network hop")
+)
+
+func main() {
+	flag.Parse()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	servers := map[string]*stubs.Stub{}
+	for addr, newStub := range map[*string]func() *stubs.Stub{
+		detailsAddr: stubs.NewDetails,
+		reviewsAddr: stubs.NewReviews,
+		ratingsAddr: stubs.NewRatings,
+	} {
+		if *addr != "" {
+			servers[*addr] = newStub()
+		}
+	}
+	if len(servers) == 0 {
+		log.Fatal("bookinfostub: no stubs to serve")
+	}
+
+	errs := make(chan error, len(servers))
+	for addr, stub := range servers {
+		stub.SetDelay(*delay)
+		srv := &http.Server{Addr: addr, Handler: stub, ReadHeaderTimeout: 10 * time.Second}
+		go func() { errs <- srv.ListenAndServe() }()
+		go func() {
+			<-ctx.Done()
+			srv.Close()
+		}()
+		log.Printf("Serving a stub on %s", addr)
+	}
+	for range servers {
+		if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("bookinfostub: %v", err)
+		}
+	}
+}
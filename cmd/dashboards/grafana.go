@@ -0,0 +1,197 @@
+package main
+
+import "fmt"
+
+// The subset of the Grafana dashboard JSON model used by the generated
+// dashboards. See https://grafana.com/docs/grafana/latest/dashboards/build-dashboards/view-dashboard-json-model/.
+
+type dashboard struct {
+	UID           string     `json:"uid"`
+	Title         string     `json:"title"`
+	Description   string     `json:"description,omitempty"`
+	Tags          []string   `json:"tags"`
+	Editable      bool       `json:"editable"`
+	Refresh       string     `json:"refresh"`
+	SchemaVersion int        `json:"schemaVersion"`
+	Time          timeRange  `json:"time"`
+	Templating    templating `json:"templating"`
+	Panels        []*panel   `json:"panels"`
+}
+
+type timeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type templating struct {
+	List []variable `json:"list"`
+}
+
+type variable struct {
+	Name       string      `json:"name"`
+	Label      string      `json:"label"`
+	Type       string      `json:"type"`
+	Query      string      `json:"query"`
+	Datasource *datasource `json:"datasource,omitempty"`
+	Refresh    int         `json:"refresh,omitempty"`
+	IncludeAll bool        `json:"includeAll"`
+	Multi      bool        `json:"multi"`
+	AllValue   string      `json:"allValue,omitempty"`
+	Sort       int         `json:"sort,omitempty"`
+}
+
+type datasource struct {
+	Type string `json:"type"`
+	UID  string `json:"uid"`
+}
+
+type panel struct {
+	ID          int          `json:"id"`
+	Type        string       `json:"type"`
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	GridPos     gridPos      `json:"gridPos"`
+	Datasource  *datasource  `json:"datasource,omitempty"`
+	Targets     []target     `json:"targets,omitempty"`
+	FieldConfig *fieldConfig `json:"fieldConfig,omitempty"`
+	Options     any          `json:"options,omitempty"`
+	Collapsed   bool         `json:"collapsed,omitempty"`
+	Panels      []*panel     `json:"panels,omitempty"`
+}
+
+type gridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type target struct {
+	RefID        string      `json:"refId"`
+	Expr         string      `json:"expr"`
+	LegendFormat string      `json:"legendFormat,omitempty"`
+	Datasource   *datasource `json:"datasource,omitempty"`
+}
+
+type fieldConfig struct {
+	Defaults  fieldDefaults `json:"defaults"`
+	Overrides []any         `json:"overrides"`
+}
+
+type fieldDefaults struct {
+	Unit     string   `json:"unit,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Decimals *int     `json:"decimals,omitempty"`
+}
+
+// prometheus is the data source of every query: the dashboard's
+// "datasource" variable, which defaults to the Prometheus data source
+// provisioned by observability/grafana.yaml.
+var prometheus = &datasource{Type: "prometheus", UID: "${datasource}"}
+
+// query is a PromQL expression and its legend.
+type query struct {
+	expr   string
+	legend string
+}
+
+// q returns a query; expr is formatted with args.
+func q(legend, expr string, args ...any) query {
+	return query{expr: fmt.Sprintf(expr, args...), legend: legend}
+}
+
+func targets(queries []query) []target {
+	ts := make([]target, len(queries))
+	for i, query := range queries {
+		ts[i] = target{
+			RefID:        string(rune('A' + i)),
+			Expr:         query.expr,
+			LegendFormat: query.legend,
+			Datasource:   prometheus,
+		}
+	}
+	return ts
+}
+
+func timeseries(title, unit string, queries ...query) *panel {
+	return &panel{
+		Type:        "timeseries",
+		Title:       title,
+		Datasource:  prometheus,
+		Targets:     targets(queries),
+		FieldConfig: &fieldConfig{Defaults: fieldDefaults{Unit: unit}, Overrides: []any{}},
+		Options: map[string]any{
+			"legend":  map[string]any{"displayMode": "list", "placement": "bottom", "showLegend": true},
+			"tooltip": map[string]any{"mode": "multi", "sort": "desc"},
+		},
+	}
+}
+
+func stat(title, unit string, queries ...query) *panel {
+	return &panel{
+		Type:        "stat",
+		Title:       title,
+		Datasource:  prometheus,
+		Targets:     targets(queries),
+		FieldConfig: &fieldConfig{Defaults: fieldDefaults{Unit: unit}, Overrides: []any{}},
+		Options: map[string]any{
+			"reduceOptions": map[string]any{"calcs": []string{"lastNotNull"}, "fields": "", "values": false},
+			"colorMode":     "value",
+			"graphMode":     "area",
+		},
+	}
+}
+
+func bargauge(title, unit string, queries ...query) *panel {
+	return &panel{
+		Type:        "bargauge",
+		Title:       title,
+		Datasource:  prometheus,
+		Targets:     targets(queries),
+		FieldConfig: &fieldConfig{Defaults: fieldDefaults{Unit: unit}, Overrides: []any{}},
+		Options: map[string]any{
+			"reduceOptions": map[string]any{"calcs": []string{"lastNotNull"}, "fields": "", "values": false},
+			"orientation":   "horizontal",
+			"displayMode":   "gradient",
+		},
+	}
+}
+
+// describe sets the panel's description.
+func (p *panel) describe(description string) *panel {
+	p.Description = description
+	return p
+}
+
+// layout places panels on Grafana's 24 column grid.
+type layout struct {
+	panels []*panel
+	x, y   int
+	rowH   int
+}
+
+// row starts a new row titled title.
+func (l *layout) row(title string) {
+	l.newline()
+	l.add(&panel{Type: "row", Title: title}, 24, 1)
+	l.newline()
+}
+
+// add places p, w columns wide and h units high, after the previous panel,
+// wrapping to a new line if it doesn't fit.
+func (l *layout) add(p *panel, w, h int) {
+	if l.x+w > 24 {
+		l.newline()
+	}
+	p.ID = len(l.panels) + 1
+	p.GridPos = gridPos{X: l.x, Y: l.y, W: w, H: h}
+	l.panels = append(l.panels, p)
+	l.x += w
+	l.rowH = max(l.rowH, h)
+}
+
+func (l *layout) newline() {
+	l.y += l.rowH
+	l.x, l.rowH = 0, 0
+}
@@ -0,0 +1,356 @@
+// Command dashboards generates the Grafana dashboards of Bookinfo.
+//
+// The dashboards query the metrics that customkube serves to Prometheus (see
+// observability/prometheus.yaml):
+//
+//   - HTTP: rate, errors and duration (RED) of every handler wrapped with
+//     weaver.InstrumentHandler, one row per handler label.
+//   - Components: calls, latency, errors and the share of remote calls of
+//     the methods of every component.
+//   - Ratings: the business metrics of the ratings component.
+//   - Business: the business metrics of details, reviews and the product
+//     page.
+//
+// Handler labels and components are read from the source, like the
+// placement configs, so the dashboards follow the code. Besides the
+// dashboard JSON files, it writes a ConfigMap that observability/grafana.yaml
+// mounts as Grafana's dashboard provisioning directory.
+//
+// Usage:
+//
+//	go run ./cmd/dashboards
+//	kubectl apply -f observability/
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cmd/internal/source"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	dir      = flag.String("dir", ".", "Root of the module to scan for components and handlers")
+	out      = flag.String("out", "observability/dashboards", "Directory where dashboard JSON files are written")
+	manifest = flag.String("manifest", "observability/grafana-dashboards.yaml", "ConfigMap manifest provisioning the dashboards in Grafana")
+)
+
+func main() {
+	flag.Parse()
+
+	components, err := source.Components(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	handlers, err := source.HandlerLabels(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	dashboards := []*dashboard{
+		httpDashboard(handlers),
+		componentsDashboard(components),
+	}
+	if ratings, ok := find(components, "ratings"); ok {
+		dashboards = append(dashboards, ratingsDashboard(ratings))
+	}
+	dashboards = append(dashboards, businessDashboard())
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, d := range dashboards {
+		data, err := json.MarshalIndent(d, "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		data = append(data, '\n')
+		name := d.UID + ".json"
+		if err := os.WriteFile(filepath.Join(*out, name), data, 0644); err != nil {
+			log.Fatal(err)
+		}
+		files[name] = string(data)
+	}
+	if err := writeManifest(*manifest, files); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Wrote %d dashboards to %s and %s\n", len(dashboards), *out, *manifest)
+}
+
+// find returns the component with the given short name.
+func find(components []string, short string) (string, bool) {
+	for _, c := range components {
+		if topology.ShortName(c) == short {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// sel selects the series of the deployments picked in the dashboard's
+// variables. Every placement deployed is a separate version.
+const sel = `app=~"$app", version=~"$version", pod=~"$pod"`
+
+// newDashboard returns a dashboard with the data source, app, version and pod
+// variables.
+func newDashboard(uid, title, description string, l *layout) *dashboard {
+	labelValues := func(name, label, filter string) variable {
+		return variable{
+			Name:       name,
+			Label:      label,
+			Type:       "query",
+			Query:      fmt.Sprintf(`label_values(up{job="serviceweaver"%s}, %s)`, filter, name),
+			Datasource: prometheus,
+			Refresh:    2, // on time range change
+			IncludeAll: true,
+			Multi:      true,
+			AllValue:   ".*",
+			Sort:       1,
+		}
+	}
+	return &dashboard{
+		UID:           uid,
+		Title:         title,
+		Description:   description,
+		Tags:          []string{"bookinfo", "serviceweaver"},
+		Editable:      true,
+		Refresh:       "30s",
+		SchemaVersion: 39,
+		Time:          timeRange{From: "now-1h", To: "now"},
+		Templating: templating{List: []variable{
+			{Name: "datasource", Label: "Data source", Type: "datasource", Query: "prometheus"},
+			labelValues("app", "App", ""),
+			labelValues("version", "Deployment", `, app=~"$app"`),
+			labelValues("pod", "Pod", `, app=~"$app", version=~"$version"`),
+		}},
+		Panels: l.panels,
+	}
+}
+
+// quantiles returns the p50, p95 and p99 of a latency histogram in
+// microseconds, in milliseconds, over the series selected by filter.
+func quantiles(histogram, filter string) []query {
+	var qs []query
+	for _, p := range []string{"50", "95", "99"} {
+		qs = append(qs, q("p"+p,
+			`histogram_quantile(0.%s, sum by (le) (rate(%s_bucket{%s, %s}[$__rate_interval]))) / 1000`,
+			p, histogram, filter, sel))
+	}
+	return qs
+}
+
+// httpDashboard shows the RED metrics of every instrumented handler.
+func httpDashboard(handlers []string) *dashboard {
+	l := &layout{}
+	l.row("All handlers")
+	l.add(timeseries("Requests/s by handler", "reqps",
+		q("{{label}}", `sum by (label) (rate(serviceweaver_http_request_count{%s}[$__rate_interval]))`, sel)), 8, 8)
+	l.add(timeseries("Error ratio by handler", "percentunit",
+		q("{{label}}", `sum by (label) (rate(serviceweaver_http_error_count{%s}[$__rate_interval])) / sum by (label) (rate(serviceweaver_http_request_count{%s}[$__rate_interval]))`, sel, sel)), 8, 8)
+	l.add(timeseries("p95 latency by handler", "ms",
+		q("{{label}}", `histogram_quantile(0.95, sum by (label, le) (rate(serviceweaver_http_request_latency_micros_bucket{%s}[$__rate_interval]))) / 1000`, sel)), 8, 8)
+
+	for _, h := range handlers {
+		filter := fmt.Sprintf("label=%q", h)
+		l.row("Handler " + h)
+		l.add(timeseries("Rate", "reqps",
+			q("requests/s", `sum(rate(serviceweaver_http_request_count{%s, %s}[$__rate_interval]))`, filter, sel)), 8, 7)
+		l.add(timeseries("Errors", "reqps",
+			q("{{code}}", `sum by (code) (rate(serviceweaver_http_error_count{%s, %s}[$__rate_interval]))`, filter, sel)), 8, 7)
+		l.add(timeseries("Duration", "ms",
+			quantiles("serviceweaver_http_request_latency_micros", filter)...), 8, 7)
+	}
+	return newDashboard("bookinfo-http", "Bookinfo / HTTP handlers",
+		"Rate, errors and duration of the handlers registered with weaver.InstrumentHandler.", l)
+}
+
+// componentsDashboard shows the method metrics of every component. Method
+// metrics are recorded by the caller, with remote="true" when the call
+// crossed process boundaries, so the share of remote calls shows what a
+// placement costs.
+func componentsDashboard(components []string) *dashboard {
+	l := &layout{}
+	l.row("Local and remote calls")
+	l.add(timeseries("Remote call ratio by component", "percentunit",
+		q("{{component}}", `sum by (component) (rate(serviceweaver_method_count{remote="true", %s}[$__rate_interval])) / sum by (component) (rate(serviceweaver_method_count{%s}[$__rate_interval]))`, sel, sel)).
+		describe("Share of the calls to each component that were remote."), 12, 8)
+	l.add(timeseries("Calls/s, local vs remote", "reqps",
+		q("remote={{remote}}", `sum by (remote) (rate(serviceweaver_method_count{%s}[$__rate_interval]))`, sel)), 12, 8)
+
+	l.row("Remote Bookinfo services")
+	l.add(timeseries("p95 latency, Service Weaver vs HTTP", "ms",
+		q("{{component}}.{{method}} weaver", `histogram_quantile(0.95, sum by (component, method, le) (rate(serviceweaver_method_latency_micros_bucket{method!~"Describe|Health", %s}[$__rate_interval]))) / 1000`, sel),
+		q("{{component}}.{{method}} http", `histogram_quantile(0.95, sum by (component, method, le) (rate(%s_bucket{%s}[$__rate_interval])))`, remoteLatencyMetric, sel)).
+		describe("Latency of the same methods called as components and through the HTTP adapters of package remote."), 12, 8)
+	l.add(timeseries("HTTP adapter calls/s", "reqps",
+		q("{{component}}.{{method}} error={{error}}", `sum by (component, method, error) (rate(%s_count{%s}[$__rate_interval]))`, remoteLatencyMetric, sel)).
+		describe("Calls to the original Bookinfo services that replace components."), 12, 8)
+
+	for _, c := range components {
+		filter := fmt.Sprintf("component=%q", c)
+		l.row("Component " + topology.ShortName(c))
+		l.add(timeseries("Calls/s by method", "reqps",
+			q("{{method}} remote={{remote}}", `sum by (method, remote) (rate(serviceweaver_method_count{%s, %s}[$__rate_interval]))`, filter, sel)), 6, 7)
+		l.add(timeseries("p95 latency by method", "ms",
+			q("{{method}} remote={{remote}}", `histogram_quantile(0.95, sum by (method, remote, le) (rate(serviceweaver_method_latency_micros_bucket{%s, %s}[$__rate_interval]))) / 1000`, filter, sel)), 6, 7)
+		l.add(timeseries("Errors/s by method", "reqps",
+			q("{{method}}", `sum by (method) (rate(serviceweaver_method_error_count{%s, %s}[$__rate_interval]))`, filter, sel)), 6, 7)
+		l.add(stat("Remote call ratio", "percentunit",
+			q("", `sum(rate(serviceweaver_method_count{remote="true", %s, %s}[$__rate_interval])) / sum(rate(serviceweaver_method_count{%s, %s}[$__rate_interval]))`, filter, sel, filter, sel)), 6, 7)
+	}
+	return newDashboard("bookinfo-components", "Bookinfo / Components",
+		"Calls, latency and errors of the component methods, and how many calls are remote in the deployed placement.", l)
+}
+
+// Business metrics of the ratings component.
+const (
+	ratingsPostedMetric      = "bookinfo_ratings_posted"      // counter of posted ratings, by stars
+	ratingsFallbacksMetric   = "bookinfo_ratings_fallbacks"   // counter of reviews served without ratings
+	ratingsUnavailableMetric = "bookinfo_ratings_unavailable" // gauge, 1 while chaos makes ratings fail
+	ratingsUnhealthyMetric   = "bookinfo_ratings_unhealthy"   // gauge, 1 while chaos marks ratings unhealthy
+)
+
+// ratingsDashboard shows the ratings business metrics next to the latency
+// of the ratings component.
+func ratingsDashboard(ratings string) *dashboard {
+	filter := fmt.Sprintf("component=%q", ratings)
+	l := &layout{}
+	l.row("Ratings posted")
+	l.add(timeseries("Ratings posted/s by stars", "reqps",
+		q("{{stars}} stars", `sum by (stars) (rate(%s{%s}[$__rate_interval]))`, ratingsPostedMetric, sel)), 12, 8)
+	l.add(bargauge("Star distribution", "short",
+		q("{{stars}} stars", `sum by (stars) (increase(%s{%s}[$__range]))`, ratingsPostedMetric, sel)).
+		describe("Ratings posted in the selected time range, by number of stars."), 12, 8)
+
+	l.row("Availability")
+	l.add(timeseries("Fallbacks/s", "reqps",
+		q("fallbacks/s", `sum(rate(%s{%s}[$__rate_interval]))`, ratingsFallbacksMetric, sel)).
+		describe("Reviews served without stars because ratings could not be fetched."), 8, 8)
+	l.add(timeseries("Fallback ratio", "percentunit",
+		q("fallbacks / GetRatings calls", `sum(rate(%s{%s}[$__rate_interval])) / sum(rate(serviceweaver_method_count{%s, method="GetRatings", %s}[$__rate_interval]))`, ratingsFallbacksMetric, sel, filter, sel)), 8, 8)
+	l.add(timeseries("Chaos state", "short",
+		q("unavailable {{pod}}", `max by (pod) (%s{%s})`, ratingsUnavailableMetric, sel),
+		q("unhealthy {{pod}}", `max by (pod) (%s{%s})`, ratingsUnhealthyMetric, sel)).
+		describe("1 while the v-unavailable or v-unhealthy versions make ratings fail."), 8, 8)
+
+	l.row("Latency")
+	l.add(timeseries("GetRatings latency", "ms",
+		quantiles("serviceweaver_method_latency_micros", filter+`, method="GetRatings"`)...), 12, 8)
+	l.add(timeseries("PostRatings latency", "ms",
+		quantiles("serviceweaver_method_latency_micros", filter+`, method="PostRatings"`)...), 12, 8)
+	return newDashboard("bookinfo-ratings", "Bookinfo / Ratings",
+		"Ratings posted, star distribution, fallbacks and chaos state of the ratings component.", l)
+}
+
+// Business metrics of the details, reviews, orders, pricing and product page
+// components.
+const (
+	detailsLookupsMetric  = "bookinfo_details_lookups"             // counter of lookups, by source
+	detailsExternalMetric = "bookinfo_details_external_latency_ms" // histogram of Google Books calls, by error
+	reviewsReturnedMetric = "bookinfo_reviews_returned"            // counter of reviews returned, by rated
+	checkoutsMetric       = "bookinfo_checkouts"                   // counter of checkouts, by outcome
+	pricesQuotedMetric    = "bookinfo_prices_quoted"               // counter of prices quoted, by currency
+	rateReloadsMetric     = "bookinfo_pricing_rate_reloads"        // counter of rates file reloads, by outcome
+	pageRendersMetric     = "bookinfo_productpage_renders"         // counter of product pages, by product
+	remoteLatencyMetric   = "bookinfo_remote_latency_ms"           // histogram of the HTTP adapters' calls, by component, method and error
+)
+
+// businessDashboard shows the business metrics of the components other than
+// ratings, which has a dashboard of its own.
+func businessDashboard() *dashboard {
+	l := &layout{}
+	l.row("Product page")
+	l.add(timeseries("Product pages rendered/s", "reqps",
+		q("product {{product}}", `sum by (product) (rate(%s{%s}[$__rate_interval]))`, pageRendersMetric, sel)), 12, 8)
+	l.add(bargauge("Most viewed products", "short",
+		q("product {{product}}", `topk(10, sum by (product) (increase(%s{%s}[$__range])))`, pageRendersMetric, sel)).
+		describe("Product pages rendered in the selected time range."), 12, 8)
+
+	l.row("Details")
+	l.add(timeseries("Lookups/s by source", "reqps",
+		q("{{source}}", `sum by (source) (rate(%s{%s}[$__rate_interval]))`, detailsLookupsMetric, sel)).
+		describe("Book details served locally or fetched from Google Books."), 8, 8)
+	var external []query
+	for _, p := range []string{"50", "95", "99"} {
+		external = append(external, q("p"+p,
+			`histogram_quantile(0.%s, sum by (le) (rate(%s_bucket{%s}[$__rate_interval])))`, p, detailsExternalMetric, sel))
+	}
+	l.add(timeseries("Google Books latency", "ms", external...), 8, 8)
+	l.add(timeseries("Google Books error ratio", "percentunit",
+		q("errors", `sum(rate(%s_count{error="true", %s}[$__rate_interval])) / sum(rate(%s_count{%s}[$__rate_interval]))`,
+			detailsExternalMetric, sel, detailsExternalMetric, sel)), 8, 8)
+
+	l.row("Reviews")
+	l.add(timeseries("Reviews returned/s", "reqps",
+		q("rated={{rated}}", `sum by (rated) (rate(%s{%s}[$__rate_interval]))`, reviewsReturnedMetric, sel)), 12, 8)
+	l.add(timeseries("Rated share", "percentunit",
+		q("rated", `sum(rate(%s{rated="true", %s}[$__rate_interval])) / sum(rate(%s{%s}[$__rate_interval]))`,
+			reviewsReturnedMetric, sel, reviewsReturnedMetric, sel)).
+		describe("Share of the reviews returned with stars."), 12, 8)
+
+	l.row("Orders")
+	l.add(timeseries("Checkouts/s by outcome", "reqps",
+		q("{{outcome}}", `sum by (outcome) (rate(%s{%s}[$__rate_interval]))`, checkoutsMetric, sel)).
+		describe("Checkouts placed, failed and compensated, out of stock, of empty carts or erroring; replays aren't counted."), 12, 8)
+	l.add(timeseries("Failed share", "percentunit",
+		q("failed", `sum(rate(%s{outcome="failed", %s}[$__rate_interval])) / sum(rate(%s{outcome=~"placed|failed", %s}[$__rate_interval]))`,
+			checkoutsMetric, sel, checkoutsMetric, sel)).
+		describe("Share of the orders that failed and had their reservations released."), 12, 8)
+
+	l.row("Pricing")
+	l.add(timeseries("Prices quoted/s by currency", "reqps",
+		q("{{currency}}", `sum by (currency) (rate(%s{%s}[$__rate_interval]))`, pricesQuotedMetric, sel)).
+		describe("Prices quoted, in the currency picked from the request, its currency cookie or Accept-Language header."), 12, 8)
+	l.add(timeseries("Rate reloads by outcome", "short",
+		q("{{outcome}}", `sum by (outcome) (increase(%s{%s}[$__rate_interval]))`, rateReloadsMetric, sel)).
+		describe("Reloads of the exchange rates file; after a failed one, the last rates keep serving."), 12, 8)
+	return newDashboard("bookinfo-business", "Bookinfo / Business",
+		"Product pages rendered, details lookups, reviews returned, checkouts and prices quoted.", l)
+}
+
+// writeManifest writes the ConfigMap holding the dashboards and the Grafana
+// provider that loads them.
+func writeManifest(path string, files map[string]string) error {
+	data := map[string]string{
+		"dashboards.yaml": strings.TrimLeft(`
+apiVersion: 1
+providers:
+- name: bookinfo
+  folder: Bookinfo
+  type: file
+  allowUiUpdates: true
+  options:
+    path: /etc/grafana/provisioning/dashboards
+`, "\n"),
+	}
+	for name, content := range files {
+		data[name] = content
+	}
+	configMap := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]string{"name": "grafana-dashboards"},
+		"data":       data,
+	}
+	var b bytes.Buffer
+	b.WriteString("# Code generated by cmd/dashboards. DO NOT EDIT.\n\n")
+	b.WriteString("# Dashboards provisioned in Grafana; see observability/grafana.yaml.\n")
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(configMap); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
@@ -0,0 +1,425 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/loadgen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"gopkg.in/yaml.v3"
+)
+
+// placement is a customkube placement config.
+type placement struct {
+	File      string  `yaml:"-"`
+	Name      string  `yaml:"-"`
+	AppConfig string  `yaml:"appConfig"`
+	Groups    []group `yaml:"groups"`
+}
+
+// group is a colocation group of a placement config.
+type group struct {
+	Name       string   `yaml:"name"`
+	Components []string `yaml:"components"`
+}
+
+// readPlacement reads a customkube config. Its name is the file name without
+// the letter prefix and extension, e.g. "pp-details_ratings-reviews".
+func readPlacement(path string) (placement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return placement{}, err
+	}
+	var p placement
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return placement{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	p.File = filepath.Base(path)
+	p.Name = strings.TrimSuffix(p.File, filepath.Ext(p.File))
+	if _, rest, ok := strings.Cut(p.Name, "-"); ok {
+		p.Name = rest
+	}
+	if p.AppConfig != "" && !filepath.IsAbs(p.AppConfig) {
+		// Like customkube, resolve appConfig relative to the config's directory.
+		if p.AppConfig, err = filepath.Abs(filepath.Join(filepath.Dir(path), p.AppConfig)); err != nil {
+			return placement{}, err
+		}
+	}
+	return p, nil
+}
+
+// deployerSections are the weaver.toml sections replaced in the generated
+// multiprocess config.
+var deployerSections = map[string]bool{"serviceweaver": true, "single": true, "multi": true, "kube": true}
+
+// multiConfig returns a weaver.toml for the multiprocess deployer that runs
+// bin with the placement's groups colocated and the product page listening
+// on addr. Component sections of the placement's app config are kept.
+func (p placement) multiConfig(bin, addr string) (string, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[serviceweaver]\nbinary = %q\ncolocate = [\n", bin)
+	for _, g := range p.Groups {
+		quoted := make([]string, len(g.Components))
+		for i, c := range g.Components {
+			quoted[i] = strconv.Quote(c)
+		}
+		fmt.Fprintf(&b, "  [%s],\n", strings.Join(quoted, ", "))
+	}
+	fmt.Fprintf(&b, "]\n\n[multi]\nlisteners.productpage = {address = %q}\n", addr)
+
+	if p.AppConfig == "" {
+		return b.String(), nil
+	}
+	f, err := os.Open(p.AppConfig)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	keep := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "[") {
+			section := strings.Trim(trimmed, "[] ")
+			keep = !deployerSections[section]
+			if keep {
+				b.WriteString("\n")
+			}
+		}
+		if keep {
+			b.WriteString(line + "\n")
+		}
+	}
+	return b.String(), scanner.Err()
+}
+
+// result is the outcome of running the workload against a placement.
+type result struct {
+	Placement placement
+	Load      *loadgen.Report
+	Calls     []edgeCalls
+}
+
+// edgeCalls counts the calls made between two components during the
+// measured part of a run.
+type edgeCalls struct {
+	Caller string
+	Callee string
+	Local  float64
+	Remote float64
+}
+
+// runPlacement deploys the application with placement p, applies the
+// workload and tears the deployment down.
+func runPlacement(ctx context.Context, p placement, bin string, w workload) (result, error) {
+	addr, err := freeAddress()
+	if err != nil {
+		return result{}, err
+	}
+	config, err := p.multiConfig(bin, addr)
+	if err != nil {
+		return result{}, err
+	}
+	dir, err := os.MkdirTemp("", "bookinfo-experiment-")
+	if err != nil {
+		return result{}, err
+	}
+	defer os.RemoveAll(dir)
+	configFile := filepath.Join(dir, "weaver.toml")
+	if err := os.WriteFile(configFile, []byte(config), 0644); err != nil {
+		return result{}, err
+	}
+
+	logFile, err := os.Create(filepath.Join(*out, strings.TrimSuffix(p.File, filepath.Ext(p.File))+".log"))
+	if err != nil {
+		return result{}, err
+	}
+	defer logFile.Close()
+	cmd := exec.Command(*weaverCmd, "multi", "deploy", configFile)
+	cmd.Stdout, cmd.Stderr = logFile, logFile
+	// Give the deployment a registry of its own, to find its status server.
+	cmd.Env = append(os.Environ(), "XDG_DATA_HOME="+dir)
+	if err := cmd.Start(); err != nil {
+		return result{}, fmt.Errorf("starting %s: %w", *weaverCmd, err)
+	}
+	defer stopDeployment(cmd)
+
+	target := "http://" + addr
+	if err := waitReady(ctx, target, *startTimeout); err != nil {
+		return result{}, err
+	}
+	statusAddr, err := statusAddress(ctx, dir, *startTimeout)
+	if err != nil {
+		return result{}, err
+	}
+
+	cfg, err := w.config(target)
+	if err != nil {
+		return result{}, err
+	}
+	warmup, err := w.warmup()
+	if err != nil {
+		return result{}, err
+	}
+	if warmup > 0 {
+		log.Printf("Warming up for %v", warmup)
+		warm := cfg
+		warm.Duration = warmup
+		if _, err := loadgen.Run(ctx, warm); err != nil {
+			return result{}, err
+		}
+	}
+
+	before, err := callCounts(ctx, statusAddr)
+	if err != nil {
+		return result{}, err
+	}
+	log.Printf("Measuring for %v", cfg.Duration)
+	report, err := loadgen.Run(ctx, cfg)
+	if err != nil {
+		return result{}, err
+	}
+	after, err := callCounts(ctx, statusAddr)
+	if err != nil {
+		return result{}, err
+	}
+	return result{Placement: p, Load: report, Calls: diffCalls(before, after)}, nil
+}
+
+// stopDeployment interrupts the multiprocess deployer, which stops the
+// application's processes, and kills it if it doesn't exit in time.
+func stopDeployment(cmd *exec.Cmd) {
+	cmd.Process.Signal(os.Interrupt)
+	done := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		cmd.Process.Kill()
+		<-done
+	}
+}
+
+// freeAddress returns a localhost address with a port nothing listens on.
+func freeAddress() (string, error) {
+	l, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		return "", err
+	}
+	defer l.Close()
+	return l.Addr().String(), nil
+}
+
+// waitReady polls the product page's readiness endpoint until it succeeds.
+func waitReady(ctx context.Context, target string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target+"/readyz", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%s not ready after %v", target, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(500 * time.Millisecond):
+		}
+	}
+}
+
+// registration is the record the multiprocess deployer writes for a
+// deployment, with the address of its status server.
+type registration struct {
+	DeploymentId string
+	Addr         string
+}
+
+// statusAddress returns the address of the status server of the deployment
+// whose data directory is dataDir. The deployer registers the deployment
+// once the status server is up, so it's polled for until timeout.
+func statusAddress(ctx context.Context, dataDir string, timeout time.Duration) (string, error) {
+	pattern := filepath.Join(dataDir, "serviceweaver", "multi", "registry", "*.registration.json")
+	deadline := time.Now().Add(timeout)
+	for {
+		files, err := filepath.Glob(pattern)
+		if err != nil {
+			return "", err
+		}
+		if len(files) > 0 {
+			data, err := os.ReadFile(files[0])
+			if err != nil {
+				return "", err
+			}
+			var reg registration
+			if err := json.Unmarshal(data, &reg); err != nil {
+				return "", fmt.Errorf("parsing %s: %w", files[0], err)
+			}
+			return reg.Addr, nil
+		}
+		if time.Now().After(deadline) {
+			return "", fmt.Errorf("deployment not registered in %s after %v", dataDir, timeout)
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+}
+
+// methodCountMetric is the counter of component method calls that Service
+// Weaver generates for every component.
+const methodCountMetric = "serviceweaver_method_count"
+
+// weaverPrefix prefixes the components of the Service Weaver runtime.
+const weaverPrefix = "github.com/ServiceWeaver/weaver/"
+
+// callKey identifies a call counter in one replica.
+type callKey struct {
+	node   string // weavelet id
+	caller string
+	callee string
+	method string
+	remote bool
+}
+
+// callCounts returns the method call counters of every replica of the
+// deployment, read from the Prometheus endpoint of the deployer's status
+// server. The deployer collects the metrics of all of its processes, so
+// none are missed.
+func callCounts(ctx context.Context, statusAddr string) (map[callKey]float64, error) {
+	url := "http://" + statusAddr + "/debug/serviceweaver/prometheus"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return parseCallCounts(resp.Body)
+}
+
+// parseCallCounts reads the method call counters from metrics in the
+// Prometheus text format.
+func parseCallCounts(r io.Reader) (map[callKey]float64, error) {
+	counts := map[callKey]float64{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		rest, ok := strings.CutPrefix(line, methodCountMetric+"{")
+		if !ok {
+			continue
+		}
+		labels, value, err := parseSample(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", err, line)
+		}
+		if strings.HasPrefix(labels["component"], weaverPrefix) {
+			continue // calls between the deployer and its processes
+		}
+		// A counter can be reported more than once, with the same value.
+		key := callKey{labels["serviceweaver_node"], labels["caller"], labels["component"], labels["method"], labels["remote"] == "true"}
+		counts[key] = max(counts[key], value)
+	}
+	return counts, scanner.Err()
+}
+
+// parseSample parses the labels and value of a sample, given the line after
+// the "{" that opens its labels, e.g. `method="Get",remote="false"} 12`.
+func parseSample(s string) (map[string]string, float64, error) {
+	labels := map[string]string{}
+	for !strings.HasPrefix(s, "}") {
+		name, rest, ok := strings.Cut(s, `="`)
+		if !ok {
+			return nil, 0, fmt.Errorf("malformed labels")
+		}
+		var value strings.Builder
+		for {
+			if rest == "" {
+				return nil, 0, fmt.Errorf("unterminated label %s", name)
+			}
+			c := rest[0]
+			rest = rest[1:]
+			if c == '"' {
+				break
+			}
+			if c == '\\' && rest != "" {
+				switch rest[0] {
+				case 'n':
+					c = '\n'
+				default:
+					c = rest[0]
+				}
+				rest = rest[1:]
+			}
+			value.WriteByte(c)
+		}
+		labels[strings.TrimPrefix(name, ",")] = value.String()
+		s = rest
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(s[1:]), 64)
+	if err != nil {
+		return nil, 0, err
+	}
+	return labels, value, nil
+}
+
+// diffCalls returns the calls made between two samples, per edge. A counter
+// missing from the first sample counts from 0: its replica hadn't made those
+// calls yet, or was started after the sample, with a node ID of its own.
+func diffCalls(before, after map[callKey]float64) []edgeCalls {
+	type edge struct{ caller, callee string }
+	byEdge := map[edge]*edgeCalls{}
+	var edges []edge
+	for key, n := range after {
+		n -= before[key]
+		if n <= 0 {
+			continue
+		}
+		e := edge{key.caller, key.callee}
+		if _, ok := byEdge[e]; !ok {
+			byEdge[e] = &edgeCalls{Caller: topology.ShortName(key.caller), Callee: topology.ShortName(key.callee)}
+			edges = append(edges, e)
+		}
+		if key.remote {
+			byEdge[e].Remote += n
+		} else {
+			byEdge[e].Local += n
+		}
+	}
+	var calls []edgeCalls
+	for _, e := range edges {
+		calls = append(calls, *byEdge[e])
+	}
+	sortEdges(calls)
+	return calls
+}
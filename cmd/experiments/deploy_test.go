@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const (
+	mainComponent    = "github.com/ServiceWeaver/weaver/Main"
+	detailsComponent = "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details"
+	reviewsComponent = "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews"
+)
+
+func TestParseCallCounts(t *testing.T) {
+	const metrics = `# Metrics in Prometheus text format [1].
+# TYPE bookinfo_details_lookups counter
+bookinfo_details_lookups{component="Details",source="local"} 4
+
+# TYPE serviceweaver_method_count counter
+serviceweaver_method_count{caller="github.com/ServiceWeaver/weaver/Main",component="github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details",method="GetDetails",remote="true",serviceweaver_generated="true",serviceweaver_node="1a2b3c4d"} 12
+serviceweaver_method_count{caller="github.com/ServiceWeaver/weaver/Main",component="github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details",method="GetDetails",remote="false",serviceweaver_generated="true",serviceweaver_node="5e6f7a8b"} 3.5
+serviceweaver_method_count{caller="github.com/ServiceWeaver/weaver/Main",component="github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details",method="GetDetails",remote="false",serviceweaver_generated="true",serviceweaver_node="5e6f7a8b"} 3.5
+serviceweaver_method_count{caller="a\"b\\c",component="x",method="M",remote="false",serviceweaver_node="1a2b3c4d"} 1
+serviceweaver_method_count{caller="github.com/ServiceWeaver/weaver/deployerControl",component="github.com/ServiceWeaver/weaver/deployerControl",method="LogBatch",remote="true",serviceweaver_generated="true",serviceweaver_node="1a2b3c4d"} 7
+
+# TYPE serviceweaver_method_error_count counter
+serviceweaver_method_error_count{caller="github.com/ServiceWeaver/weaver/Main",component="x",method="M",remote="true",serviceweaver_node="1a2b3c4d"} 7
+`
+	got, err := parseCallCounts(strings.NewReader(metrics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[callKey]float64{
+		{"1a2b3c4d", mainComponent, detailsComponent, "GetDetails", true}:  12,
+		{"5e6f7a8b", mainComponent, detailsComponent, "GetDetails", false}: 3.5,
+		{"1a2b3c4d", `a"b\c`, "x", "M", false}:                             1,
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("parseCallCounts() = %v, want %v", got, want)
+	}
+
+	for _, line := range []string{
+		`serviceweaver_method_count{caller="a"`,
+		`serviceweaver_method_count{caller} 1`,
+		`serviceweaver_method_count{caller="a"} x`,
+	} {
+		if got, err := parseCallCounts(strings.NewReader(line)); err == nil {
+			t.Errorf("parseCallCounts(%s) = %v, want error", line, got)
+		}
+	}
+}
+
+func TestDiffCalls(t *testing.T) {
+	key := func(node, caller, callee string, remote bool) callKey {
+		return callKey{node, caller, callee, "Method", remote}
+	}
+	before := map[callKey]float64{
+		key("n1", mainComponent, detailsComponent, true):     10,
+		key("n1", mainComponent, reviewsComponent, false):    4,
+		key("n2", reviewsComponent, detailsComponent, false): 2,
+	}
+	after := map[callKey]float64{
+		key("n1", mainComponent, detailsComponent, true):     25,
+		key("n1", mainComponent, reviewsComponent, false):    4, // no new calls
+		key("n1", mainComponent, reviewsComponent, true):     6, // first calls
+		key("n2", reviewsComponent, detailsComponent, false): 5,
+		key("n3", mainComponent, detailsComponent, true):     100, // started while measuring
+		key("n4", reviewsComponent, detailsComponent, true):  1,
+	}
+	calls := diffCalls(before, after)
+	want := []edgeCalls{
+		{Caller: "pp", Callee: "details", Remote: 115},
+		{Caller: "pp", Callee: "reviews", Remote: 6},
+		{Caller: "reviews", Callee: "details", Local: 3, Remote: 1},
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("diffCalls() = %v, want %v", calls, want)
+	}
+
+	// Without a warmup, the first sample may have no counters at all.
+	calls = diffCalls(map[callKey]float64{}, after)
+	want = []edgeCalls{
+		{Caller: "pp", Callee: "details", Remote: 125},
+		{Caller: "pp", Callee: "reviews", Local: 4, Remote: 6},
+		{Caller: "reviews", Callee: "details", Local: 5, Remote: 1},
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("diffCalls() with an empty first sample = %v, want %v", calls, want)
+	}
+}
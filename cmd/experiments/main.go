@@ -0,0 +1,218 @@
+// Command experiments compares component placements under load.
+//
+// For every placement config (the customkube files in yamls/), it deploys the
+// application locally with the multiprocess deployer, colocating components
+// as the config's groups say, warms it up, runs the load generator and
+// records the number of local and remote component method calls, read from
+// the method metrics Service Weaver generates, which the deployer collects
+// from every replica. It then
+// writes a report comparing latency percentiles, throughput and remote calls
+// across the placements.
+//
+// Usage:
+//
+//	go run ./cmd/experiments -workload workload.json -out results yamls/*.yaml
+//
+// The weaver command must be on the PATH (or passed with -weaver).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/loadgen"
+)
+
+var (
+	workloadFile = flag.String("workload", "", "JSON workload spec (default: closed loop, 8 workers, 30s)")
+	out          = flag.String("out", "experiments", "Directory for the report and raw results")
+	binary       = flag.String("binary", "", "Application binary (default: built from -module)")
+	module       = flag.String("module", ".", "Directory of the application's main package, used when -binary is empty")
+	weaverCmd    = flag.String("weaver", "weaver", "Path to the weaver command")
+	startTimeout = flag.Duration("start-timeout", time.Minute, "How long to wait for a deployment to become ready")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] placement.yaml...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := run(ctx, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(ctx context.Context, configs []string) error {
+	w, err := readWorkload(*workloadFile)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return err
+	}
+	bin := *binary
+	if bin == "" {
+		if bin, err = build(ctx, *module, *out); err != nil {
+			return err
+		}
+	}
+	if bin, err = filepath.Abs(bin); err != nil {
+		return err
+	}
+
+	var results []result
+	for _, config := range configs {
+		p, err := readPlacement(config)
+		if err != nil {
+			return err
+		}
+		log.Printf("Running %s (%d groups)", p.Name, len(p.Groups))
+		res, err := runPlacement(ctx, p, bin, w)
+		if err != nil {
+			return fmt.Errorf("%s: %w", config, err)
+		}
+		if err := saveRaw(res); err != nil {
+			return err
+		}
+		results = append(results, res)
+	}
+
+	return writeReports(results, w)
+}
+
+// build compiles the application into dir and returns the binary's path.
+func build(ctx context.Context, pkg, dir string) (string, error) {
+	bin := filepath.Join(dir, "bookinfo")
+	log.Printf("Building %s", bin)
+	cmd := exec.CommandContext(ctx, "go", "build", "-o", bin, ".")
+	cmd.Dir = pkg
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("building %s: %w", pkg, err)
+	}
+	return bin, nil
+}
+
+// workload is the load applied to every placement. Durations use Go syntax,
+// e.g. "30s". In open-loop mode, at most Concurrency requests are in flight
+// and Backlog wait for one of them to finish; the requests due while the
+// backlog is full are dropped, so an overloaded placement doesn't pile up
+// requests without bound.
+type workload struct {
+	Mode        string  `json:"mode"`
+	Rate        float64 `json:"rate"`
+	Concurrency int     `json:"concurrency"`
+	Backlog     int     `json:"backlog"` // Concurrency if 0
+	Mix         string  `json:"mix"`
+	Products    int     `json:"products"`
+	Warmup      string  `json:"warmup"`
+	Duration    string  `json:"duration"`
+	Timeout     string  `json:"timeout"`
+}
+
+// readWorkload reads a workload spec, filling in defaults for missing fields.
+func readWorkload(path string) (workload, error) {
+	w := workload{
+		Mode:        string(loadgen.Closed),
+		Rate:        100,
+		Concurrency: 8,
+		Mix:         "productpage=1,product=1,reviews=1,ratings=1",
+		Products:    1,
+		Warmup:      "10s",
+		Duration:    "30s",
+		Timeout:     "10s",
+	}
+	if path == "" {
+		return w, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return w, err
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return w, fmt.Errorf("parsing workload %s: %w", path, err)
+	}
+	if w.Backlog <= 0 {
+		w.Backlog = w.Concurrency
+	}
+	return w, nil
+}
+
+// config returns the load generator config of the measured part of a run.
+func (w workload) config(target string) (loadgen.Config, error) {
+	mix, err := loadgen.ParseMix(w.Mix)
+	if err != nil {
+		return loadgen.Config{}, err
+	}
+	duration, err := time.ParseDuration(w.Duration)
+	if err != nil {
+		return loadgen.Config{}, fmt.Errorf("workload duration: %w", err)
+	}
+	timeout, err := time.ParseDuration(w.Timeout)
+	if err != nil {
+		return loadgen.Config{}, fmt.Errorf("workload timeout: %w", err)
+	}
+	return loadgen.Config{
+		Target:      target,
+		Mix:         mix,
+		Mode:        loadgen.Mode(w.Mode),
+		Rate:        w.Rate,
+		Concurrency: w.Concurrency,
+		Backlog:     w.Backlog,
+		Duration:    duration,
+		Timeout:     timeout,
+		Products:    w.Products,
+	}, nil
+}
+
+// warmup returns the duration of the warmup phase.
+func (w workload) warmup() (time.Duration, error) {
+	if w.Warmup == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(w.Warmup)
+	if err != nil {
+		return 0, fmt.Errorf("workload warmup: %w", err)
+	}
+	return d, nil
+}
+
+// saveRaw writes the load generator report of a run as JSON and CSV.
+func saveRaw(res result) error {
+	base := filepath.Join(*out, strings.TrimSuffix(res.Placement.File, filepath.Ext(res.Placement.File)))
+	for ext, write := range map[string]func(*os.File) error{
+		".json": func(f *os.File) error { return res.Load.WriteJSON(f) },
+		".csv":  func(f *os.File) error { return res.Load.WriteCSV(f) },
+	} {
+		f, err := os.Create(base + ext)
+		if err != nil {
+			return err
+		}
+		if err := write(f); err != nil {
+			f.Close()
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
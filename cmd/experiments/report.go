@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/loadgen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// row is a line of the comparison table.
+type row struct {
+	Placement    string
+	Groups       string
+	Throughput   float64
+	P50          float64
+	P95          float64
+	P99          float64
+	ErrorRate    float64
+	Dropped      int64 // open-loop requests dropped because the backlog was full
+	RemoteCalls  float64
+	LocalCalls   float64
+	RemotePerReq float64
+	Edges        []edgeCalls
+}
+
+// rows summarizes the results, in the order the placements were run.
+func rows(results []result) []row {
+	var out []row
+	for _, res := range results {
+		total, _ := res.Load.Result(loadgen.Total)
+		r := row{
+			Placement:  res.Placement.Name,
+			Groups:     groupsString(res.Placement.Groups),
+			Throughput: total.Throughput,
+			P50:        total.P50,
+			P95:        total.P95,
+			P99:        total.P99,
+			ErrorRate:  total.ErrorRate,
+			Dropped:    total.Dropped,
+			Edges:      res.Calls,
+		}
+		for _, e := range res.Calls {
+			r.RemoteCalls += e.Remote
+			r.LocalCalls += e.Local
+		}
+		if total.Requests > 0 {
+			r.RemotePerReq = r.RemoteCalls / float64(total.Requests)
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+// groupsString returns the groups of a placement, e.g. "[pp details] [ratings reviews]".
+func groupsString(groups []group) string {
+	var parts []string
+	for _, g := range groups {
+		var names []string
+		for _, c := range g.Components {
+			names = append(names, topology.ShortName(c))
+		}
+		parts = append(parts, "["+strings.Join(names, " ")+"]")
+	}
+	return strings.Join(parts, " ")
+}
+
+// sortEdges sorts edges by caller, then callee.
+func sortEdges(edges []edgeCalls) {
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Caller != edges[j].Caller {
+			return edges[i].Caller < edges[j].Caller
+		}
+		return edges[i].Callee < edges[j].Callee
+	})
+}
+
+// writeReports writes report.md and report.html to the output directory.
+func writeReports(results []result, w workload) error {
+	rs := rows(results)
+	md := filepath.Join(*out, "report.md")
+	if err := os.WriteFile(md, []byte(markdown(rs, w)), 0644); err != nil {
+		return err
+	}
+	f, err := os.Create(filepath.Join(*out, "report.html"))
+	if err != nil {
+		return err
+	}
+	if err := htmlReport.Execute(f, map[string]any{"rows": rs, "workload": w}); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	log.Printf("Wrote %s and report.html", md)
+	return nil
+}
+
+// markdown renders the comparison as a markdown document.
+func markdown(rs []row, w workload) string {
+	var b strings.Builder
+	b.WriteString("# Placement comparison\n\n")
+	fmt.Fprintf(&b, "Workload: %s loop, mix `%s`, %d products, warmup %s, duration %s", w.Mode, w.Mix, w.Products, w.Warmup, w.Duration)
+	if w.Mode == string(loadgen.Open) {
+		fmt.Fprintf(&b, ", %.0f req/s, at most %d in flight and %d waiting.\n\n", w.Rate, w.Concurrency, w.Backlog)
+	} else {
+		fmt.Fprintf(&b, ", %d workers.\n\n", w.Concurrency)
+	}
+
+	b.WriteString("| Placement | Groups | req/s | p50 ms | p95 ms | p99 ms | Errors | Dropped | Remote calls | Local calls | Remote calls/req |\n")
+	b.WriteString("|---|---|--:|--:|--:|--:|--:|--:|--:|--:|--:|\n")
+	for _, r := range rs {
+		fmt.Fprintf(&b, "| %s | %s | %.1f | %.2f | %.2f | %.2f | %.2f%% | %d | %.0f | %.0f | %.2f |\n",
+			r.Placement, r.Groups, r.Throughput, r.P50, r.P95, r.P99, 100*r.ErrorRate, r.Dropped, r.RemoteCalls, r.LocalCalls, r.RemotePerReq)
+	}
+
+	b.WriteString("\n## Calls per edge\n")
+	for _, r := range rs {
+		fmt.Fprintf(&b, "\n### %s\n\n| Caller | Callee | Local | Remote |\n|---|---|--:|--:|\n", r.Placement)
+		for _, e := range r.Edges {
+			fmt.Fprintf(&b, "| %s | %s | %.0f | %.0f |\n", e.Caller, e.Callee, e.Local, e.Remote)
+		}
+	}
+	return b.String()
+}
+
+var htmlReport = template.Must(template.New("report").Funcs(template.FuncMap{
+	"percent": func(f float64) string { return fmt.Sprintf("%.2f%%", 100*f) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Placement comparison</title>
+<style>
+  body { font-family: sans-serif; margin: 2em; }
+  table { border-collapse: collapse; margin-bottom: 2em; }
+  th, td { border: 1px solid lightgrey; padding: .4em .8em; }
+  th { color: #fff; background: #466BB0; }
+  td.num { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Placement comparison</h1>
+<p>Workload: {{ .workload.Mode }} loop, mix <code>{{ .workload.Mix }}</code>, {{ .workload.Products }} products,
+warmup {{ .workload.Warmup }}, duration {{ .workload.Duration }}, concurrency {{ .workload.Concurrency }}{{ if eq .workload.Mode "open" }}, backlog {{ .workload.Backlog }}, {{ .workload.Rate }} req/s{{ end }}.</p>
+<table>
+<tr><th>Placement</th><th>Groups</th><th>req/s</th><th>p50 ms</th><th>p95 ms</th><th>p99 ms</th><th>Errors</th><th>Dropped</th><th>Remote calls</th><th>Local calls</th><th>Remote calls/req</th></tr>
+{{ range .rows }}
+<tr>
+<td>{{ .Placement }}</td><td>{{ .Groups }}</td>
+<td class="num">{{ printf "%.1f" .Throughput }}</td>
+<td class="num">{{ printf "%.2f" .P50 }}</td>
+<td class="num">{{ printf "%.2f" .P95 }}</td>
+<td class="num">{{ printf "%.2f" .P99 }}</td>
+<td class="num">{{ percent .ErrorRate }}</td>
+<td class="num">{{ .Dropped }}</td>
+<td class="num">{{ printf "%.0f" .RemoteCalls }}</td>
+<td class="num">{{ printf "%.0f" .LocalCalls }}</td>
+<td class="num">{{ printf "%.2f" .RemotePerReq }}</td>
+</tr>
+{{ end }}
+</table>
+<h2>Calls per edge</h2>
+{{ range .rows }}
+<h3>{{ .Placement }}</h3>
+<table>
+<tr><th>Caller</th><th>Callee</th><th>Local</th><th>Remote</th></tr>
+{{ range .Edges }}<tr><td>{{ .Caller }}</td><td>{{ .Callee }}</td><td class="num">{{ printf "%.0f" .Local }}</td><td class="num">{{ printf "%.0f" .Remote }}</td></tr>
+{{ end }}
+</table>
+{{ end }}
+</body>
+</html>
+`))
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/loadgen"
+)
+
+var testResults = []result{
+	{
+		Placement: placement{Name: "colocated", Groups: []group{{Components: []string{mainComponent, detailsComponent, reviewsComponent}}}},
+		Load:      &loadgen.Report{Results: []loadgen.Result{{Endpoint: loadgen.Total, Requests: 100, Throughput: 50, P50: 1, P95: 2, P99: 3}}},
+		Calls:     []edgeCalls{{Caller: "pp", Callee: "details", Local: 100}},
+	},
+	{
+		Placement: placement{Name: "distributed", Groups: []group{{Components: []string{mainComponent}}, {Components: []string{detailsComponent}}, {Components: []string{reviewsComponent}}}},
+		Load:      &loadgen.Report{Results: []loadgen.Result{{Endpoint: loadgen.Total, Requests: 80, Errors: 2, Dropped: 1, ErrorRate: 0.025, Throughput: 40, P50: 2, P95: 4, P99: 8}}},
+		Calls:     []edgeCalls{{Caller: "pp", Callee: "details", Remote: 80}, {Caller: "pp", Callee: "reviews", Local: 10, Remote: 70}},
+	},
+}
+
+func TestRows(t *testing.T) {
+	rs := rows(testResults)
+	if len(rs) != 2 {
+		t.Fatalf("rows() returned %d rows, want 2", len(rs))
+	}
+	if got, want := rs[0].Groups, "[pp details reviews]"; got != want {
+		t.Errorf("Groups = %q, want %q", got, want)
+	}
+	if got, want := rs[1].Groups, "[pp] [details] [reviews]"; got != want {
+		t.Errorf("Groups = %q, want %q", got, want)
+	}
+	r := rs[1]
+	if r.RemoteCalls != 150 || r.LocalCalls != 10 || r.RemotePerReq != 150.0/80 {
+		t.Errorf("calls = %v remote, %v local, %v remote/req; want 150, 10, 1.875", r.RemoteCalls, r.LocalCalls, r.RemotePerReq)
+	}
+	if r.ErrorRate != 0.025 || r.Dropped != 1 || r.Throughput != 40 || r.P99 != 8 {
+		t.Errorf("load = %+v, want the total result of the run", r)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	w := workload{Mode: string(loadgen.Closed), Mix: "productpage=1", Products: 1, Warmup: "1s", Duration: "2s", Concurrency: 4}
+	got := markdown(rows(testResults), w)
+	for _, want := range []string{
+		"Workload: closed loop, mix `productpage=1`, 1 products, warmup 1s, duration 2s, 4 workers.",
+		"| colocated | [pp details reviews] | 50.0 | 1.00 | 2.00 | 3.00 | 0.00% | 0 | 0 | 100 | 0.00 |",
+		"| distributed | [pp] [details] [reviews] | 40.0 | 2.00 | 4.00 | 8.00 | 2.50% | 1 | 150 | 10 | 1.88 |",
+		"### distributed\n\n| Caller | Callee | Local | Remote |\n|---|---|--:|--:|\n| pp | details | 0 | 80 |\n| pp | reviews | 10 | 70 |\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("markdown() doesn't contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestHTMLReport(t *testing.T) {
+	w := workload{Mode: string(loadgen.Open), Rate: 100, Concurrency: 8, Backlog: 16}
+	var b strings.Builder
+	if err := htmlReport.Execute(&b, map[string]any{"rows": rows(testResults), "workload": w}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<td>distributed</td><td>[pp] [details] [reviews]</td>",
+		`<td class="num">2.50%</td>`,
+		"<tr><td>pp</td><td>reviews</td><td class=\"num\">10</td><td class=\"num\">70</td></tr>",
+		", backlog 16, 100 req/s.",
+		`<td class="num">1</td>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("report.html doesn't contain %q:\n%s", want, b.String())
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package source finds the Service Weaver components and instrumented HTTP
+// handlers of the application by parsing its source, so the commands that
+// generate configs from them follow the code.
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Components returns the full names of the components registered in the
+// weaver_gen.go files under root, sorted.
+func Components(root string) ([]string, error) {
+	var names []string
+	err := walkGo(root, func(path string) error {
+		if filepath.Base(path) != "weaver_gen.go" {
+			return nil
+		}
+		found, err := registrations(path)
+		if err != nil {
+			return err
+		}
+		names = append(names, found...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no components registered in weaver_gen.go files under %s", root)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// registrations returns the Name field of every codegen.Register call
+// in the given file.
+func registrations(path string) ([]string, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok || len(call.Args) != 1 || !isCall(call, "codegen", "Register") {
+			return true
+		}
+		lit, ok := call.Args[0].(*ast.CompositeLit)
+		if !ok {
+			return true
+		}
+		for _, elt := range lit.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.Ident); !ok || key.Name != "Name" {
+				continue
+			}
+			if value, ok := kv.Value.(*ast.BasicLit); ok && value.Kind == token.STRING {
+				name, err := strconv.Unquote(value.Value)
+				if err == nil {
+					names = append(names, name)
+				}
+			}
+		}
+		return true
+	})
+	return names, nil
+}
+
+// HandlerLabels returns the labels passed to weaver.InstrumentHandler in the
+// Go files under root, sorted and without duplicates. They are the values of
+// the "label" label of the serviceweaver_http_* metrics.
+func HandlerLabels(root string) ([]string, error) {
+	seen := map[string]bool{}
+	err := walkGo(root, func(path string) error {
+		if strings.HasSuffix(path, "_test.go") || filepath.Base(path) == "weaver_gen.go" {
+			return nil
+		}
+		file, err := parser.ParseFile(token.NewFileSet(), path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) != 2 || !isCall(call, "weaver", "InstrumentHandler") {
+				return true
+			}
+			if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if label, err := strconv.Unquote(lit.Value); err == nil {
+					seen[label] = true
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	labels := make([]string, 0, len(seen))
+	for label := range seen {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	return labels, nil
+}
+
+// walkGo calls f with the path of every Go file under root, skipping .git
+// and vendor directories.
+func walkGo(root string, f func(path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && (d.Name() == ".git" || d.Name() == "vendor") {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), ".go") {
+			return nil
+		}
+		return f(path)
+	})
+}
+
+// isCall reports whether call calls pkg.name.
+func isCall(call *ast.CallExpr, pkg, name string) bool {
+	sel, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != name {
+		return false
+	}
+	id, ok := sel.X.(*ast.Ident)
+	return ok && id.Name == pkg
+}
@@ -0,0 +1,120 @@
+// Command loadgen drives load against the Bookinfo product page and reports
+// latency percentiles, error rates and throughput.
+//
+// Usage:
+//
+//	go run ./cmd/loadgen -mode closed -concurrency 16 -duration 1m
+//	go run ./cmd/loadgen -mode open -rate 200 -mix productpage=3,reviews=1 -json out.json -csv out.csv
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/loadgen"
+)
+
+var (
+	target      = flag.String("target", "http://localhost:12345", "Base URL of the product page")
+	mix         = flag.String("mix", "productpage=1,product=1,reviews=1,ratings=1", "Weighted request mix; endpoints: "+endpointNames())
+	mode        = flag.String("mode", "closed", `Load model: "open" (constant arrival rate) or "closed" (fixed concurrency)`)
+	rate        = flag.Float64("rate", 100, "Requests per second in open-loop mode")
+	concurrency = flag.Int("concurrency", 8, "Workers in closed-loop mode; maximum in-flight requests in open-loop mode")
+	backlog     = flag.Int("backlog", 0, "Requests waiting for an in-flight slot in open-loop mode, beyond which they are dropped (default: -concurrency)")
+	duration    = flag.Duration("duration", 30*time.Second, "How long results are recorded")
+	warmup      = flag.Duration("warmup", 5*time.Second, "How long load runs before results are recorded")
+	timeout     = flag.Duration("timeout", 10*time.Second, "Per-request timeout")
+	products    = flag.Int("products", 1, "Product IDs are drawn uniformly from [0, products)")
+	jsonOut     = flag.String("json", "", "Write the report as JSON to this file (- for stdout)")
+	csvOut      = flag.String("csv", "", "Write the report as CSV to this file (- for stdout)")
+)
+
+func main() {
+	flag.Parse()
+
+	m, err := loadgen.ParseMix(*mix)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cfg := loadgen.Config{
+		Target:      *target,
+		Mix:         m,
+		Mode:        loadgen.Mode(*mode),
+		Rate:        *rate,
+		Concurrency: *concurrency,
+		Backlog:     *backlog,
+		Duration:    *duration,
+		Warmup:      *warmup,
+		Timeout:     *timeout,
+		Products:    *products,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Sending %s load to %s for %v (+%v warmup)...\n", *mode, *target, *duration, *warmup)
+	report, err := loadgen.Run(ctx, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := write(*jsonOut, report.WriteJSON); err != nil {
+		log.Fatal(err)
+	}
+	if err := write(*csvOut, report.WriteCSV); err != nil {
+		log.Fatal(err)
+	}
+	if *jsonOut != "-" && *csvOut != "-" {
+		summarize(os.Stdout, report)
+	}
+}
+
+// write writes the report to path with f. An empty path writes nothing and
+// "-" writes to stdout.
+func write(path string, f func(io.Writer) error) error {
+	switch path {
+	case "":
+		return nil
+	case "-":
+		return f(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// summarize prints a human-readable table of the report.
+func summarize(w io.Writer, report *loadgen.Report) {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "endpoint\trequests\terrors\ttimed out\tunfinished\tdropped\treq/s\tp50 ms\tp95 ms\tp99 ms\tmax ms\t")
+	for _, r := range report.Results {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%.1f\t%.2f\t%.2f\t%.2f\t%.2f\t\n",
+			r.Endpoint, r.Requests, r.Errors, r.TimedOut, r.Unfinished, r.Dropped, r.Throughput, r.P50, r.P95, r.P99, r.Max)
+	}
+	tw.Flush()
+}
+
+// endpointNames returns the endpoints accepted in a mix.
+func endpointNames() string {
+	var names []string
+	for name := range loadgen.Endpoints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,353 @@
+// Command placements generates customkube deployment configs for every way of
+// grouping the Bookinfo components into colocation groups.
+//
+// The components are read from the registrations in the weaver_gen.go files
+// produced by "weaver generate", so the set of configs follows the code. Each
+// grouping is a set partition of the components: four components have 15
+// groupings, five have 52. Flags restrict the output to a subset.
+//
+// Usage:
+//
+//	go run ./cmd/placements -base config.yaml -out yamls
+//	go run ./cmd/placements -colocate pp,details -max-groups 2 -list
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cmd/internal/source"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// listFlag is a repeatable flag holding a comma-separated list of components.
+type listFlag [][]string
+
+func (l *listFlag) String() string { return fmt.Sprint(*l) }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, strings.Split(v, ","))
+	return nil
+}
+
+var (
+	dir        = flag.String("dir", ".", "Root of the module to scan for weaver_gen.go files")
+	base       = flag.String("base", "config.yaml", "Config whose settings are copied into every generated file; its groups are replaced")
+	out        = flag.String("out", "yamls", "Directory where configs are written")
+	components = flag.String("components", "", "Comma-separated short names of the components to place (default: all registered)")
+	minGroups  = flag.Int("min-groups", 1, "Minimum number of groups")
+	maxGroups  = flag.Int("max-groups", 0, "Maximum number of groups (0 means no limit)")
+	clean      = flag.Bool("clean", false, "Remove existing .yaml files from the output directory first")
+	list       = flag.Bool("list", false, "Print the file names that would be generated and exit")
+	colocate   listFlag
+	separate   listFlag
+)
+
+func main() {
+	flag.Var(&colocate, "colocate", "Components that must share a group, e.g. pp,details (repeatable)")
+	flag.Var(&separate, "separate", "Components that must be in different groups, e.g. reviews,ratings (repeatable)")
+	flag.Parse()
+
+	names, err := source.Components(*dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	names, err = selectComponents(names, *components)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := checkConstraints(names); err != nil {
+		log.Fatal(err)
+	}
+
+	var placements []placement
+	for _, p := range partitions(names) {
+		if accept(p) {
+			placements = append(placements, newPlacement(p, len(names)))
+		}
+	}
+	sort.SliceStable(placements, func(i, j int) bool {
+		if len(placements[i].groups) != len(placements[j].groups) {
+			return len(placements[i].groups) < len(placements[j].groups)
+		}
+		return placements[i].name < placements[j].name
+	})
+
+	width := prefixWidth(len(placements))
+	for i := range placements {
+		placements[i].file = prefix(i, width) + "-" + placements[i].name + ".yaml"
+	}
+
+	if *list {
+		for _, p := range placements {
+			fmt.Println(p.file)
+		}
+		return
+	}
+
+	header, err := readBase(*base, *out)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		log.Fatal(err)
+	}
+	if *clean {
+		old, err := filepath.Glob(filepath.Join(*out, "*.yaml"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, f := range old {
+			if err := os.Remove(f); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+	for _, p := range placements {
+		path := filepath.Join(*out, p.file)
+		if err := os.WriteFile(path, p.render(header), 0644); err != nil {
+			log.Fatal(err)
+		}
+	}
+	fmt.Printf("Wrote %d configs for %d components to %s\n", len(placements), len(names), *out)
+}
+
+// selectComponents restricts names to the comma-separated short names in
+// selection. An empty selection keeps every component.
+func selectComponents(names []string, selection string) ([]string, error) {
+	if selection == "" {
+		return names, nil
+	}
+	byShort := map[string]string{}
+	for _, name := range names {
+		byShort[topology.ShortName(name)] = name
+	}
+	var selected []string
+	for _, short := range strings.Split(selection, ",") {
+		name, ok := byShort[short]
+		if !ok {
+			return nil, fmt.Errorf("unknown component %q", short)
+		}
+		selected = append(selected, name)
+	}
+	sort.Strings(selected)
+	return selected, nil
+}
+
+// checkConstraints verifies that -colocate and -separate only name
+// components being placed.
+func checkConstraints(names []string) error {
+	known := map[string]bool{}
+	for _, name := range names {
+		known[topology.ShortName(name)] = true
+	}
+	for _, set := range append(append(listFlag{}, colocate...), separate...) {
+		for _, c := range set {
+			if !known[c] {
+				return fmt.Errorf("unknown component %q in constraint %s", c, strings.Join(set, ","))
+			}
+		}
+	}
+	return nil
+}
+
+// partitions returns every set partition of items. Partitions are generated
+// as restricted growth strings: item i goes in group a[i], where a[i] is at
+// most one more than the largest group used by items before it.
+func partitions(items []string) [][][]string {
+	var result [][][]string
+	assignment := make([]int, len(items))
+	var rec func(i, groups int)
+	rec = func(i, groups int) {
+		if i == len(items) {
+			p := make([][]string, groups)
+			for j, g := range assignment {
+				p[g] = append(p[g], items[j])
+			}
+			result = append(result, p)
+			return
+		}
+		for g := 0; g <= groups; g++ {
+			assignment[i] = g
+			next := groups
+			if g == groups {
+				next++
+			}
+			rec(i+1, next)
+		}
+	}
+	rec(0, 0)
+	return result
+}
+
+// accept reports whether a partition satisfies the filtering flags.
+func accept(p [][]string) bool {
+	if len(p) < *minGroups || (*maxGroups > 0 && len(p) > *maxGroups) {
+		return false
+	}
+	groupOf := map[string]int{}
+	for i, group := range p {
+		for _, c := range group {
+			groupOf[topology.ShortName(c)] = i
+		}
+	}
+	for _, set := range colocate {
+		for _, c := range set[1:] {
+			if groupOf[c] != groupOf[set[0]] {
+				return false
+			}
+		}
+	}
+	for _, set := range separate {
+		seen := map[int]bool{}
+		for _, c := range set {
+			if seen[groupOf[c]] {
+				return false
+			}
+			seen[groupOf[c]] = true
+		}
+	}
+	return true
+}
+
+// placement is a grouping of components and the file it is written to.
+type placement struct {
+	name   string     // e.g. "pp-details_ratings-reviews"
+	file   string     // e.g. "c-pp-details_ratings-reviews.yaml"
+	groups [][]string // full component names, per group
+}
+
+// newPlacement names the grouping p of n components. Group names join the
+// short names of their components with "-" and placement names join group
+// names with "_". A single group is "colocated" and n singleton groups are
+// "distributed".
+func newPlacement(p [][]string, n int) placement {
+	groups := make([][]string, len(p))
+	for i, g := range p {
+		groups[i] = append([]string(nil), g...)
+		sort.Slice(groups[i], func(a, b int) bool {
+			return less(groups[i][a], groups[i][b])
+		})
+	}
+	sort.Slice(groups, func(a, b int) bool {
+		return less(groups[a][0], groups[b][0])
+	})
+
+	var name string
+	switch {
+	case len(groups) == 1 && n > 1:
+		name = "colocated"
+	case len(groups) == n && n > 1:
+		name = "distributed"
+	default:
+		var parts []string
+		for _, g := range groups {
+			parts = append(parts, topology.GroupName(g))
+		}
+		name = strings.Join(parts, "_")
+	}
+	return placement{name: name, groups: groups}
+}
+
+// less orders components by short name, with the product page first.
+func less(a, b string) bool {
+	sa, sb := topology.ShortName(a), topology.ShortName(b)
+	if sa == "pp" || sb == "pp" {
+		return sa == "pp" && sb != "pp"
+	}
+	return sa < sb
+}
+
+// render returns the config file for the placement: the base config
+// followed by the groups.
+func (p placement) render(header []byte) []byte {
+	var b bytes.Buffer
+	b.Write(header)
+	b.WriteString("groups:\n")
+	for _, g := range p.groups {
+		name := topology.GroupName(g)
+		if len(p.groups) == 1 {
+			name = "colocated"
+		}
+		fmt.Fprintf(&b, "- name: %s\n  components:\n", name)
+		for _, c := range g {
+			fmt.Fprintf(&b, "  - %s\n", c)
+		}
+	}
+	return b.Bytes()
+}
+
+// readBase returns the base config without its groups section, ending with a
+// blank line. customkube resolves appConfig relative to the directory of the
+// deploy config, so a relative appConfig is rewritten to be relative to the
+// output directory.
+func readBase(path, outDir string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var b bytes.Buffer
+	inGroups := false
+	for _, line := range strings.Split(string(data), "\n") {
+		topLevel := line != "" && line[0] != ' ' && line[0] != '-' && line[0] != '#'
+		if topLevel {
+			inGroups = strings.HasPrefix(line, "groups:")
+		}
+		if value, ok := strings.CutPrefix(line, "appConfig:"); ok {
+			appConfig := strings.TrimSpace(value)
+			if !filepath.IsAbs(appConfig) {
+				rel, err := relativeTo(outDir, filepath.Join(filepath.Dir(path), appConfig))
+				if err != nil {
+					return nil, err
+				}
+				line = "appConfig: " + filepath.ToSlash(rel)
+			}
+		}
+		if !inGroups {
+			b.WriteString(line + "\n")
+		}
+	}
+	return append(bytes.TrimRight(b.Bytes(), "\n"), "\n\n"...), nil
+}
+
+// relativeTo returns the path of target relative to dir. Both are made
+// absolute first, since filepath.Rel can't relate a relative path to an
+// absolute one, or to one that climbs out of the working directory with "..".
+func relativeTo(dir, target string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	absTarget, err := filepath.Abs(target)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Rel(absDir, absTarget)
+}
+
+// prefixWidth returns how many letters are needed to give n files distinct
+// prefixes that sort in order.
+func prefixWidth(n int) int {
+	width := 1
+	for capacity := 26; capacity < n; capacity *= 26 {
+		width++
+	}
+	return width
+}
+
+// prefix returns the i-th fixed-width letter prefix: a, b, ..., or aa, ab, ...
+func prefix(i, width int) string {
+	letters := make([]byte, width)
+	for j := width - 1; j >= 0; j-- {
+		letters[j] = byte('a' + i%26)
+		i /= 26
+	}
+	return string(letters)
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	pp      = "github.com/ServiceWeaver/weaver/Main"
+	details = "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details"
+	ratings = "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings"
+	reviews = "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews"
+)
+
+func TestPartitions(t *testing.T) {
+	// The number of partitions of n items is the n-th Bell number.
+	for n, want := range []int{1, 1, 2, 5, 15, 52, 203} {
+		items := make([]string, n)
+		for i := range items {
+			items[i] = fmt.Sprint(i)
+		}
+		got := partitions(items)
+		if len(got) != want {
+			t.Errorf("partitions(%d items) returned %d partitions, want %d", n, len(got), want)
+		}
+		seen := map[string]bool{}
+		for _, p := range got {
+			key := fmt.Sprint(p)
+			if seen[key] {
+				t.Errorf("partitions(%d items) returned %s twice", n, key)
+			}
+			seen[key] = true
+			count := 0
+			for _, group := range p {
+				count += len(group)
+			}
+			if count != n {
+				t.Errorf("partition %s of %d items has %d items", key, n, count)
+			}
+		}
+	}
+}
+
+func TestAccept(t *testing.T) {
+	defer func(min, max int, c, s listFlag) {
+		*minGroups, *maxGroups, colocate, separate = min, max, c, s
+	}(*minGroups, *maxGroups, colocate, separate)
+	*minGroups, *maxGroups = 1, 2
+	colocate = listFlag{{"pp", "details"}}
+	separate = listFlag{{"reviews", "ratings"}}
+
+	for _, test := range []struct {
+		p    [][]string
+		want bool
+	}{
+		{[][]string{{pp, details, reviews}, {ratings}}, true},
+		{[][]string{{pp, details, ratings}, {reviews}}, true},
+		{[][]string{{pp, details}, {reviews, ratings}}, false},   // separate
+		{[][]string{{pp, reviews}, {details, ratings}}, false},   // colocate
+		{[][]string{{pp, details, reviews, ratings}}, false},     // separate
+		{[][]string{{pp, details}, {reviews}, {ratings}}, false}, // max-groups
+		{[][]string{{pp}, {details, reviews, ratings}}, false},   // colocate
+	} {
+		if got := accept(test.p); got != test.want {
+			t.Errorf("accept(%v) = %v, want %v", test.p, got, test.want)
+		}
+	}
+}
+
+func TestNewPlacement(t *testing.T) {
+	for _, test := range []struct {
+		p    [][]string
+		want string
+	}{
+		{[][]string{{reviews, pp, details, ratings}}, "colocated"},
+		{[][]string{{ratings}, {reviews}, {pp}, {details}}, "distributed"},
+		{[][]string{{reviews, details}, {ratings, pp}}, "pp-ratings_details-reviews"},
+		{[][]string{{ratings}, {details, reviews, pp}}, "pp-details-reviews_ratings"},
+		{[][]string{{pp}}, "pp"},
+	} {
+		n := 0
+		for _, g := range test.p {
+			n += len(g)
+		}
+		if got := newPlacement(test.p, n).name; got != test.want {
+			t.Errorf("newPlacement(%v).name = %q, want %q", test.p, got, test.want)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	p := newPlacement([][]string{{reviews, ratings}, {pp, details}}, 4)
+	got := string(p.render([]byte("appConfig: weaver.toml\n\n")))
+	want := `appConfig: weaver.toml
+
+groups:
+- name: pp-details
+  components:
+  - github.com/ServiceWeaver/weaver/Main
+  - github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details
+- name: ratings-reviews
+  components:
+  - github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings
+  - github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews
+`
+	if got != want {
+		t.Errorf("render() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	for _, test := range []struct {
+		n, width int
+		last     string
+	}{
+		{1, 1, "a"},
+		{15, 1, "o"},
+		{26, 1, "z"},
+		{27, 2, "ba"},
+		{52, 2, "bz"},
+		{203, 2, "hu"},
+		{677, 3, "baa"},
+	} {
+		width := prefixWidth(test.n)
+		if width != test.width {
+			t.Errorf("prefixWidth(%d) = %d, want %d", test.n, width, test.width)
+		}
+		if got := prefix(test.n-1, width); got != test.last {
+			t.Errorf("prefix(%d, %d) = %q, want %q", test.n-1, width, got, test.last)
+		}
+		// The file names sort in the order they're generated.
+		if width > 1 && prefix(0, width) >= prefix(1, width) {
+			t.Errorf("prefix(0, %d) doesn't sort before prefix(1, %d)", width, width)
+		}
+	}
+}
+
+func TestReadBase(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "config.yaml")
+	config := `appConfig: weaver.toml
+repo: docker.io/bookinfo
+
+groups:
+- name: old
+  components:
+  - github.com/ServiceWeaver/weaver/Main
+
+listeners:
+  - name: productpage
+`
+	if err := os.WriteFile(base, []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The base config is relative, as with the default -base, and the
+	// output directory absolute or relative.
+	relBase, err := filepath.Rel(wd, base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := filepath.Rel(wd, filepath.Join(dir, "a", "..", "out"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		out, want string
+	}{
+		{filepath.Join(dir, "yamls"), "../weaver.toml"},
+		{filepath.Join(dir, "yamls", "nested"), "../../weaver.toml"},
+		{dir, "weaver.toml"},
+		{parent, "../weaver.toml"}, // relative, with ".."
+	} {
+		got, err := readBase(relBase, test.out)
+		if err != nil {
+			t.Errorf("readBase(%q): %v", test.out, err)
+			continue
+		}
+		want := fmt.Sprintf("appConfig: %s\nrepo: docker.io/bookinfo\n\nlisteners:\n  - name: productpage\n\n", test.want)
+		if string(got) != want {
+			t.Errorf("readBase(%q) =\n%s\nwant\n%s", test.out, got, want)
+		}
+	}
+
+	// An absolute appConfig is kept as is.
+	if err := os.WriteFile(base, []byte("appConfig: /etc/weaver.toml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := readBase(base, "/tmp/yamls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(got), "appConfig: /etc/weaver.toml\n") {
+		t.Errorf("readBase() = %q, want the absolute appConfig kept", got)
+	}
+}
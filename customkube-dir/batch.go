@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// batcher exports items in batches of up to maxBatch items, at least every
+// timeout, in the background. Items added while the queue is full are
+// dropped, so a slow collector never blocks the application.
+type batcher[T any] struct {
+	what     string // what is batched, for error messages
+	export   func(context.Context, []T) error
+	queue    chan T
+	timeout  time.Duration
+	maxBatch int
+	dropped  atomic.Int64
+}
+
+// newBatcher returns a batcher that exports with export until ctx is done.
+func newBatcher[T any](ctx context.Context, what string, maxBatch, maxQueue int, timeout time.Duration, export func(context.Context, []T) error) *batcher[T] {
+	b := &batcher[T]{
+		what:     what,
+		export:   export,
+		queue:    make(chan T, maxQueue),
+		timeout:  timeout,
+		maxBatch: maxBatch,
+	}
+	go b.run(ctx)
+	return b
+}
+
+// Add queues an item for export.
+func (b *batcher[T]) Add(item T) {
+	select {
+	case b.queue <- item:
+	default:
+		if n := b.dropped.Add(1); n%1000 == 1 {
+			fmt.Fprintf(os.Stderr, "customkube: %s queue full, %d dropped\n", b.what, n)
+		}
+	}
+}
+
+func (b *batcher[T]) run(ctx context.Context) {
+	ticker := time.NewTicker(b.timeout)
+	defer ticker.Stop()
+	batch := make([]T, 0, b.maxBatch)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		exportCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := b.export(exportCtx, batch); err != nil {
+			fmt.Fprintf(os.Stderr, "customkube: exporting %d %s: %v\n", len(batch), b.what, err)
+		}
+		batch = batch[:0]
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			flush()
+			return
+		case item := <-b.queue:
+			batch = append(batch, item)
+			if len(batch) >= b.maxBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
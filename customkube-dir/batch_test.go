@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// recordBatches returns an export function that sends every batch it
+// exports to the returned channel.
+func recordBatches() (func(context.Context, []int) error, chan []int) {
+	batches := make(chan []int, 10)
+	return func(_ context.Context, batch []int) error {
+		batches <- append([]int(nil), batch...)
+		return nil
+	}, batches
+}
+
+// nextBatch returns the next batch exported, failing if none is exported
+// within a second.
+func nextBatch(t *testing.T, batches chan []int) []int {
+	t.Helper()
+	select {
+	case batch := <-batches:
+		return batch
+	case <-time.After(time.Second):
+		t.Fatal("no batch exported")
+		return nil
+	}
+}
+
+func TestBatcherFlushesFullBatches(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	export, batches := recordBatches()
+	b := newBatcher(ctx, "ints", 3, 10, time.Hour, export)
+	for i := range 7 {
+		b.Add(i)
+	}
+	for _, want := range []int{3, 3} {
+		if got := nextBatch(t, batches); len(got) != want {
+			t.Errorf("batch = %v, want %d items", got, want)
+		}
+	}
+	// The last item waits for the timeout, here an hour.
+	select {
+	case batch := <-batches:
+		t.Errorf("exported %v before the timeout", batch)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestBatcherFlushesOnTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	export, batches := recordBatches()
+	b := newBatcher(ctx, "ints", 100, 100, 20*time.Millisecond, export)
+	b.Add(1)
+	b.Add(2)
+	if got := nextBatch(t, batches); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("batch = %v, want [1 2]", got)
+	}
+}
+
+func TestBatcherFlushesWhenDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	export, batches := recordBatches()
+	b := newBatcher(ctx, "ints", 100, 100, time.Hour, export)
+	b.Add(1)
+	// Give the batcher time to take the item from the queue.
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if got := nextBatch(t, batches); len(got) != 1 || got[0] != 1 {
+		t.Errorf("batch = %v, want [1]", got)
+	}
+}
+
+func TestBatcherDropsWhenQueueIsFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	exporting := make(chan struct{})
+	unblock := make(chan struct{})
+	b := newBatcher(ctx, "ints", 1, 1, time.Hour, func(context.Context, []int) error {
+		exporting <- struct{}{}
+		<-unblock
+		return nil
+	})
+	defer close(unblock)
+
+	// The first item is being exported, the second fills the queue and the
+	// others are dropped rather than blocking.
+	b.Add(1)
+	<-exporting
+	for i := range 3 {
+		b.Add(2 + i)
+	}
+	if got := b.dropped.Load(); got != 2 {
+		t.Errorf("dropped = %d, want 2", got)
+	}
+}
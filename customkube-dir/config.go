@@ -0,0 +1,238 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"sigs.k8s.io/yaml"
+)
+
+// config is the telemetry configuration of customkube. It is read only from
+// the [customkube] section of the app config (weaver.toml), which the deployer
+// mounts into every pod, so the same settings apply to every replica. There
+// are no flags and no deploy YAML settings for it: the exporters run in the
+// babysitter, whose arguments are fixed by the deployer and which only
+// receives weaver.toml and config.textpb.
+//
+//	[customkube.traces]
+//	exporters = ["otlp-grpc", "file"]
+//	otlp_endpoint = "otel-collector:4317"
+//	sampling_ratio = 0.25
+//	file = "/tmp/traces.jsonl"
+//
+//	[customkube.metrics]
+//	exporters = ["prometheus"]
+//
+//	[customkube.logs]
+//	exporters = ["text", "http"]
+//	http_endpoint = "http://localhost:9880/weaver"
+type config struct {
+	Traces  traceConfig  `toml:"traces"`
+	Metrics metricConfig `toml:"metrics"`
+	Logs    logConfig    `toml:"logs"`
+}
+
+// traceConfig configures how spans are exported.
+type traceConfig struct {
+	// Exporters lists the span exporters to use: "jaeger", "otlp-grpc",
+	// "otlp-http", "zipkin", "stdout", "file" or "none".
+	Exporters []string `toml:"exporters"`
+
+	JaegerEndpoint string `toml:"jaeger_endpoint"` // Jaeger collector URL
+	OTLPEndpoint   string `toml:"otlp_endpoint"`   // host:port of the OTLP receiver; empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost
+	OTLPInsecure   bool   `toml:"otlp_insecure"`   // disable TLS for OTLP
+	ZipkinEndpoint string `toml:"zipkin_endpoint"` // Zipkin v2 spans URL
+
+	SamplingRate float64 `toml:"sampling_ratio"` // fraction of traces exported
+
+	fileConfig
+	batchConfig
+}
+
+// metricConfig configures how metrics are exported. Metrics are collected
+// every telemetry.metrics.exportInterval of the deploy YAML; framework
+// metrics, like method call counts and HTTP latencies, are only included
+// with telemetry.metrics.generated set.
+type metricConfig struct {
+	// Exporters lists the metric exporters to use: "prometheus",
+	// "otlp-grpc", "otlp-http" or "none".
+	Exporters []string `toml:"exporters"`
+
+	PrometheusAddress string `toml:"prometheus_address"` // address of the /metrics endpoint in every pod
+	OTLPEndpoint      string `toml:"otlp_endpoint"`      // host:port of the OTLP receiver; empty uses OTEL_EXPORTER_OTLP_ENDPOINT or localhost
+	OTLPInsecure      bool   `toml:"otlp_insecure"`      // disable TLS for OTLP
+}
+
+// logConfig configures how log entries are exported.
+type logConfig struct {
+	// Exporters lists the log exporters to use: "text" (pretty printed to
+	// stdout), "json" (JSON lines on stdout), "file", "http" or "none".
+	Exporters []string `toml:"exporters"`
+
+	HTTPEndpoint string `toml:"http_endpoint"` // collector URL batches of entries are posted to
+
+	fileConfig
+	batchConfig
+}
+
+// fileConfig configures a rotating JSON-lines file.
+type fileConfig struct {
+	File        string `toml:"file"`        // path of the file
+	MaxFileSize int64  `toml:"max_file_mb"` // size at which the file is rotated
+	MaxFiles    int    `toml:"max_files"`   // rotated files to keep
+}
+
+// batchConfig configures the batching of exports.
+type batchConfig struct {
+	BatchTimeout string `toml:"batch_timeout"`  // maximum delay before a batch is exported
+	MaxBatchSize int    `toml:"max_batch_size"` // maximum items per export
+	MaxQueueSize int    `toml:"max_queue_size"` // items buffered before dropping
+}
+
+// defaultConfig is used for settings the app config leaves out. It exports
+// every span to the Jaeger collector in the cluster, as customkube always has,
+// no metrics, and pretty prints logs like the babysitter does by default.
+func defaultConfig() config {
+	batch := batchConfig{
+		BatchTimeout: "5s",
+		MaxBatchSize: 512,
+		MaxQueueSize: 2048,
+	}
+	return config{
+		Traces: traceConfig{
+			Exporters:      []string{"jaeger"},
+			JaegerEndpoint: "http://jaeger:14268/api/traces",
+			OTLPInsecure:   true,
+			ZipkinEndpoint: "http://zipkin:9411/api/v2/spans",
+			SamplingRate:   1,
+			fileConfig:     fileConfig{File: "/tmp/weaver-traces.jsonl", MaxFileSize: 100, MaxFiles: 5},
+			batchConfig:    batch,
+		},
+		Metrics: metricConfig{
+			Exporters:         []string{"none"},
+			PrometheusAddress: ":9464",
+			OTLPInsecure:      true,
+		},
+		Logs: logConfig{
+			Exporters:    []string{"text"},
+			HTTPEndpoint: "http://localhost:9880/weaver",
+			fileConfig:   fileConfig{File: "/tmp/weaver-logs.jsonl", MaxFileSize: 100, MaxFiles: 5},
+			batchConfig:  batch,
+		},
+	}
+}
+
+// batchTimeout returns the parsed batch timeout.
+func (c batchConfig) batchTimeout() (time.Duration, error) {
+	d, err := time.ParseDuration(c.BatchTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("batch_timeout: %w", err)
+	}
+	return d, nil
+}
+
+func (c batchConfig) validate() error {
+	if _, err := c.batchTimeout(); err != nil {
+		return err
+	}
+	if c.MaxBatchSize <= 0 {
+		return fmt.Errorf("max_batch_size: must be positive")
+	}
+	if c.MaxQueueSize < c.MaxBatchSize {
+		return fmt.Errorf("max_queue_size: must be at least max_batch_size")
+	}
+	return nil
+}
+
+// validate checks the settings that can be checked without connecting to
+// anything.
+func (c config) validate() error {
+	if err := checkExporters("traces", c.Traces.Exporters, traceExporters); err != nil {
+		return err
+	}
+	if r := c.Traces.SamplingRate; r < 0 || r > 1 {
+		return fmt.Errorf("customkube.traces.sampling_ratio: %v is not in [0, 1]", r)
+	}
+	if err := c.Traces.batchConfig.validate(); err != nil {
+		return fmt.Errorf("customkube.traces.%w", err)
+	}
+	if err := checkExporters("metrics", c.Metrics.Exporters, metricExporters); err != nil {
+		return err
+	}
+	if err := checkExporters("logs", c.Logs.Exporters, logExporters); err != nil {
+		return err
+	}
+	if err := c.Logs.batchConfig.validate(); err != nil {
+		return fmt.Errorf("customkube.logs.%w", err)
+	}
+	return nil
+}
+
+// checkExporters checks that every name in names is "none" or a key of
+// known.
+func checkExporters[T any](section string, names []string, known map[string]T) error {
+	for _, name := range names {
+		if _, ok := known[name]; !ok && name != "none" {
+			return fmt.Errorf("customkube.%s.exporters: unknown exporter %q", section, name)
+		}
+	}
+	return nil
+}
+
+// loadConfig returns the customkube config for the command in args:
+//
+//	customkube babysitter weaver.toml config.textpb components...
+//	customkube deploy config.yaml
+//
+// The babysitter runs in every pod and reads the app config it is given. The
+// deploy command reads the app config named by the deploy YAML, so mistakes
+// are reported before anything is deployed. Other commands use the defaults.
+func loadConfig(args []string) (config, error) {
+	cfg := defaultConfig()
+	var appConfig string
+	switch {
+	case len(args) >= 2 && args[0] == "babysitter":
+		appConfig = args[1]
+	case len(args) >= 2 && args[0] == "deploy":
+		path, err := appConfigPath(args[len(args)-1])
+		if err != nil {
+			return cfg, err
+		}
+		appConfig = path
+	default:
+		return cfg, nil
+	}
+
+	var file struct {
+		Customkube *config `toml:"customkube"`
+	}
+	file.Customkube = &cfg
+	if _, err := toml.DecodeFile(appConfig, &file); err != nil {
+		return cfg, fmt.Errorf("reading %s: %w", appConfig, err)
+	}
+	return cfg, cfg.validate()
+}
+
+// appConfigPath returns the app config named by a deploy YAML.
+func appConfigPath(deployYAML string) (string, error) {
+	data, err := os.ReadFile(deployYAML)
+	if err != nil {
+		return "", err
+	}
+	var deploy struct {
+		AppConfig string `json:"appConfig"`
+	}
+	if err := yaml.Unmarshal(data, &deploy); err != nil {
+		return "", fmt.Errorf("parsing %s: %w", deployYAML, err)
+	}
+	if deploy.AppConfig == "" {
+		return "", fmt.Errorf("%s: no appConfig", deployYAML)
+	}
+	if !filepath.IsAbs(deploy.AppConfig) {
+		return filepath.Join(filepath.Dir(deployYAML), deploy.AppConfig), nil
+	}
+	return deploy.AppConfig, nil
+}
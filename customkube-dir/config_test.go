@@ -0,0 +1,112 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile writes content to name in dir and returns its path.
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	for _, args := range [][]string{nil, {"status"}, {"babysitter"}} {
+		cfg, err := loadConfig(args)
+		if err != nil {
+			t.Fatalf("loadConfig(%q): %v", args, err)
+		}
+		if got := cfg.Traces.Exporters; len(got) != 1 || got[0] != "jaeger" {
+			t.Errorf("loadConfig(%q) trace exporters = %q, want the defaults", args, got)
+		}
+	}
+}
+
+func TestLoadConfigBabysitter(t *testing.T) {
+	appConfig := writeFile(t, t.TempDir(), "weaver.toml", `
+[serviceweaver]
+binary = "./bookinfo"
+
+[customkube.traces]
+exporters = ["zipkin", "file"]
+sampling_ratio = 0.25
+max_file_mb = 10
+
+[customkube.metrics]
+exporters = ["prometheus"]
+
+[customkube.logs]
+exporters = ["json"]
+batch_timeout = "1s"
+`)
+	cfg, err := loadConfig([]string{"babysitter", appConfig, "config.textpb", "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cfg.Traces.Exporters, ","); got != "zipkin,file" {
+		t.Errorf("trace exporters = %s, want zipkin,file", got)
+	}
+	if cfg.Traces.SamplingRate != 0.25 || cfg.Traces.MaxFileSize != 10 {
+		t.Errorf("traces = %+v, want a sampling ratio of 0.25 and files of 10 MB", cfg.Traces)
+	}
+	// The settings left out keep their defaults.
+	if cfg.Traces.ZipkinEndpoint != "http://zipkin:9411/api/v2/spans" || cfg.Traces.MaxFiles != 5 || cfg.Traces.MaxBatchSize != 512 {
+		t.Errorf("traces = %+v, want the default endpoint, files and batch size", cfg.Traces)
+	}
+	if got := strings.Join(cfg.Metrics.Exporters, ","); got != "prometheus" || cfg.Metrics.PrometheusAddress != ":9464" {
+		t.Errorf("metrics = %+v, want prometheus on :9464", cfg.Metrics)
+	}
+	if cfg.Logs.BatchTimeout != "1s" || cfg.Logs.MaxQueueSize != 2048 {
+		t.Errorf("logs = %+v, want a batch timeout of 1s and the default queue", cfg.Logs)
+	}
+}
+
+func TestLoadConfigDeploy(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "weaver.toml", `
+[customkube.traces]
+exporters = ["stdout"]
+`)
+	// A relative appConfig is relative to the deploy YAML.
+	deploy := writeFile(t, dir, "deploy.yaml", "appConfig: weaver.toml\nrepo: docker.io/bookinfo\n")
+	cfg, err := loadConfig([]string{"deploy", deploy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(cfg.Traces.Exporters, ","); got != "stdout" {
+		t.Errorf("trace exporters = %s, want stdout", got)
+	}
+
+	missing := writeFile(t, dir, "missing.yaml", "repo: docker.io/bookinfo\n")
+	if _, err := loadConfig([]string{"deploy", missing}); err == nil || !strings.Contains(err.Error(), "no appConfig") {
+		t.Errorf("loadConfig() of a deploy YAML without appConfig = %v, want an error", err)
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, test := range []struct {
+		toml, want string
+	}{
+		{"[customkube.traces]\nexporters = [\"carrier-pigeon\"]", `customkube.traces.exporters: unknown exporter "carrier-pigeon"`},
+		{"[customkube.metrics]\nexporters = [\"jaeger\"]", `customkube.metrics.exporters: unknown exporter "jaeger"`},
+		{"[customkube.logs]\nexporters = [\"zipkin\"]", `customkube.logs.exporters: unknown exporter "zipkin"`},
+		{"[customkube.traces]\nsampling_ratio = 1.5", "customkube.traces.sampling_ratio: 1.5 is not in [0, 1]"},
+		{"[customkube.traces]\nbatch_timeout = \"soon\"", "customkube.traces.batch_timeout"},
+		{"[customkube.logs]\nmax_batch_size = 0", "customkube.logs.max_batch_size: must be positive"},
+		{"[customkube.logs]\nmax_batch_size = 100\nmax_queue_size = 10", "customkube.logs.max_queue_size: must be at least max_batch_size"},
+		{"[customkube.traces]\nexporters = \"jaeger\"", "reading"},
+	} {
+		appConfig := writeFile(t, t.TempDir(), "weaver.toml", test.toml)
+		_, err := loadConfig([]string{"babysitter", appConfig})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("loadConfig() of %q = %v, want an error containing %q", test.toml, err, test.want)
+		}
+	}
+}
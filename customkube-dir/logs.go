@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+)
+
+// logRecord is a log entry as exported in JSON.
+type logRecord struct {
+	Time      time.Time         `json:"time"`
+	Level     string            `json:"level"`
+	Msg       string            `json:"msg"`
+	App       string            `json:"app"`
+	Version   string            `json:"version"`
+	Component string            `json:"component"`
+	Node      string            `json:"node"`
+	File      string            `json:"file,omitempty"`
+	Line      int32             `json:"line,omitempty"`
+	Attrs     map[string]string `json:"attrs,omitempty"`
+}
+
+func toLogRecord(e *protos.LogEntry) logRecord {
+	r := logRecord{
+		Time:      time.UnixMicro(e.TimeMicros).UTC(),
+		Level:     e.Level,
+		Msg:       e.Msg,
+		App:       e.App,
+		Version:   e.Version,
+		Component: logging.ShortenComponent(e.Component),
+		Node:      e.Node,
+		File:      e.File,
+		Line:      e.Line,
+	}
+	if len(e.Attrs) > 0 {
+		r.Attrs = make(map[string]string, len(e.Attrs)/2)
+		for i := 0; i+1 < len(e.Attrs); i += 2 {
+			r.Attrs[e.Attrs[i]] = e.Attrs[i+1]
+		}
+	}
+	return r
+}
+
+// logExporter receives every log entry of the pod's weavelet.
+type logExporter interface {
+	ExportLog(context.Context, *protos.LogEntry) error
+}
+
+// logExporters are the log exporters that can be listed in
+// customkube.logs.exporters, by name. "text" keeps the babysitter's default
+// of pretty printing to stdout.
+var logExporters = map[string]func(context.Context, logConfig) (logExporter, error){
+	"text": func(context.Context, logConfig) (logExporter, error) {
+		return &textLogExporter{printer: logging.NewPrettyPrinter(false)}, nil
+	},
+	"json": func(context.Context, logConfig) (logExporter, error) {
+		return &jsonLogExporter{w: os.Stdout}, nil
+	},
+	"file": func(_ context.Context, c logConfig) (logExporter, error) {
+		f, err := newRotatingFile(c.File, c.MaxFileSize<<20, c.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		return &jsonLogExporter{w: f}, nil
+	},
+	"http": func(ctx context.Context, c logConfig) (logExporter, error) {
+		timeout, err := c.batchTimeout()
+		if err != nil {
+			return nil, err
+		}
+		return newHTTPLogExporter(ctx, c.HTTPEndpoint, c.MaxBatchSize, c.MaxQueueSize, timeout), nil
+	},
+}
+
+// newLogHandler returns the babysitter's HandleLogEntry plugin. It returns
+// nil if the only exporter is "text", so the babysitter prints the logs
+// itself.
+func newLogHandler(ctx context.Context, c logConfig) (func(context.Context, *protos.LogEntry) error, error) {
+	if len(c.Exporters) == 1 && c.Exporters[0] == "text" {
+		return nil, nil
+	}
+	var exporters []logExporter
+	for _, name := range c.Exporters {
+		if name == "none" {
+			continue
+		}
+		e, err := logExporters[name](ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("log exporter %q: %w", name, err)
+		}
+		exporters = append(exporters, e)
+	}
+	return func(ctx context.Context, entry *protos.LogEntry) error {
+		// As with metrics, a failing exporter must not stop the babysitter.
+		for _, e := range exporters {
+			if err := e.ExportLog(ctx, entry); err != nil {
+				fmt.Fprintf(os.Stderr, "customkube: exporting log entry: %v\n", err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// textLogExporter pretty prints logs to stdout, like the babysitter does by
+// default.
+type textLogExporter struct {
+	printer *logging.PrettyPrinter
+	mu      sync.Mutex
+}
+
+// ExportLog implements logExporter.
+func (t *textLogExporter) ExportLog(_ context.Context, e *protos.LogEntry) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, err := fmt.Println(t.printer.Format(e))
+	return err
+}
+
+// jsonLogExporter writes every log record as a line of JSON.
+type jsonLogExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// ExportLog implements logExporter.
+func (j *jsonLogExporter) ExportLog(_ context.Context, e *protos.LogEntry) error {
+	line, err := json.Marshal(toLogRecord(e))
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	_, err = j.w.Write(append(line, '\n'))
+	return err
+}
+
+// httpLogExporter posts batches of log records, as a JSON array, to a log
+// collector such as Fluent Bit's or Vector's HTTP input.
+type httpLogExporter struct {
+	batcher *batcher[logRecord]
+}
+
+func newHTTPLogExporter(ctx context.Context, url string, maxBatch, maxQueue int, timeout time.Duration) *httpLogExporter {
+	client := &http.Client{Timeout: 10 * time.Second}
+	post := func(ctx context.Context, records []logRecord) error {
+		body, err := json.Marshal(records)
+		if err != nil {
+			return err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		io.Copy(io.Discard, resp.Body)
+		if resp.StatusCode >= 300 {
+			return fmt.Errorf("POST %s: %s", url, resp.Status)
+		}
+		return nil
+	}
+	return &httpLogExporter{batcher: newBatcher(ctx, "log entries", maxBatch, maxQueue, timeout, post)}
+}
+
+// ExportLog implements logExporter.
+func (h *httpLogExporter) ExportLog(_ context.Context, e *protos.LogEntry) error {
+	h.batcher.Add(toLogRecord(e))
+	return nil
+}
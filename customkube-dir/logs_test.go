@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+)
+
+func testLogEntry(msg string) *protos.LogEntry {
+	return &protos.LogEntry{
+		App:        "bookinfo",
+		Version:    "v1",
+		Component:  "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details",
+		Node:       "pod-1",
+		TimeMicros: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC).UnixMicro(),
+		Level:      "info",
+		File:       "details.go",
+		Line:       42,
+		Msg:        msg,
+		Attrs:      []string{"product_id", "1", "dangling"},
+	}
+}
+
+func TestJSONLogExporter(t *testing.T) {
+	var b bytes.Buffer
+	e := &jsonLogExporter{w: &b}
+	if err := e.ExportLog(context.Background(), testLogEntry("found")); err != nil {
+		t.Fatal(err)
+	}
+	var got logRecord
+	if err := json.Unmarshal(b.Bytes(), &got); err != nil {
+		t.Fatalf("%q: %v", b.String(), err)
+	}
+	if got.Msg != "found" || got.Component != "details.Details" || got.Line != 42 || !got.Time.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("record = %+v, want the entry with a short component", got)
+	}
+	// An attribute without a value is left out.
+	if len(got.Attrs) != 1 || got.Attrs["product_id"] != "1" {
+		t.Errorf("attrs = %v, want product_id", got.Attrs)
+	}
+}
+
+func TestHTTPLogExporter(t *testing.T) {
+	batches := make(chan []logRecord, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var records []logRecord
+		if err := json.NewDecoder(r.Body).Decode(&records); err != nil {
+			t.Error(err)
+		}
+		batches <- records
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	e := newHTTPLogExporter(ctx, server.URL, 2, 10, time.Hour)
+	for _, msg := range []string{"a", "b", "c"} {
+		e.ExportLog(ctx, testLogEntry(msg))
+	}
+	select {
+	case got := <-batches:
+		if len(got) != 2 || got[0].Msg != "a" || got[1].Msg != "b" {
+			t.Errorf("posted %+v, want a full batch of a and b", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no batch posted")
+	}
+}
@@ -3,30 +3,37 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 
-	"go.opentelemetry.io/otel/sdk/trace"
-	"go.opentelemetry.io/otel/exporters/jaeger"
 	"github.com/ServiceWeaver/weaver-kube/tool"
 )
 
-const jaegerPort = 14268
-
 func main() {
-	// Ajuste para o nome do service Jaeger no seu cluster.
-	// Use `kubectl get svc` e veja se é "jaeger-collector" ou só "jaeger".
-	jaegerURL := fmt.Sprintf("http://jaeger:%d/api/traces", jaegerPort)
-
-	endpoint := jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(jaegerURL))
-	traceExporter, err := jaeger.New(endpoint)
+	// Os exporters são configurados na seção [customkube] do weaver.toml;
+	// veja config.go. Sem ela, os traces vão para o Jaeger do cluster.
+	cfg, err := loadConfig(os.Args[1:])
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "customkube: %v\n", err)
+		os.Exit(1)
 	}
 
-	handleTraceSpans := func(ctx context.Context, spans []trace.ReadOnlySpan) error {
-		return traceExporter.ExportSpans(ctx, spans)
+	// Os plugins só são usados pelo babysitter, que roda dentro dos pods.
+	var plugins tool.Plugins
+	if len(os.Args) > 1 && os.Args[1] == "babysitter" {
+		ctx := context.Background()
+		if plugins.HandleTraceSpans, err = newTraceHandler(ctx, cfg.Traces); err != nil {
+			fmt.Fprintf(os.Stderr, "customkube: %v\n", err)
+			os.Exit(1)
+		}
+		if plugins.HandleMetrics, err = newMetricHandler(ctx, cfg.Metrics); err != nil {
+			fmt.Fprintf(os.Stderr, "customkube: %v\n", err)
+			os.Exit(1)
+		}
+		if plugins.HandleLogEntry, err = newLogHandler(ctx, cfg.Logs); err != nil {
+			fmt.Fprintf(os.Stderr, "customkube: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	tool.Run("customkube", tool.Plugins{
-		HandleTraceSpans: handleTraceSpans,
-	})
+	tool.Run("customkube", plugins)
 }
@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/prometheus"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/sdk/instrumentation"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// metricExporter receives the metrics of the pod's weavelet every time the
+// babysitter collects them (telemetry.metrics.exportInterval in the deploy
+// YAML).
+type metricExporter interface {
+	ExportMetrics(context.Context, []*metrics.MetricSnapshot) error
+}
+
+// metricExporters are the metric exporters that can be listed in
+// customkube.metrics.exporters, by name.
+var metricExporters = map[string]func(context.Context, metricConfig) (metricExporter, error){
+	"prometheus": func(ctx context.Context, c metricConfig) (metricExporter, error) {
+		return newPrometheusExporter(ctx, c.PrometheusAddress)
+	},
+	"otlp-grpc": func(ctx context.Context, c metricConfig) (metricExporter, error) {
+		var opts []otlpmetricgrpc.Option
+		if c.OTLPEndpoint != "" {
+			opts = append(opts, otlpmetricgrpc.WithEndpoint(c.OTLPEndpoint))
+		}
+		if c.OTLPInsecure {
+			opts = append(opts, otlpmetricgrpc.WithInsecure())
+		}
+		e, err := otlpmetricgrpc.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return newOTLPMetricExporter(e), nil
+	},
+	"otlp-http": func(ctx context.Context, c metricConfig) (metricExporter, error) {
+		var opts []otlpmetrichttp.Option
+		if c.OTLPEndpoint != "" {
+			opts = append(opts, otlpmetrichttp.WithEndpoint(c.OTLPEndpoint))
+		}
+		if c.OTLPInsecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		e, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return newOTLPMetricExporter(e), nil
+	},
+}
+
+// newMetricHandler returns the babysitter's HandleMetrics plugin, or nil if
+// no exporter is configured.
+func newMetricHandler(ctx context.Context, c metricConfig) (func(context.Context, []*metrics.MetricSnapshot) error, error) {
+	var exporters []metricExporter
+	for _, name := range c.Exporters {
+		if name == "none" {
+			continue
+		}
+		e, err := metricExporters[name](ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("metric exporter %q: %w", name, err)
+		}
+		exporters = append(exporters, e)
+	}
+	if len(exporters) == 0 {
+		return nil, nil
+	}
+	return func(ctx context.Context, snaps []*metrics.MetricSnapshot) error {
+		// An error returned here stops the babysitter, so export failures,
+		// e.g. an unreachable collector, are only reported.
+		for _, e := range exporters {
+			if err := e.ExportMetrics(ctx, snaps); err != nil {
+				fmt.Fprintf(os.Stderr, "customkube: exporting metrics: %v\n", err)
+			}
+		}
+		return nil
+	}, nil
+}
+
+// prometheusExporter serves the latest metrics in the Prometheus text format
+// at /metrics.
+type prometheusExporter struct {
+	addr string
+
+	mu    sync.Mutex
+	snaps []*metrics.MetricSnapshot
+}
+
+func newPrometheusExporter(ctx context.Context, addr string) (*prometheusExporter, error) {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	p := &prometheusExporter{addr: lis.Addr().String()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", p.serveMetrics)
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "customkube: prometheus endpoint: %v\n", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	return p, nil
+}
+
+// ExportMetrics implements metricExporter.
+func (p *prometheusExporter) ExportMetrics(_ context.Context, snaps []*metrics.MetricSnapshot) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.snaps = snaps
+	return nil
+}
+
+func (p *prometheusExporter) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	snaps := p.snaps
+	p.mu.Unlock()
+	var b bytes.Buffer
+	prometheus.TranslateMetricsToPrometheusTextFormat(&b, snaps, p.addr, "/metrics")
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.Write(b.Bytes())
+}
+
+// otlpMetricExporter pushes metrics to an OpenTelemetry collector. Weaver
+// metrics are cumulative, so they are sent with cumulative temporality and
+// the babysitter's start time as the start of every series.
+type otlpMetricExporter struct {
+	exporter interface {
+		Export(context.Context, *metricdata.ResourceMetrics) error
+	}
+	start time.Time
+}
+
+func newOTLPMetricExporter(e interface {
+	Export(context.Context, *metricdata.ResourceMetrics) error
+}) *otlpMetricExporter {
+	return &otlpMetricExporter{exporter: e, start: time.Now()}
+}
+
+// ExportMetrics implements metricExporter.
+func (o *otlpMetricExporter) ExportMetrics(ctx context.Context, snaps []*metrics.MetricSnapshot) error {
+	if len(snaps) == 0 {
+		return nil
+	}
+	return o.exporter.Export(ctx, o.resourceMetrics(snaps, time.Now()))
+}
+
+// resourceMetrics converts snapshots to OpenTelemetry metrics, one metric per
+// name with a data point per label set.
+func (o *otlpMetricExporter) resourceMetrics(snaps []*metrics.MetricSnapshot, now time.Time) *metricdata.ResourceMetrics {
+	byName := map[string][]*metrics.MetricSnapshot{}
+	var names []string
+	var res []attribute.KeyValue
+	for _, s := range snaps {
+		if _, ok := byName[s.Name]; !ok {
+			names = append(names, s.Name)
+		}
+		byName[s.Name] = append(byName[s.Name], s)
+		if res == nil && s.Labels["serviceweaver_app"] != "" {
+			res = []attribute.KeyValue{
+				attribute.String("service.name", s.Labels["serviceweaver_app"]),
+				attribute.String("service.version", s.Labels["serviceweaver_version"]),
+				attribute.String("service.instance.id", s.Labels["serviceweaver_node"]),
+			}
+		}
+	}
+	sort.Strings(names)
+
+	var ms []metricdata.Metrics
+	for _, name := range names {
+		series := byName[name]
+		m := metricdata.Metrics{Name: name, Description: series[0].Help}
+		switch series[0].Type {
+		case protos.MetricType_COUNTER:
+			sum := metricdata.Sum[float64]{Temporality: metricdata.CumulativeTemporality, IsMonotonic: true}
+			for _, s := range series {
+				sum.DataPoints = append(sum.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: labelSet(s.Labels), StartTime: o.start, Time: now, Value: s.Value,
+				})
+			}
+			m.Data = sum
+		case protos.MetricType_GAUGE:
+			var gauge metricdata.Gauge[float64]
+			for _, s := range series {
+				gauge.DataPoints = append(gauge.DataPoints, metricdata.DataPoint[float64]{
+					Attributes: labelSet(s.Labels), Time: now, Value: s.Value,
+				})
+			}
+			m.Data = gauge
+		case protos.MetricType_HISTOGRAM:
+			hist := metricdata.Histogram[float64]{Temporality: metricdata.CumulativeTemporality}
+			for _, s := range series {
+				var count uint64
+				for _, c := range s.Counts {
+					count += c
+				}
+				hist.DataPoints = append(hist.DataPoints, metricdata.HistogramDataPoint[float64]{
+					Attributes:   labelSet(s.Labels),
+					StartTime:    o.start,
+					Time:         now,
+					Count:        count,
+					Bounds:       s.Bounds,
+					BucketCounts: s.Counts,
+					Sum:          s.Value,
+				})
+			}
+			m.Data = hist
+		default:
+			continue
+		}
+		ms = append(ms, m)
+	}
+
+	return &metricdata.ResourceMetrics{
+		Resource: resource.NewSchemaless(res...),
+		ScopeMetrics: []metricdata.ScopeMetrics{{
+			Scope:   instrumentation.Scope{Name: "github.com/ServiceWeaver/weaver"},
+			Metrics: ms,
+		}},
+	}
+}
+
+// labelSet returns metric labels as OpenTelemetry attributes.
+func labelSet(labels map[string]string) attribute.Set {
+	kvs := make([]attribute.KeyValue, 0, len(labels))
+	for k, v := range labels {
+		kvs = append(kvs, attribute.String(k, v))
+	}
+	return attribute.NewSet(kvs...)
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+	"github.com/ServiceWeaver/weaver/runtime/protos"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// testSnapshots returns a counter, a gauge and a histogram of one app.
+func testSnapshots() []*metrics.MetricSnapshot {
+	labels := map[string]string{
+		"serviceweaver_app":     "bookinfo",
+		"serviceweaver_version": "v1",
+		"serviceweaver_node":    "pod-1",
+		"component":             "details",
+	}
+	return []*metrics.MetricSnapshot{
+		{Id: 1, Name: "bookinfo_requests", Type: protos.MetricType_COUNTER, Help: "Requests.", Labels: labels, Value: 7},
+		{Id: 2, Name: "bookinfo_in_flight", Type: protos.MetricType_GAUGE, Help: "In flight.", Labels: labels, Value: 2},
+		{
+			Id: 3, Name: "bookinfo_latency_ms", Type: protos.MetricType_HISTOGRAM, Help: "Latency.", Labels: labels,
+			Value: 35, Bounds: []float64{10, 100}, Counts: []uint64{1, 2, 0},
+		},
+	}
+}
+
+func TestPrometheusExporter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	p, err := newPrometheusExporter(ctx, "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.ExportMetrics(ctx, testSnapshots()); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := http.Get("http://" + p.addr + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want the text format", ct)
+	}
+	// The translation leaves out the app and version labels.
+	got := string(body)
+	for _, want := range []string{
+		"# TYPE bookinfo_requests counter",
+		`bookinfo_requests{component="details",serviceweaver_node="pod-1"} 7`,
+		"# TYPE bookinfo_in_flight gauge",
+		"# TYPE bookinfo_latency_ms histogram",
+		`bookinfo_latency_ms_bucket{component="details",serviceweaver_node="pod-1",le="100"} 3`,
+		`bookinfo_latency_ms_count{component="details",serviceweaver_node="pod-1"} 3`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("/metrics does not contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestResourceMetrics(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	o := &otlpMetricExporter{start: start}
+	rm := o.resourceMetrics(testSnapshots(), start.Add(time.Minute))
+
+	if name, _ := rm.Resource.Set().Value("service.name"); name.AsString() != "bookinfo" {
+		t.Errorf("service.name = %q, want bookinfo", name.AsString())
+	}
+	ms := rm.ScopeMetrics[0].Metrics
+	if len(ms) != 3 {
+		t.Fatalf("got %d metrics, want 3", len(ms))
+	}
+	// Metrics are sorted by name.
+	hist, ok := ms[1].Data.(metricdata.Histogram[float64])
+	if ms[1].Name != "bookinfo_latency_ms" || !ok {
+		t.Fatalf("metric = %+v, want the latency histogram", ms[1])
+	}
+	if dp := hist.DataPoints[0]; dp.Count != 3 || dp.Sum != 35 || !dp.StartTime.Equal(start) {
+		t.Errorf("histogram point = %+v, want 3 values adding up to 35 since the start", dp)
+	}
+	sum, ok := ms[2].Data.(metricdata.Sum[float64])
+	if ms[2].Name != "bookinfo_requests" || !ok || !sum.IsMonotonic || sum.Temporality != metricdata.CumulativeTemporality {
+		t.Fatalf("metric = %+v, want the requests as a cumulative sum", ms[2])
+	}
+	dp := sum.DataPoints[0]
+	if component, _ := dp.Attributes.Value(attribute.Key("component")); dp.Value != 7 || component.AsString() != "details" {
+		t.Errorf("sum point = %+v, want 7 requests to details", dp)
+	}
+	if _, ok := ms[0].Data.(metricdata.Gauge[float64]); !ok {
+		t.Errorf("metric = %+v, want the in flight gauge", ms[0])
+	}
+}
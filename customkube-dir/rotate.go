@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// rotatingFile is an append-only file that is rotated when it reaches a
+// maximum size. The current file is path, older ones are path.1 (newest) up
+// to path.<maxFiles>; the oldest is removed on rotation.
+//
+// A single Write is never split across files, so writing one JSON document
+// per Write yields files of whole lines.
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// newRotatingFile opens path for appending. A maxSize of zero disables
+// rotation.
+func newRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	r := &rotatingFile{path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, info.Size()
+	return nil
+}
+
+// Write implements io.Writer.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.maxSize > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxSize {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+// rotate shifts path.i to path.i+1, moves the current file to path.1 and
+// opens a new one.
+func (r *rotatingFile) rotate() error {
+	if err := r.f.Close(); err != nil {
+		return err
+	}
+	if r.maxFiles <= 0 {
+		if err := os.Remove(r.path); err != nil {
+			return err
+		}
+		return r.open()
+	}
+	os.Remove(fmt.Sprintf("%s.%d", r.path, r.maxFiles))
+	for i := r.maxFiles - 1; i >= 1; i-- {
+		os.Rename(fmt.Sprintf("%s.%d", r.path, i), fmt.Sprintf("%s.%d", r.path, i+1))
+	}
+	if err := os.Rename(r.path, r.path+".1"); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+// Close closes the current file.
+func (r *rotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.f.Close()
+}
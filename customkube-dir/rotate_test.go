@@ -0,0 +1,65 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.jsonl")
+	f, err := newRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every line is 6 bytes, so every file holds one: a second one would
+	// take it over 10 bytes.
+	for _, line := range []string{"one..\n", "two..\n", "three\n", "four.\n"} {
+		if _, err := f.Write([]byte(line)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]string{
+		path:        "four.\n",
+		path + ".1": "three\n",
+		path + ".2": "two..\n",
+	} {
+		if got, err := os.ReadFile(name); err != nil || string(got) != want {
+			t.Errorf("%s = %q, %v, want %q", filepath.Base(name), got, err, want)
+		}
+	}
+	// Only maxFiles rotated files are kept.
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("%s.3 exists, want the oldest file removed", filepath.Base(path))
+	}
+
+	// Reopening appends, counting the size already written.
+	if f, err = newRotatingFile(path, 10, 2); err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("five\n"))
+	f.Close()
+	if got, _ := os.ReadFile(path + ".1"); string(got) != "four.\n" {
+		t.Errorf("%s.1 after reopening = %q, want the file before it", filepath.Base(path), got)
+	}
+}
+
+func TestRotatingFileWithoutBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs.jsonl")
+	f, err := newRotatingFile(path, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Write([]byte("one..\n"))
+	f.Write([]byte("two..\n"))
+	f.Close()
+	if got, _ := os.ReadFile(path); string(got) != "two..\n" {
+		t.Errorf("file = %q, want only the last line", got)
+	}
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Error("a rotated file was kept, want none")
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/jaeger"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// traceExporters are the span exporters that can be listed in
+// customkube.traces.exporters, by name.
+var traceExporters = map[string]func(context.Context, traceConfig) (trace.SpanExporter, error){
+	"jaeger": func(_ context.Context, c traceConfig) (trace.SpanExporter, error) {
+		return jaeger.New(jaeger.WithCollectorEndpoint(jaeger.WithEndpoint(c.JaegerEndpoint)))
+	},
+	"otlp-grpc": func(ctx context.Context, c traceConfig) (trace.SpanExporter, error) {
+		var opts []otlptracegrpc.Option
+		if c.OTLPEndpoint != "" {
+			opts = append(opts, otlptracegrpc.WithEndpoint(c.OTLPEndpoint))
+		}
+		if c.OTLPInsecure {
+			opts = append(opts, otlptracegrpc.WithInsecure())
+		}
+		return otlptracegrpc.New(ctx, opts...)
+	},
+	"otlp-http": func(ctx context.Context, c traceConfig) (trace.SpanExporter, error) {
+		var opts []otlptracehttp.Option
+		if c.OTLPEndpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpoint(c.OTLPEndpoint))
+		}
+		if c.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		return otlptracehttp.New(ctx, opts...)
+	},
+	"zipkin": func(_ context.Context, c traceConfig) (trace.SpanExporter, error) {
+		return newZipkinExporter(c.ZipkinEndpoint), nil
+	},
+	"stdout": func(context.Context, traceConfig) (trace.SpanExporter, error) {
+		return stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+	},
+	"file": func(_ context.Context, c traceConfig) (trace.SpanExporter, error) {
+		f, err := newRotatingFile(c.File, c.MaxFileSize<<20, c.MaxFiles)
+		if err != nil {
+			return nil, err
+		}
+		return stdouttrace.New(stdouttrace.WithWriter(f))
+	},
+}
+
+// multiExporter exports spans to several exporters.
+type multiExporter []trace.SpanExporter
+
+// ExportSpans implements trace.SpanExporter.
+func (m multiExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	var errs []error
+	for _, e := range m {
+		errs = append(errs, e.ExportSpans(ctx, spans))
+	}
+	return errors.Join(errs...)
+}
+
+// Shutdown implements trace.SpanExporter.
+func (m multiExporter) Shutdown(ctx context.Context) error {
+	var errs []error
+	for _, e := range m {
+		errs = append(errs, e.Shutdown(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// newTraceHandler returns the babysitter's HandleTraceSpans plugin. Spans of
+// sampled traces are queued and exported in batches, in the background, to
+// every configured exporter. It returns nil if no exporter is configured, in
+// which case spans are dropped.
+//
+// The SDK's BatchSpanProcessor can't be used: it drops spans whose context
+// isn't marked as sampled, and the spans the weavelets send aren't.
+func newTraceHandler(ctx context.Context, c traceConfig) (func(context.Context, []trace.ReadOnlySpan) error, error) {
+	var exporters multiExporter
+	for _, name := range c.Exporters {
+		if name == "none" {
+			continue
+		}
+		e, err := traceExporters[name](ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("trace exporter %q: %w", name, err)
+		}
+		exporters = append(exporters, e)
+	}
+	if len(exporters) == 0 || c.SamplingRate == 0 {
+		return nil, nil
+	}
+
+	timeout, err := c.batchTimeout()
+	if err != nil {
+		return nil, err
+	}
+	b := newBatcher(ctx, "spans", c.MaxBatchSize, c.MaxQueueSize, timeout, func(ctx context.Context, spans []trace.ReadOnlySpan) error {
+		return exporters.ExportSpans(ctx, spans)
+	})
+
+	// Sampling is by trace ID, so every replica keeps or drops the spans of
+	// a trace alike and sampled traces are complete.
+	sampler := trace.TraceIDRatioBased(c.SamplingRate)
+	return func(_ context.Context, spans []trace.ReadOnlySpan) error {
+		for _, s := range spans {
+			params := trace.SamplingParameters{TraceID: s.SpanContext().TraceID()}
+			if sampler.ShouldSample(params).Decision == trace.RecordAndSample {
+				b.Add(s)
+			}
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// zipkinExporter posts spans to a Zipkin collector using the v2 JSON API.
+//
+// The upstream Zipkin exporter requires a newer OpenTelemetry SDK than the
+// one Service Weaver is built with, and the span model is small enough to
+// encode here.
+type zipkinExporter struct {
+	url    string
+	client *http.Client
+}
+
+func newZipkinExporter(url string) *zipkinExporter {
+	return &zipkinExporter{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// zipkinSpan is a span in the Zipkin v2 model.
+type zipkinSpan struct {
+	TraceID       string             `json:"traceId"`
+	ID            string             `json:"id"`
+	ParentID      string             `json:"parentId,omitempty"`
+	Name          string             `json:"name"`
+	Kind          string             `json:"kind,omitempty"`
+	Timestamp     int64              `json:"timestamp"` // µs since the epoch
+	Duration      int64              `json:"duration"`  // µs
+	LocalEndpoint zipkinEndpoint     `json:"localEndpoint"`
+	Annotations   []zipkinAnnotation `json:"annotations,omitempty"`
+	Tags          map[string]string  `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+type zipkinAnnotation struct {
+	Timestamp int64  `json:"timestamp"`
+	Value     string `json:"value"`
+}
+
+var zipkinKinds = map[oteltrace.SpanKind]string{
+	oteltrace.SpanKindServer:   "SERVER",
+	oteltrace.SpanKindClient:   "CLIENT",
+	oteltrace.SpanKindProducer: "PRODUCER",
+	oteltrace.SpanKindConsumer: "CONSUMER",
+}
+
+func toZipkin(s trace.ReadOnlySpan) zipkinSpan {
+	z := zipkinSpan{
+		TraceID:   s.SpanContext().TraceID().String(),
+		ID:        s.SpanContext().SpanID().String(),
+		Name:      s.Name(),
+		Kind:      zipkinKinds[s.SpanKind()],
+		Timestamp: s.StartTime().UnixMicro(),
+		Duration:  s.EndTime().Sub(s.StartTime()).Microseconds(),
+		Tags:      map[string]string{},
+	}
+	if p := s.Parent(); p.SpanID().IsValid() {
+		z.ParentID = p.SpanID().String()
+	}
+	if res := s.Resource(); res != nil {
+		if v, ok := res.Set().Value("service.name"); ok {
+			z.LocalEndpoint.ServiceName = v.Emit()
+		}
+	}
+	for _, a := range s.Attributes() {
+		z.Tags[string(a.Key)] = a.Value.Emit()
+	}
+	if st := s.Status(); st.Code == codes.Error {
+		z.Tags["error"] = st.Description
+	}
+	for _, e := range s.Events() {
+		z.Annotations = append(z.Annotations, zipkinAnnotation{Timestamp: e.Time.UnixMicro(), Value: e.Name})
+	}
+	return z
+}
+
+// ExportSpans implements trace.SpanExporter.
+func (z *zipkinExporter) ExportSpans(ctx context.Context, spans []trace.ReadOnlySpan) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	batch := make([]zipkinSpan, len(spans))
+	for i, s := range spans {
+		batch[i] = toZipkin(s)
+	}
+	body, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, z.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := z.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("zipkin: POST %s: %s", z.url, resp.Status)
+	}
+	return nil
+}
+
+// Shutdown implements trace.SpanExporter.
+func (z *zipkinExporter) Shutdown(context.Context) error {
+	return nil
+}
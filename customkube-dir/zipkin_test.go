@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	"go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	oteltrace "go.opentelemetry.io/otel/trace"
+)
+
+// testSpan returns a client span of a call to details, child of another
+// span, that failed.
+func testSpan() trace.ReadOnlySpan {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	traceID := oteltrace.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16}
+	return tracetest.SpanStub{
+		Name: "details.Details.GetBookDetails",
+		SpanContext: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  oteltrace.SpanID{0, 0, 0, 0, 0, 0, 0, 2},
+		}),
+		Parent: oteltrace.NewSpanContext(oteltrace.SpanContextConfig{
+			TraceID: traceID,
+			SpanID:  oteltrace.SpanID{0, 0, 0, 0, 0, 0, 0, 1},
+		}),
+		SpanKind:   oteltrace.SpanKindClient,
+		StartTime:  start,
+		EndTime:    start.Add(1500 * time.Microsecond),
+		Attributes: []attribute.KeyValue{attribute.String("product_id", "1"), attribute.Int("attempt", 2)},
+		Events:     []trace.Event{{Name: "retry", Time: start.Add(time.Millisecond)}},
+		Status:     trace.Status{Code: codes.Error, Description: "details is down"},
+		Resource:   resource.NewSchemaless(attribute.String("service.name", "bookinfo")),
+	}.Snapshot()
+}
+
+func TestToZipkin(t *testing.T) {
+	got := toZipkin(testSpan())
+	if got.TraceID != "0102030405060708090a0b0c0d0e0f10" || got.ID != "0000000000000002" || got.ParentID != "0000000000000001" {
+		t.Errorf("IDs = %s/%s/%s, want the trace, span and parent IDs in hex", got.TraceID, got.ID, got.ParentID)
+	}
+	if got.Name != "details.Details.GetBookDetails" || got.Kind != "CLIENT" || got.LocalEndpoint.ServiceName != "bookinfo" {
+		t.Errorf("span = %+v, want a CLIENT span of bookinfo", got)
+	}
+	if got.Timestamp != 1714564800000000 || got.Duration != 1500 {
+		t.Errorf("timestamp, duration = %d, %d, want microseconds", got.Timestamp, got.Duration)
+	}
+	if got.Tags["product_id"] != "1" || got.Tags["attempt"] != "2" || got.Tags["error"] != "details is down" {
+		t.Errorf("tags = %v, want the attributes and the error", got.Tags)
+	}
+	if len(got.Annotations) != 1 || got.Annotations[0].Value != "retry" || got.Annotations[0].Timestamp != 1714564800001000 {
+		t.Errorf("annotations = %+v, want the retry event", got.Annotations)
+	}
+
+	// A root span without a kind leaves both out.
+	root := toZipkin(tracetest.SpanStub{Name: "root"}.Snapshot())
+	if root.ParentID != "" || root.Kind != "" {
+		t.Errorf("root span = %+v, want no parent and no kind", root)
+	}
+}
+
+func TestZipkinExporter(t *testing.T) {
+	var got []zipkinSpan
+	status := http.StatusAccepted
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request = %s with %q, want a JSON POST", r.Method, r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	e := newZipkinExporter(server.URL)
+	ctx := context.Background()
+	if err := e.ExportSpans(ctx, []trace.ReadOnlySpan{testSpan(), testSpan()}); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].Name != "details.Details.GetBookDetails" {
+		t.Errorf("posted %+v, want the two spans", got)
+	}
+
+	status = http.StatusInternalServerError
+	if err := e.ExportSpans(ctx, []trace.ReadOnlySpan{testSpan()}); err == nil {
+		t.Error("ExportSpans() with a failing collector succeeded")
+	}
+}
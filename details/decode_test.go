@@ -0,0 +1,143 @@
+package details
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+)
+
+// discard is a logger that drops everything.
+var discard = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// googleResponse is an abridged Google Books response for ISBN 0486424618.
+const googleResponse = `{
+  "kind": "books#volumes",
+  "totalItems": 1,
+  "items": [{
+    "volumeInfo": {
+      "title": "The Comedy of Errors",
+      "authors": ["William Shakespeare"],
+      "publisher": "Courier Corporation",
+      "publishedDate": "2002-11-05",
+      "industryIdentifiers": [
+        {"type": "ISBN_10", "identifier": "0486424618"},
+        {"type": "ISBN_13", "identifier": "9780486424613"}
+      ],
+      "pageCount": 66,
+      "printType": "BOOK",
+      "language": "en"
+    }
+  }]
+}`
+
+func TestDecodeGoogleBook(t *testing.T) {
+	got, err := decodeGoogleBook(discard, strings.NewReader(googleResponse), 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := BookDetails{
+		ID:        7,
+		Author:    "William Shakespeare",
+		Year:      2002,
+		Type:      "paperback",
+		Pages:     66,
+		Publisher: "Courier Corporation",
+		Language:  "English",
+		ISBN10:    "0486424618",
+		ISBN13:    "9780486424613",
+	}
+	if got != want {
+		t.Errorf("decodeGoogleBook() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDecodeGoogleBookErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, body string
+	}{
+		{"empty", ``},
+		{"truncated", `{"items": [`},
+		{"no items", `{"totalItems": 0}`},
+		{"wrong type", `{"items": [{"volumeInfo": {"authors": "William Shakespeare"}}]}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if got, err := decodeGoogleBook(discard, strings.NewReader(test.body), 1); err == nil {
+				t.Errorf("decodeGoogleBook(%q) = %+v, want error", test.body, got)
+			}
+		})
+	}
+}
+
+// TestDecodeGoogleBookRoundTrip checks that the details encoded as a Google
+// Books response decode back to themselves.
+func TestDecodeGoogleBookRoundTrip(t *testing.T) {
+	roundTrip := func(id int, author, publisher string, year uint16, pages uint16, isbn10, isbn13 string) bool {
+		want := BookDetails{
+			ID:        id,
+			Author:    author,
+			Year:      1000 + int(year)%9000, // four digits
+			Type:      "paperback",
+			Pages:     int(pages),
+			Publisher: publisher,
+			Language:  "English",
+			ISBN10:    isbn10,
+			ISBN13:    isbn13,
+		}
+		info := map[string]any{
+			"authors":       []string{want.Author},
+			"publisher":     want.Publisher,
+			"publishedDate": strconv.Itoa(want.Year) + "-01-01",
+			"pageCount":     want.Pages,
+			"printType":     "BOOK",
+			"language":      "en",
+			"industryIdentifiers": []map[string]string{
+				{"type": "ISBN_10", "identifier": want.ISBN10},
+				{"type": "ISBN_13", "identifier": want.ISBN13},
+			},
+		}
+		body, err := json.Marshal(map[string]any{"items": []any{map[string]any{"volumeInfo": info}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := decodeGoogleBook(discard, strings.NewReader(string(body)), id)
+		if err != nil {
+			t.Logf("decodeGoogleBook(%s): %v", body, err)
+			return false
+		}
+		if got != want {
+			t.Logf("decodeGoogleBook(%s) = %+v, want %+v", body, got, want)
+			return false
+		}
+		return true
+	}
+	if err := quick.Check(roundTrip, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func FuzzDecodeGoogleBook(f *testing.F) {
+	f.Add(googleResponse, 1)
+	f.Add(`{"items": [{"volumeInfo": {}}]}`, 0)
+	f.Add(`{"items": [{"volumeInfo": {"publishedDate": "95", "pageCount": 1e300}}]}`, -1)
+	f.Add(`{"items": [{"volumeInfo": {"industryIdentifiers": [null, {"type": "ISBN_13"}]}}]}`, 3)
+	f.Add(`{"items": []}`, 2)
+	f.Fuzz(func(t *testing.T, body string, id int) {
+		got, err := decodeGoogleBook(discard, strings.NewReader(body), id)
+		if err != nil {
+			return
+		}
+		if got.ID != id {
+			t.Errorf("decodeGoogleBook(%q, %d): ID = %d", body, id, got.ID)
+		}
+		if got.Type != "paperback" && got.Type != "unknown" {
+			t.Errorf("decodeGoogleBook(%q): Type = %q", body, got.Type)
+		}
+		if got.Language != "English" && got.Language != "unknown" {
+			t.Errorf("decodeGoogleBook(%q): Language = %q", body, got.Language)
+		}
+	})
+}
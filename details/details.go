@@ -3,13 +3,21 @@ package details
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"golang.org/x/sync/errgroup"
 )
 
 type BookDetails struct {
@@ -27,36 +35,156 @@ type BookDetails struct {
 
 type Details interface {
 	GetBookDetails(context.Context, int, map[string]string) (BookDetails, error)
+	// GetBookDetailsBatch returns the details of the products ids, by ID.
+	// Products that aren't in the catalog are left out.
+	GetBookDetailsBatch(context.Context, []int) (map[int]BookDetails, error)
+	Health(context.Context) error
+	Describe(context.Context) (topology.Replica, error)
 }
 
 type details struct {
 	weaver.Implements[Details]
+	weaver.WithConfig[logging.Config]
+	catalog weaver.Ref[catalog.Catalog]
+	name    string     // full component name, used as a metric label
+	level   slog.Level // minimum level logged
 }
 
-func (d *details) GetBookDetails(_ context.Context, id int, headers map[string]string) (BookDetails, error) {
+// Init records the component's name, used to label its metrics, and its
+// log level.
+func (d *details) Init(context.Context) error {
+	d.name = topology.Name[Details]()
+	level, err := d.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	d.level = level
+	return nil
+}
+
+// logger returns the component's logger for the request in ctx.
+func (d *details) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, d.Logger(ctx), d.level)
+}
+
+// GetBookDetails returns the details of the catalog's product id, or the
+// catalog's NotFoundError. With ENABLE_EXTERNAL_BOOK_SERVICE=true, they're
+// fetched from Google Books by the product's ISBN-10.
+func (d *details) GetBookDetails(ctx context.Context, id int, headers map[string]string) (BookDetails, error) {
+	product, err := d.catalog.Get().Get(ctx, id)
+	if err != nil {
+		return BookDetails{}, err
+	}
+	return d.bookDetails(ctx, product, headers)
+}
+
+// GetBookDetailsBatch implements Details. It looks up every product as
+// GetBookDetails does, with getEach.
+func (d *details) GetBookDetailsBatch(ctx context.Context, ids []int) (map[int]BookDetails, error) {
+	return getEach(ctx, ids, d.GetBookDetails)
+}
+
+// maxParallel is the maximum number of products a batch looks up at a time,
+// which bounds the concurrent requests to an external service.
+const maxParallel = 8
+
+// getEach returns the details of the products ids, by ID, getting each one
+// with get, concurrently, at most maxParallel at a time. Products get
+// reports as a catalog.NotFoundError are left out. If a lookup fails, the
+// others are canceled and its error is returned.
+func getEach(ctx context.Context, ids []int, get func(context.Context, int, map[string]string) (BookDetails, error)) (map[int]BookDetails, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallel)
+	var mu sync.Mutex // guards books
+	books := map[int]BookDetails{}
+	requested := map[int]bool{}
+	for _, id := range ids {
+		if requested[id] {
+			continue
+		}
+		requested[id] = true
+		g.Go(func() error {
+			book, err := get(ctx, id, nil)
+			if errors.As(err, new(catalog.NotFoundError)) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			books[id] = book
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return books, nil
+}
+
+// bookDetails returns the details of product.
+func (d *details) bookDetails(ctx context.Context, product catalog.Product, headers map[string]string) (BookDetails, error) {
+	id := product.ID
+	logger := d.logger(ctx).With("product_id", id)
 	if os.Getenv("ENABLE_EXTERNAL_BOOK_SERVICE") == "true" {
-		isbn := "0486424618"
-		return fetchDetailsFromExternalService(isbn, id, headers)
+		lookups.Get(lookupLabels{Component: d.name, Source: sourceExternal}).Inc()
+		isbn := product.ISBN10
+		start := time.Now()
+		book, err := fetchDetailsFromExternalService(ctx, logger, isbn, id, headers)
+		elapsed := time.Since(start)
+		externalLatency.Get(externalLabels{Component: d.name, Error: err != nil}).Put(float64(elapsed.Microseconds()) / 1000)
+		if err != nil {
+			logger.Error("Could not fetch book details from Google Books", "isbn", isbn, "err", err)
+			return BookDetails{}, err
+		}
+		logger.Debug("Fetched book details from Google Books", "isbn", isbn, "elapsed", elapsed)
+		return book, nil
 	}
 
+	lookups.Get(lookupLabels{Component: d.name, Source: sourceLocal}).Inc()
+	logger.Debug("Serving local book details")
+
 	return BookDetails{
 		ID:        id,
-		Author:    "William Shakespeare",
-		Year:      1595,
-		Type:      "paperback",
-		Pages:     200,
-		Publisher: "PublisherA",
-		Language:  "English",
-		ISBN10:    "1234567890",
-		ISBN13:    "123-1234567890",
+		Author:    product.Author,
+		Year:      product.Year,
+		Type:      product.Type,
+		Pages:     product.Pages,
+		Publisher: product.Publisher,
+		Language:  product.Language,
+		ISBN10:    product.ISBN10,
+		ISBN13:    product.ISBN13,
 	}, nil
 }
 
-func fetchDetailsFromExternalService(isbn string, id int, headers map[string]string) (BookDetails, error) {
-	client := &http.Client{Timeout: 5 * time.Second}
-	url := "https://www.googleapis.com/books/v1/volumes?q=isbn:" + isbn
+// Health reports whether the details component is able to serve requests.
+func (d *details) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the details component serving the call.
+func (d *details) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Details](), nil
+}
+
+// googleBooksURL is the Google Books volumes API. Tests point it at a fake.
+var googleBooksURL = "https://www.googleapis.com/books/v1/volumes"
+
+// googleBooksClient is shared by every lookup, so connections to Google
+// Books are reused.
+var googleBooksClient = &http.Client{Timeout: 5 * time.Second}
+
+// maxGoogleBookSize is the most of a Google Books response that is read. A
+// volumes response for one ISBN is a few kilobytes.
+const maxGoogleBookSize = 1 << 20
 
-	req, err := http.NewRequest("GET", url, nil)
+// fetchDetailsFromExternalService looks up product id in Google Books by
+// its ISBN-10. A response other than a 2xx is an error.
+func fetchDetailsFromExternalService(ctx context.Context, logger *slog.Logger, isbn string, id int, headers map[string]string) (BookDetails, error) {
+	url := googleBooksURL + "?q=isbn:" + isbn
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return BookDetails{}, err
 	}
@@ -65,61 +193,92 @@ func fetchDetailsFromExternalService(isbn string, id int, headers map[string]str
 		req.Header.Add(key, value)
 	}
 
-	resp, err := client.Do(req)
+	resp, err := googleBooksClient.Do(req)
 	if err != nil {
 		return BookDetails{}, err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return BookDetails{}, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
 
-	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	return decodeGoogleBook(logger, io.LimitReader(resp.Body, maxGoogleBookSize), id)
+}
+
+// googleVolumes is the part of a Google Books volumes response used for the
+// book details.
+type googleVolumes struct {
+	Items []struct {
+		VolumeInfo googleVolumeInfo `json:"volumeInfo"`
+	} `json:"items"`
+}
+
+type googleVolumeInfo struct {
+	Authors             []string `json:"authors"`
+	Publisher           string   `json:"publisher"`
+	PublishedDate       string   `json:"publishedDate"`
+	PageCount           float64  `json:"pageCount"`
+	PrintType           string   `json:"printType"`
+	Language            string   `json:"language"`
+	IndustryIdentifiers []struct {
+		Type       string `json:"type"`
+		Identifier string `json:"identifier"`
+	} `json:"industryIdentifiers"`
+}
+
+// decodeGoogleBook decodes the details of product id from a Google Books
+// volumes response. Only the first volume is used. Missing fields are left
+// empty, and a malformed response is an error rather than a panic.
+func decodeGoogleBook(logger *slog.Logger, r io.Reader, id int) (BookDetails, error) {
+	var result googleVolumes
+	if err := json.NewDecoder(r).Decode(&result); err != nil {
 		return BookDetails{}, err
 	}
+	if len(result.Items) == 0 {
+		return BookDetails{}, errors.New("no book found")
+	}
+	book := result.Items[0].VolumeInfo
 
-	book := result["items"].([]interface{})[0].(map[string]interface{})["volumeInfo"].(map[string]interface{})
-
-	language := book["language"].(string)
-	if language == "en" {
+	language := "unknown"
+	if book.Language == "en" {
 		language = "English"
-	} else {
-		language = "unknown"
 	}
 
-	bookType := book["printType"].(string)
-	if bookType == "BOOK" {
+	bookType := "unknown"
+	if book.PrintType == "BOOK" {
 		bookType = "paperback"
-	} else {
-		bookType = "unknown"
 	}
 
-	isbn10 := getISBN(book, "ISBN_10")
-	isbn13 := getISBN(book, "ISBN_13")
+	var author string
+	if len(book.Authors) > 0 {
+		author = book.Authors[0]
+	}
 
-	yearStr := book["publishedDate"].(string)
-	year, err := strconv.Atoi(yearStr[:4])
+	// The published date starts with the year, e.g. "2003" or "2003-01-01".
+	year, err := strconv.Atoi(book.PublishedDate[:min(4, len(book.PublishedDate))])
 	if err != nil {
-		log.Printf("Failed to extract year: %v", err)
+		logger.Warn("Failed to extract year", "published_date", book.PublishedDate, "err", err)
 		year = 0
 	}
 
 	return BookDetails{
 		ID:        id,
-		Author:    book["authors"].([]interface{})[0].(string),
+		Author:    author,
 		Year:      year,
 		Type:      bookType,
-		Pages:     int(book["pageCount"].(float64)),
-		Publisher: book["publisher"].(string),
+		Pages:     int(book.PageCount),
+		Publisher: book.Publisher,
 		Language:  language,
-		ISBN10:    isbn10,
-		ISBN13:    isbn13,
+		ISBN10:    book.isbn("ISBN_10"),
+		ISBN13:    book.isbn("ISBN_13"),
 	}, nil
 }
 
-func getISBN(book map[string]interface{}, isbnType string) string {
-	for _, identifier := range book["industryIdentifiers"].([]interface{}) {
-		id := identifier.(map[string]interface{})
-		if id["type"] == isbnType {
-			return id["identifier"].(string)
+// isbn returns the identifier of type isbnType, or "" if there is none.
+func (book googleVolumeInfo) isbn(isbnType string) string {
+	for _, id := range book.IndustryIdentifiers {
+		if id.Type == isbnType {
+			return id.Identifier
 		}
 	}
 	return ""
@@ -0,0 +1,155 @@
+package details
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+func TestGetBookDetails(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, d Details) {
+			for _, id := range []int{0, 1, 42} {
+				got, err := d.GetBookDetails(context.Background(), id, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				want := BookDetails{
+					ID:        id,
+					Author:    "William Shakespeare",
+					Year:      1595,
+					Type:      "paperback",
+					Pages:     200,
+					Publisher: "PublisherA",
+					Language:  "English",
+					ISBN10:    "1234567890",
+					ISBN13:    "123-1234567890",
+				}
+				if got != want {
+					t.Errorf("GetBookDetails(%d) = %+v, want %+v", id, got, want)
+				}
+			}
+
+			var notFound catalog.NotFoundError
+			if _, err := d.GetBookDetails(context.Background(), 1000, nil); !errors.As(err, &notFound) || notFound.ID != 1000 {
+				t.Errorf("GetBookDetails(1000) = %v, want a catalog.NotFoundError", err)
+			}
+		})
+	}
+}
+
+func TestGetBookDetailsBatch(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, d Details) {
+			ctx := context.Background()
+			got, err := d.GetBookDetailsBatch(ctx, []int{0, 3, 1000})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 {
+				t.Errorf("GetBookDetailsBatch(0, 3, 1000) = %+v, want products 0 and 3", got)
+			}
+			for _, id := range []int{0, 3} {
+				want, err := d.GetBookDetails(ctx, id, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got[id] != want {
+					t.Errorf("GetBookDetailsBatch()[%d] = %+v, want %+v", id, got[id], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHealth(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, d Details) {
+			if err := d.Health(context.Background()); err != nil {
+				t.Errorf("Health() = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestExternalService(t *testing.T) {
+	t.Setenv("ENABLE_EXTERNAL_BOOK_SERVICE", "true")
+	var mu sync.Mutex
+	inFlight, peak := 0, 0
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		peak = max(peak, inFlight)
+		code := status
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+		}()
+		time.Sleep(20 * time.Millisecond)
+		if code != http.StatusOK {
+			http.Error(w, "quota exceeded", code)
+			return
+		}
+		io.WriteString(w, googleResponse)
+	}))
+	defer server.Close()
+	defer func(url string) { googleBooksURL = url }(googleBooksURL)
+	googleBooksURL = server.URL
+
+	weavertest.Local.Test(t, func(t *testing.T, d Details) {
+		ctx := context.Background()
+		var ids []int
+		for id := range 3 * maxParallel {
+			ids = append(ids, id)
+		}
+		books, err := d.GetBookDetailsBatch(ctx, ids)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(books) != len(ids) || books[5].Publisher != "Courier Corporation" {
+			t.Errorf("GetBookDetailsBatch() = %+v, want %d books from Google Books", books, len(ids))
+		}
+		if peak < 2 || peak > maxParallel {
+			t.Errorf("GetBookDetailsBatch() made up to %d concurrent requests, want 2 to %d", peak, maxParallel)
+		}
+
+		mu.Lock()
+		status = http.StatusTooManyRequests
+		mu.Unlock()
+		if book, err := d.GetBookDetails(ctx, 1, nil); err == nil || !strings.Contains(err.Error(), "429") {
+			t.Errorf("GetBookDetails() with a 429 from Google Books = %+v, %v, want an error", book, err)
+		}
+		if books, err := d.GetBookDetailsBatch(ctx, ids); err == nil {
+			t.Errorf("GetBookDetailsBatch() with a 429 from Google Books = %+v, want an error", books)
+		}
+	})
+}
+
+func TestExternalServiceLargeResponse(t *testing.T) {
+	// Only the first maxGoogleBookSize bytes are read, which end in the
+	// middle of the padding.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"padding": "`+strings.Repeat("x", 2*maxGoogleBookSize)+`", `+strings.TrimPrefix(googleResponse, "{"))
+	}))
+	defer server.Close()
+	defer func(url string) { googleBooksURL = url }(googleBooksURL)
+	googleBooksURL = server.URL
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if book, err := fetchDetailsFromExternalService(context.Background(), logger, "0486424618", 1, nil); err == nil {
+		t.Errorf("fetchDetailsFromExternalService() of a %d byte response = %+v, want an error", 2*maxGoogleBookSize, book)
+	}
+}
@@ -0,0 +1,34 @@
+package details
+
+import "github.com/ServiceWeaver/weaver/metrics"
+
+// Sources of book details.
+const (
+	sourceLocal    = "local"    // the built-in details
+	sourceExternal = "external" // the Google Books API
+)
+
+// lookupLabels label the details lookups. Component is the full name of the
+// details component, as in Service Weaver's method metrics.
+type lookupLabels struct {
+	Component string
+	Source    string
+}
+
+// externalLabels label the calls to the external book service.
+type externalLabels struct {
+	Component string
+	Error     bool
+}
+
+var (
+	lookups = metrics.NewCounterMap[lookupLabels](
+		"bookinfo_details_lookups",
+		"Book details lookups, by source (local or external)",
+	)
+	externalLatency = metrics.NewHistogramMap[externalLabels](
+		"bookinfo_details_external_latency_ms",
+		"Latency of the external book service, in milliseconds",
+		[]float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000},
+	)
+)
@@ -0,0 +1,58 @@
+package details
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// remoteDetails calls an original Bookinfo details service, which serves
+// GET /details/{id} and GET /health.
+type remoteDetails struct {
+	client *remote.Client
+}
+
+var _ Details = (*remoteDetails)(nil)
+
+// NewRemote returns a Details that calls the original details service at
+// baseURL, e.g. "http://details:9080".
+func NewRemote(baseURL string) (Details, error) {
+	client, err := remote.NewClient(topology.Name[Details](), baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteDetails{client: client}, nil
+}
+
+// GetBookDetails implements Details. The headers are sent with the request,
+// and a 404 is returned as a catalog.NotFoundError.
+func (d *remoteDetails) GetBookDetails(ctx context.Context, id int, headers map[string]string) (BookDetails, error) {
+	var book BookDetails
+	err := d.client.Call(ctx, "GetBookDetails", http.MethodGet, "/details/"+strconv.Itoa(id), nil, headers, &book)
+	var e *remote.Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusNotFound {
+		return BookDetails{}, catalog.NotFoundError{ID: id}
+	}
+	return book, err
+}
+
+// GetBookDetailsBatch implements Details. The original service has no batch
+// API, so the products are requested one per call, with getEach.
+func (d *remoteDetails) GetBookDetailsBatch(ctx context.Context, ids []int) (map[int]BookDetails, error) {
+	return getEach(ctx, ids, d.GetBookDetails)
+}
+
+// Health implements Details.
+func (d *remoteDetails) Health(ctx context.Context) error {
+	return d.client.Call(ctx, "Health", http.MethodGet, "/health", nil, nil, nil)
+}
+
+// Describe implements Details.
+func (d *remoteDetails) Describe(context.Context) (topology.Replica, error) {
+	return d.client.Describe(), nil
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"reflect"
@@ -20,10 +21,10 @@ func init() {
 		Iface: reflect.TypeOf((*Details)(nil)).Elem(),
 		Impl:  reflect.TypeOf(details{}),
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return details_local_stub{impl: impl.(Details), tracer: tracer, getBookDetailsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "GetBookDetails", Remote: false, Generated: true})}
+			return details_local_stub{impl: impl.(Details), tracer: tracer, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "Describe", Remote: false, Generated: true}), getBookDetailsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "GetBookDetails", Remote: false, Generated: true}), getBookDetailsBatchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "GetBookDetailsBatch", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "Health", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return details_client_stub{stub: stub, getBookDetailsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "GetBookDetails", Remote: true, Generated: true})}
+			return details_client_stub{stub: stub, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "Describe", Remote: true, Generated: true}), getBookDetailsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "GetBookDetails", Remote: true, Generated: true}), getBookDetailsBatchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "GetBookDetailsBatch", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details", Method: "Health", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return details_server_stub{impl: impl.(Details), addLoad: addLoad}
@@ -31,7 +32,7 @@ func init() {
 		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
 			return details_reflect_stub{caller: caller}
 		},
-		RefData: "",
+		RefData: "⟦553a442a:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details→github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog⟧\n",
 	})
 }
 
@@ -44,14 +45,37 @@ var _ weaver.Unrouted = (*details)(nil)
 // Local stub implementations.
 
 type details_local_stub struct {
-	impl                  Details
-	tracer                trace.Tracer
-	getBookDetailsMetrics *codegen.MethodMetrics
+	impl                       Details
+	tracer                     trace.Tracer
+	describeMetrics            *codegen.MethodMetrics
+	getBookDetailsMetrics      *codegen.MethodMetrics
+	getBookDetailsBatchMetrics *codegen.MethodMetrics
+	healthMetrics              *codegen.MethodMetrics
 }
 
 // Check that details_local_stub implements the Details interface.
 var _ Details = (*details_local_stub)(nil)
 
+func (s details_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "details.Details.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
 func (s details_local_stub) GetBookDetails(ctx context.Context, a0 int, a1 map[string]string) (r0 BookDetails, err error) {
 	// Update metrics.
 	begin := s.getBookDetailsMetrics.Begin()
@@ -72,16 +96,106 @@ func (s details_local_stub) GetBookDetails(ctx context.Context, a0 int, a1 map[s
 	return s.impl.GetBookDetails(ctx, a0, a1)
 }
 
+func (s details_local_stub) GetBookDetailsBatch(ctx context.Context, a0 []int) (r0 map[int]BookDetails, err error) {
+	// Update metrics.
+	begin := s.getBookDetailsBatchMetrics.Begin()
+	defer func() { s.getBookDetailsBatchMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "details.Details.GetBookDetailsBatch", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.GetBookDetailsBatch(ctx, a0)
+}
+
+func (s details_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "details.Details.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
 // Client stub implementations.
 
 type details_client_stub struct {
-	stub                  codegen.Stub
-	getBookDetailsMetrics *codegen.MethodMetrics
+	stub                       codegen.Stub
+	describeMetrics            *codegen.MethodMetrics
+	getBookDetailsMetrics      *codegen.MethodMetrics
+	getBookDetailsBatchMetrics *codegen.MethodMetrics
+	healthMetrics              *codegen.MethodMetrics
 }
 
 // Check that details_client_stub implements the Details interface.
 var _ Details = (*details_client_stub)(nil)
 
+func (s details_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "details.Details.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
 func (s details_client_stub) GetBookDetails(ctx context.Context, a0 int, a1 map[string]string) (r0 BookDetails, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
@@ -120,7 +234,7 @@ func (s details_client_stub) GetBookDetails(ctx context.Context, a0 int, a1 map[
 	// Call the remote method.
 	requestBytes = len(enc.Data())
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
 	replyBytes = len(results)
 	if err != nil {
 		err = errors.Join(weaver.RemoteCallError, err)
@@ -134,6 +248,108 @@ func (s details_client_stub) GetBookDetails(ctx context.Context, a0 int, a1 map[
 	return
 }
 
+func (s details_client_stub) GetBookDetailsBatch(ctx context.Context, a0 []int) (r0 map[int]BookDetails, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getBookDetailsBatchMetrics.Begin()
+	defer func() { s.getBookDetailsBatchMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "details.Details.GetBookDetailsBatch", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 8))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_int_7c8c8866(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_map_int_BookDetails_d03a606a(dec)
+	err = dec.Error()
+	return
+}
+
+func (s details_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "details.Details.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
@@ -170,13 +386,39 @@ var _ codegen.Server = (*details_server_stub)(nil)
 // GetStubFn implements the codegen.Server interface.
 func (s details_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
 	switch method {
+	case "Describe":
+		return s.describe
 	case "GetBookDetails":
 		return s.getBookDetails
+	case "GetBookDetailsBatch":
+		return s.getBookDetailsBatch
+	case "Health":
+		return s.health
 	default:
 		return nil
 	}
 }
 
+func (s details_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 func (s details_server_stub) getBookDetails(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
@@ -204,6 +446,50 @@ func (s details_server_stub) getBookDetails(ctx context.Context, args []byte) (r
 	return enc.Data(), nil
 }
 
+func (s details_server_stub) getBookDetailsBatch(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []int
+	a0 = serviceweaver_dec_slice_int_7c8c8866(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetBookDetailsBatch(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_map_int_BookDetails_d03a606a(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s details_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 // Reflect stub implementations.
 
 type details_reflect_stub struct {
@@ -213,11 +499,26 @@ type details_reflect_stub struct {
 // Check that details_reflect_stub implements the Details interface.
 var _ Details = (*details_reflect_stub)(nil)
 
+func (s details_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
 func (s details_reflect_stub) GetBookDetails(ctx context.Context, a0 int, a1 map[string]string) (r0 BookDetails, err error) {
 	err = s.caller("GetBookDetails", ctx, []any{a0, a1}, []any{&r0})
 	return
 }
 
+func (s details_reflect_stub) GetBookDetailsBatch(ctx context.Context, a0 []int) (r0 map[int]BookDetails, err error) {
+	err = s.caller("GetBookDetailsBatch", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s details_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
 // AutoMarshal implementations.
 
 var _ codegen.AutoMarshal = (*BookDetails)(nil)
@@ -296,3 +597,54 @@ func serviceweaver_dec_map_string_string_219dd46d(dec *codegen.Decoder) map[stri
 	}
 	return res
 }
+
+func serviceweaver_enc_slice_int_7c8c8866(enc *codegen.Encoder, arg []int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Int(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]int, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Int()
+	}
+	return res
+}
+
+func serviceweaver_enc_map_int_BookDetails_d03a606a(enc *codegen.Encoder, arg map[int]BookDetails) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for k, v := range arg {
+		enc.Int(k)
+		(v).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_map_int_BookDetails_d03a606a(dec *codegen.Decoder) map[int]BookDetails {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make(map[int]BookDetails, n)
+	var k int
+	var v BookDetails
+	for i := 0; i < n; i++ {
+		k = dec.Int()
+		(&v).WeaverUnmarshal(dec)
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,159 @@
+// Package fakes provides fake implementations of the Bookinfo components for
+// tests.
+//
+// The fakes are plain Go values that can replace the real components in a
+// weavertest runner:
+//
+//	fake := fakes.NewRatings()
+//	fake.FailWith("GetRatings", errors.New("ratings are down"))
+//	runner := weavertest.Local
+//	runner.Fakes = append(runner.Fakes, weavertest.Fake[ratings.Ratings](fake))
+//	runner.Test(t, func(t *testing.T, r reviews.Reviews) { ... })
+//
+// Every fake returns scripted responses, which default to the ones of the real
+// component, and embeds a Behavior, which injects errors and latency into its
+// methods and records the calls made to them. Fakes are safe for concurrent
+// use. They run in the test process, so the Multi runner colocates the
+// components that call them with the test.
+//
+// The RPC runner executes every retriable method call twice, to detect
+// methods that aren't idempotent, so a call can reach a fake more than once.
+// An error queued with FailNext is then returned to the first attempt only;
+// use FailWith to make a method fail until told otherwise.
+package fakes
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// AnyMethod makes FailWith, FailNext and SetLatency apply to every method of
+// a fake.
+const AnyMethod = ""
+
+// Call is a call made to a method of a fake.
+type Call struct {
+	Method string
+	Args   []any // the arguments, without the context
+	Time   time.Time
+	Err    error // the error injected into the call, if any
+}
+
+// Behavior injects errors and latency into the methods of a fake and records
+// the calls made to them. Methods are identified by name, e.g. "GetRatings".
+// The zero value is ready to use: no errors, no latency.
+type Behavior struct {
+	mu      sync.Mutex
+	errs    map[string]error         // errors returned by every call
+	next    map[string][]error       // errors returned by the next calls
+	latency map[string]time.Duration // delays before every call returns
+	calls   []Call
+}
+
+// FailWith makes every call to method return err, until it's called again
+// with a nil err.
+func (b *Behavior) FailWith(method string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.errs == nil {
+		b.errs = map[string]error{}
+	}
+	if err == nil {
+		delete(b.errs, method)
+		return
+	}
+	b.errs[method] = err
+}
+
+// FailNext makes the next len(errs) calls to method return errs, in order.
+// They take precedence over the error set by FailWith.
+func (b *Behavior) FailNext(method string, errs ...error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.next == nil {
+		b.next = map[string][]error{}
+	}
+	b.next[method] = append(b.next[method], errs...)
+}
+
+// SetLatency delays every call to method by d. Delayed calls return early
+// with the context's error if it's cancelled.
+func (b *Behavior) SetLatency(method string, d time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.latency == nil {
+		b.latency = map[string]time.Duration{}
+	}
+	b.latency[method] = d
+}
+
+// Calls returns the calls made to the fake, oldest first. With method names,
+// it returns only the calls to those methods.
+func (b *Behavior) Calls(methods ...string) []Call {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(methods) == 0 {
+		return slices.Clone(b.calls)
+	}
+	var calls []Call
+	for _, call := range b.calls {
+		if slices.Contains(methods, call.Method) {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset clears the injected errors and latency and the recorded calls.
+func (b *Behavior) Reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.errs, b.next, b.latency, b.calls = nil, nil, nil, nil
+}
+
+// call records a call to method, waits for its latency and returns the error
+// it must fail with, if any.
+func (b *Behavior) call(ctx context.Context, method string, args ...any) error {
+	b.mu.Lock()
+	err := b.nextErr(method)
+	if err == nil {
+		err = b.nextErr(AnyMethod)
+	}
+	if err == nil {
+		err = b.errs[method]
+	}
+	if err == nil {
+		err = b.errs[AnyMethod]
+	}
+	latency, ok := b.latency[method]
+	if !ok {
+		latency = b.latency[AnyMethod]
+	}
+	b.calls = append(b.calls, Call{Method: method, Args: args, Time: time.Now(), Err: err})
+	b.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
+
+// nextErr pops the next error queued for method.
+//
+// REQUIRES: b.mu is held.
+func (b *Behavior) nextErr(method string) error {
+	errs := b.next[method]
+	if len(errs) == 0 {
+		return nil
+	}
+	b.next[method] = errs[1:]
+	return errs[0]
+}
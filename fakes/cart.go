@@ -0,0 +1,161 @@
+package fakes
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Cart is a fake cart.Cart. Its methods are Get, Add, Set, Clear,
+// RemoveOrdered, Health and Describe.
+//
+// Like the real component, it keeps the carts in memory, and adds only the
+// default products of the catalog to them. It doesn't limit the units or
+// products in a cart.
+type Cart struct {
+	Behavior
+	mu       sync.Mutex
+	products map[int]catalog.Product
+	carts    map[string]map[int]cart.Item // items by product ID, by cart ID
+}
+
+var _ cart.Cart = (*Cart)(nil)
+
+// NewCart returns a fake with no carts.
+func NewCart() *Cart {
+	products, err := catalog.DefaultProducts()
+	if err != nil {
+		panic(err)
+	}
+	c := &Cart{products: map[int]catalog.Product{}, carts: map[string]map[int]cart.Item{}}
+	for _, p := range products {
+		c.products[p.ID] = p
+	}
+	return c
+}
+
+// Get implements cart.Cart.
+func (c *Cart) Get(ctx context.Context, cartID string) (cart.Contents, error) {
+	if err := c.call(ctx, "Get", cartID); err != nil {
+		return cart.Contents{}, err
+	}
+	if err := cart.ValidateID(cartID); err != nil {
+		return cart.Contents{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.contentsLocked(cartID), nil
+}
+
+// Add implements cart.Cart.
+func (c *Cart) Add(ctx context.Context, cartID string, productID int, quantity int) (cart.Contents, error) {
+	if err := c.call(ctx, "Add", cartID, productID, quantity); err != nil {
+		return cart.Contents{}, err
+	}
+	if quantity < 1 {
+		return cart.Contents{}, cart.InvalidError{Reason: "quantities must be positive"}
+	}
+	return c.set(cartID, productID, func(current int) int { return current + quantity })
+}
+
+// Set implements cart.Cart.
+func (c *Cart) Set(ctx context.Context, cartID string, productID int, quantity int) (cart.Contents, error) {
+	if err := c.call(ctx, "Set", cartID, productID, quantity); err != nil {
+		return cart.Contents{}, err
+	}
+	if quantity < 0 {
+		return cart.Contents{}, cart.InvalidError{Reason: "quantities can't be negative"}
+	}
+	return c.set(cartID, productID, func(int) int { return quantity })
+}
+
+// set sets the units of a product in a cart to quantity applied to the
+// current ones.
+func (c *Cart) set(cartID string, productID int, quantity func(current int) int) (cart.Contents, error) {
+	if err := cart.ValidateID(cartID); err != nil {
+		return cart.Contents{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	item := c.carts[cartID][productID]
+	item.Quantity = quantity(item.Quantity)
+	if item.Quantity == 0 {
+		delete(c.carts[cartID], productID)
+		return c.contentsLocked(cartID), nil
+	}
+	p, ok := c.products[productID]
+	if !ok {
+		return cart.Contents{}, catalog.NotFoundError{ID: productID}
+	}
+	item.ProductID, item.Title = p.ID, p.Title
+	if c.carts[cartID] == nil {
+		c.carts[cartID] = map[int]cart.Item{}
+	}
+	c.carts[cartID][productID] = item
+	return c.contentsLocked(cartID), nil
+}
+
+// contentsLocked returns the contents of cart id.
+//
+// REQUIRES: c.mu is held.
+func (c *Cart) contentsLocked(id string) cart.Contents {
+	contents := cart.Contents{ID: id, Items: []cart.Item{}}
+	for _, item := range c.carts[id] {
+		contents.Items = append(contents.Items, item)
+		contents.Quantity += item.Quantity
+	}
+	slices.SortFunc(contents.Items, func(a, b cart.Item) int { return a.ProductID - b.ProductID })
+	return contents
+}
+
+// Clear implements cart.Cart.
+func (c *Cart) Clear(ctx context.Context, cartID string) error {
+	if err := c.call(ctx, "Clear", cartID); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.carts, cartID)
+	return nil
+}
+
+// RemoveOrdered implements cart.Cart.
+func (c *Cart) RemoveOrdered(ctx context.Context, cartID string, items []cart.Item) (cart.Contents, error) {
+	if err := c.call(ctx, "RemoveOrdered", cartID, items); err != nil {
+		return cart.Contents{}, err
+	}
+	if err := cart.ValidateID(cartID); err != nil {
+		return cart.Contents{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, ordered := range items {
+		item, ok := c.carts[cartID][ordered.ProductID]
+		if !ok {
+			continue
+		}
+		if item.Quantity -= ordered.Quantity; item.Quantity > 0 {
+			c.carts[cartID][ordered.ProductID] = item
+		} else {
+			delete(c.carts[cartID], ordered.ProductID)
+		}
+	}
+	return c.contentsLocked(cartID), nil
+}
+
+// Health implements cart.Cart.
+func (c *Cart) Health(ctx context.Context) error {
+	return c.call(ctx, "Health")
+}
+
+// Describe implements cart.Cart.
+func (c *Cart) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := c.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[cart.Cart](), nil
+}
@@ -0,0 +1,170 @@
+package fakes
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Catalog is a fake catalog.Catalog. Its methods are Get, List, Search,
+// Create, Update, Delete, Changes, Health and Describe.
+//
+// Like the real component with the memory store, it keeps the products in
+// memory, so Get returns what Create and Update stored. Changes returns no
+// changes if none were made since the version asked for, and every product
+// otherwise.
+type Catalog struct {
+	Behavior
+	mu       sync.Mutex
+	products map[int]catalog.Product
+	version  int64 // incremented on every change
+}
+
+var _ catalog.Catalog = (*Catalog)(nil)
+
+// NewCatalog returns a fake holding the default products of the real
+// component.
+func NewCatalog() *Catalog {
+	products, err := catalog.DefaultProducts()
+	if err != nil {
+		panic(err)
+	}
+	c := &Catalog{products: map[int]catalog.Product{}, version: 1}
+	for _, p := range products {
+		c.products[p.ID] = p
+	}
+	return c
+}
+
+// SetProduct adds or replaces the product p.ID, without validating it.
+func (c *Catalog) SetProduct(p catalog.Product) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.products[p.ID] = p
+	c.version++
+}
+
+// Get implements catalog.Catalog.
+func (c *Catalog) Get(ctx context.Context, id int) (catalog.Product, error) {
+	if err := c.call(ctx, "Get", id); err != nil {
+		return catalog.Product{}, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.products[id]
+	if !ok {
+		return catalog.Product{}, catalog.NotFoundError{ID: id}
+	}
+	return p, nil
+}
+
+// List implements catalog.Catalog.
+func (c *Catalog) List(ctx context.Context) ([]catalog.Product, error) {
+	if err := c.call(ctx, "List"); err != nil {
+		return nil, err
+	}
+	return c.list(), nil
+}
+
+// Search implements catalog.Catalog. It matches products like the real
+// component.
+func (c *Catalog) Search(ctx context.Context, query string) ([]catalog.Product, error) {
+	if err := c.call(ctx, "Search", query); err != nil {
+		return nil, err
+	}
+	return catalog.Match(c.list(), query), nil
+}
+
+// Create implements catalog.Catalog. Only an empty title is invalid.
+func (c *Catalog) Create(ctx context.Context, p catalog.Product) (catalog.Product, error) {
+	if err := c.call(ctx, "Create", p); err != nil {
+		return catalog.Product{}, err
+	}
+	if p.Title == "" {
+		return catalog.Product{}, catalog.InvalidError{Reason: "no title"}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p.ID = 0
+	for id := range c.products {
+		p.ID = max(p.ID, id+1)
+	}
+	c.products[p.ID] = p
+	c.version++
+	return p, nil
+}
+
+// Update implements catalog.Catalog. Only an empty title is invalid.
+func (c *Catalog) Update(ctx context.Context, p catalog.Product) (catalog.Product, error) {
+	if err := c.call(ctx, "Update", p); err != nil {
+		return catalog.Product{}, err
+	}
+	if p.Title == "" {
+		return catalog.Product{}, catalog.InvalidError{Reason: "no title"}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.products[p.ID]; !ok {
+		return catalog.Product{}, catalog.NotFoundError{ID: p.ID}
+	}
+	c.products[p.ID] = p
+	c.version++
+	return p, nil
+}
+
+// Delete implements catalog.Catalog.
+func (c *Catalog) Delete(ctx context.Context, id int) error {
+	if err := c.call(ctx, "Delete", id); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.products[id]; !ok {
+		return catalog.NotFoundError{ID: id}
+	}
+	delete(c.products, id)
+	c.version++
+	return nil
+}
+
+// Changes implements catalog.Catalog.
+func (c *Catalog) Changes(ctx context.Context, since int64) (catalog.Changes, error) {
+	if err := c.call(ctx, "Changes", since); err != nil {
+		return catalog.Changes{}, err
+	}
+	c.mu.Lock()
+	version := c.version
+	c.mu.Unlock()
+	if since == version {
+		return catalog.Changes{Version: version, Products: []catalog.Product{}, Deleted: []int{}}, nil
+	}
+	return catalog.Changes{Version: version, Reset: true, Products: c.list()}, nil
+}
+
+// Health implements catalog.Catalog.
+func (c *Catalog) Health(ctx context.Context) error {
+	return c.call(ctx, "Health")
+}
+
+// Describe implements catalog.Catalog.
+func (c *Catalog) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := c.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[catalog.Catalog](), nil
+}
+
+// list returns the products, by ID.
+func (c *Catalog) list() []catalog.Product {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	products := make([]catalog.Product, 0, len(c.products))
+	for _, p := range c.products {
+		products = append(products, p)
+	}
+	slices.SortFunc(products, func(a, b catalog.Product) int { return a.ID - b.ID })
+	return products
+}
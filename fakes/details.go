@@ -0,0 +1,87 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Details is a fake details.Details. Its methods are GetBookDetails,
+// GetBookDetailsBatch, Health and Describe.
+type Details struct {
+	Behavior
+	mu    sync.Mutex
+	books map[int]details.BookDetails
+}
+
+var _ details.Details = (*Details)(nil)
+
+// NewDetails returns a fake that serves the local book details of the real
+// component for every product.
+func NewDetails() *Details {
+	return &Details{books: map[int]details.BookDetails{}}
+}
+
+// SetBook makes GetBookDetails return book for the product book.ID.
+func (d *Details) SetBook(book details.BookDetails) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.books[book.ID] = book
+}
+
+// GetBookDetails implements details.Details.
+func (d *Details) GetBookDetails(ctx context.Context, id int, headers map[string]string) (details.BookDetails, error) {
+	if err := d.call(ctx, "GetBookDetails", id, headers); err != nil {
+		return details.BookDetails{}, err
+	}
+	return d.book(id), nil
+}
+
+// GetBookDetailsBatch implements details.Details. Like GetBookDetails, it
+// serves every product.
+func (d *Details) GetBookDetailsBatch(ctx context.Context, ids []int) (map[int]details.BookDetails, error) {
+	if err := d.call(ctx, "GetBookDetailsBatch", ids); err != nil {
+		return nil, err
+	}
+	books := map[int]details.BookDetails{}
+	for _, id := range ids {
+		books[id] = d.book(id)
+	}
+	return books, nil
+}
+
+// book returns the scripted details of product id, or the local book
+// details of the real component.
+func (d *Details) book(id int) details.BookDetails {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if book, ok := d.books[id]; ok {
+		return book
+	}
+	return details.BookDetails{
+		ID:        id,
+		Author:    "William Shakespeare",
+		Year:      1595,
+		Type:      "paperback",
+		Pages:     200,
+		Publisher: "PublisherA",
+		Language:  "English",
+		ISBN10:    "1234567890",
+		ISBN13:    "123-1234567890",
+	}
+}
+
+// Health implements details.Details.
+func (d *Details) Health(ctx context.Context) error {
+	return d.call(ctx, "Health")
+}
+
+// Describe implements details.Details.
+func (d *Details) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := d.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[details.Details](), nil
+}
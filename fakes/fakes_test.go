@@ -0,0 +1,356 @@
+package fakes_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/fakes"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+)
+
+func TestFailNext(t *testing.T) {
+	ctx := context.Background()
+	r := fakes.NewRatings()
+	first, second := errors.New("first"), errors.New("second")
+	r.FailNext("GetRatings", first, second)
+
+	for i, want := range []error{first, second, nil} {
+		if _, err := r.GetRatings(ctx, 1); err != want {
+			t.Errorf("call %d: err = %v, want %v", i, err, want)
+		}
+	}
+	if _, err := r.Health(ctx); err != nil {
+		t.Errorf("Health: err = %v, want nil", err)
+	}
+}
+
+func TestFailWith(t *testing.T) {
+	ctx := context.Background()
+	d := fakes.NewDetails()
+	down := errors.New("down")
+	d.FailWith(fakes.AnyMethod, down)
+	if _, err := d.GetBookDetails(ctx, 1, nil); err != down {
+		t.Errorf("GetBookDetails: err = %v, want %v", err, down)
+	}
+	if err := d.Health(ctx); err != down {
+		t.Errorf("Health: err = %v, want %v", err, down)
+	}
+
+	d.FailWith(fakes.AnyMethod, nil)
+	if err := d.Health(ctx); err != nil {
+		t.Errorf("Health after clearing: err = %v, want nil", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	r := fakes.NewReviews()
+	r.SetLatency("BookReviewsByID", 50*time.Millisecond)
+
+	start := time.Now()
+	if _, err := r.BookReviewsByID(context.Background(), "1"); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("call took %v, want at least 50ms", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := r.BookReviewsByID(ctx, "1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("call with a 10ms deadline: err = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestCalls(t *testing.T) {
+	ctx := context.Background()
+	r := fakes.NewRatings()
+	r.PostRatings(ctx, "3", []byte(`{"Reviewer1": 2}`))
+	r.GetRatings(ctx, 3)
+	r.Health(ctx)
+
+	calls := r.Calls("GetRatings", "PostRatings")
+	if len(calls) != 2 || calls[0].Method != "PostRatings" || calls[1].Method != "GetRatings" {
+		t.Fatalf("calls = %+v, want PostRatings then GetRatings", calls)
+	}
+	if got := calls[1].Args[0]; got != 3 {
+		t.Errorf("GetRatings product = %v, want 3", got)
+	}
+	if got := len(r.Calls()); got != 3 {
+		t.Errorf("got %d calls, want 3", got)
+	}
+
+	r.Reset()
+	if got := len(r.Calls()); got != 0 {
+		t.Errorf("got %d calls after Reset, want 0", got)
+	}
+}
+
+func TestScriptedResponses(t *testing.T) {
+	ctx := context.Background()
+	r := fakes.NewRatings()
+	r.SetRatings(7, map[string]int{"Reviewer1": 1})
+	got, err := r.GetRatings(ctx, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Ratings["Reviewer1"] != 1 || len(got.Ratings) != 1 {
+		t.Errorf("GetRatings(7) = %+v, want the scripted ratings", got)
+	}
+
+	if _, err := r.PostRatings(ctx, "seven", []byte(`{}`)); err == nil {
+		t.Error("PostRatings with a non numeric ID succeeded, want error")
+	}
+}
+
+func TestFakeRatingsInWeavertest(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		fake := fakes.NewRatings()
+		fake.SetRatings(1, map[string]int{"Reviewer1": 3, "Reviewer2": 2})
+		runner.Fakes = append(runner.Fakes, weavertest.Fake[ratings.Ratings](fake))
+		runner.Test(t, func(t *testing.T, r reviews.Reviews) {
+			ctx := context.Background()
+
+			// While ratings are down, the reviews come without stars.
+			fake.FailWith("GetRatings", errors.New("ratings are down"))
+			got, err := r.BookReviewsByID(ctx, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stars := got.Reviews[0].Rating.Stars; stars != -1 {
+				t.Errorf("stars with ratings down = %d, want -1", stars)
+			}
+
+			fake.FailWith("GetRatings", nil)
+			got, err = r.BookReviewsByID(ctx, "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stars := got.Reviews[0].Rating.Stars; stars != 3 {
+				t.Errorf("stars = %d, want 3", stars)
+			}
+
+			calls := fake.Calls("GetRatings")
+			if len(calls) < 2 || calls[0].Err == nil || calls[len(calls)-1].Err != nil {
+				t.Errorf("GetRatings calls = %+v, want failed then successful calls", calls)
+			}
+			for _, call := range calls {
+				if call.Args[0] != 1 {
+					t.Errorf("GetRatings product = %v, want 1", call.Args[0])
+				}
+			}
+		})
+	}
+}
+
+func TestFakeCatalogInWeavertest(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		fake := fakes.NewCatalog()
+		fake.SetProduct(catalog.Product{ID: 3, Title: "Venus and Adonis", Author: "William Shakespeare", Year: 1593})
+		runner.Fakes = append(runner.Fakes, weavertest.Fake[catalog.Catalog](fake))
+		runner.Test(t, func(t *testing.T, d details.Details) {
+			ctx := context.Background()
+			got, err := d.GetBookDetails(ctx, 3, nil)
+			if err != nil || got.Year != 1593 {
+				t.Errorf("GetBookDetails(3) = %+v, %v, want the scripted product", got, err)
+			}
+
+			fake.FailWith("Get", errors.New("catalog is down"))
+			if _, err := d.GetBookDetails(ctx, 3, nil); err == nil {
+				t.Error("GetBookDetails(3) with the catalog down succeeded")
+			}
+		})
+	}
+}
+
+func TestFakeCatalogInSearch(t *testing.T) {
+	fake := fakes.NewCatalog()
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search"]
+sync_interval = "10ms"
+`
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[catalog.Catalog](fake))
+	runner.Test(t, func(t *testing.T, s search.Search) {
+		ctx := context.Background()
+		fake.FailWith("Changes", errors.New("catalog is down"))
+		if _, err := s.Search(ctx, "tempest", 5); err == nil {
+			t.Fatal("Search() before the index was ever built succeeded")
+		}
+
+		fake.FailWith("Changes", nil)
+		if got, err := s.Search(ctx, "tempest", 5); err != nil || got.Total == 0 {
+			t.Fatalf("Search(tempest) = %+v, %v, want hits", got, err)
+		}
+		// The index is synced in the background, within a sync interval.
+		fake.SetProduct(catalog.Product{ID: 100, Title: "Venus and Adonis"})
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got, err := s.Search(ctx, "venus", 5)
+			if err == nil && got.Total == 1 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Search(venus) after a change = %+v, %v, want a hit", got, err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		// Once built, the index keeps serving while the catalog is down.
+		fake.FailWith("Changes", errors.New("catalog is down"))
+		time.Sleep(50 * time.Millisecond)
+		if got, err := s.Search(ctx, "tempest", 5); err != nil || got.Total == 0 {
+			t.Errorf("Search(tempest) with the catalog down = %+v, %v, want the stale hits", got, err)
+		}
+	})
+}
+
+func TestFakeRatingsInRecommendations(t *testing.T) {
+	fake := fakes.NewRatings()
+	fake.FailWith("GetRatingsBatch", errors.New("ratings are down"))
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations"]
+refresh_interval = "10ms"
+`
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[ratings.Ratings](fake))
+	runner.Test(t, func(t *testing.T, r recommendations.Recommendations) {
+		ctx := context.Background()
+		if got, err := r.Recommend(ctx, 1, 3); err != nil || len(got) != 0 {
+			t.Fatalf("Recommend() before the model was ever computed = %+v, %v, want none", got, err)
+		}
+
+		fake.FailWith("GetRatingsBatch", nil)
+		// Both readers liked 1 and 2 less than the rest.
+		fake.SetRatings(1, map[string]int{"Reviewer1": 2, "Reviewer2": 1})
+		fake.SetRatings(2, map[string]int{"Reviewer1": 1, "Reviewer2": 1})
+		// The model is computed in the background, within a refresh interval.
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			got, err := r.Recommend(ctx, 1, 3)
+			if err == nil && len(got) == 1 && got[0].ProductID == 2 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Recommend(1, 3) = %+v, %v, want product 2", got, err)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+
+		// Once computed, the model keeps serving while the ratings are down.
+		fake.FailWith("GetRatingsBatch", errors.New("ratings are down"))
+		time.Sleep(50 * time.Millisecond)
+		if got, err := r.Recommend(ctx, 1, 3); err != nil || len(got) != 1 {
+			t.Errorf("Recommend(1, 3) with the ratings down = %+v, %v, want the stale recommendations", got, err)
+		}
+	})
+}
+
+func TestFakeInventoryInOrders(t *testing.T) {
+	fake := fakes.NewInventory(10)
+	runner := weavertest.Local
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[inventory.Inventory](fake))
+	runner.Test(t, func(t *testing.T, o orders.Orders, c cart.Cart) {
+		ctx := context.Background()
+		if _, err := c.Add(ctx, "alice", 1, 2); err != nil {
+			t.Fatal(err)
+		}
+
+		// A reservation that can't be committed is released, and the
+		// order recorded as failed.
+		fake.FailNext("Commit", errors.New("inventory is down"))
+		order, err := o.Checkout(ctx, "alice", "key-1")
+		var failed orders.FailedError
+		if !errors.As(err, &failed) || order.Status != orders.Failed || failed.OrderID != order.ID {
+			t.Fatalf("Checkout with Commit failing = %+v, %v, want a failed order", order, err)
+		}
+		if state, _ := fake.State(order.ID); state != inventory.Released {
+			t.Errorf("reservation of the failed order is %q, want released", state)
+		}
+		if stock, _ := fake.Stock(ctx, []int{1}); stock[1] != 10 {
+			t.Errorf("stock after the failed order = %v, want 10 units", stock)
+		}
+		if contents, err := c.Get(ctx, "alice"); err != nil || contents.Quantity != 2 {
+			t.Errorf("cart after the failed order = %+v, %v, want it kept", contents, err)
+		}
+
+		// Replaying the checkout returns the failed order again, without
+		// calling the inventory.
+		calls := len(fake.Calls())
+		if again, err := o.Checkout(ctx, "alice", "key-1"); !errors.As(err, &failed) || again.ID != order.ID {
+			t.Errorf("Checkout again = %+v, %v, want the failed order", again, err)
+		}
+		if got := len(fake.Calls()); got != calls {
+			t.Errorf("Checkout again called the inventory %d times, want none", got-calls)
+		}
+
+		// A reservation that may have been made is released too.
+		fake.FailNext("Reserve", errors.New("inventory is down"))
+		order, err = o.Checkout(ctx, "alice", "key-2")
+		if !errors.As(err, &failed) || len(fake.Calls("Release")) != 2 {
+			t.Errorf("Checkout with Reserve failing = %+v, %v, want a failed order released", order, err)
+		}
+
+		// With the inventory back, a new key places the order.
+		if order, err := o.Checkout(ctx, "alice", "key-3"); err != nil || order.Status != orders.Placed {
+			t.Errorf("Checkout with a new key = %+v, %v, want it placed", order, err)
+		}
+		if stock, _ := fake.Stock(ctx, []int{1}); stock[1] != 8 {
+			t.Errorf("stock after the placed order = %v, want 8 units", stock)
+		}
+
+		// Units added to the cart during a checkout stay in it.
+		if _, err := c.Add(ctx, "alice", 1, 2); err != nil {
+			t.Fatal(err)
+		}
+		fake.SetLatency("Commit", 200*time.Millisecond)
+		commits := len(fake.Calls("Commit"))
+		placed := make(chan error, 1)
+		go func() {
+			_, err := o.Checkout(ctx, "alice", "key-4")
+			placed <- err
+		}()
+		for len(fake.Calls("Commit")) == commits {
+			time.Sleep(time.Millisecond)
+		}
+		if _, err := c.Add(ctx, "alice", 1, 1); err != nil {
+			t.Fatal(err)
+		}
+		if err := <-placed; err != nil {
+			t.Fatal(err)
+		}
+		if contents, err := c.Get(ctx, "alice"); err != nil || contents.Quantity != 1 {
+			t.Errorf("cart after an order placed while adding = %+v, %v, want the unit added", contents, err)
+		}
+	})
+}
+
+func TestFakePricingMatchesPricing(t *testing.T) {
+	fake := fakes.NewPricing()
+	weavertest.Local.Test(t, func(t *testing.T, p pricing.Pricing) {
+		ctx := context.Background()
+		for _, currencies := range [][]string{nil, {"EUR"}, {"jpy"}, {"CHF", "GBP"}} {
+			want, err := p.Price(ctx, 3, currencies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, err := fake.Price(ctx, 3, currencies); err != nil || got != want {
+				t.Errorf("fake Price(3, %q) = %+v, %v, want %+v", currencies, got, err, want)
+			}
+		}
+		if _, err := fake.Price(ctx, 1000, nil); !errors.As(err, new(pricing.NotFoundError)) {
+			t.Errorf("fake Price(1000): err = %v, want a NotFoundError", err)
+		}
+	})
+}
@@ -0,0 +1,75 @@
+package fakes
+
+import (
+	"context"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Inventory is a fake inventory.Inventory. Its methods are Stock, Reserve,
+// Commit, Release, Health and Describe.
+//
+// It keeps the stock in an inventory.Stock, like the real component, so its
+// reservations behave the same.
+type Inventory struct {
+	Behavior
+	stock *inventory.Stock
+}
+
+var _ inventory.Inventory = (*Inventory)(nil)
+
+// NewInventory returns a fake holding initial units of every product.
+func NewInventory(initial int) *Inventory {
+	return &Inventory{stock: inventory.NewStock(initial)}
+}
+
+// State returns the state of a reservation, and whether it exists.
+func (i *Inventory) State(reservationID string) (inventory.State, bool) {
+	return i.stock.State(reservationID)
+}
+
+// Stock implements inventory.Inventory.
+func (i *Inventory) Stock(ctx context.Context, productIDs []int) (map[int]int, error) {
+	if err := i.call(ctx, "Stock", productIDs); err != nil {
+		return nil, err
+	}
+	return i.stock.Available(productIDs), nil
+}
+
+// Reserve implements inventory.Inventory.
+func (i *Inventory) Reserve(ctx context.Context, reservationID string, items []inventory.Item) error {
+	if err := i.call(ctx, "Reserve", reservationID, items); err != nil {
+		return err
+	}
+	return i.stock.Reserve(reservationID, items)
+}
+
+// Commit implements inventory.Inventory.
+func (i *Inventory) Commit(ctx context.Context, reservationID string) error {
+	if err := i.call(ctx, "Commit", reservationID); err != nil {
+		return err
+	}
+	return i.stock.Commit(reservationID)
+}
+
+// Release implements inventory.Inventory.
+func (i *Inventory) Release(ctx context.Context, reservationID string) error {
+	if err := i.call(ctx, "Release", reservationID); err != nil {
+		return err
+	}
+	return i.stock.Release(reservationID)
+}
+
+// Health implements inventory.Inventory.
+func (i *Inventory) Health(ctx context.Context) error {
+	return i.call(ctx, "Health")
+}
+
+// Describe implements inventory.Inventory.
+func (i *Inventory) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := i.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[inventory.Inventory](), nil
+}
@@ -0,0 +1,72 @@
+package fakes
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Orders is a fake orders.Orders. Its methods are Checkout, Get, Health and
+// Describe.
+//
+// It places an order, with no items, on the first checkout with an
+// idempotency key, and returns it on the next ones, like the real component
+// does. It doesn't look at carts or the stock.
+type Orders struct {
+	Behavior
+	mu     sync.Mutex
+	orders map[string]orders.Order // by ID
+}
+
+var _ orders.Orders = (*Orders)(nil)
+
+// NewOrders returns a fake with no orders.
+func NewOrders() *Orders {
+	return &Orders{orders: map[string]orders.Order{}}
+}
+
+// Checkout implements orders.Orders.
+func (o *Orders) Checkout(ctx context.Context, cartID string, idempotencyKey string) (orders.Order, error) {
+	if err := o.call(ctx, "Checkout", cartID, idempotencyKey); err != nil {
+		return orders.Order{}, err
+	}
+	id := orders.OrderID(cartID, idempotencyKey)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, ok := o.orders[id]
+	if !ok {
+		order = orders.Order{ID: id, CartID: cartID, Status: orders.Placed, Created: time.Now().UTC()}
+		o.orders[id] = order
+	}
+	return order, nil
+}
+
+// Get implements orders.Orders.
+func (o *Orders) Get(ctx context.Context, orderID string) (orders.Order, error) {
+	if err := o.call(ctx, "Get", orderID); err != nil {
+		return orders.Order{}, err
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, ok := o.orders[orderID]
+	if !ok {
+		return orders.Order{}, orders.NotFoundError{OrderID: orderID}
+	}
+	return order, nil
+}
+
+// Health implements orders.Orders.
+func (o *Orders) Health(ctx context.Context) error {
+	return o.call(ctx, "Health")
+}
+
+// Describe implements orders.Orders.
+func (o *Orders) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := o.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[orders.Orders](), nil
+}
@@ -0,0 +1,66 @@
+package fakes
+
+import (
+	"context"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Pricing is a fake pricing.Pricing. Its methods are Price, Currencies,
+// Health and Describe.
+//
+// It quotes the default price list at the default rates with a
+// pricing.Table, like the real component without a rates file.
+type Pricing struct {
+	Behavior
+	table *pricing.Table
+}
+
+var _ pricing.Pricing = (*Pricing)(nil)
+
+// NewPricing returns a fake quoting the default prices.
+func NewPricing() *Pricing {
+	list, err := pricing.DefaultPriceList()
+	if err != nil {
+		panic(err)
+	}
+	rates, err := pricing.DefaultRates()
+	if err != nil {
+		panic(err)
+	}
+	table, err := pricing.NewTable(list, rates)
+	if err != nil {
+		panic(err)
+	}
+	return &Pricing{table: table}
+}
+
+// Price implements pricing.Pricing.
+func (p *Pricing) Price(ctx context.Context, productID int, currencies []string) (pricing.Price, error) {
+	if err := p.call(ctx, "Price", productID, currencies); err != nil {
+		return pricing.Price{}, err
+	}
+	return p.table.Quote(productID, currencies)
+}
+
+// Currencies implements pricing.Pricing.
+func (p *Pricing) Currencies(ctx context.Context) ([]string, error) {
+	if err := p.call(ctx, "Currencies"); err != nil {
+		return nil, err
+	}
+	return p.table.Currencies(), nil
+}
+
+// Health implements pricing.Pricing.
+func (p *Pricing) Health(ctx context.Context) error {
+	return p.call(ctx, "Health")
+}
+
+// Describe implements pricing.Pricing.
+func (p *Pricing) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := p.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[pricing.Pricing](), nil
+}
@@ -0,0 +1,112 @@
+package fakes
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Ratings is a fake ratings.Ratings. Its methods are GetRatings,
+// GetRatingsBatch, PostRatings, Health and Describe.
+//
+// Like the real component without a database, it keeps posted ratings in
+// memory, so GetRatings returns what PostRatings stored.
+type Ratings struct {
+	Behavior
+	mu      sync.Mutex
+	ratings map[int]map[string]int
+	health  ratings.HealthStatus
+}
+
+var _ ratings.Ratings = (*Ratings)(nil)
+
+// NewRatings returns a healthy fake that rates every product 5 stars by
+// Reviewer1 and 4 by Reviewer2, like the real component.
+func NewRatings() *Ratings {
+	return &Ratings{
+		ratings: map[int]map[string]int{},
+		health:  ratings.HealthStatus{Healthy: true, Database: ratings.DatabaseNotConfigured},
+	}
+}
+
+// SetRatings makes GetRatings return the ratings for product id.
+func (r *Ratings) SetRatings(id int, ratings map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ratings[id] = maps.Clone(ratings)
+}
+
+// SetHealth makes Health return status.
+func (r *Ratings) SetHealth(status ratings.HealthStatus) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.health = status
+}
+
+// GetRatings implements ratings.Ratings.
+func (r *Ratings) GetRatings(ctx context.Context, productId int) (ratings.RatingResponse, error) {
+	if err := r.call(ctx, "GetRatings", productId); err != nil {
+		return ratings.RatingResponse{}, err
+	}
+	return r.get(productId), nil
+}
+
+// GetRatingsBatch implements ratings.Ratings. Unlike the real component, it
+// doesn't require the products to be in the same shard.
+func (r *Ratings) GetRatingsBatch(ctx context.Context, productIds []int) (map[int]ratings.RatingResponse, error) {
+	if err := r.call(ctx, "GetRatingsBatch", productIds); err != nil {
+		return nil, err
+	}
+	responses := map[int]ratings.RatingResponse{}
+	for _, id := range productIds {
+		responses[id] = r.get(id)
+	}
+	return responses, nil
+}
+
+// PostRatings implements ratings.Ratings. It validates its arguments like
+// the real component.
+func (r *Ratings) PostRatings(ctx context.Context, productIdStr string, requestBody []byte) (ratings.RatingResponse, error) {
+	if err := r.call(ctx, "PostRatings", productIdStr, requestBody); err != nil {
+		return ratings.RatingResponse{}, err
+	}
+	productId, posted, err := ratings.ParseRatings(productIdStr, requestBody)
+	if err != nil {
+		return ratings.RatingResponse{}, err
+	}
+	r.SetRatings(productId, posted)
+	return r.get(productId), nil
+}
+
+func (r *Ratings) get(productId int) ratings.RatingResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if val, ok := r.ratings[productId]; ok {
+		return ratings.RatingResponse{ID: productId, Ratings: maps.Clone(val)}
+	}
+	return ratings.RatingResponse{
+		ID:      productId,
+		Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4},
+	}
+}
+
+// Health implements ratings.Ratings.
+func (r *Ratings) Health(ctx context.Context) (ratings.HealthStatus, error) {
+	if err := r.call(ctx, "Health"); err != nil {
+		return ratings.HealthStatus{}, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.health, nil
+}
+
+// Describe implements ratings.Ratings.
+func (r *Ratings) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := r.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[ratings.Ratings](), nil
+}
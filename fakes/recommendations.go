@@ -0,0 +1,75 @@
+package fakes
+
+import (
+	"context"
+	"maps"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Recommendations is a fake recommendations.Recommendations. Its methods are
+// Recommend, Health and Describe.
+//
+// It recommends from a model of its own, like the real component, computed
+// from the default products of the catalog and ratings set on the fake
+// rather than asked for.
+type Recommendations struct {
+	Behavior
+	mu       sync.Mutex
+	products []catalog.Product
+	ratings  map[int]map[string]int
+	model    *recommendations.Model
+}
+
+var _ recommendations.Recommendations = (*Recommendations)(nil)
+
+// NewRecommendations returns a fake recommending the default products of the
+// catalog, every one rated 5 stars by Reviewer1 and 4 by Reviewer2, like the
+// real ratings component. Rated alike by readers who rate every product the
+// same, none is similar to another until SetRatings is called.
+func NewRecommendations() *Recommendations {
+	products, err := catalog.DefaultProducts()
+	if err != nil {
+		panic(err)
+	}
+	r := &Recommendations{products: products, ratings: map[int]map[string]int{}}
+	for _, p := range products {
+		r.ratings[p.ID] = map[string]int{"Reviewer1": 5, "Reviewer2": 4}
+	}
+	r.model = recommendations.NewModel(r.products, r.ratings)
+	return r
+}
+
+// SetRatings replaces the ratings of product id and recomputes the model.
+func (r *Recommendations) SetRatings(id int, ratings map[string]int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ratings[id] = maps.Clone(ratings)
+	r.model = recommendations.NewModel(r.products, r.ratings)
+}
+
+// Recommend implements recommendations.Recommendations.
+func (r *Recommendations) Recommend(ctx context.Context, productID int, n int) ([]recommendations.Recommendation, error) {
+	if err := r.call(ctx, "Recommend", productID, n); err != nil {
+		return nil, err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.model.Recommend(productID, n), nil
+}
+
+// Health implements recommendations.Recommendations.
+func (r *Recommendations) Health(ctx context.Context) error {
+	return r.call(ctx, "Health")
+}
+
+// Describe implements recommendations.Recommendations.
+func (r *Recommendations) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := r.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[recommendations.Recommendations](), nil
+}
@@ -0,0 +1,87 @@
+package fakes
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Reviews is a fake reviews.Reviews. Its methods are BookReviewsByID,
+// ReviewCounts, Health and Describe.
+type Reviews struct {
+	Behavior
+	mu      sync.Mutex
+	reviews map[string][]reviews.Review
+}
+
+var _ reviews.Reviews = (*Reviews)(nil)
+
+// NewReviews returns a fake that serves two reviews for every product, rated
+// 5 and 4 black stars, from the pod "fake-reviews" of the cluster "fake".
+func NewReviews() *Reviews {
+	return &Reviews{reviews: map[string][]reviews.Review{}}
+}
+
+// SetReviews makes BookReviewsByID return list for productId.
+func (r *Reviews) SetReviews(productId string, list []reviews.Review) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reviews[productId] = slices.Clone(list)
+}
+
+// BookReviewsByID implements reviews.Reviews.
+func (r *Reviews) BookReviewsByID(ctx context.Context, productId string) (reviews.Response, error) {
+	if err := r.call(ctx, "BookReviewsByID", productId); err != nil {
+		return reviews.Response{}, err
+	}
+	return reviews.Response{
+		ID:          productId,
+		PodName:     "fake-reviews",
+		ClusterName: "fake",
+		Reviews:     r.list(productId),
+	}, nil
+}
+
+// ReviewCounts implements reviews.Reviews. It counts the reviews
+// BookReviewsByID returns.
+func (r *Reviews) ReviewCounts(ctx context.Context, productIds []int) (map[int]int, error) {
+	if err := r.call(ctx, "ReviewCounts", productIds); err != nil {
+		return nil, err
+	}
+	counts := map[int]int{}
+	for _, id := range productIds {
+		counts[id] = len(r.list(strconv.Itoa(id)))
+	}
+	return counts, nil
+}
+
+// list returns a copy of the reviews of productId.
+func (r *Reviews) list(productId string) []reviews.Review {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list, ok := r.reviews[productId]
+	if !ok {
+		list = []reviews.Review{
+			{Reviewer: "Reviewer1", Text: "An extremely entertaining play by Shakespeare. The slapstick humour is refreshing!", Rating: reviews.Rating{Stars: 5, Color: "black"}},
+			{Reviewer: "Reviewer2", Text: "Absolutely fun and entertaining. The play lacks thematic depth when compared to other plays by Shakespeare.", Rating: reviews.Rating{Stars: 4, Color: "black"}},
+		}
+	}
+	return slices.Clone(list)
+}
+
+// Health implements reviews.Reviews.
+func (r *Reviews) Health(ctx context.Context) error {
+	return r.call(ctx, "Health")
+}
+
+// Describe implements reviews.Reviews.
+func (r *Reviews) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := r.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[reviews.Reviews](), nil
+}
@@ -0,0 +1,56 @@
+package fakes
+
+import (
+	"context"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Search is a fake search.Search. Its methods are Search, Health and
+// Describe.
+//
+// It searches an index of its own, like the real component, without asking a
+// catalog for the products.
+type Search struct {
+	Behavior
+	index *search.Index
+}
+
+var _ search.Search = (*Search)(nil)
+
+// NewSearch returns a fake searching the default products of the catalog.
+func NewSearch() *Search {
+	products, err := catalog.DefaultProducts()
+	if err != nil {
+		panic(err)
+	}
+	return &Search{index: search.NewIndex(products...)}
+}
+
+// SetProduct adds or replaces the product p.ID in the index.
+func (s *Search) SetProduct(p catalog.Product) {
+	s.index.Put(p)
+}
+
+// Search implements search.Search.
+func (s *Search) Search(ctx context.Context, query string, limit int) (search.Results, error) {
+	if err := s.call(ctx, "Search", query, limit); err != nil {
+		return search.Results{}, err
+	}
+	return s.index.Search(query, limit), nil
+}
+
+// Health implements search.Search.
+func (s *Search) Health(ctx context.Context) error {
+	return s.call(ctx, "Health")
+}
+
+// Describe implements search.Search.
+func (s *Search) Describe(ctx context.Context) (topology.Replica, error) {
+	if err := s.call(ctx, "Describe"); err != nil {
+		return topology.Replica{}, err
+	}
+	return topology.Self[search.Search](), nil
+}
@@ -0,0 +1,273 @@
+// Package inventory implements the Inventory component, which keeps the
+// stock of the products of the bookstore.
+//
+// Orders set the units they sell aside with a reservation, which they then
+// commit, once the order is placed, or release, if it fails. Every call is
+// idempotent, so it can be retried, and a reservation takes all of its units
+// or none. Every product starts with the same number of units, set in the
+// component's config:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory"]
+//	initial_stock = 100
+//
+// A reservation neither committed nor released within a while, ten minutes
+// by default, is released on its own, so the units of an order that failed
+// without releasing them go back in stock. Committed and released
+// reservations are kept for a while longer, a day by default, so the calls
+// made for them can be repeated, and then forgotten:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory"]
+//	reservation_hold = "5m"
+//	reservation_ttl = "1h"
+//
+// The stock is kept in memory, by every replica on its own, and the component
+// routes every call to the same replica. Routing is best effort, though: the
+// calls made while replicas start, stop or fail over may reach another
+// replica, whose stock doesn't know about the units sold through the first.
+// Run a single replica of the inventory, e.g. in a group of its own, for
+// the stock to be exact.
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+const (
+	// defaultInitialStock is the number of units every product starts with
+	// when the config doesn't say.
+	defaultInitialStock = 20
+
+	// defaultReservationTTL is how long a committed or released reservation
+	// is kept when the config doesn't say.
+	defaultReservationTTL = 24 * time.Hour
+
+	// defaultReservationHold is how long a reservation stays reserved,
+	// neither committed nor released, before it is released, when the
+	// config doesn't say.
+	defaultReservationHold = 10 * time.Minute
+
+	// maxPruneInterval is how often, at most, the expired reservations are
+	// released or forgotten.
+	maxPruneInterval = time.Minute
+)
+
+// Item is a number of units of a product.
+type Item struct {
+	weaver.AutoMarshal
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// Inventory keeps the stock of the products.
+type Inventory interface {
+	// Stock returns the units of the products with the given IDs that can
+	// be reserved, by ID.
+	Stock(ctx context.Context, productIDs []int) (map[int]int, error)
+	// Reserve sets the items aside under the given reservation ID: all of
+	// them or, with an OutOfStockError, none. Reserving again under the
+	// same ID does nothing, unless the reservation was released, which is
+	// a StateError.
+	Reserve(ctx context.Context, reservationID string, items []Item) error
+	// Commit sells the items of a reservation. It returns a NotFoundError
+	// for an unknown reservation and a StateError for a released one.
+	Commit(ctx context.Context, reservationID string) error
+	// Release puts the items of a reservation back in stock. Releasing an
+	// unknown reservation keeps a later Reserve under its ID from setting
+	// items aside. It returns a StateError for a committed one.
+	Release(ctx context.Context, reservationID string) error
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// OutOfStockError is returned for a reservation of more units of a product
+// than are left.
+type OutOfStockError struct {
+	weaver.AutoMarshal
+	ProductID int
+	Available int
+	Requested int
+}
+
+func (e OutOfStockError) Error() string {
+	return fmt.Sprintf("product %d is out of stock: %d requested, %d left", e.ProductID, e.Requested, e.Available)
+}
+
+// NotFoundError is returned for a reservation that doesn't exist.
+type NotFoundError struct {
+	weaver.AutoMarshal
+	ReservationID string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("reservation %q not found", e.ReservationID)
+}
+
+// StateError is returned for a change that a reservation in State can't
+// make.
+type StateError struct {
+	weaver.AutoMarshal
+	ReservationID string
+	State         State
+}
+
+func (e StateError) Error() string {
+	return fmt.Sprintf("reservation %q is %s", e.ReservationID, e.State)
+}
+
+// InvalidError is returned for a reservation that can't be made.
+type InvalidError struct {
+	weaver.AutoMarshal
+	Reason string
+}
+
+func (e InvalidError) Error() string {
+	return "invalid reservation: " + e.Reason
+}
+
+// config is the configuration of the inventory component, read from its
+// section of the app config.
+type config struct {
+	logging.Config
+
+	// InitialStock is the number of units every product starts with.
+	InitialStock int `toml:"initial_stock"`
+
+	// ReservationTTL is how long a reservation is kept once committed or
+	// released, as a Go duration, e.g. "1h". It's a day by default.
+	ReservationTTL string `toml:"reservation_ttl"`
+
+	// ReservationHold is how long a reservation stays reserved before it is
+	// released on its own, as a Go duration, e.g. "5m". It's ten minutes by
+	// default.
+	ReservationHold string `toml:"reservation_hold"`
+}
+
+type inventory struct {
+	weaver.Implements[Inventory]
+	weaver.WithConfig[config]
+	weaver.WithRouter[router]
+	stock *Stock
+	level slog.Level    // minimum level logged
+	ttl   time.Duration // how long a committed or released reservation is kept
+	hold  time.Duration // how long a reservation stays reserved
+	done  chan struct{} // closed by Shutdown
+}
+
+// router sends every call to the same replica, which owns the stock.
+type router struct{}
+
+func (router) Stock(context.Context, []int) string            { return "" }
+func (router) Reserve(context.Context, string, []Item) string { return "" }
+func (router) Commit(context.Context, string) string          { return "" }
+func (router) Release(context.Context, string) string         { return "" }
+
+// Init fills the stock.
+func (i *inventory) Init(ctx context.Context) error {
+	level, err := i.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	i.level = level
+
+	initial := i.Config().InitialStock
+	if initial == 0 {
+		initial = defaultInitialStock
+	} else if initial < 0 {
+		return fmt.Errorf("invalid initial_stock %d", initial)
+	}
+	i.ttl = defaultReservationTTL
+	if s := i.Config().ReservationTTL; s != "" {
+		if i.ttl, err = time.ParseDuration(s); err != nil || i.ttl <= 0 {
+			return fmt.Errorf("invalid reservation_ttl %q", s)
+		}
+	}
+	i.hold = defaultReservationHold
+	if s := i.Config().ReservationHold; s != "" {
+		if i.hold, err = time.ParseDuration(s); err != nil || i.hold <= 0 {
+			return fmt.Errorf("invalid reservation_hold %q", s)
+		}
+	}
+	i.stock = NewStock(initial)
+	i.done = make(chan struct{})
+	go i.pruneEvery(min(i.ttl, i.hold, maxPruneInterval))
+	i.logger(ctx).Info("Inventory component initialized", "initial_stock", initial, "reservation_ttl", i.ttl, "reservation_hold", i.hold)
+	return nil
+}
+
+// Shutdown stops the background pruning.
+func (i *inventory) Shutdown(context.Context) error {
+	close(i.done)
+	return nil
+}
+
+// pruneEvery releases the reservations reserved for longer than the hold, and
+// forgets the ones committed or released more than the TTL ago, every
+// interval, until Shutdown.
+func (i *inventory) pruneEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-i.done:
+			return
+		case now := <-ticker.C:
+			released, forgotten := i.stock.Prune(now.Add(-i.ttl), now.Add(-i.hold))
+			if released > 0 {
+				i.logger(context.Background()).Warn("Released reservations held too long", "reservations", released)
+			}
+			if forgotten > 0 {
+				i.logger(context.Background()).Debug("Forgot expired reservations", "reservations", forgotten)
+			}
+		}
+	}
+}
+
+// logger returns the component's logger for the request in ctx.
+func (i *inventory) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, i.Logger(ctx), i.level)
+}
+
+func (i *inventory) Stock(_ context.Context, productIDs []int) (map[int]int, error) {
+	return i.stock.Available(productIDs), nil
+}
+
+func (i *inventory) Reserve(ctx context.Context, reservationID string, items []Item) error {
+	if err := i.stock.Reserve(reservationID, items); err != nil {
+		return err
+	}
+	i.logger(ctx).Debug("Reserved items", "reservation_id", reservationID, "items", len(items))
+	return nil
+}
+
+func (i *inventory) Commit(ctx context.Context, reservationID string) error {
+	if err := i.stock.Commit(reservationID); err != nil {
+		return err
+	}
+	i.logger(ctx).Debug("Committed reservation", "reservation_id", reservationID)
+	return nil
+}
+
+func (i *inventory) Release(ctx context.Context, reservationID string) error {
+	if err := i.stock.Release(reservationID); err != nil {
+		return err
+	}
+	i.logger(ctx).Info("Released reservation", "reservation_id", reservationID)
+	return nil
+}
+
+// Health reports whether the inventory component is able to serve requests.
+func (i *inventory) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the inventory component serving the call.
+func (i *inventory) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Inventory](), nil
+}
@@ -0,0 +1,50 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestInventory(t *testing.T) {
+	// The router sends every call to the replica that owns the stock when
+	// the deployer assigns routes, as "weaver multi" does, but the Multi
+	// runner balances routed calls over its two replicas, so it isn't tested
+	// here.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Config = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory"]
+initial_stock = 3
+`
+		runner.Test(t, func(t *testing.T, inv Inventory) {
+			ctx := context.Background()
+			if err := inv.Reserve(ctx, "order-1", items(1, 2)); err != nil {
+				t.Fatal(err)
+			}
+			if err := inv.Commit(ctx, "order-1"); err != nil {
+				t.Fatal(err)
+			}
+
+			// The errors keep their type across calls.
+			err := inv.Reserve(ctx, "order-2", items(1, 2))
+			var outOfStock OutOfStockError
+			if !errors.As(err, &outOfStock) || outOfStock.Available != 1 {
+				t.Errorf("Reserve(order-2): err = %v, want an OutOfStockError with 1 unit left", err)
+			}
+			var stateErr StateError
+			if err := inv.Release(ctx, "order-1"); !errors.As(err, &stateErr) {
+				t.Errorf("Release(order-1): err = %v, want a StateError", err)
+			}
+
+			stock, err := inv.Stock(ctx, []int{1, 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stock[1] != 1 || stock[2] != 3 {
+				t.Errorf("Stock(1, 2) = %v, want 1 and 3 units", stock)
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package inventory
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// State is the state of a reservation.
+type State string
+
+const (
+	Reserved  State = "reserved"  // its items are set aside
+	Committed State = "committed" // its items are sold
+	Released  State = "released"  // its items are back in stock
+)
+
+// Stock keeps the stock of the products, and the reservations made from it,
+// in memory. Every product starts with the same number of units.
+type Stock struct {
+	mu           sync.Mutex
+	initial      int                     // units of a product at first
+	available    map[int]int             // units left, by product ID, if not initial
+	reservations map[string]*reservation // by ID
+}
+
+type reservation struct {
+	items   []Item
+	state   State
+	changed time.Time // when it got to its state
+}
+
+// NewStock returns a stock holding initial units of every product.
+func NewStock(initial int) *Stock {
+	return &Stock{initial: initial, available: map[int]int{}, reservations: map[string]*reservation{}}
+}
+
+// Available returns the units of the products with the given IDs that can be
+// reserved, by ID.
+func (s *Stock) Available(productIDs []int) map[int]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	available := make(map[int]int, len(productIDs))
+	for _, id := range productIDs {
+		available[id] = s.availableLocked(id)
+	}
+	return available
+}
+
+// availableLocked returns the units of product id left.
+//
+// REQUIRES: s.mu is held.
+func (s *Stock) availableLocked(id int) int {
+	if n, ok := s.available[id]; ok {
+		return n
+	}
+	return s.initial
+}
+
+// Reserve sets the items aside under the reservation id, either all of them
+// or, if a product doesn't have enough units left, none of them. Reserving
+// under an ID already reserved or committed does nothing.
+func (s *Stock) Reserve(id string, items []Item) error {
+	if id == "" {
+		return InvalidError{Reason: "empty reservation ID"}
+	}
+	if len(items) == 0 {
+		return InvalidError{Reason: "no items"}
+	}
+	wanted := map[int]int{}
+	for _, item := range items {
+		if item.Quantity < 1 {
+			return InvalidError{Reason: "quantities must be positive"}
+		}
+		wanted[item.ProductID] += item.Quantity
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if r, ok := s.reservations[id]; ok {
+		if r.state == Released {
+			return StateError{ReservationID: id, State: r.state}
+		}
+		return nil
+	}
+	ids := make([]int, 0, len(wanted))
+	for productID := range wanted {
+		ids = append(ids, productID)
+	}
+	slices.Sort(ids) // so the same product is reported on every call
+	for _, productID := range ids {
+		if n := s.availableLocked(productID); n < wanted[productID] {
+			return OutOfStockError{ProductID: productID, Available: n, Requested: wanted[productID]}
+		}
+	}
+	for _, productID := range ids {
+		s.available[productID] = s.availableLocked(productID) - wanted[productID]
+	}
+	s.reservations[id] = &reservation{items: slices.Clone(items), state: Reserved, changed: time.Now()}
+	return nil
+}
+
+// Commit sells the items of reservation id. Committing a committed
+// reservation does nothing.
+func (s *Stock) Commit(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.reservations[id]
+	if !ok {
+		return NotFoundError{ReservationID: id}
+	}
+	switch r.state {
+	case Reserved:
+		r.state, r.changed = Committed, time.Now()
+	case Released:
+		return StateError{ReservationID: id, State: r.state}
+	}
+	return nil
+}
+
+// Release puts the items of reservation id back in stock. Releasing a
+// released reservation does nothing, and releasing an unknown one records it
+// as released, so that a Reserve arriving after it fails rather than sets the
+// items aside for good.
+func (s *Stock) Release(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.reservations[id]
+	if !ok {
+		s.reservations[id] = &reservation{state: Released, changed: time.Now()}
+		return nil
+	}
+	switch r.state {
+	case Reserved:
+		s.releaseLocked(r, time.Now())
+	case Committed:
+		return StateError{ReservationID: id, State: r.state}
+	}
+	return nil
+}
+
+// releaseLocked puts the items of the reserved reservation r back in stock,
+// releasing it at now.
+//
+// REQUIRES: s.mu is held.
+func (s *Stock) releaseLocked(r *reservation, now time.Time) {
+	for _, item := range r.items {
+		s.available[item.ProductID] = s.availableLocked(item.ProductID) + item.Quantity
+	}
+	r.state, r.changed = Released, now
+}
+
+// State returns the state of reservation id, and whether it exists.
+func (s *Stock) State(id string) (State, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	r, ok := s.reservations[id]
+	if !ok {
+		return "", false
+	}
+	return r.state, true
+}
+
+// Prune releases the reservations still reserved since before holdCutoff,
+// putting their items back in stock as Release does, and forgets the ones
+// committed or released before cutoff. It returns how many it released and
+// forgot. A released reservation is kept like any other, so a late Commit
+// fails rather than sells items back in stock. Once forgotten, a
+// reservation ID is unknown again: Commit returns a NotFoundError for it, and
+// Reserve sets its items aside anew.
+func (s *Stock) Prune(cutoff, holdCutoff time.Time) (released, forgotten int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, r := range s.reservations {
+		switch {
+		case r.state == Reserved && r.changed.Before(holdCutoff):
+			s.releaseLocked(r, now)
+			released++
+		case r.state != Reserved && r.changed.Before(cutoff):
+			delete(s.reservations, id)
+			forgotten++
+		}
+	}
+	return released, forgotten
+}
@@ -0,0 +1,188 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// items returns the items with the given product IDs and quantities, in
+// pairs.
+func items(idsAndQuantities ...int) []Item {
+	var items []Item
+	for i := 0; i < len(idsAndQuantities); i += 2 {
+		items = append(items, Item{ProductID: idsAndQuantities[i], Quantity: idsAndQuantities[i+1]})
+	}
+	return items
+}
+
+func TestStockReserve(t *testing.T) {
+	s := NewStock(5)
+	if err := s.Reserve("a", items(1, 2, 2, 5)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1, 2, 3})), "map[1:3 2:0 3:5]"; got != want {
+		t.Errorf("Available after reserving = %s, want %s", got, want)
+	}
+
+	// Reserving again under the same ID does nothing.
+	if err := s.Reserve("a", items(1, 2, 2, 5)); err != nil {
+		t.Errorf("Reserve again: %v", err)
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1, 2})), "map[1:3 2:0]"; got != want {
+		t.Errorf("Available after reserving again = %s, want %s", got, want)
+	}
+
+	// A reservation takes all of its units or none.
+	err := s.Reserve("b", items(1, 1, 2, 1))
+	var outOfStock OutOfStockError
+	if !errors.As(err, &outOfStock) || outOfStock != (OutOfStockError{ProductID: 2, Available: 0, Requested: 1}) {
+		t.Errorf("Reserve of a product out of stock: err = %v, want an OutOfStockError for product 2", err)
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1})), "map[1:3]"; got != want {
+		t.Errorf("Available after failing to reserve = %s, want %s", got, want)
+	}
+	if _, ok := s.State("b"); ok {
+		t.Errorf("a failed reservation was recorded")
+	}
+
+	// The quantities of a product listed twice add up.
+	if err := s.Reserve("c", items(1, 2, 1, 2)); !errors.As(err, &outOfStock) || outOfStock.Requested != 4 {
+		t.Errorf("Reserve of 2+2 units of 3: err = %v, want an OutOfStockError for 4 units", err)
+	}
+
+	for _, bad := range [][]Item{nil, items(1, 0), items(1, -1)} {
+		var invalid InvalidError
+		if err := s.Reserve("d", bad); !errors.As(err, &invalid) {
+			t.Errorf("Reserve(%v): err = %v, want an InvalidError", bad, err)
+		}
+	}
+	if err := s.Reserve("", items(1, 1)); err == nil {
+		t.Errorf("Reserve with an empty ID succeeded")
+	}
+}
+
+func TestStockCommitAndRelease(t *testing.T) {
+	s := NewStock(5)
+	for _, id := range []string{"committed", "released"} {
+		if err := s.Reserve(id, items(1, 2)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Commits and releases can be repeated.
+	for range 2 {
+		if err := s.Commit("committed"); err != nil {
+			t.Errorf("Commit: %v", err)
+		}
+		if err := s.Release("released"); err != nil {
+			t.Errorf("Release: %v", err)
+		}
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1})), "map[1:3]"; got != want {
+		t.Errorf("Available = %s, want %s, with the released units back", got, want)
+	}
+	for id, want := range map[string]State{"committed": Committed, "released": Released} {
+		if got, _ := s.State(id); got != want {
+			t.Errorf("State(%q) = %q, want %q", id, got, want)
+		}
+	}
+
+	var stateErr StateError
+	if err := s.Release("committed"); !errors.As(err, &stateErr) || stateErr.State != Committed {
+		t.Errorf("Release of a committed reservation: err = %v, want a StateError", err)
+	}
+	if err := s.Commit("released"); !errors.As(err, &stateErr) || stateErr.State != Released {
+		t.Errorf("Commit of a released reservation: err = %v, want a StateError", err)
+	}
+	if err := s.Reserve("released", items(1, 1)); !errors.As(err, &stateErr) {
+		t.Errorf("Reserve under a released ID: err = %v, want a StateError", err)
+	}
+	var notFound NotFoundError
+	if err := s.Commit("unknown"); !errors.As(err, &notFound) {
+		t.Errorf("Commit of an unknown reservation: err = %v, want a NotFoundError", err)
+	}
+
+	// A Reserve arriving after the Release that compensates for it doesn't
+	// set the units aside.
+	if err := s.Release("late"); err != nil {
+		t.Errorf("Release of an unknown reservation: %v", err)
+	}
+	if err := s.Reserve("late", items(1, 1)); !errors.As(err, &stateErr) {
+		t.Errorf("Reserve after Release: err = %v, want a StateError", err)
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1})), "map[1:3]"; got != want {
+		t.Errorf("Available = %s, want %s", got, want)
+	}
+}
+
+func TestStockPrune(t *testing.T) {
+	s := NewStock(5)
+	for _, id := range []string{"committed", "reserved"} {
+		if err := s.Reserve(id, items(1, 1)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Commit("committed"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Release("released"); err != nil {
+		t.Fatal(err)
+	}
+
+	past := time.Now().Add(-time.Hour)
+	if released, forgotten := s.Prune(past, past); released+forgotten != 0 {
+		t.Errorf("Prune of an hour ago released %d and forgot %d reservations, want 0", released, forgotten)
+	}
+	if released, forgotten := s.Prune(time.Now().Add(time.Hour), past); released != 0 || forgotten != 2 {
+		t.Errorf("Prune released %d and forgot %d reservations, want 0 and 2", released, forgotten)
+	}
+	for id, want := range map[string]State{"committed": "", "released": "", "reserved": Reserved} {
+		if got, _ := s.State(id); got != want {
+			t.Errorf("State(%s) after Prune = %q, want %q", id, got, want)
+		}
+	}
+	// The units of the reservations stay where they were.
+	if got, want := fmt.Sprint(s.Available([]int{1})), "map[1:3]"; got != want {
+		t.Errorf("Available after Prune = %s, want %s", got, want)
+	}
+	var notFound NotFoundError
+	if err := s.Commit("committed"); !errors.As(err, &notFound) {
+		t.Errorf("Commit of a forgotten reservation: err = %v, want a NotFoundError", err)
+	}
+}
+
+func TestStockHold(t *testing.T) {
+	s := NewStock(5)
+	if err := s.Reserve("held", items(1, 2)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1})), "map[1:3]"; got != want {
+		t.Fatalf("Available after Reserve = %s, want %s", got, want)
+	}
+
+	// The reservation ages past the hold: its units come back.
+	past := time.Now().Add(-time.Hour)
+	if released, forgotten := s.Prune(past, time.Now().Add(time.Minute)); released != 1 || forgotten != 0 {
+		t.Errorf("Prune released %d and forgot %d reservations, want 1 and 0", released, forgotten)
+	}
+	if got, want := fmt.Sprint(s.Available([]int{1})), "map[1:5]"; got != want {
+		t.Errorf("Available after the hold = %s, want %s", got, want)
+	}
+	if got, _ := s.State("held"); got != Released {
+		t.Errorf("State after the hold = %q, want %q", got, Released)
+	}
+	var stateErr StateError
+	if err := s.Commit("held"); !errors.As(err, &stateErr) {
+		t.Errorf("Commit after the hold: err = %v, want a StateError", err)
+	}
+
+	// It's kept, released, for the TTL, and then forgotten.
+	if released, forgotten := s.Prune(past, past); released+forgotten != 0 {
+		t.Errorf("Prune within the TTL released %d and forgot %d reservations, want 0", released, forgotten)
+	}
+	if _, forgotten := s.Prune(time.Now().Add(time.Hour), past); forgotten != 1 {
+		t.Errorf("Prune after the TTL forgot %d reservations, want 1", forgotten)
+	}
+}
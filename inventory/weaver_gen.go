@@ -0,0 +1,1016 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:   "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory",
+		Iface:  reflect.TypeOf((*Inventory)(nil)).Elem(),
+		Impl:   reflect.TypeOf(inventory{}),
+		Routed: true,
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return inventory_local_stub{impl: impl.(Inventory), tracer: tracer, commitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Commit", Remote: false, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Describe", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Health", Remote: false, Generated: true}), releaseMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Release", Remote: false, Generated: true}), reserveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Reserve", Remote: false, Generated: true}), stockMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Stock", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return inventory_client_stub{stub: stub, commitMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Commit", Remote: true, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Describe", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Health", Remote: true, Generated: true}), releaseMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Release", Remote: true, Generated: true}), reserveMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Reserve", Remote: true, Generated: true}), stockMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory", Method: "Stock", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return inventory_server_stub{impl: impl.(Inventory), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return inventory_reflect_stub{caller: caller}
+		},
+		RefData: "",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Inventory] = (*inventory)(nil)
+
+// weaver.Router checks.
+var _ weaver.RoutedBy[router] = (*inventory)(nil)
+
+// Component "inventory", router "router" checks.
+type __inventory_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate struct {
+	router
+	__inventory_router_embedding
+}
+
+type __inventory_router_embedding struct{}
+
+func (__inventory_router_embedding) Describe() {}
+func (__inventory_router_embedding) Health()   {}
+
+var _ func(context.Context, []int) string = (&router{}).Stock                                            // routed
+var _ func(context.Context, string, []Item) string = (&router{}).Reserve                                 // routed
+var _ func(context.Context, string) string = (&router{}).Commit                                          // routed
+var _ func(context.Context, string) string = (&router{}).Release                                         // routed
+var _ = (&__inventory_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Describe // unrouted
+var _ = (&__inventory_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Health   // unrouted
+
+// Local stub implementations.
+
+type inventory_local_stub struct {
+	impl            Inventory
+	tracer          trace.Tracer
+	commitMetrics   *codegen.MethodMetrics
+	describeMetrics *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+	releaseMetrics  *codegen.MethodMetrics
+	reserveMetrics  *codegen.MethodMetrics
+	stockMetrics    *codegen.MethodMetrics
+}
+
+// Check that inventory_local_stub implements the Inventory interface.
+var _ Inventory = (*inventory_local_stub)(nil)
+
+func (s inventory_local_stub) Commit(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.commitMetrics.Begin()
+	defer func() { s.commitMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "inventory.Inventory.Commit", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Commit(ctx, a0)
+}
+
+func (s inventory_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "inventory.Inventory.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s inventory_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "inventory.Inventory.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s inventory_local_stub) Release(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	begin := s.releaseMetrics.Begin()
+	defer func() { s.releaseMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "inventory.Inventory.Release", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Release(ctx, a0)
+}
+
+func (s inventory_local_stub) Reserve(ctx context.Context, a0 string, a1 []Item) (err error) {
+	// Update metrics.
+	begin := s.reserveMetrics.Begin()
+	defer func() { s.reserveMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "inventory.Inventory.Reserve", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Reserve(ctx, a0, a1)
+}
+
+func (s inventory_local_stub) Stock(ctx context.Context, a0 []int) (r0 map[int]int, err error) {
+	// Update metrics.
+	begin := s.stockMetrics.Begin()
+	defer func() { s.stockMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "inventory.Inventory.Stock", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Stock(ctx, a0)
+}
+
+// Client stub implementations.
+
+type inventory_client_stub struct {
+	stub            codegen.Stub
+	commitMetrics   *codegen.MethodMetrics
+	describeMetrics *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+	releaseMetrics  *codegen.MethodMetrics
+	reserveMetrics  *codegen.MethodMetrics
+	stockMetrics    *codegen.MethodMetrics
+}
+
+// Check that inventory_client_stub implements the Inventory interface.
+var _ Inventory = (*inventory_client_stub)(nil)
+
+func (s inventory_client_stub) Commit(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.commitMetrics.Begin()
+	defer func() { s.commitMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "inventory.Inventory.Commit", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashInventory(r.Commit(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s inventory_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "inventory.Inventory.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s inventory_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "inventory.Inventory.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s inventory_client_stub) Release(ctx context.Context, a0 string) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.releaseMetrics.Begin()
+	defer func() { s.releaseMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "inventory.Inventory.Release", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashInventory(r.Release(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s inventory_client_stub) Reserve(ctx context.Context, a0 string, a1 []Item) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.reserveMetrics.Begin()
+	defer func() { s.reserveMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "inventory.Inventory.Reserve", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + (len(a1) * 16))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	serviceweaver_enc_slice_Item_aeef05ae(enc, a1)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashInventory(r.Reserve(ctx, a0, a1))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s inventory_client_stub) Stock(ctx context.Context, a0 []int) (r0 map[int]int, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.stockMetrics.Begin()
+	defer func() { s.stockMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "inventory.Inventory.Stock", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 8))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_int_7c8c8866(enc, a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashInventory(r.Stock(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 5, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_map_int_int_61995a5d(dec)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type inventory_server_stub struct {
+	impl    Inventory
+	addLoad func(key uint64, load float64)
+}
+
+// Check that inventory_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*inventory_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s inventory_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Commit":
+		return s.commit
+	case "Describe":
+		return s.describe
+	case "Health":
+		return s.health
+	case "Release":
+		return s.release
+	case "Reserve":
+		return s.reserve
+	case "Stock":
+		return s.stock
+	default:
+		return nil
+	}
+}
+
+func (s inventory_server_stub) commit(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var r router
+	s.addLoad(_hashInventory(r.Commit(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Commit(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s inventory_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s inventory_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s inventory_server_stub) release(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var r router
+	s.addLoad(_hashInventory(r.Release(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Release(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s inventory_server_stub) reserve(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 []Item
+	a1 = serviceweaver_dec_slice_Item_aeef05ae(dec)
+	var r router
+	s.addLoad(_hashInventory(r.Reserve(ctx, a0, a1)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Reserve(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s inventory_server_stub) stock(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []int
+	a0 = serviceweaver_dec_slice_int_7c8c8866(dec)
+	var r router
+	s.addLoad(_hashInventory(r.Stock(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Stock(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_map_int_int_61995a5d(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type inventory_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that inventory_reflect_stub implements the Inventory interface.
+var _ Inventory = (*inventory_reflect_stub)(nil)
+
+func (s inventory_reflect_stub) Commit(ctx context.Context, a0 string) (err error) {
+	err = s.caller("Commit", ctx, []any{a0}, []any{})
+	return
+}
+
+func (s inventory_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s inventory_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s inventory_reflect_stub) Release(ctx context.Context, a0 string) (err error) {
+	err = s.caller("Release", ctx, []any{a0}, []any{})
+	return
+}
+
+func (s inventory_reflect_stub) Reserve(ctx context.Context, a0 string, a1 []Item) (err error) {
+	err = s.caller("Reserve", ctx, []any{a0, a1}, []any{})
+	return
+}
+
+func (s inventory_reflect_stub) Stock(ctx context.Context, a0 []int) (r0 map[int]int, err error) {
+	err = s.caller("Stock", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*InvalidError)(nil)
+
+type __is_InvalidError[T ~struct {
+	weaver.AutoMarshal
+	Reason string
+}] struct{}
+
+var _ __is_InvalidError[InvalidError]
+
+func (x *InvalidError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Reason)
+}
+
+func (x *InvalidError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverUnmarshal: nil receiver"))
+	}
+	x.Reason = dec.String()
+}
+func init() { codegen.RegisterSerializable[*InvalidError]() }
+
+var _ codegen.AutoMarshal = (*Item)(nil)
+
+type __is_Item[T ~struct {
+	weaver.AutoMarshal
+	ProductID int "json:\"product_id\""
+	Quantity  int "json:\"quantity\""
+}] struct{}
+
+var _ __is_Item[Item]
+
+func (x *Item) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Item.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+	enc.Int(x.Quantity)
+}
+
+func (x *Item) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Item.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+	x.Quantity = dec.Int()
+}
+
+var _ codegen.AutoMarshal = (*NotFoundError)(nil)
+
+type __is_NotFoundError[T ~struct {
+	weaver.AutoMarshal
+	ReservationID string
+}] struct{}
+
+var _ __is_NotFoundError[NotFoundError]
+
+func (x *NotFoundError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ReservationID)
+}
+
+func (x *NotFoundError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverUnmarshal: nil receiver"))
+	}
+	x.ReservationID = dec.String()
+}
+func init() { codegen.RegisterSerializable[*NotFoundError]() }
+
+var _ codegen.AutoMarshal = (*OutOfStockError)(nil)
+
+type __is_OutOfStockError[T ~struct {
+	weaver.AutoMarshal
+	ProductID int
+	Available int
+	Requested int
+}] struct{}
+
+var _ __is_OutOfStockError[OutOfStockError]
+
+func (x *OutOfStockError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("OutOfStockError.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+	enc.Int(x.Available)
+	enc.Int(x.Requested)
+}
+
+func (x *OutOfStockError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("OutOfStockError.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+	x.Available = dec.Int()
+	x.Requested = dec.Int()
+}
+func init() { codegen.RegisterSerializable[*OutOfStockError]() }
+
+var _ codegen.AutoMarshal = (*StateError)(nil)
+
+type __is_StateError[T ~struct {
+	weaver.AutoMarshal
+	ReservationID string
+	State         State
+}] struct{}
+
+var _ __is_StateError[StateError]
+
+func (x *StateError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("StateError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ReservationID)
+	enc.String((string)(x.State))
+}
+
+func (x *StateError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("StateError.WeaverUnmarshal: nil receiver"))
+	}
+	x.ReservationID = dec.String()
+	*(*string)(&x.State) = dec.String()
+}
+func init() { codegen.RegisterSerializable[*StateError]() }
+
+// Router methods.
+
+// _hashInventory returns a 64 bit hash of the provided value.
+func _hashInventory(r string) uint64 {
+	var h codegen.Hasher
+	h.WriteString(string(r))
+	return h.Sum64()
+}
+
+// _orderedCodeInventory returns an order-preserving serialization of the provided value.
+func _orderedCodeInventory(r string) codegen.OrderedCode {
+	var enc codegen.OrderedEncoder
+	enc.WriteString(string(r))
+	return enc.Encode()
+}
+
+// Encoding/decoding implementations.
+
+func serviceweaver_enc_slice_Item_aeef05ae(enc *codegen.Encoder, arg []Item) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Item_aeef05ae(dec *codegen.Decoder) []Item {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]Item, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+func serviceweaver_enc_slice_int_7c8c8866(enc *codegen.Encoder, arg []int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Int(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]int, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Int()
+	}
+	return res
+}
+
+func serviceweaver_enc_map_int_int_61995a5d(enc *codegen.Encoder, arg map[int]int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for k, v := range arg {
+		enc.Int(k)
+		enc.Int(v)
+	}
+}
+
+func serviceweaver_dec_map_int_int_61995a5d(dec *codegen.Decoder) map[int]int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make(map[int]int, n)
+	var k int
+	var v int
+	for i := 0; i < n; i++ {
+		k = dec.Int()
+		v = dec.Int()
+		res[k] = v
+	}
+	return res
+}
+
+// Size implementations.
+
+// serviceweaver_size_Item_242c421b returns the size (in bytes) of the serialization
+// of the provided type.
+func serviceweaver_size_Item_242c421b(x *Item) int {
+	size := 0
+	size += 0
+	size += 8
+	size += 8
+	return size
+}
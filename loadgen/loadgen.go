@@ -0,0 +1,313 @@
+// Package loadgen drives HTTP load against the product page.
+//
+// A run sends a weighted mix of requests to the product page endpoints,
+// either at a constant arrival rate (open loop) or from a fixed number of
+// workers that each wait for a response before sending the next request
+// (closed loop). Latencies are recorded in HDR histograms; in open-loop mode
+// they are measured from the time a request was scheduled, so a slow server
+// can't hide its queueing delay by slowing the load down. For the same
+// reason, open-loop requests still unanswered when a run ends are recorded as
+// unfinished, and the ones that find the backlog full are dropped and
+// recorded as errors, rather than left out. In closed-loop mode, the request
+// every worker has in flight when a run ends is left out: it was cut off by
+// the end of the run, and no other request waited for it. Requests that time
+// out are errors.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endpoints maps the names accepted in a request mix to the path requested
+// for a product ID.
+var Endpoints = map[string]func(id int) string{
+	"productpage": func(id int) string { return fmt.Sprintf("/productpage?id=%d", id) },
+	"product":     func(id int) string { return fmt.Sprintf("/api/v1/products/%d", id) },
+	"reviews":     func(id int) string { return fmt.Sprintf("/api/v1/products/%d/reviews", id) },
+	"ratings":     func(id int) string { return fmt.Sprintf("/api/v1/products/%d/ratings", id) },
+}
+
+// Mode is the way load is generated.
+type Mode string
+
+const (
+	// Open sends requests at a constant rate, regardless of responses.
+	Open Mode = "open"
+	// Closed keeps a fixed number of requests in flight.
+	Closed Mode = "closed"
+)
+
+// Mix is the relative weight of each endpoint in a run.
+type Mix map[string]int
+
+// ParseMix parses a mix such as "productpage=4,reviews=1". An endpoint
+// without a weight gets weight 1.
+func ParseMix(s string) (Mix, error) {
+	mix := Mix{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, weight, found := strings.Cut(part, "=")
+		if _, ok := Endpoints[name]; !ok {
+			return nil, fmt.Errorf("unknown endpoint %q", name)
+		}
+		w := 1
+		if found {
+			var err error
+			if w, err = strconv.Atoi(weight); err != nil || w < 0 {
+				return nil, fmt.Errorf("invalid weight %q for endpoint %q", weight, name)
+			}
+		}
+		mix[name] += w
+	}
+	total := 0
+	for _, w := range mix {
+		total += w
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("mix %q has no requests", s)
+	}
+	return mix, nil
+}
+
+// String returns the mix in the format accepted by ParseMix.
+func (m Mix) String() string {
+	var parts []string
+	for _, name := range m.names() {
+		parts = append(parts, fmt.Sprintf("%s=%d", name, m[name]))
+	}
+	return strings.Join(parts, ",")
+}
+
+// names returns the endpoints in the mix, sorted.
+func (m Mix) names() []string {
+	var names []string
+	for name, w := range m {
+		if w > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Config configures a load generation run.
+type Config struct {
+	Target      string        // base URL of the product page, e.g. http://localhost:12345
+	Mix         Mix           // relative weight of each endpoint
+	Mode        Mode          // Open or Closed
+	Rate        float64       // requests per second, for Open
+	Concurrency int           // workers for Closed; maximum in-flight requests for Open
+	Backlog     int           // requests waiting for a slot in Open, beyond which they are dropped; Concurrency if 0
+	Duration    time.Duration // how long results are recorded
+	Warmup      time.Duration // how long load runs before results are recorded
+	Timeout     time.Duration // per-request timeout
+	Products    int           // product IDs are drawn uniformly from [0, Products)
+	Client      *http.Client  // if nil, a client with Timeout is used
+}
+
+// request is a single request to send.
+type request struct {
+	endpoint string
+	url      string
+}
+
+// Run generates load as described by cfg and returns the results.
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	if cfg.Client == nil {
+		cfg.Client = &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: &http.Transport{MaxIdleConnsPerHost: cfg.Concurrency},
+		}
+	}
+
+	rec := newRecorder(cfg.Mix.names())
+	start := time.Now()
+	rec.measureFrom = start.Add(cfg.Warmup)
+	deadline := rec.measureFrom.Add(cfg.Duration)
+
+	ctx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	next := cfg.picker()
+	switch cfg.Mode {
+	case Closed:
+		runClosed(ctx, cfg, next, rec)
+	case Open:
+		runOpen(ctx, cfg, next, rec)
+	}
+
+	return rec.report(cfg, time.Since(rec.measureFrom)), nil
+}
+
+// validate checks cfg and fills in defaults.
+func (cfg *Config) validate() error {
+	if cfg.Target == "" {
+		return fmt.Errorf("no target")
+	}
+	cfg.Target = strings.TrimRight(cfg.Target, "/")
+	if len(cfg.Mix.names()) == 0 {
+		return fmt.Errorf("empty request mix")
+	}
+	if cfg.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Backlog <= 0 {
+		cfg.Backlog = cfg.Concurrency
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.Products <= 0 {
+		cfg.Products = 1
+	}
+	switch cfg.Mode {
+	case Closed:
+	case Open:
+		if cfg.Rate <= 0 {
+			return fmt.Errorf("open-loop mode needs a positive rate")
+		}
+	default:
+		return fmt.Errorf("unknown mode %q", cfg.Mode)
+	}
+	return nil
+}
+
+// picker returns a function that draws requests according to the mix.
+func (cfg *Config) picker() func() request {
+	names := cfg.Mix.names()
+	total := 0
+	for _, name := range names {
+		total += cfg.Mix[name]
+	}
+	return func() request {
+		n := rand.IntN(total)
+		for _, name := range names {
+			if n -= cfg.Mix[name]; n < 0 {
+				path := Endpoints[name](rand.IntN(cfg.Products))
+				return request{endpoint: name, url: cfg.Target + path}
+			}
+		}
+		panic("unreachable")
+	}
+}
+
+// runClosed runs cfg.Concurrency workers that send requests back to back.
+// The requests cut off by the end of the run aren't recorded.
+func runClosed(ctx context.Context, cfg Config, next func() request, rec *recorder) {
+	var wg sync.WaitGroup
+	for range cfg.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				req := next()
+				start := time.Now()
+				err := send(ctx, cfg.Client, req.url)
+				if ctx.Err() != nil {
+					return
+				}
+				rec.record(req.endpoint, start, time.Now(), err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// runOpen schedules requests at a constant rate. Latency is measured from the
+// time each request was due, including any time spent waiting for one of the
+// cfg.Concurrency slots. At most cfg.Backlog requests wait for a slot; the
+// ones due while the backlog is full are dropped.
+func runOpen(ctx context.Context, cfg Config, next func() request, rec *recorder) {
+	interval := time.Duration(float64(time.Second) / cfg.Rate)
+	slots := make(chan struct{}, cfg.Concurrency)
+	pending := make(chan struct{}, cfg.Concurrency+cfg.Backlog) // in flight or waiting
+	var wg sync.WaitGroup
+	begin := time.Now()
+	for i := 0; ; i++ {
+		due := begin.Add(time.Duration(i) * interval)
+		if wait := time.Until(due); wait > 0 {
+			select {
+			case <-ctx.Done():
+				wg.Wait()
+				return
+			case <-time.After(wait):
+			}
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		req := next()
+		select {
+		case pending <- struct{}{}:
+		default:
+			rec.drop(req.endpoint, due)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-pending }()
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				rec.unanswered(req.endpoint, due, time.Now())
+				return
+			}
+			defer func() { <-slots }()
+			err := send(ctx, cfg.Client, req.url)
+			if ctx.Err() != nil {
+				rec.unanswered(req.endpoint, due, time.Now())
+				return
+			}
+			rec.record(req.endpoint, due, time.Now(), err)
+		}()
+	}
+	wg.Wait()
+}
+
+// send issues a GET request and reads the whole response. Responses with a
+// status code of 400 or above are errors.
+func send(ctx context.Context, client *http.Client, url string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+	return nil
+}
+
+// isTimeout reports whether err is a request that timed out.
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
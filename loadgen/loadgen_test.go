@@ -0,0 +1,221 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMix(t *testing.T) {
+	for _, test := range []struct {
+		in, want string
+	}{
+		{"productpage", "productpage=1"},
+		{"productpage=4, reviews=1", "productpage=4,reviews=1"},
+		{"ratings=2,product,ratings=1", "product=1,ratings=3"},
+		{"reviews=0,product=2,", "product=2"},
+	} {
+		mix, err := ParseMix(test.in)
+		if err != nil {
+			t.Errorf("ParseMix(%q): %v", test.in, err)
+			continue
+		}
+		if got := mix.String(); got != test.want {
+			t.Errorf("ParseMix(%q) = %s, want %s", test.in, got, test.want)
+		}
+	}
+
+	for _, in := range []string{"", "books=1", "productpage=x", "productpage=-1", "productpage=0", " , "} {
+		if mix, err := ParseMix(in); err == nil {
+			t.Errorf("ParseMix(%q) = %v, want error", in, mix)
+		}
+	}
+}
+
+func TestPicker(t *testing.T) {
+	cfg := Config{Target: "http://bookinfo", Mix: Mix{"productpage": 3, "reviews": 1, "ratings": 0}, Products: 4}
+	next := cfg.picker()
+	const n = 40000
+	counts := map[string]int{}
+	for range n {
+		req := next()
+		counts[req.endpoint]++
+		var want string
+		switch req.endpoint {
+		case "productpage":
+			want = "http://bookinfo/productpage?id="
+		case "reviews":
+			want = "http://bookinfo/api/v1/products/"
+		default:
+			t.Fatalf("picked %q, which isn't in the mix", req.endpoint)
+		}
+		if !strings.HasPrefix(req.url, want) {
+			t.Fatalf("picked %s for %s, want a URL starting with %s", req.url, req.endpoint, want)
+		}
+		if req.endpoint == "reviews" {
+			id := strings.TrimSuffix(strings.TrimPrefix(req.url, want), "/reviews")
+			if len(id) != 1 || id[0] < '0' || id[0] > '3' {
+				t.Fatalf("picked %s, want a product ID in [0, 4)", req.url)
+			}
+		}
+	}
+	// The share of productpage requests is 3/4, within five standard
+	// deviations.
+	share := float64(counts["productpage"]) / n
+	if sd := math.Sqrt(0.75 * 0.25 / n); math.Abs(share-0.75) > 5*sd {
+		t.Errorf("productpage share = %.3f, want 0.75", share)
+	}
+}
+
+func TestRecorder(t *testing.T) {
+	start := time.Now()
+	rec := newRecorder([]string{"product", "reviews"})
+	rec.measureFrom = start
+
+	ms := func(n int) time.Time { return start.Add(time.Duration(n) * time.Millisecond) }
+	rec.record("product", ms(-5), ms(1), nil) // warmup
+	rec.drop("product", ms(-1))               // warmup
+	rec.record("product", ms(0), ms(10), nil)
+	rec.record("product", ms(0), ms(20), errors.New("500"))
+	rec.record("reviews", ms(0), ms(30), nil)
+	rec.record("product", ms(0), ms(40), timeoutError{})
+	rec.unanswered("reviews", ms(0), ms(1000))
+	rec.drop("reviews", ms(5))
+
+	report := rec.report(Config{Mode: Open, Rate: 10, Mix: Mix{"product": 1, "reviews": 1}}, time.Second)
+	if report.Rate != 10 || report.Mix != "product=1,reviews=1" {
+		t.Errorf("report = %+v, want rate 10 and the mix", report)
+	}
+	for _, want := range []Result{
+		{Endpoint: Total, Requests: 6, Errors: 3, TimedOut: 1, Unfinished: 1, Dropped: 1, ErrorRate: 0.5, Throughput: 4, Max: 1000},
+		{Endpoint: "product", Requests: 3, Errors: 2, TimedOut: 1, ErrorRate: 2.0 / 3, Throughput: 3, Max: 40},
+		{Endpoint: "reviews", Requests: 3, Errors: 1, Unfinished: 1, Dropped: 1, ErrorRate: 1.0 / 3, Throughput: 1, Max: 1000},
+	} {
+		got, ok := report.Result(want.Endpoint)
+		if !ok {
+			t.Errorf("no result for %s", want.Endpoint)
+			continue
+		}
+		if got.Requests != want.Requests || got.Errors != want.Errors || got.TimedOut != want.TimedOut ||
+			got.Unfinished != want.Unfinished || got.Dropped != want.Dropped || got.ErrorRate != want.ErrorRate || got.Throughput != want.Throughput ||
+			math.Abs(got.Max-want.Max) > want.Max/100 {
+			t.Errorf("result = %+v, want %+v", got, want)
+		}
+	}
+}
+
+func TestRunOpenRecordsUnfinishedRequests(t *testing.T) {
+	// The server answers the first request and then hangs, so every later
+	// request either waits for the one slot, which is kept by a hung
+	// request, or finds the backlog full and is dropped.
+	block := make(chan struct{})
+	answered := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case answered <- struct{}{}:
+			return
+		default:
+		}
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	report, err := Run(context.Background(), Config{
+		Target:      server.URL,
+		Mix:         Mix{"productpage": 1},
+		Mode:        Open,
+		Rate:        200,
+		Concurrency: 1,
+		Backlog:     2,
+		Duration:    250 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, _ := report.Result(Total)
+	// One answered, one in flight and two waiting for the slot when the
+	// run ends; the other ~46 dropped.
+	if total.Unfinished != 3 || total.TimedOut != 0 {
+		t.Errorf("unfinished = %d, timed out = %d, want 3 and 0 (%+v)", total.Unfinished, total.TimedOut, total)
+	}
+	if total.Dropped < 30 || total.Errors != total.Dropped {
+		t.Errorf("dropped = %d, errors = %d; want most requests dropped, as errors (%+v)", total.Dropped, total.Errors, total)
+	}
+	if want := 1 + total.Unfinished + total.Dropped; total.Requests != want {
+		t.Errorf("requests = %d, want %d (%+v)", total.Requests, want, total)
+	}
+}
+
+// timeoutError is a net.Error that timed out.
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRunClosedLeavesOutCutOffRequests(t *testing.T) {
+	// Every request takes 40ms, so the run ends while each worker has one
+	// in flight, which is left out.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(40 * time.Millisecond):
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+
+	report, err := Run(context.Background(), Config{
+		Target:      server.URL,
+		Mix:         Mix{"productpage": 1},
+		Mode:        Closed,
+		Concurrency: 2,
+		Duration:    150 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, _ := report.Result(Total)
+	if total.Requests == 0 || total.Errors != 0 || total.TimedOut != 0 || total.Unfinished != 0 {
+		t.Errorf("result = %+v, want only answered requests", total)
+	}
+	if total.Max > 100 {
+		t.Errorf("max = %.1fms, want about 40ms, without the cut-off requests", total.Max)
+	}
+}
+
+func TestRunClosedCountsTimeouts(t *testing.T) {
+	block := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-block:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(block)
+
+	report, err := Run(context.Background(), Config{
+		Target:      server.URL,
+		Mix:         Mix{"productpage": 1},
+		Mode:        Closed,
+		Concurrency: 1,
+		Duration:    200 * time.Millisecond,
+		Timeout:     30 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	total, _ := report.Result(Total)
+	if total.TimedOut < 3 || total.Errors != total.TimedOut || total.Requests != total.TimedOut {
+		t.Errorf("result = %+v, want every request timed out, as errors", total)
+	}
+}
@@ -0,0 +1,231 @@
+package loadgen
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// Histograms track latencies in microseconds, from 1µs to 1 minute, with
+// three significant digits.
+const (
+	minLatency = 1
+	maxLatency = int64(time.Minute / time.Microsecond)
+	sigFigs    = 3
+)
+
+// Total is the name of the result that aggregates every endpoint.
+const Total = "total"
+
+// Report holds the results of a run.
+type Report struct {
+	Target      string   `json:"target"`
+	Mode        Mode     `json:"mode"`
+	Mix         string   `json:"mix"`
+	Rate        float64  `json:"rate,omitempty"`
+	Concurrency int      `json:"concurrency"`
+	Seconds     float64  `json:"duration_s"`
+	Results     []Result `json:"results"`
+}
+
+// Result summarizes the requests sent to one endpoint. Latencies are in
+// milliseconds.
+//
+// Requests counts every request due in the measured part of the run: those
+// answered, those that timed out, those unfinished because they were
+// unanswered when an open-loop run ended and those dropped because the
+// backlog was full. Timed out and dropped requests are errors, and unfinished
+// ones are in the latencies with the time they had waited, a lower bound.
+// Throughput only counts answered requests.
+type Result struct {
+	Endpoint   string  `json:"endpoint"`
+	Requests   int64   `json:"requests"`
+	Errors     int64   `json:"errors"`
+	TimedOut   int64   `json:"timed_out"`
+	Unfinished int64   `json:"unfinished"`
+	Dropped    int64   `json:"dropped"`
+	ErrorRate  float64 `json:"error_rate"`
+	Throughput float64 `json:"throughput"`
+	Mean       float64 `json:"mean_ms"`
+	P50        float64 `json:"p50_ms"`
+	P90        float64 `json:"p90_ms"`
+	P95        float64 `json:"p95_ms"`
+	P99        float64 `json:"p99_ms"`
+	P999       float64 `json:"p999_ms"`
+	Max        float64 `json:"max_ms"`
+}
+
+// Result returns the result for the named endpoint, or Total.
+func (r *Report) Result(endpoint string) (Result, bool) {
+	for _, res := range r.Results {
+		if res.Endpoint == endpoint {
+			return res, true
+		}
+	}
+	return Result{}, false
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// csvHeader is the header row written by WriteCSV.
+var csvHeader = []string{
+	"endpoint", "requests", "errors", "timed_out", "unfinished", "dropped", "error_rate", "throughput",
+	"mean_ms", "p50_ms", "p90_ms", "p95_ms", "p99_ms", "p999_ms", "max_ms",
+}
+
+// WriteCSV writes one row per result, preceded by a header row.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', 3, 64) }
+	for _, res := range r.Results {
+		row := []string{
+			res.Endpoint,
+			strconv.FormatInt(res.Requests, 10),
+			strconv.FormatInt(res.Errors, 10),
+			strconv.FormatInt(res.TimedOut, 10),
+			strconv.FormatInt(res.Unfinished, 10),
+			strconv.FormatInt(res.Dropped, 10),
+			f(res.ErrorRate), f(res.Throughput),
+			f(res.Mean), f(res.P50), f(res.P90), f(res.P95), f(res.P99), f(res.P999), f(res.Max),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// recorder accumulates latencies and errors per endpoint.
+type recorder struct {
+	measureFrom time.Time // requests started before this are warmup
+
+	mu         sync.Mutex
+	names      []string
+	hists      map[string]*hdrhistogram.Histogram
+	errors     map[string]int64
+	timedOut   map[string]int64
+	unfinished map[string]int64
+	dropped    map[string]int64
+}
+
+func newRecorder(names []string) *recorder {
+	rec := &recorder{
+		names:      append([]string{Total}, names...),
+		hists:      map[string]*hdrhistogram.Histogram{},
+		errors:     map[string]int64{},
+		timedOut:   map[string]int64{},
+		unfinished: map[string]int64{},
+		dropped:    map[string]int64{},
+	}
+	for _, name := range rec.names {
+		rec.hists[name] = hdrhistogram.New(minLatency, maxLatency, sigFigs)
+	}
+	return rec
+}
+
+// record records a request to endpoint that started at start and finished
+// at end, with the given error, which may be a timeout.
+func (rec *recorder) record(endpoint string, start, end time.Time, err error) {
+	if isTimeout(err) {
+		rec.add(endpoint, start, end, true, rec.timedOut)
+		return
+	}
+	rec.add(endpoint, start, end, err != nil, nil)
+}
+
+// unanswered records a request to endpoint that started at start and was
+// still unanswered when the run ended at end.
+func (rec *recorder) unanswered(endpoint string, start, end time.Time) {
+	rec.add(endpoint, start, end, false, rec.unfinished)
+}
+
+// drop records a request to endpoint due at due that was never sent.
+func (rec *recorder) drop(endpoint string, due time.Time) {
+	if due.Before(rec.measureFrom) {
+		return
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for _, name := range []string{Total, endpoint} {
+		rec.dropped[name]++
+	}
+}
+
+// add records the latency of a request, whether it failed, and counts it in
+// counter too, if not nil.
+func (rec *recorder) add(endpoint string, start, end time.Time, failed bool, counter map[string]int64) {
+	if start.Before(rec.measureFrom) {
+		return
+	}
+	micros := end.Sub(start).Microseconds()
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	for _, name := range []string{Total, endpoint} {
+		// Values out of range are clamped rather than dropped.
+		rec.hists[name].RecordValue(min(max(micros, minLatency), maxLatency))
+		if failed {
+			rec.errors[name]++
+		}
+		if counter != nil {
+			counter[name]++
+		}
+	}
+}
+
+// report builds the report of a run whose measured part lasted elapsed.
+func (rec *recorder) report(cfg Config, elapsed time.Duration) *Report {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	report := &Report{
+		Target:      cfg.Target,
+		Mode:        cfg.Mode,
+		Mix:         cfg.Mix.String(),
+		Concurrency: cfg.Concurrency,
+		Seconds:     elapsed.Seconds(),
+	}
+	if cfg.Mode == Open {
+		report.Rate = cfg.Rate
+	}
+	ms := func(micros int64) float64 { return float64(micros) / 1000 }
+	for _, name := range rec.names {
+		h := rec.hists[name]
+		res := Result{
+			Endpoint:   name,
+			Requests:   h.TotalCount() + rec.dropped[name],
+			Errors:     rec.errors[name] + rec.dropped[name],
+			TimedOut:   rec.timedOut[name],
+			Unfinished: rec.unfinished[name],
+			Dropped:    rec.dropped[name],
+			Mean:       h.Mean() / 1000,
+			P50:        ms(h.ValueAtQuantile(50)),
+			P90:        ms(h.ValueAtQuantile(90)),
+			P95:        ms(h.ValueAtQuantile(95)),
+			P99:        ms(h.ValueAtQuantile(99)),
+			P999:       ms(h.ValueAtQuantile(99.9)),
+			Max:        ms(h.Max()),
+		}
+		if res.Requests > 0 {
+			res.ErrorRate = float64(res.Errors) / float64(res.Requests)
+		}
+		if elapsed > 0 {
+			res.Throughput = float64(h.TotalCount()-res.Unfinished) / elapsed.Seconds()
+		}
+		report.Results = append(report.Results, res)
+	}
+	return report
+}
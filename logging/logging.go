@@ -0,0 +1,100 @@
+// Package logging adds request attributes to the loggers of Bookinfo
+// components.
+//
+// The product page tags every request with a request ID and the user, and
+// NewContext stores them in the context's Service Weaver metadata, which is
+// propagated with component method calls, local or remote. Logger adds them
+// to a component's logger, whose entries Service Weaver already labels with
+// the trace and span IDs of the call, so the entries of a request can be
+// followed across components.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/ServiceWeaver/weaver/metadata"
+)
+
+// Metadata keys of the request attributes, also used as log attribute keys.
+const (
+	RequestIDKey = "request_id"
+	UserKey      = "user"
+)
+
+// Config is the logging configuration of a component, read from the
+// component's section of the app config:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings"]
+//	log_level = "debug"
+type Config struct {
+	// Level is the minimum level logged: "debug", "info" (the default),
+	// "warn" or "error".
+	Level string `toml:"log_level"`
+}
+
+// ParseLevel returns the level named by the config.
+func (c Config) ParseLevel() (slog.Level, error) {
+	if c.Level == "" {
+		return slog.LevelInfo, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Level)); err != nil {
+		return 0, fmt.Errorf("invalid log_level %q: %w", c.Level, err)
+	}
+	return level, nil
+}
+
+// NewContext returns a context carrying the request ID and user, which are
+// propagated to the components called with it. Empty values are left out.
+func NewContext(ctx context.Context, requestID, user string) context.Context {
+	meta, _ := metadata.FromContext(ctx)
+	if meta == nil {
+		meta = map[string]string{}
+	}
+	if requestID != "" {
+		meta[RequestIDKey] = requestID
+	}
+	if user != "" {
+		meta[UserKey] = user
+	}
+	return metadata.NewContext(ctx, meta)
+}
+
+// RequestID returns the request ID carried by ctx, if any.
+func RequestID(ctx context.Context) string {
+	meta, _ := metadata.FromContext(ctx)
+	return meta[RequestIDKey]
+}
+
+// Logger returns logger, dropping entries below level and with the request
+// ID and user carried by ctx.
+func Logger(ctx context.Context, logger *slog.Logger, level slog.Level) *slog.Logger {
+	logger = slog.New(levelHandler{level: level, Handler: logger.Handler()})
+	meta, _ := metadata.FromContext(ctx)
+	for _, key := range []string{RequestIDKey, UserKey} {
+		if value, ok := meta[key]; ok {
+			logger = logger.With(key, value)
+		}
+	}
+	return logger
+}
+
+// levelHandler drops the entries below level.
+type levelHandler struct {
+	level slog.Level
+	slog.Handler
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level && h.Handler.Enabled(ctx, level)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{level: h.level, Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{level: h.level, Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,37 @@
+package orders
+
+import (
+	"errors"
+
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+)
+
+// checkoutLabels label the checkouts by outcome.
+type checkoutLabels struct {
+	Component string
+	Outcome   string
+}
+
+var checkouts = metrics.NewCounterMap[checkoutLabels](
+	"bookinfo_checkouts",
+	"Checkouts, by outcome: placed, failed, out_of_stock, empty_cart or error; replays aren't counted",
+)
+
+// count counts a checkout that returned order and err.
+func (o *orders) count(order Order, err error) {
+	outcome := "error"
+	var outOfStock inventory.OutOfStockError
+	var emptyCart EmptyCartError
+	switch {
+	case err == nil:
+		outcome = "placed"
+	case order.Status == Failed:
+		outcome = "failed"
+	case errors.As(err, &outOfStock):
+		outcome = "out_of_stock"
+	case errors.As(err, &emptyCart):
+		outcome = "empty_cart"
+	}
+	checkouts.Get(checkoutLabels{Component: o.name, Outcome: outcome}).Inc()
+}
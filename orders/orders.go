@@ -0,0 +1,409 @@
+// Package orders implements the Orders component, which places the orders of
+// the bookstore.
+//
+// A checkout is a saga over three components: it reserves the items of the
+// cart in the inventory, places the order and commits the reservation, and
+// then takes the ordered units out of the cart. If the items can't be reserved or the reservation
+// committed, it releases the reservation, so the items go back in stock, and
+// records the order as failed. Only items out of stock fail a checkout
+// without recording an order.
+//
+// Every checkout carries an idempotency key, picked by the client for the
+// purchase, which the order ID is derived from. Repeating a checkout with the
+// same key, e.g. after a timeout, returns the order of the first one instead
+// of placing another, and concurrent checkouts with the same key wait for the
+// first. Orders are kept in memory, and the component routes the calls for an
+// order to the same replica.
+//
+// Orders are kept for a while, a day by default, and then forgotten, so a
+// checkout can only be repeated within that time. It's set in the
+// component's config:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders"]
+//	order_ttl = "1h"
+package orders
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// compensationTimeout bounds the calls that undo a failed checkout, which
+// are made even if the checkout's context is done.
+const compensationTimeout = 10 * time.Second
+
+// MaxKeyLength is the length of the longest idempotency key.
+const MaxKeyLength = 128
+
+const (
+	// defaultOrderTTL is how long an order is kept when the config doesn't
+	// say.
+	defaultOrderTTL = 24 * time.Hour
+
+	// maxPruneInterval is how often, at most, the expired orders are
+	// forgotten.
+	maxPruneInterval = time.Minute
+)
+
+// Status is the status of an order.
+type Status string
+
+const (
+	Placed Status = "placed" // its items are sold
+	Failed Status = "failed" // its items went back in stock
+)
+
+// Order is an order of the items of a cart.
+type Order struct {
+	weaver.AutoMarshal
+	ID       string      `json:"id"`
+	CartID   string      `json:"cart_id"`
+	Items    []cart.Item `json:"items"`
+	Quantity int         `json:"quantity"` // the units of every item
+	Status   Status      `json:"status"`
+	Reason   string      `json:"reason,omitempty"` // why it failed
+	Created  time.Time   `json:"created"`
+}
+
+// err returns the error of a checkout that resulted in order o.
+func (o Order) err() error {
+	if o.Status == Failed {
+		return FailedError{OrderID: o.ID, Reason: o.Reason}
+	}
+	return nil
+}
+
+// Orders places orders.
+type Orders interface {
+	// Checkout orders the items of a cart and takes them out of it. It returns
+	// the order if it's placed, and the failed order with a FailedError
+	// if it's recorded as failed. It records no order if the cart is
+	// empty, which is an EmptyCartError, or if an item is out of stock,
+	// which is an inventory.OutOfStockError.
+	//
+	// A checkout with the idempotency key of a previous one for the same
+	// cart, within the order TTL, returns what the previous one did,
+	// without ordering again.
+	Checkout(ctx context.Context, cartID string, idempotencyKey string) (Order, error)
+	// Get returns the order with the given ID, or a NotFoundError.
+	Get(ctx context.Context, orderID string) (Order, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// EmptyCartError is returned for the checkout of an empty cart.
+type EmptyCartError struct {
+	weaver.AutoMarshal
+	CartID string
+}
+
+func (e EmptyCartError) Error() string {
+	return fmt.Sprintf("cart %q is empty", e.CartID)
+}
+
+// FailedError is returned for an order that failed.
+type FailedError struct {
+	weaver.AutoMarshal
+	OrderID string
+	Reason  string
+}
+
+func (e FailedError) Error() string {
+	return fmt.Sprintf("order %s failed: %s", e.OrderID, e.Reason)
+}
+
+// NotFoundError is returned for an order that doesn't exist.
+type NotFoundError struct {
+	weaver.AutoMarshal
+	OrderID string
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("order %q not found", e.OrderID)
+}
+
+// InvalidError is returned for a checkout that can't be made.
+type InvalidError struct {
+	weaver.AutoMarshal
+	Reason string
+}
+
+func (e InvalidError) Error() string {
+	return "invalid checkout: " + e.Reason
+}
+
+// ValidateKey returns an InvalidError unless key is a valid idempotency key:
+// 1 to MaxKeyLength printable ASCII characters.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > MaxKeyLength {
+		return InvalidError{Reason: fmt.Sprintf("the idempotency key must have 1 to %d characters", MaxKeyLength)}
+	}
+	for _, c := range []byte(key) {
+		if c < ' ' || c > '~' {
+			return InvalidError{Reason: "the idempotency key must be printable ASCII"}
+		}
+	}
+	return nil
+}
+
+// OrderID returns the ID of the order placed by the checkout of a cart with
+// an idempotency key.
+func OrderID(cartID, idempotencyKey string) string {
+	sum := sha256.Sum256([]byte(cartID + "\x00" + idempotencyKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+// config is the configuration of the orders component, read from its section
+// of the app config.
+type config struct {
+	logging.Config
+
+	// OrderTTL is how long an order is kept after its checkout, as a Go
+	// duration, e.g. "1h". It's a day by default.
+	OrderTTL string `toml:"order_ttl"`
+}
+
+type orders struct {
+	weaver.Implements[Orders]
+	weaver.WithConfig[config]
+	weaver.WithRouter[router]
+	cart      weaver.Ref[cart.Cart]
+	inventory weaver.Ref[inventory.Inventory]
+	name      string        // full component name, used as a metric label
+	level     slog.Level    // minimum level logged
+	ttl       time.Duration // how long an order is kept
+	done      chan struct{} // closed by Shutdown
+
+	mu       sync.Mutex
+	orders   map[string]Order         // by ID
+	inflight map[string]chan struct{} // closed when the checkout of an order ID ends
+}
+
+// router sends the checkout of an order, and the calls to get it, to the same
+// replica, which keeps it.
+type router struct{}
+
+func (router) Checkout(_ context.Context, cartID string, idempotencyKey string) string {
+	return OrderID(cartID, idempotencyKey)
+}
+
+func (router) Get(_ context.Context, orderID string) string {
+	return orderID
+}
+
+func (o *orders) Init(ctx context.Context) error {
+	level, err := o.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	o.level = level
+	o.name = topology.Name[Orders]()
+	o.ttl = defaultOrderTTL
+	if s := o.Config().OrderTTL; s != "" {
+		if o.ttl, err = time.ParseDuration(s); err != nil || o.ttl <= 0 {
+			return fmt.Errorf("invalid order_ttl %q", s)
+		}
+	}
+	o.orders = map[string]Order{}
+	o.inflight = map[string]chan struct{}{}
+	o.done = make(chan struct{})
+	go o.pruneEvery(min(o.ttl, maxPruneInterval))
+	o.logger(ctx).Info("Orders component initialized", "order_ttl", o.ttl)
+	return nil
+}
+
+// Shutdown stops the background pruning.
+func (o *orders) Shutdown(context.Context) error {
+	close(o.done)
+	return nil
+}
+
+// pruneEvery forgets the orders older than the TTL every interval, until
+// Shutdown.
+func (o *orders) pruneEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-o.done:
+			return
+		case now := <-ticker.C:
+			if n := o.prune(now.Add(-o.ttl)); n > 0 {
+				o.logger(context.Background()).Debug("Forgot expired orders", "orders", n)
+			}
+		}
+	}
+}
+
+// prune forgets the orders created before cutoff and returns how many.
+func (o *orders) prune(cutoff time.Time) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	n := 0
+	for id, order := range o.orders {
+		if order.Created.Before(cutoff) {
+			delete(o.orders, id)
+			n++
+		}
+	}
+	return n
+}
+
+// logger returns the component's logger for the request in ctx.
+func (o *orders) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, o.Logger(ctx), o.level)
+}
+
+func (o *orders) Checkout(ctx context.Context, cartID string, idempotencyKey string) (Order, error) {
+	if err := cart.ValidateID(cartID); err != nil {
+		return Order{}, err
+	}
+	if err := ValidateKey(idempotencyKey); err != nil {
+		return Order{}, err
+	}
+	id := OrderID(cartID, idempotencyKey)
+	unlock, err := o.lock(ctx, id)
+	if err != nil {
+		return Order{}, err
+	}
+	defer unlock()
+
+	o.mu.Lock()
+	order, ok := o.orders[id]
+	o.mu.Unlock()
+	if ok {
+		o.logger(ctx).Debug("Replayed checkout", "order_id", id, "status", order.Status)
+		return order, order.err()
+	}
+	order, err = o.checkout(ctx, id, cartID)
+	o.count(order, err)
+	return order, err
+}
+
+// lock waits for the checkouts of order id in progress to end, and starts one.
+// It returns the function that ends it.
+func (o *orders) lock(ctx context.Context, id string) (func(), error) {
+	for {
+		o.mu.Lock()
+		done, busy := o.inflight[id]
+		if !busy {
+			done = make(chan struct{})
+			o.inflight[id] = done
+			o.mu.Unlock()
+			return func() {
+				o.mu.Lock()
+				delete(o.inflight, id)
+				o.mu.Unlock()
+				close(done)
+			}, nil
+		}
+		o.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// checkout places order id for the items of a cart.
+func (o *orders) checkout(ctx context.Context, id, cartID string) (Order, error) {
+	contents, err := o.cart.Get().Get(ctx, cartID)
+	if err != nil {
+		return Order{}, fmt.Errorf("failed to get the cart: %w", err)
+	}
+	if len(contents.Items) == 0 {
+		return Order{}, EmptyCartError{CartID: cartID}
+	}
+	items := make([]inventory.Item, len(contents.Items))
+	for i, item := range contents.Items {
+		items[i] = inventory.Item{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	// Reserve the items, all or none, and sell them once the order is
+	// placed.
+	order := Order{
+		ID:       id,
+		CartID:   cartID,
+		Items:    contents.Items,
+		Quantity: contents.Quantity,
+		Status:   Placed,
+		Created:  time.Now().UTC(),
+	}
+	if err := o.inventory.Get().Reserve(ctx, id, items); err != nil {
+		var outOfStock inventory.OutOfStockError
+		if errors.As(err, &outOfStock) {
+			return Order{}, outOfStock
+		}
+		// The reservation may have been made before the call failed.
+		o.compensate(ctx, &order, fmt.Sprintf("failed to reserve the items: %v", err))
+	} else if err := o.inventory.Get().Commit(ctx, id); err != nil {
+		o.compensate(ctx, &order, fmt.Sprintf("failed to commit the reservation: %v", err))
+	}
+	o.mu.Lock()
+	o.orders[id] = order
+	o.mu.Unlock()
+	if order.Status == Failed {
+		o.logger(ctx).Warn("Order failed", "order_id", id, "cart_id", cartID, "reason", order.Reason)
+		return order, order.err()
+	}
+
+	// The order is placed even if its items can't be taken out of the
+	// cart. Only the ordered units are, so the ones added to the cart
+	// during the checkout stay in it.
+	if _, err := o.cart.Get().RemoveOrdered(ctx, cartID, order.Items); err != nil {
+		o.logger(ctx).Warn("Failed to take the items of a placed order out of the cart", "order_id", id, "cart_id", cartID, "err", err)
+	}
+	o.logger(ctx).Info("Order placed", "order_id", id, "cart_id", cartID, "quantity", order.Quantity)
+	return order, nil
+}
+
+// compensate puts the items of an order whose reservation failed back in
+// stock, and marks it as failed for reason. If the reservation was committed
+// before the call that failed, the order is placed after all.
+func (o *orders) compensate(ctx context.Context, order *Order, reason string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), compensationTimeout)
+	defer cancel()
+	err := o.inventory.Get().Release(ctx, order.ID)
+	var stateErr inventory.StateError
+	if errors.As(err, &stateErr) && stateErr.State == inventory.Committed {
+		return
+	}
+	if err != nil {
+		o.logger(ctx).Error("Failed to release a reservation; its items are out of stock until the inventory releases it", "order_id", order.ID, "err", err)
+	}
+	order.Status = Failed
+	order.Reason = reason
+}
+
+func (o *orders) Get(_ context.Context, orderID string) (Order, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	order, ok := o.orders[orderID]
+	if !ok {
+		return Order{}, NotFoundError{OrderID: orderID}
+	}
+	return order, nil
+}
+
+// Health reports whether the orders component is able to serve requests.
+func (o *orders) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the orders component serving the call.
+func (o *orders) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Orders](), nil
+}
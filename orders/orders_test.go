@@ -0,0 +1,168 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+)
+
+const testConfig = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory"]
+initial_stock = 5
+`
+
+func TestCheckout(t *testing.T) {
+	// Carts, orders and the stock are kept by the replicas their calls are
+	// routed to when the deployer assigns routes, as "weaver multi" does,
+	// but the Multi runner balances routed calls over its two replicas, so
+	// it isn't tested here.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Config = testConfig
+		runner.Test(t, func(t *testing.T, o Orders, c cart.Cart, inv inventory.Inventory) {
+			ctx := context.Background()
+			if _, err := c.Add(ctx, "alice", 1, 2); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Add(ctx, "alice", 2, 1); err != nil {
+				t.Fatal(err)
+			}
+			order, err := o.Checkout(ctx, "alice", "key-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if order.ID != OrderID("alice", "key-1") || order.Status != Placed || order.Quantity != 3 || len(order.Items) != 2 {
+				t.Errorf("Checkout = %+v, want a placed order of 3 units", order)
+			}
+			if contents, err := c.Get(ctx, "alice"); err != nil || len(contents.Items) != 0 {
+				t.Errorf("cart after checkout = %+v, %v, want it empty", contents, err)
+			}
+
+			// A checkout with the same key returns the same order and
+			// doesn't take units again.
+			again, err := o.Checkout(ctx, "alice", "key-1")
+			if err != nil || again.ID != order.ID || !again.Created.Equal(order.Created) {
+				t.Errorf("Checkout again = %+v, %v, want %+v", again, err, order)
+			}
+			if got, err := o.Get(ctx, order.ID); err != nil || !got.Created.Equal(order.Created) {
+				t.Errorf("Get(%s) = %+v, %v, want %+v", order.ID, got, err, order)
+			}
+			stock, err := inv.Stock(ctx, []int{1, 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if stock[1] != 3 || stock[2] != 4 {
+				t.Errorf("stock after checkout = %v, want 3 and 4 units", stock)
+			}
+		})
+	}
+}
+
+func TestConcurrentCheckouts(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, o Orders, c cart.Cart, inv inventory.Inventory) {
+		ctx := context.Background()
+		if _, err := c.Add(ctx, "alice", 1, 1); err != nil {
+			t.Fatal(err)
+		}
+		var wg sync.WaitGroup
+		ids := make([]string, 5)
+		for i := range ids {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				order, err := o.Checkout(ctx, "alice", "key")
+				if err != nil {
+					t.Error(err)
+				}
+				ids[i] = order.ID
+			}()
+		}
+		wg.Wait()
+		for _, id := range ids {
+			if id != ids[0] {
+				t.Errorf("concurrent checkouts placed orders %v, want one", ids)
+				break
+			}
+		}
+		if stock, err := inv.Stock(ctx, []int{1}); err != nil || stock[1] != 19 {
+			t.Errorf("stock = %v, %v, want 19 units", stock, err)
+		}
+	})
+}
+
+func TestCheckoutErrors(t *testing.T) {
+	runner := weavertest.Local
+	runner.Config = testConfig
+	runner.Test(t, func(t *testing.T, o Orders, c cart.Cart) {
+		ctx := context.Background()
+		var emptyCart EmptyCartError
+		if _, err := o.Checkout(ctx, "alice", "key"); !errors.As(err, &emptyCart) {
+			t.Errorf("Checkout of an empty cart: err = %v, want an EmptyCartError", err)
+		}
+
+		// No order is recorded for items out of stock, and the cart is
+		// kept.
+		if _, err := c.Add(ctx, "alice", 1, 6); err != nil {
+			t.Fatal(err)
+		}
+		var outOfStock inventory.OutOfStockError
+		if _, err := o.Checkout(ctx, "alice", "key"); !errors.As(err, &outOfStock) || outOfStock.ProductID != 1 {
+			t.Errorf("Checkout of 6 units of 5: err = %v, want an OutOfStockError", err)
+		}
+		var notFound NotFoundError
+		if _, err := o.Get(ctx, OrderID("alice", "key")); !errors.As(err, &notFound) {
+			t.Errorf("Get of the order out of stock: err = %v, want a NotFoundError", err)
+		}
+		if contents, err := c.Get(ctx, "alice"); err != nil || contents.Quantity != 6 {
+			t.Errorf("cart after failed checkout = %+v, %v, want it kept", contents, err)
+		}
+
+		// With enough units, a checkout with the same key goes through.
+		if _, err := c.Set(ctx, "alice", 1, 5); err != nil {
+			t.Fatal(err)
+		}
+		if order, err := o.Checkout(ctx, "alice", "key"); err != nil || order.Status != Placed {
+			t.Errorf("Checkout after the cart is fixed = %+v, %v, want it placed", order, err)
+		}
+
+		for _, key := range []string{"", "\n", string(make([]byte, MaxKeyLength+1))} {
+			var invalid InvalidError
+			if _, err := o.Checkout(ctx, "alice", key); !errors.As(err, &invalid) {
+				t.Errorf("Checkout with key %q: err = %v, want an InvalidError", key, err)
+			}
+		}
+	})
+}
+
+func TestOrderTTL(t *testing.T) {
+	runner := weavertest.Local
+	runner.Config = testConfig + `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders"]
+order_ttl = "20ms"
+`
+	runner.Test(t, func(t *testing.T, o Orders, c cart.Cart) {
+		ctx := context.Background()
+		if _, err := c.Add(ctx, "alice", 1, 1); err != nil {
+			t.Fatal(err)
+		}
+		order, err := o.Checkout(ctx, "alice", "key")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var notFound NotFoundError
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+			_, err := o.Get(ctx, order.ID)
+			if errors.As(err, &notFound) {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("Get(%s) after the TTL: err = %v, want a NotFoundError", order.ID, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,736 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+	"time"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:   "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders",
+		Iface:  reflect.TypeOf((*Orders)(nil)).Elem(),
+		Impl:   reflect.TypeOf(orders{}),
+		Routed: true,
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return orders_local_stub{impl: impl.(Orders), tracer: tracer, checkoutMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Checkout", Remote: false, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Describe", Remote: false, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Get", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Health", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return orders_client_stub{stub: stub, checkoutMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Checkout", Remote: true, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Describe", Remote: true, Generated: true}), getMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Get", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders", Method: "Health", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return orders_server_stub{impl: impl.(Orders), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return orders_reflect_stub{caller: caller}
+		},
+		RefData: "⟦7f041bff:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders→github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart⟧\n⟦718e8f09:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders→github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory⟧\n",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Orders] = (*orders)(nil)
+
+// weaver.Router checks.
+var _ weaver.RoutedBy[router] = (*orders)(nil)
+
+// Component "orders", router "router" checks.
+type __orders_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate struct {
+	router
+	__orders_router_embedding
+}
+
+type __orders_router_embedding struct{}
+
+func (__orders_router_embedding) Describe() {}
+func (__orders_router_embedding) Health()   {}
+
+var _ func(_ context.Context, cartID string, idempotencyKey string) string = (&router{}).Checkout     // routed
+var _ func(_ context.Context, orderID string) string = (&router{}).Get                                // routed
+var _ = (&__orders_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Describe // unrouted
+var _ = (&__orders_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Health   // unrouted
+
+// Local stub implementations.
+
+type orders_local_stub struct {
+	impl            Orders
+	tracer          trace.Tracer
+	checkoutMetrics *codegen.MethodMetrics
+	describeMetrics *codegen.MethodMetrics
+	getMetrics      *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+}
+
+// Check that orders_local_stub implements the Orders interface.
+var _ Orders = (*orders_local_stub)(nil)
+
+func (s orders_local_stub) Checkout(ctx context.Context, a0 string, a1 string) (r0 Order, err error) {
+	// Update metrics.
+	begin := s.checkoutMetrics.Begin()
+	defer func() { s.checkoutMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "orders.Orders.Checkout", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Checkout(ctx, a0, a1)
+}
+
+func (s orders_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "orders.Orders.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s orders_local_stub) Get(ctx context.Context, a0 string) (r0 Order, err error) {
+	// Update metrics.
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "orders.Orders.Get", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Get(ctx, a0)
+}
+
+func (s orders_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "orders.Orders.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+// Client stub implementations.
+
+type orders_client_stub struct {
+	stub            codegen.Stub
+	checkoutMetrics *codegen.MethodMetrics
+	describeMetrics *codegen.MethodMetrics
+	getMetrics      *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+}
+
+// Check that orders_client_stub implements the Orders interface.
+var _ Orders = (*orders_client_stub)(nil)
+
+func (s orders_client_stub) Checkout(ctx context.Context, a0 string, a1 string) (r0 Order, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.checkoutMetrics.Begin()
+	defer func() { s.checkoutMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "orders.Orders.Checkout", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += (4 + len(a1))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.String(a1)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashOrders(r.Checkout(ctx, a0, a1))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s orders_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "orders.Orders.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s orders_client_stub) Get(ctx context.Context, a0 string) (r0 Order, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getMetrics.Begin()
+	defer func() { s.getMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "orders.Orders.Get", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashOrders(r.Get(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s orders_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "orders.Orders.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type orders_server_stub struct {
+	impl    Orders
+	addLoad func(key uint64, load float64)
+}
+
+// Check that orders_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*orders_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s orders_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Checkout":
+		return s.checkout
+	case "Describe":
+		return s.describe
+	case "Get":
+		return s.get
+	case "Health":
+		return s.health
+	default:
+		return nil
+	}
+}
+
+func (s orders_server_stub) checkout(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 string
+	a1 = dec.String()
+	var r router
+	s.addLoad(_hashOrders(r.Checkout(ctx, a0, a1)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Checkout(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s orders_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s orders_server_stub) get(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var r router
+	s.addLoad(_hashOrders(r.Get(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Get(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s orders_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type orders_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that orders_reflect_stub implements the Orders interface.
+var _ Orders = (*orders_reflect_stub)(nil)
+
+func (s orders_reflect_stub) Checkout(ctx context.Context, a0 string, a1 string) (r0 Order, err error) {
+	err = s.caller("Checkout", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
+func (s orders_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s orders_reflect_stub) Get(ctx context.Context, a0 string) (r0 Order, err error) {
+	err = s.caller("Get", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s orders_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*EmptyCartError)(nil)
+
+type __is_EmptyCartError[T ~struct {
+	weaver.AutoMarshal
+	CartID string
+}] struct{}
+
+var _ __is_EmptyCartError[EmptyCartError]
+
+func (x *EmptyCartError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("EmptyCartError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.CartID)
+}
+
+func (x *EmptyCartError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("EmptyCartError.WeaverUnmarshal: nil receiver"))
+	}
+	x.CartID = dec.String()
+}
+func init() { codegen.RegisterSerializable[*EmptyCartError]() }
+
+var _ codegen.AutoMarshal = (*FailedError)(nil)
+
+type __is_FailedError[T ~struct {
+	weaver.AutoMarshal
+	OrderID string
+	Reason  string
+}] struct{}
+
+var _ __is_FailedError[FailedError]
+
+func (x *FailedError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("FailedError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.OrderID)
+	enc.String(x.Reason)
+}
+
+func (x *FailedError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("FailedError.WeaverUnmarshal: nil receiver"))
+	}
+	x.OrderID = dec.String()
+	x.Reason = dec.String()
+}
+func init() { codegen.RegisterSerializable[*FailedError]() }
+
+var _ codegen.AutoMarshal = (*InvalidError)(nil)
+
+type __is_InvalidError[T ~struct {
+	weaver.AutoMarshal
+	Reason string
+}] struct{}
+
+var _ __is_InvalidError[InvalidError]
+
+func (x *InvalidError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Reason)
+}
+
+func (x *InvalidError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("InvalidError.WeaverUnmarshal: nil receiver"))
+	}
+	x.Reason = dec.String()
+}
+func init() { codegen.RegisterSerializable[*InvalidError]() }
+
+var _ codegen.AutoMarshal = (*NotFoundError)(nil)
+
+type __is_NotFoundError[T ~struct {
+	weaver.AutoMarshal
+	OrderID string
+}] struct{}
+
+var _ __is_NotFoundError[NotFoundError]
+
+func (x *NotFoundError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.OrderID)
+}
+
+func (x *NotFoundError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverUnmarshal: nil receiver"))
+	}
+	x.OrderID = dec.String()
+}
+func init() { codegen.RegisterSerializable[*NotFoundError]() }
+
+var _ codegen.AutoMarshal = (*Order)(nil)
+
+type __is_Order[T ~struct {
+	weaver.AutoMarshal
+	ID       string      "json:\"id\""
+	CartID   string      "json:\"cart_id\""
+	Items    []cart.Item "json:\"items\""
+	Quantity int         "json:\"quantity\""
+	Status   Status      "json:\"status\""
+	Reason   string      "json:\"reason,omitempty\""
+	Created  time.Time   "json:\"created\""
+}] struct{}
+
+var _ __is_Order[Order]
+
+func (x *Order) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Order.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.ID)
+	enc.String(x.CartID)
+	serviceweaver_enc_slice_Item_ec799a90(enc, x.Items)
+	enc.Int(x.Quantity)
+	enc.String((string)(x.Status))
+	enc.String(x.Reason)
+	enc.EncodeBinaryMarshaler(&x.Created)
+}
+
+func (x *Order) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Order.WeaverUnmarshal: nil receiver"))
+	}
+	x.ID = dec.String()
+	x.CartID = dec.String()
+	x.Items = serviceweaver_dec_slice_Item_ec799a90(dec)
+	x.Quantity = dec.Int()
+	*(*string)(&x.Status) = dec.String()
+	x.Reason = dec.String()
+	dec.DecodeBinaryUnmarshaler(&x.Created)
+}
+
+func serviceweaver_enc_slice_Item_ec799a90(enc *codegen.Encoder, arg []cart.Item) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Item_ec799a90(dec *codegen.Decoder) []cart.Item {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]cart.Item, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
+
+// Router methods.
+
+// _hashOrders returns a 64 bit hash of the provided value.
+func _hashOrders(r string) uint64 {
+	var h codegen.Hasher
+	h.WriteString(string(r))
+	return h.Sum64()
+}
+
+// _orderedCodeOrders returns an order-preserving serialization of the provided value.
+func _orderedCodeOrders(r string) codegen.OrderedCode {
+	var enc codegen.OrderedEncoder
+	enc.WriteString(string(r))
+	return enc.Encode()
+}
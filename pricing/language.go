@@ -0,0 +1,31 @@
+package pricing
+
+import (
+	"slices"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+)
+
+// LanguageCurrencies returns the currencies of the regions of the languages
+// in an Accept-Language header, most preferred first, e.g. EUR and USD for
+// "de-AT, en;q=0.5". A language without a region gets the region it's most
+// likely spoken in, e.g. JP for "ja". An invalid header has none.
+func LanguageCurrencies(acceptLanguage string) []string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil {
+		return nil
+	}
+	var codes []string
+	for _, tag := range tags {
+		region, confidence := tag.Region()
+		if confidence == language.No {
+			continue
+		}
+		unit, ok := currency.FromRegion(region)
+		if ok && !slices.Contains(codes, unit.String()) {
+			codes = append(codes, unit.String())
+		}
+	}
+	return codes
+}
@@ -0,0 +1,27 @@
+package pricing
+
+import "github.com/ServiceWeaver/weaver/metrics"
+
+// quoteLabels label the prices quoted. Component is the full name of the
+// pricing component, as in Service Weaver's method metrics.
+type quoteLabels struct {
+	Component string
+	Currency  string
+}
+
+// reloadLabels label the reloads of the rates file by outcome.
+type reloadLabels struct {
+	Component string
+	Outcome   string
+}
+
+var (
+	quotes = metrics.NewCounterMap[quoteLabels](
+		"bookinfo_prices_quoted",
+		"Prices quoted, by currency",
+	)
+	reloads = metrics.NewCounterMap[reloadLabels](
+		"bookinfo_pricing_rate_reloads",
+		"Reloads of the exchange rates file, by outcome: changed, unchanged or failed",
+	)
+)
@@ -0,0 +1,237 @@
+// Package pricing implements the Pricing component, which prices the
+// products of the bookstore.
+//
+// Every product has a list price, and may have a discount, in the currency of
+// the price list embedded in data/prices.json. Prices are quoted in other
+// currencies with exchange rates read from a JSON rates file, which is
+// reloaded in the background every reload interval, so that new rates are
+// picked up without a restart. Without a rates file, the rates embedded in
+// data/rates.json are used:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing"]
+//	rates_file = "/etc/bookinfo/rates.json"
+//	reload_interval = "5m"
+package pricing
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+//go:embed data/prices.json
+var pricesJSON []byte
+
+//go:embed data/rates.json
+var ratesJSON []byte
+
+// defaultReloadInterval is how often the rates file is reloaded when the
+// config doesn't say.
+const defaultReloadInterval = time.Minute
+
+// Price is the price of a product in a currency.
+type Price struct {
+	weaver.AutoMarshal
+	ProductID int    `json:"product_id"`
+	Currency  string `json:"currency"` // ISO 4217 code
+	// List is the list price, e.g. "12.99", and Discounted the price to
+	// pay, the list price less the discount.
+	List            string `json:"list"`
+	Discounted      string `json:"discounted"`
+	DiscountPercent int    `json:"discount_percent"` // 0 if none
+	// Rate is the units of Currency per unit of the currency of the price
+	// list, which the price was converted at.
+	Rate float64 `json:"rate"`
+}
+
+// Pricing prices products.
+type Pricing interface {
+	// Price returns the price of a product in the first of currencies
+	// that there's an exchange rate for, or in the currency of the price
+	// list if none. It returns a NotFoundError for a product without a
+	// price.
+	Price(ctx context.Context, productID int, currencies []string) (Price, error)
+	// Currencies returns the codes of the currencies prices are quoted
+	// in, sorted.
+	Currencies(ctx context.Context) ([]string, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// NotFoundError is returned for a product without a price.
+type NotFoundError struct {
+	weaver.AutoMarshal
+	ProductID int
+}
+
+func (e NotFoundError) Error() string {
+	return fmt.Sprintf("no price for product %d", e.ProductID)
+}
+
+// config is the configuration of the pricing component, read from its
+// section of the app config.
+type config struct {
+	logging.Config
+
+	// RatesFile is a JSON file of exchange rates, see ParseRates. The rates
+	// embedded in data/rates.json are used if it's empty.
+	RatesFile string `toml:"rates_file"`
+
+	// ReloadInterval is how often the rates file is reloaded, as a Go
+	// duration, e.g. "5m". It's a minute by default.
+	ReloadInterval string `toml:"reload_interval"`
+}
+
+type pricing struct {
+	weaver.Implements[Pricing]
+	weaver.WithConfig[config]
+	name  string        // full component name, used as a metric label
+	level slog.Level    // minimum level logged
+	list  PriceList     // the prices, in the currency of the list
+	done  chan struct{} // closed by Shutdown
+
+	table atomic.Pointer[Table] // with the last rates loaded
+}
+
+// Init loads the prices and rates, and starts reloading the rates file, if
+// any, in the background.
+func (p *pricing) Init(ctx context.Context) error {
+	level, err := p.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	p.level = level
+	p.name = topology.Name[Pricing]()
+	if p.list, err = DefaultPriceList(); err != nil {
+		return err
+	}
+
+	path := p.Config().RatesFile
+	var rates Rates
+	if path == "" {
+		rates, err = DefaultRates()
+	} else {
+		rates, err = readRates(path)
+	}
+	if err != nil {
+		return err
+	}
+	table, err := NewTable(p.list, rates)
+	if err != nil {
+		return err
+	}
+	p.table.Store(table)
+
+	p.done = make(chan struct{})
+	if path != "" {
+		interval := defaultReloadInterval
+		if s := p.Config().ReloadInterval; s != "" {
+			if interval, err = time.ParseDuration(s); err != nil || interval <= 0 {
+				return fmt.Errorf("invalid reload_interval %q", s)
+			}
+		}
+		go p.reloadEvery(path, interval)
+		p.logger(ctx).Info("Pricing component initialized", "rates_file", path, "reload_interval", interval, "currencies", len(rates.Rates))
+		return nil
+	}
+	p.logger(ctx).Info("Pricing component initialized", "currencies", len(rates.Rates))
+	return nil
+}
+
+// Shutdown stops the background reloads.
+func (p *pricing) Shutdown(context.Context) error {
+	close(p.done)
+	return nil
+}
+
+// logger returns the component's logger for the request in ctx.
+func (p *pricing) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, p.Logger(ctx), p.level)
+}
+
+// readRates reads a rates file.
+func readRates(path string) (Rates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Rates{}, fmt.Errorf("failed to read the rates: %w", err)
+	}
+	rates, err := ParseRates(data)
+	if err != nil {
+		return Rates{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rates, nil
+}
+
+// reloadEvery reloads the rates file every interval until Shutdown. If a
+// reload fails, the last rates keep serving.
+func (p *pricing) reloadEvery(path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.done:
+			return
+		case <-ticker.C:
+		}
+		ctx := context.Background()
+		outcome, err := p.reload(path)
+		reloads.Get(reloadLabels{Component: p.name, Outcome: outcome}).Inc()
+		switch outcome {
+		case "failed":
+			p.logger(ctx).Warn("Failed to reload the exchange rates; serving the last ones", "err", err)
+		case "changed":
+			p.logger(ctx).Info("Reloaded the exchange rates", "rates_file", path, "currencies", len(p.table.Load().rates.Rates))
+		}
+	}
+}
+
+// reload reloads the rates file, and returns whether the rates "changed",
+// are "unchanged" or "failed" to load.
+func (p *pricing) reload(path string) (string, error) {
+	rates, err := readRates(path)
+	if err != nil {
+		return "failed", err
+	}
+	last := p.table.Load().rates
+	if rates.Base == last.Base && maps.Equal(rates.Rates, last.Rates) {
+		return "unchanged", nil
+	}
+	table, err := NewTable(p.list, rates)
+	if err != nil {
+		return "failed", err
+	}
+	p.table.Store(table)
+	return "changed", nil
+}
+
+func (p *pricing) Price(_ context.Context, productID int, currencies []string) (Price, error) {
+	price, err := p.table.Load().Quote(productID, currencies)
+	if err != nil {
+		return Price{}, err
+	}
+	quotes.Get(quoteLabels{Component: p.name, Currency: price.Currency}).Inc()
+	return price, nil
+}
+
+func (p *pricing) Currencies(context.Context) ([]string, error) {
+	return p.table.Load().Currencies(), nil
+}
+
+// Health reports whether the pricing component is able to serve requests.
+func (p *pricing) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the pricing component serving the call.
+func (p *pricing) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Pricing](), nil
+}
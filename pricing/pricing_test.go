@@ -0,0 +1,83 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestPrice(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, p Pricing) {
+			ctx := context.Background()
+			got, err := p.Price(ctx, 3, []string{"EUR"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Currency != "EUR" || got.List != "18.39" || got.Discounted != "14.71" || got.DiscountPercent != 20 {
+				t.Errorf("Price(3, EUR) = %+v, want 18.39 EUR with 20%% off", got)
+			}
+			if got, err := p.Price(ctx, 0, nil); err != nil || got.Currency != "USD" || got.List != got.Discounted {
+				t.Errorf("Price(0) = %+v, %v, want a USD price without discount", got, err)
+			}
+			if _, err := p.Price(ctx, 1000, nil); !errors.As(err, new(NotFoundError)) {
+				t.Errorf("Price(1000): err = %v, want a NotFoundError", err)
+			}
+			if got, err := p.Currencies(ctx); err != nil || fmt.Sprint(got) != "[AUD BRL CAD EUR GBP INR JPY USD]" {
+				t.Errorf("Currencies() = %v, %v, want the embedded currencies", got, err)
+			}
+		})
+	}
+}
+
+func TestReload(t *testing.T) {
+	// The Multi runner may start a replica after the file changes, which
+	// then serves the new rates from the start.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		if err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0.5}}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		runner.Config = fmt.Sprintf(`
+["github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing"]
+rates_file = %q
+reload_interval = "10ms"
+`, path)
+		runner.Test(t, func(t *testing.T, p Pricing) {
+			ctx := context.Background()
+			if got, err := p.Price(ctx, 0, []string{"EUR"}); err != nil || got.List != "10.50" {
+				t.Fatalf("Price(0, EUR) = %+v, %v, want 10.50 EUR", got, err)
+			}
+
+			// A file that doesn't parse keeps the last rates.
+			if err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 0}}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(50 * time.Millisecond)
+			if got, err := p.Price(ctx, 0, []string{"EUR"}); err != nil || got.List != "10.50" {
+				t.Fatalf("Price(0, EUR) with an invalid file = %+v, %v, want 10.50 EUR", got, err)
+			}
+
+			if err := os.WriteFile(path, []byte(`{"base": "USD", "rates": {"EUR": 2, "GBP": 1}}`), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			var got Price
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				var err error
+				if got, err = p.Price(ctx, 0, []string{"EUR"}); err != nil {
+					t.Fatal(err)
+				}
+				if got.List == "41.98" {
+					return
+				}
+			}
+			t.Errorf("Price(0, EUR) after changing the rates = %+v, want 41.98 EUR", got)
+		})
+	}
+}
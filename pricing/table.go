@@ -0,0 +1,214 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/text/currency"
+)
+
+// maxDiscount is the highest discount of a price, in percent.
+const maxDiscount = 90
+
+// PriceList is a list of prices in a currency.
+type PriceList struct {
+	Currency string      `json:"currency"` // ISO 4217 code
+	Prices   []ListPrice `json:"prices"`
+}
+
+// ListPrice is the price of a product in a price list.
+type ListPrice struct {
+	ProductID       int    `json:"product_id"`
+	List            string `json:"list"`             // e.g. "12.99"
+	DiscountPercent int    `json:"discount_percent"` // off the list price; 0 if none
+}
+
+// DefaultPriceList returns the price list the component serves, embedded in
+// data/prices.json.
+func DefaultPriceList() (PriceList, error) {
+	var list PriceList
+	if err := json.Unmarshal(pricesJSON, &list); err != nil {
+		return PriceList{}, fmt.Errorf("failed to parse the embedded prices: %w", err)
+	}
+	return list, nil
+}
+
+// Rates are exchange rates.
+type Rates struct {
+	Base  string             `json:"base"`  // ISO 4217 code of the currency the rates are from
+	Rates map[string]float64 `json:"rates"` // units of a currency per unit of Base, by code
+}
+
+// DefaultRates returns the exchange rates embedded in data/rates.json, which
+// the component uses without a rates file.
+func DefaultRates() (Rates, error) {
+	return ParseRates(ratesJSON)
+}
+
+// ParseRates parses a rates file, a JSON object such as
+//
+//	{"base": "USD", "rates": {"EUR": 0.92, "JPY": 149.2}}
+//
+// The base currency has a rate of 1, whether it's listed or not.
+func ParseRates(data []byte) (Rates, error) {
+	var r Rates
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Rates{}, fmt.Errorf("invalid rates: %w", err)
+	}
+	base, err := parseCode(r.Base)
+	if err != nil {
+		return Rates{}, fmt.Errorf("invalid base currency: %w", err)
+	}
+	rates := map[string]float64{base: 1}
+	for code, rate := range r.Rates {
+		c, err := parseCode(code)
+		if err != nil {
+			return Rates{}, err
+		}
+		if rate <= 0 || math.IsInf(rate, 0) || math.IsNaN(rate) {
+			return Rates{}, fmt.Errorf("invalid rate %v for %s", rate, c)
+		}
+		if c == base && rate != 1 {
+			return Rates{}, fmt.Errorf("the base currency %s has rate %v, want 1", c, rate)
+		}
+		rates[c] = rate
+	}
+	return Rates{Base: base, Rates: rates}, nil
+}
+
+// parseCode returns the ISO 4217 code of a currency, in upper case.
+func parseCode(code string) (string, error) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", fmt.Errorf("unknown currency %q", code)
+	}
+	return unit.String(), nil
+}
+
+// Table quotes the prices of a price list in the currencies of a set of
+// exchange rates. It's immutable, and safe for concurrent use.
+type Table struct {
+	currency  string        // of the price list
+	list      map[int]int64 // list prices in minor units of currency, by product ID
+	discounts map[int]int   // in percent, by product ID
+	rates     Rates
+}
+
+// NewTable returns a table quoting the prices of list with rates, which must
+// have a rate for the currency of the list.
+func NewTable(list PriceList, rates Rates) (*Table, error) {
+	code, err := parseCode(list.Currency)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price list currency: %w", err)
+	}
+	if _, ok := rates.Rates[code]; !ok {
+		return nil, fmt.Errorf("no rate for %s, the currency of the price list", code)
+	}
+	t := &Table{
+		currency:  code,
+		list:      make(map[int]int64, len(list.Prices)),
+		discounts: make(map[int]int, len(list.Prices)),
+		rates:     rates,
+	}
+	for _, p := range list.Prices {
+		amount, err := parseAmount(p.List, scale(code))
+		if err != nil {
+			return nil, fmt.Errorf("invalid price of product %d: %w", p.ProductID, err)
+		}
+		if p.DiscountPercent < 0 || p.DiscountPercent > maxDiscount {
+			return nil, fmt.Errorf("invalid discount of product %d: %d%%, want 0 to %d%%", p.ProductID, p.DiscountPercent, maxDiscount)
+		}
+		t.list[p.ProductID] = amount
+		t.discounts[p.ProductID] = p.DiscountPercent
+	}
+	return t, nil
+}
+
+// Currencies returns the codes of the currencies prices are quoted in,
+// sorted.
+func (t *Table) Currencies() []string {
+	codes := make([]string, 0, len(t.rates.Rates))
+	for code := range t.rates.Rates {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Quote returns the price of a product in the first of currencies that the
+// table has a rate for, or in the currency of the price list if none. It
+// returns a NotFoundError for a product without a price.
+func (t *Table) Quote(productID int, currencies []string) (Price, error) {
+	list, ok := t.list[productID]
+	if !ok {
+		return Price{}, NotFoundError{ProductID: productID}
+	}
+	to := t.currency
+	for _, c := range currencies {
+		if c = strings.ToUpper(c); t.rates.Rates[c] != 0 {
+			to = c
+			break
+		}
+	}
+	discount := t.discounts[productID]
+	discounted := list - (list*int64(discount)+50)/100
+	rate := t.rates.Rates[to] / t.rates.Rates[t.currency]
+	return Price{
+		ProductID:       productID,
+		Currency:        to,
+		List:            formatAmount(t.convert(list, to, rate), scale(to)),
+		Discounted:      formatAmount(t.convert(discounted, to, rate), scale(to)),
+		DiscountPercent: discount,
+		Rate:            rate,
+	}, nil
+}
+
+// convert converts amount, in minor units of the price list's currency, to
+// minor units of currency to, at rate.
+func (t *Table) convert(amount int64, to string, rate float64) int64 {
+	if to == t.currency {
+		return amount
+	}
+	v := float64(amount) / math.Pow10(scale(t.currency)) * rate
+	return int64(math.Round(v * math.Pow10(scale(to))))
+}
+
+// scale returns the number of decimals of the amounts of a currency, e.g. 2
+// for USD and 0 for JPY.
+func scale(code string) int {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return 2
+	}
+	s, _ := currency.Standard.Rounding(unit)
+	return s
+}
+
+// parseAmount parses a non-negative decimal amount with up to scale
+// decimals, e.g. "12.99", into minor units, e.g. 1299.
+func parseAmount(s string, scale int) (int64, error) {
+	whole, frac, _ := strings.Cut(s, ".")
+	if whole == "" || len(frac) > scale || strings.HasPrefix(whole, "+") || strings.HasPrefix(whole, "-") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	frac += strings.Repeat("0", scale-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return n, nil
+}
+
+// formatAmount formats an amount in minor units, e.g. 1299, with scale
+// decimals, e.g. "12.99".
+func formatAmount(amount int64, scale int) string {
+	if scale == 0 {
+		return strconv.FormatInt(amount, 10)
+	}
+	p := int64(math.Pow10(scale))
+	return fmt.Sprintf("%d.%0*d", amount/p, scale, amount%p)
+}
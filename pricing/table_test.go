@@ -0,0 +1,166 @@
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+var testList = PriceList{
+	Currency: "USD",
+	Prices: []ListPrice{
+		{ProductID: 1, List: "10"},
+		{ProductID: 2, List: "19.99", DiscountPercent: 20},
+		{ProductID: 3, List: "0.05", DiscountPercent: 10},
+	},
+}
+
+var testRates = Rates{Base: "EUR", Rates: map[string]float64{"EUR": 1, "USD": 1.25, "JPY": 160}}
+
+func TestParseRates(t *testing.T) {
+	got, err := ParseRates([]byte(`{"base": "usd", "rates": {"eur": 0.92, "JPY": 149.2}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "{USD map[EUR:0.92 JPY:149.2 USD:1]}"; fmt.Sprint(got) != want {
+		t.Errorf("ParseRates() = %v, want %v", got, want)
+	}
+
+	for _, data := range []string{
+		``,
+		`{"base": "XYZ", "rates": {}}`,
+		`{"rates": {"EUR": 0.92}}`,
+		`{"base": "USD", "rates": {"EURO": 0.92}}`,
+		`{"base": "USD", "rates": {"EUR": 0}}`,
+		`{"base": "USD", "rates": {"EUR": -1}}`,
+		`{"base": "USD", "rates": {"USD": 2}}`,
+		`{"base": "USD", "rates": {"EUR": "0.92"}}`,
+	} {
+		if got, err := ParseRates([]byte(data)); err == nil {
+			t.Errorf("ParseRates(%s) = %v, want error", data, got)
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	list, err := DefaultPriceList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates, err := DefaultRates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := NewTable(list, rates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every product of the catalog has a price.
+	for id := 0; id < 100; id++ {
+		if _, err := table.Quote(id, nil); err != nil {
+			t.Errorf("Quote(%d): %v", id, err)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	table, err := NewTable(testList, testRates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range []struct {
+		id         int
+		currencies []string
+		want       string
+	}{
+		{1, nil, "USD 10.00 10.00 0%"},
+		{2, nil, "USD 19.99 15.99 20%"},
+		{3, nil, "USD 0.05 0.04 10%"}, // half a cent off is rounded up
+		{2, []string{"EUR"}, "EUR 15.99 12.79 20%"},
+		{2, []string{"jpy"}, "JPY 2559 2047 20%"}, // yen have no decimals
+		{2, []string{"GBP", "XXX", "EUR", "JPY"}, "EUR 15.99 12.79 20%"},
+		{2, []string{"GBP"}, "USD 19.99 15.99 20%"},
+	} {
+		got, err := table.Quote(test.id, test.currencies)
+		if err != nil {
+			t.Errorf("Quote(%d, %q): %v", test.id, test.currencies, err)
+			continue
+		}
+		if s := fmt.Sprintf("%s %s %s %d%%", got.Currency, got.List, got.Discounted, got.DiscountPercent); s != test.want {
+			t.Errorf("Quote(%d, %q) = %s, want %s", test.id, test.currencies, s, test.want)
+		}
+	}
+
+	if _, err := table.Quote(4, nil); !errors.Is(err, NotFoundError{ProductID: 4}) {
+		t.Errorf("Quote(4): err = %v, want a NotFoundError", err)
+	}
+	if got, want := fmt.Sprint(table.Currencies()), "[EUR JPY USD]"; got != want {
+		t.Errorf("Currencies() = %s, want %s", got, want)
+	}
+}
+
+func TestNewTableErrors(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		list  PriceList
+		rates Rates
+	}{
+		{"unknown currency", PriceList{Currency: "XYZ"}, testRates},
+		{"no rate", PriceList{Currency: "GBP"}, testRates},
+		{"invalid price", PriceList{Currency: "USD", Prices: []ListPrice{{ProductID: 1, List: "1.999"}}}, testRates},
+		{"negative price", PriceList{Currency: "USD", Prices: []ListPrice{{ProductID: 1, List: "-1"}}}, testRates},
+		{"discount too high", PriceList{Currency: "USD", Prices: []ListPrice{{ProductID: 1, List: "1", DiscountPercent: 95}}}, testRates},
+	} {
+		if _, err := NewTable(test.list, test.rates); err == nil {
+			t.Errorf("NewTable() with %s succeeded, want error", test.name)
+		}
+	}
+}
+
+func TestAmounts(t *testing.T) {
+	for _, test := range []struct {
+		s     string
+		scale int
+		want  int64
+	}{
+		{"12.99", 2, 1299},
+		{"12.9", 2, 1290},
+		{"12", 2, 1200},
+		{"0.05", 2, 5},
+		{"2558", 0, 2558},
+	} {
+		got, err := parseAmount(test.s, test.scale)
+		if err != nil || got != test.want {
+			t.Errorf("parseAmount(%q, %d) = %d, %v, want %d", test.s, test.scale, got, err, test.want)
+		}
+	}
+	for _, s := range []string{"", ".5", "1.999", "+1", "-1", "1e3", "12.9.9"} {
+		if got, err := parseAmount(s, 2); err == nil {
+			t.Errorf("parseAmount(%q) = %d, want error", s, got)
+		}
+	}
+	if got := formatAmount(1205, 2); got != "12.05" {
+		t.Errorf("formatAmount(1205, 2) = %s, want 12.05", got)
+	}
+	if got := formatAmount(2558, 0); got != "2558" {
+		t.Errorf("formatAmount(2558, 0) = %s, want 2558", got)
+	}
+}
+
+func TestLanguageCurrencies(t *testing.T) {
+	for _, test := range []struct {
+		header, want string
+	}{
+		{"de-AT, en;q=0.5", "[EUR USD]"},
+		{"pt-BR,pt;q=0.9", "[BRL]"},
+		{"ja", "[JPY]"}, // the region it's most likely spoken in
+		{"en-GB, en-IE, fr-FR", "[GBP EUR]"},
+		{"*", "[]"},
+		{"", "[]"},
+		{"!!!", "[]"},
+	} {
+		if got := fmt.Sprint(LanguageCurrencies(test.header)); got != test.want {
+			t.Errorf("LanguageCurrencies(%q) = %s, want %s", test.header, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,595 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:  "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing",
+		Iface: reflect.TypeOf((*Pricing)(nil)).Elem(),
+		Impl:  reflect.TypeOf(pricing{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return pricing_local_stub{impl: impl.(Pricing), tracer: tracer, currenciesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Currencies", Remote: false, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Describe", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Health", Remote: false, Generated: true}), priceMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Price", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return pricing_client_stub{stub: stub, currenciesMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Currencies", Remote: true, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Describe", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Health", Remote: true, Generated: true}), priceMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing", Method: "Price", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return pricing_server_stub{impl: impl.(Pricing), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return pricing_reflect_stub{caller: caller}
+		},
+		RefData: "",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Pricing] = (*pricing)(nil)
+
+// weaver.Router checks.
+var _ weaver.Unrouted = (*pricing)(nil)
+
+// Local stub implementations.
+
+type pricing_local_stub struct {
+	impl              Pricing
+	tracer            trace.Tracer
+	currenciesMetrics *codegen.MethodMetrics
+	describeMetrics   *codegen.MethodMetrics
+	healthMetrics     *codegen.MethodMetrics
+	priceMetrics      *codegen.MethodMetrics
+}
+
+// Check that pricing_local_stub implements the Pricing interface.
+var _ Pricing = (*pricing_local_stub)(nil)
+
+func (s pricing_local_stub) Currencies(ctx context.Context) (r0 []string, err error) {
+	// Update metrics.
+	begin := s.currenciesMetrics.Begin()
+	defer func() { s.currenciesMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "pricing.Pricing.Currencies", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Currencies(ctx)
+}
+
+func (s pricing_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "pricing.Pricing.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s pricing_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "pricing.Pricing.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s pricing_local_stub) Price(ctx context.Context, a0 int, a1 []string) (r0 Price, err error) {
+	// Update metrics.
+	begin := s.priceMetrics.Begin()
+	defer func() { s.priceMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "pricing.Pricing.Price", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Price(ctx, a0, a1)
+}
+
+// Client stub implementations.
+
+type pricing_client_stub struct {
+	stub              codegen.Stub
+	currenciesMetrics *codegen.MethodMetrics
+	describeMetrics   *codegen.MethodMetrics
+	healthMetrics     *codegen.MethodMetrics
+	priceMetrics      *codegen.MethodMetrics
+}
+
+// Check that pricing_client_stub implements the Pricing interface.
+var _ Pricing = (*pricing_client_stub)(nil)
+
+func (s pricing_client_stub) Currencies(ctx context.Context) (r0 []string, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.currenciesMetrics.Begin()
+	defer func() { s.currenciesMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "pricing.Pricing.Currencies", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_string_4af10117(dec)
+	err = dec.Error()
+	return
+}
+
+func (s pricing_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "pricing.Pricing.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s pricing_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "pricing.Pricing.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s pricing_client_stub) Price(ctx context.Context, a0 int, a1 []string) (r0 Price, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.priceMetrics.Begin()
+	defer func() { s.priceMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "pricing.Pricing.Price", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Encode arguments.
+	enc := codegen.NewEncoder()
+	enc.Int(a0)
+	serviceweaver_enc_slice_string_4af10117(enc, a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type pricing_server_stub struct {
+	impl    Pricing
+	addLoad func(key uint64, load float64)
+}
+
+// Check that pricing_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*pricing_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s pricing_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Currencies":
+		return s.currencies
+	case "Describe":
+		return s.describe
+	case "Health":
+		return s.health
+	case "Price":
+		return s.price
+	default:
+		return nil
+	}
+}
+
+func (s pricing_server_stub) currencies(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Currencies(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_string_4af10117(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s pricing_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s pricing_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s pricing_server_stub) price(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 int
+	a0 = dec.Int()
+	var a1 []string
+	a1 = serviceweaver_dec_slice_string_4af10117(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Price(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type pricing_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that pricing_reflect_stub implements the Pricing interface.
+var _ Pricing = (*pricing_reflect_stub)(nil)
+
+func (s pricing_reflect_stub) Currencies(ctx context.Context) (r0 []string, err error) {
+	err = s.caller("Currencies", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s pricing_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s pricing_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s pricing_reflect_stub) Price(ctx context.Context, a0 int, a1 []string) (r0 Price, err error) {
+	err = s.caller("Price", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*NotFoundError)(nil)
+
+type __is_NotFoundError[T ~struct {
+	weaver.AutoMarshal
+	ProductID int
+}] struct{}
+
+var _ __is_NotFoundError[NotFoundError]
+
+func (x *NotFoundError) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+}
+
+func (x *NotFoundError) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("NotFoundError.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+}
+func init() { codegen.RegisterSerializable[*NotFoundError]() }
+
+var _ codegen.AutoMarshal = (*Price)(nil)
+
+type __is_Price[T ~struct {
+	weaver.AutoMarshal
+	ProductID       int     "json:\"product_id\""
+	Currency        string  "json:\"currency\""
+	List            string  "json:\"list\""
+	Discounted      string  "json:\"discounted\""
+	DiscountPercent int     "json:\"discount_percent\""
+	Rate            float64 "json:\"rate\""
+}] struct{}
+
+var _ __is_Price[Price]
+
+func (x *Price) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Price.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+	enc.String(x.Currency)
+	enc.String(x.List)
+	enc.String(x.Discounted)
+	enc.Int(x.DiscountPercent)
+	enc.Float64(x.Rate)
+}
+
+func (x *Price) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Price.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+	x.Currency = dec.String()
+	x.List = dec.String()
+	x.Discounted = dec.String()
+	x.DiscountPercent = dec.Int()
+	x.Rate = dec.Float64()
+}
+
+// Encoding/decoding implementations.
+
+func serviceweaver_enc_slice_string_4af10117(enc *codegen.Encoder, arg []string) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.String(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_string_4af10117(dec *codegen.Decoder) []string {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]string, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.String()
+	}
+	return res
+}
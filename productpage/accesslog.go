@@ -0,0 +1,90 @@
+package productpage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+)
+
+// requestIDHeader carries the request ID. An ID set by the client or a proxy
+// in front of the product page is kept, so logs can be matched with theirs.
+const requestIDHeader = "X-Request-Id"
+
+// userCookie holds the name of the signed in user.
+const userCookie = "user"
+
+// logger returns the product page's logger for the request in ctx.
+func (s *Server) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, s.Logger(ctx), s.level)
+}
+
+// logged tags the requests served by h with a request ID and the user, which
+// are propagated to the components h calls, and writes an access log entry
+// for every request.
+func (s *Server) logged(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		r = r.WithContext(logging.NewContext(r.Context(), requestID, requestUser(r)))
+		w.Header().Set(requestIDHeader, requestID)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(rec, r)
+
+		level := slog.LevelInfo
+		if rec.status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+		s.logger(r.Context()).Log(r.Context(), level, "Access",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration_ms", float64(time.Since(start).Microseconds())/1000,
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		)
+	})
+}
+
+// requestUser returns the signed in user, or "" if there is none.
+func requestUser(r *http.Request) string {
+	if cookie, err := r.Cookie(userCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// newRequestID returns a random request ID.
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// statusRecorder records the status and size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
@@ -0,0 +1,129 @@
+package productpage
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+// maxProductBody bounds the size of posted products.
+const maxProductBody = 64 << 10
+
+// requireAdmin wraps h so that it's served only to requests with the admin
+// token of the config as a bearer token. Without a configured token the
+// admin API is disabled.
+func (s *Server) requireAdmin(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := s.Config().AdminToken
+		if token == "" {
+			writeProblem(w, r, http.StatusForbidden, "the admin API is disabled; set admin_token to enable it")
+			return
+		}
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="bookinfo admin"`)
+			writeProblem(w, r, http.StatusUnauthorized, "missing or wrong admin token")
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// adminProductHandler serves a product of the catalog.
+func (s *Server) adminProductHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	product, err := s.catalog.Get().Get(r.Context(), id)
+	if err != nil {
+		s.catalogProblem(w, r, id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, product)
+}
+
+// createProductHandler adds the posted product to the catalog and serves it,
+// with its new ID.
+func (s *Server) createProductHandler(w http.ResponseWriter, r *http.Request) {
+	product, ok := readProduct(w, r)
+	if !ok {
+		return
+	}
+	product, err := s.catalog.Get().Create(r.Context(), product)
+	if err != nil {
+		s.catalogProblem(w, r, product.ID, err)
+		return
+	}
+	w.Header().Set("Location", "/api/v1/admin/products/"+strconv.Itoa(product.ID))
+	writeJSON(w, http.StatusCreated, product)
+}
+
+// updateProductHandler replaces a product of the catalog with the posted one
+// and serves it.
+func (s *Server) updateProductHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	product, ok := readProduct(w, r)
+	if !ok {
+		return
+	}
+	product.ID = id
+	product, err := s.catalog.Get().Update(r.Context(), product)
+	if err != nil {
+		s.catalogProblem(w, r, id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, product)
+}
+
+// deleteProductHandler deletes a product from the catalog.
+func (s *Server) deleteProductHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	if err := s.catalog.Get().Delete(r.Context(), id); err != nil {
+		s.catalogProblem(w, r, id, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// readProduct decodes the product in the body of r. If the body is invalid,
+// it writes a problem and returns false.
+func readProduct(w http.ResponseWriter, r *http.Request) (catalog.Product, bool) {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxProductBody))
+	dec.DisallowUnknownFields()
+	var product catalog.Product
+	if err := dec.Decode(&product); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("products are limited to %d bytes", maxProductBody))
+			return catalog.Product{}, false
+		}
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid product: %v", err))
+		return catalog.Product{}, false
+	}
+	return product, true
+}
+
+// catalogProblem writes the problem of a failed change to product id of the
+// catalog: a 400 for an invalid product, a 404 for a missing one and a 502
+// otherwise.
+func (s *Server) catalogProblem(w http.ResponseWriter, r *http.Request, id int, err error) {
+	var invalid catalog.InvalidError
+	if errors.As(err, &invalid) {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	s.componentProblem(w, r, "catalog", id, err)
+}
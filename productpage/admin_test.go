@@ -0,0 +1,108 @@
+package productpage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+)
+
+// serveAdmin serves a request to the handler of s with the given bearer
+// token, if any, and returns the recorded response.
+func serveAdmin(s *Server, method, target, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAdminAuth(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		if rec := serveAdmin(s, http.MethodGet, "/api/v1/admin/products/0", "secret", ""); rec.Code != http.StatusForbidden {
+			t.Errorf("without admin_token: status %d, want %d", rec.Code, http.StatusForbidden)
+		}
+	})
+
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/ServiceWeaver/weaver/Main"]
+admin_token = "secret"
+`
+	runner.Test(t, func(t *testing.T, s *Server) {
+		for _, token := range []string{"", "wrong"} {
+			rec := serveAdmin(s, http.MethodGet, "/api/v1/admin/products/0", token, "")
+			if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("token %q: status %d, headers %v, want %d with WWW-Authenticate", token, rec.Code, rec.Header(), http.StatusUnauthorized)
+			}
+		}
+		if rec := serveAdmin(s, http.MethodGet, "/api/v1/admin/products/0", "secret", ""); rec.Code != http.StatusOK {
+			t.Errorf("right token: status %d, want %d", rec.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestAdminProducts(t *testing.T) {
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/ServiceWeaver/weaver/Main"]
+admin_token = "secret"
+`
+	runner.Test(t, func(t *testing.T, s *Server) {
+		rec := serveAdmin(s, http.MethodPost, "/api/v1/admin/products", "secret", `{"title": "Venus and Adonis", "author": "William Shakespeare", "year": 1593}`)
+		created := decode[catalog.Product](t, rec.Body.String())
+		if rec.Code != http.StatusCreated || created.ID != 100 || rec.Header().Get("Location") != "/api/v1/admin/products/100" {
+			t.Fatalf("POST: status %d, Location %q, product %+v, want product 100 created", rec.Code, rec.Header().Get("Location"), created)
+		}
+
+		// The new product is listed, searchable and has details.
+		_, body := serveGet(s, "/api/v1/products?q=venus")
+		if got := decode[[]Product](t, body); len(got) != 1 || got[0].ID != 100 {
+			t.Errorf("products matching venus = %+v, want product 100", got)
+		}
+		if _, body := serveGet(s, "/api/v1/products/100"); decode[details.BookDetails](t, body).Year != 1593 {
+			t.Errorf("details of product 100 = %s, want year 1593", body)
+		}
+
+		rec = serveAdmin(s, http.MethodPut, "/api/v1/admin/products/100", "secret", `{"title": "Venus and Adonis", "year": 1594}`)
+		if got := decode[catalog.Product](t, rec.Body.String()); rec.Code != http.StatusOK || got.ID != 100 || got.Year != 1594 {
+			t.Errorf("PUT: status %d, product %+v, want product 100 of 1594", rec.Code, got)
+		}
+
+		if rec := serveAdmin(s, http.MethodDelete, "/api/v1/admin/products/100", "secret", ""); rec.Code != http.StatusNoContent {
+			t.Errorf("DELETE: status %d, want %d", rec.Code, http.StatusNoContent)
+		}
+		if code, _ := serveGet(s, "/api/v1/products/100"); code != http.StatusNotFound {
+			t.Errorf("details of a deleted product: status %d, want %d", code, http.StatusNotFound)
+		}
+
+		for _, test := range []struct {
+			method, target, body string
+			status               int
+		}{
+			{http.MethodDelete, "/api/v1/admin/products/100", "", http.StatusNotFound},
+			{http.MethodPut, "/api/v1/admin/products/100", `{"title": "Venus and Adonis"}`, http.StatusNotFound},
+			{http.MethodPost, "/api/v1/admin/products", `{"title": " "}`, http.StatusBadRequest},
+			{http.MethodPost, "/api/v1/admin/products", `{"title": "Venus and Adonis", "price": 10}`, http.StatusBadRequest},
+			{http.MethodPost, "/api/v1/admin/products", `{"title": "` + strings.Repeat("x", maxProductBody) + `"}`, http.StatusRequestEntityTooLarge},
+		} {
+			if rec := serveAdmin(s, test.method, test.target, "secret", test.body); rec.Code != test.status {
+				t.Errorf("%s %s %.40q: status %d, want %d", test.method, test.target, test.body, rec.Code, test.status)
+			}
+		}
+	})
+}
+
+// serveGet serves a GET request to the handler of s and returns the status
+// and body of the response.
+func serveGet(s *Server, target string) (int, string) {
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec.Code, rec.Body.String()
+}
@@ -0,0 +1,149 @@
+package productpage
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+)
+
+// booksPerPage is the number of books on a page of /books. It's a ratings
+// shard, so the ratings of a page of consecutive IDs take a single call.
+const booksPerPage = ratings.ShardSize
+
+// booksDetailsUnavailable, ratingsUnavailable and booksReviewsUnavailable
+// are shown on the books page when the details, the ratings or the review
+// counts can't be fetched.
+const (
+	booksDetailsUnavailable = "Sorry, book details are currently unavailable."
+	ratingsUnavailable      = "Sorry, ratings are currently unavailable."
+	booksReviewsUnavailable = "Sorry, review counts are currently unavailable."
+)
+
+// BooksView is the data of the books.html template, a page of the catalog.
+type BooksView struct {
+	Nav        NavView
+	Books      []BookView
+	Total      int // the number of books in the catalog
+	Page       int // from 1
+	Pages      int
+	Prev, Next int // the previous and next pages; 0 if none
+
+	DetailsError string // set if the details couldn't be fetched
+	RatingsError string // set if the ratings couldn't be fetched
+	ReviewsError string // set if the review counts couldn't be fetched
+}
+
+// BookView is a book of the books page.
+type BookView struct {
+	ID      int
+	Title   string
+	Author  string  // empty if the details are missing
+	Reviews int     // the number of reviews of the book; 0 if missing
+	Rated   bool    // whether the book has ratings
+	Average float64 // of the ratings; 0 if none
+	// Stars and EmptyStars have one element per full and empty star of the
+	// rounded average, as in ReviewView.
+	Stars      []int
+	EmptyStars []int
+}
+
+// newBookView returns the view of a book with its details, ratings and
+// review count, which are zero if they're missing.
+func newBookView(p catalog.Product, book details.BookDetails, resp ratings.RatingResponse, reviews int) BookView {
+	view := BookView{ID: p.ID, Title: p.Title, Author: book.Author, Reviews: reviews, Rated: len(resp.Ratings) > 0}
+	if view.Rated {
+		total := 0
+		for _, stars := range resp.Ratings {
+			total += stars
+		}
+		view.Average = float64(total) / float64(len(resp.Ratings))
+		stars := min(max(int(math.Round(view.Average)), 0), maxStars)
+		view.Stars = makeSeq(stars)
+		view.EmptyStars = makeSeq(maxStars - stars)
+	}
+	return view
+}
+
+// booksHandler serves a page of the catalog, picked by the page query
+// parameter, with the author, ratings and review count of every book. They're
+// fetched concurrently with one batch call to details, one to reviews and
+// one to ratings per ratings shard of the page, usually one.
+func (s *Server) booksHandler(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	page := 1
+	if p := r.URL.Query().Get("page"); p != "" {
+		var err error
+		if page, err = strconv.Atoi(p); err != nil || page < 1 || strconv.Itoa(page) != p {
+			writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid page %q", p))
+			return
+		}
+	}
+
+	products, err := s.catalog.Get().List(ctx)
+	if err != nil {
+		s.logger(ctx).Error("Component call failed", "component", "catalog", "err", err)
+		writeProblem(w, r, http.StatusBadGateway, fmt.Sprintf("failed to list the products: %v", err))
+		return
+	}
+	total := len(products)
+	pages := max(1, (total+booksPerPage-1)/booksPerPage)
+	if page > pages {
+		writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("page %d not found; there are %d", page, pages))
+		return
+	}
+	products = products[(page-1)*booksPerPage : min(page*booksPerPage, len(products))]
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	var books map[int]details.BookDetails
+	var bookRatings map[int]ratings.RatingResponse
+	var reviewCounts map[int]int
+	var detailsErr, ratingsErr, reviewsErr error
+	var wg sync.WaitGroup
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		books, detailsErr = s.bookDetails.GetBookDetailsBatch(ctx, ids)
+	}()
+	go func() {
+		defer wg.Done()
+		bookRatings, ratingsErr = ratings.GetMany(ctx, s.bookRatings, ids)
+	}()
+	go func() {
+		defer wg.Done()
+		reviewCounts, reviewsErr = s.bookReviews.ReviewCounts(ctx, ids)
+	}()
+	wg.Wait()
+
+	view := BooksView{Nav: NavView{User: requestUser(r)}, Total: total, Page: page, Pages: pages}
+	if page > 1 {
+		view.Prev = page - 1
+	}
+	if page < pages {
+		view.Next = page + 1
+	}
+	if detailsErr != nil {
+		s.logger(ctx).Error("Failed to get book details", "page", page, "err", detailsErr)
+		view.DetailsError = booksDetailsUnavailable
+	}
+	if ratingsErr != nil {
+		s.logger(ctx).Error("Failed to get book ratings", "page", page, "err", ratingsErr)
+		view.RatingsError = ratingsUnavailable
+	}
+	if reviewsErr != nil {
+		s.logger(ctx).Error("Failed to get review counts", "page", page, "err", reviewsErr)
+		view.ReviewsError = booksReviewsUnavailable
+	}
+	for _, p := range products {
+		view.Books = append(view.Books, newBookView(p, books[p.ID], bookRatings[p.ID], reviewCounts[p.ID]))
+	}
+	s.render(w, r, "books.html", view)
+}
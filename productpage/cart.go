@@ -0,0 +1,349 @@
+package productpage
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+)
+
+const (
+	// cartCookie holds the ID of the cart of a browser.
+	cartCookie = "cart"
+	// cartCookieAge is how long a browser keeps its cart.
+	cartCookieAge = 30 * 24 * time.Hour
+	// maxCartBody bounds the size of the bodies of the cart operations.
+	maxCartBody = 4 << 10
+)
+
+// Messages shown on the cart page when a component fails.
+const (
+	cartUnavailable  = "Sorry, your cart is currently unavailable."
+	orderUnavailable = "Sorry, your order couldn't be placed. Please try again."
+)
+
+// CartView is the data of the cart.html template.
+type CartView struct {
+	Nav   NavView
+	Cart  cart.Contents
+	Key   string // the idempotency key of the checkout form
+	Error string // set if the last change to the cart or checkout failed
+}
+
+// CartItemRequest is the body of a request adding a product to a cart.
+type CartItemRequest struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// QuantityRequest is the body of a request setting the units of a product in
+// a cart.
+type QuantityRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// ProductStock is the stock of a product.
+type ProductStock struct {
+	ProductID int `json:"product_id"`
+	Available int `json:"available"` // units that can be ordered
+}
+
+// requestCart returns the ID of the cart in the cart cookie of r, or "" if it
+// has none.
+func requestCart(r *http.Request) string {
+	c, err := r.Cookie(cartCookie)
+	if err != nil || cart.ValidateID(c.Value) != nil {
+		return ""
+	}
+	return c.Value
+}
+
+// sessionCart returns the ID of the cart of r, and gives the browser a new
+// cart if it has none.
+func sessionCart(w http.ResponseWriter, r *http.Request) string {
+	if id := requestCart(r); id != "" {
+		return id
+	}
+	id := randomID()
+	http.SetCookie(w, &http.Cookie{
+		Name:     cartCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(cartCookieAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	return id
+}
+
+// randomID returns a random ID, for carts and idempotency keys.
+func randomID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// cartPageHandler serves the cart of the browser, with a checkout form.
+func (s *Server) cartPageHandler(w http.ResponseWriter, r *http.Request) {
+	s.renderCart(w, r, requestCart(r), nil)
+}
+
+// renderCart serves the page of cart id. If failure isn't nil, the change to
+// the cart or the checkout that failed with it is explained on the page.
+func (s *Server) renderCart(w http.ResponseWriter, r *http.Request, id string, failure error) {
+	view := CartView{
+		Nav:  NavView{User: requestUser(r)},
+		Cart: cart.Contents{ID: id},
+		Key:  randomID(),
+	}
+	if id != "" {
+		contents, err := s.cart.Get().Get(r.Context(), id)
+		if err != nil {
+			s.logger(r.Context()).Error("Failed to get the cart", "cart_id", id, "err", err)
+			view.Error = cartUnavailable
+		} else {
+			view.Cart = contents
+		}
+	}
+	status := http.StatusOK
+	if failure != nil {
+		status = shopStatus(failure)
+		view.Error = failure.Error()
+		var outOfStock inventory.OutOfStockError
+		switch {
+		case errors.As(failure, &outOfStock):
+			title := fmt.Sprintf("product %d", outOfStock.ProductID)
+			for _, item := range view.Cart.Items {
+				if item.ProductID == outOfStock.ProductID {
+					title = item.Title
+				}
+			}
+			view.Error = fmt.Sprintf("Sorry, only %d left of %s.", outOfStock.Available, title)
+		case errors.As(failure, new(orders.EmptyCartError)):
+			view.Error = "Your cart is empty."
+		case status == http.StatusBadGateway:
+			s.logger(r.Context()).Error("Cart or checkout failed", "cart_id", id, "err", failure)
+			view.Error = orderUnavailable
+		}
+	}
+	s.renderStatus(w, r, status, "cart.html", view)
+}
+
+// cartPageAddHandler adds the product_id and quantity of the posted form to
+// the browser's cart, and redirects to the cart page.
+func (s *Server) cartPageAddHandler(w http.ResponseWriter, r *http.Request) {
+	productID, err := parseProductID(r.PostFormValue("product_id"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	quantity, ok := formQuantity(w, r, 1)
+	if !ok {
+		return
+	}
+	id := sessionCart(w, r)
+	if _, err := s.cart.Get().Add(r.Context(), id, productID, quantity); err != nil {
+		s.renderCart(w, r, id, err)
+		return
+	}
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
+
+// cartPageSetHandler sets the units of a product in the browser's cart to the
+// quantity of the posted form, removing it for 0, and redirects to the cart
+// page.
+func (s *Server) cartPageSetHandler(w http.ResponseWriter, r *http.Request) {
+	productID, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	quantity, ok := formQuantity(w, r, 0)
+	if !ok {
+		return
+	}
+	id := sessionCart(w, r)
+	if _, err := s.cart.Get().Set(r.Context(), id, productID, quantity); err != nil {
+		s.renderCart(w, r, id, err)
+		return
+	}
+	http.Redirect(w, r, "/cart", http.StatusSeeOther)
+}
+
+// formQuantity returns the quantity of the posted form, or def if it has
+// none. If it's invalid, it writes a 400 problem and returns false.
+func formQuantity(w http.ResponseWriter, r *http.Request, def int) (int, bool) {
+	v := r.PostFormValue("quantity")
+	if v == "" {
+		return def, true
+	}
+	quantity, err := strconv.Atoi(v)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid quantity %q", v))
+		return 0, false
+	}
+	return quantity, true
+}
+
+// shopStatus returns the status of the response to a cart, inventory or
+// orders call that failed with err.
+func shopStatus(err error) int {
+	switch {
+	case errors.As(err, new(catalog.NotFoundError)), errors.As(err, new(orders.NotFoundError)):
+		return http.StatusNotFound
+	case errors.As(err, new(cart.InvalidError)), errors.As(err, new(orders.InvalidError)):
+		return http.StatusBadRequest
+	case errors.As(err, new(inventory.OutOfStockError)), errors.As(err, new(orders.EmptyCartError)), errors.As(err, new(orders.FailedError)):
+		return http.StatusConflict
+	}
+	return http.StatusBadGateway
+}
+
+// shopProblem writes the problem of a cart, inventory or orders call to
+// component that failed with err. Component failures are logged.
+func (s *Server) shopProblem(w http.ResponseWriter, r *http.Request, component string, err error) {
+	status := shopStatus(err)
+	if status != http.StatusBadGateway {
+		writeProblem(w, r, status, err.Error())
+		return
+	}
+	s.logger(r.Context()).Error("Component call failed", "component", component, "err", err)
+	writeProblem(w, r, status, fmt.Sprintf("the %s component failed: %v", component, err))
+}
+
+// pathCartID returns the cart ID in the {cart} wildcard of r's path. If the
+// ID is invalid, it writes a 400 problem and returns false.
+func pathCartID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	id := r.PathValue("cart")
+	if err := cart.ValidateID(id); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return "", false
+	}
+	return id, true
+}
+
+// readJSON decodes the JSON body of r, of at most maxCartBody bytes, into v.
+// If the body is invalid, it writes a problem and returns false.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxCartBody))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("request bodies are limited to %d bytes", maxCartBody))
+			return false
+		}
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return false
+	}
+	return true
+}
+
+// writeCart writes the contents of a cart.
+func writeCart(w http.ResponseWriter, contents cart.Contents) {
+	if contents.Items == nil {
+		contents.Items = []cart.Item{}
+	}
+	writeJSON(w, http.StatusOK, contents)
+}
+
+// cartHandler serves the contents of a cart.
+func (s *Server) cartHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathCartID(w, r)
+	if !ok {
+		return
+	}
+	contents, err := s.cart.Get().Get(r.Context(), id)
+	if err != nil {
+		s.shopProblem(w, r, "cart", err)
+		return
+	}
+	writeCart(w, contents)
+}
+
+// addCartItemHandler adds units of a product to a cart and serves its
+// contents.
+func (s *Server) addCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathCartID(w, r)
+	if !ok {
+		return
+	}
+	var req CartItemRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	contents, err := s.cart.Get().Add(r.Context(), id, req.ProductID, req.Quantity)
+	if err != nil {
+		s.shopProblem(w, r, "cart", err)
+		return
+	}
+	writeCart(w, contents)
+}
+
+// setCartItemHandler sets the units of a product in a cart and serves its
+// contents.
+func (s *Server) setCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathCartID(w, r)
+	if !ok {
+		return
+	}
+	productID, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	var req QuantityRequest
+	if !readJSON(w, r, &req) {
+		return
+	}
+	contents, err := s.cart.Get().Set(r.Context(), id, productID, req.Quantity)
+	if err != nil {
+		s.shopProblem(w, r, "cart", err)
+		return
+	}
+	writeCart(w, contents)
+}
+
+// deleteCartItemHandler removes a product from a cart and serves its
+// contents.
+func (s *Server) deleteCartItemHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathCartID(w, r)
+	if !ok {
+		return
+	}
+	productID, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	contents, err := s.cart.Get().Set(r.Context(), id, productID, 0)
+	if err != nil {
+		s.shopProblem(w, r, "cart", err)
+		return
+	}
+	writeCart(w, contents)
+}
+
+// productStockHandler serves the units of a product that can be ordered.
+func (s *Server) productStockHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	if _, err := s.catalog.Get().Get(r.Context(), id); err != nil {
+		s.componentProblem(w, r, "catalog", id, err)
+		return
+	}
+	stock, err := s.inventory.Get().Stock(r.Context(), []int{id})
+	if err != nil {
+		s.componentProblem(w, r, "inventory", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ProductStock{ProductID: id, Available: stock[id]})
+}
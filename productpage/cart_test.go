@@ -0,0 +1,188 @@
+package productpage
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/fakes"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+)
+
+// serveShop serves a request to the handler of s with the given JSON body and
+// idempotency key, if any, and returns the recorded response.
+func serveShop(s *Server, method, target, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// postForm posts a form to path on srv, following redirects, and returns the
+// response and its body.
+func postForm(t *testing.T, client *http.Client, srv *httptest.Server, path string, form url.Values) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.PostForm(srv.URL+path, form)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+// checkoutKey matches the idempotency key of the checkout form of a cart page.
+var checkoutKey = regexp.MustCompile(`name="idempotency_key" value="([0-9a-f]+)"`)
+
+func TestCartPages(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+		jar, err := cookiejar.New(nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		client := srv.Client()
+		client.Jar = jar
+
+		// Adding a book gives the browser a cart, and redirects to it.
+		resp, body := postForm(t, client, srv, "/cart/items", url.Values{"product_id": {"1"}, "quantity": {"2"}})
+		if resp.StatusCode != http.StatusOK || resp.Request.URL.Path != "/cart" || !strings.Contains(body, "Believe as You List") {
+			t.Fatalf("POST /cart/items: status %d at %s, want the cart with Believe as You List", resp.StatusCode, resp.Request.URL.Path)
+		}
+		if cookies := jar.Cookies(resp.Request.URL); len(cookies) != 1 || cookies[0].Name != cartCookie {
+			t.Errorf("cookies = %v, want a %s cookie", cookies, cartCookie)
+		}
+		resp, body = postForm(t, client, srv, "/cart/items/1", url.Values{"quantity": {"21"}})
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, `value="21"`) {
+			t.Fatalf("POST /cart/items/1: status %d, want 21 units in the cart", resp.StatusCode)
+		}
+
+		// Only 20 units are in stock.
+		match := checkoutKey.FindStringSubmatch(body)
+		if match == nil {
+			t.Fatal("the cart page has no checkout form")
+		}
+		resp, body = postForm(t, client, srv, "/checkout", url.Values{"idempotency_key": {match[1]}})
+		if resp.StatusCode != http.StatusConflict || !strings.Contains(body, "Sorry, only 20 left of Believe as You List.") {
+			t.Errorf("POST /checkout of 21 units: status %d, want %d and the units left", resp.StatusCode, http.StatusConflict)
+		}
+
+		// With fewer units, the order is placed, once for a key submitted
+		// twice.
+		postForm(t, client, srv, "/cart/items/1", url.Values{"quantity": {"3"}})
+		for i := 0; i < 2; i++ {
+			resp, body = postForm(t, client, srv, "/checkout", url.Values{"idempotency_key": {match[1]}})
+			if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Request.URL.Path, "/orders/") || !strings.Contains(body, "Thank you for your order") {
+				t.Fatalf("POST /checkout: status %d at %s, want the placed order", resp.StatusCode, resp.Request.URL.Path)
+			}
+		}
+		if _, body := get(t, srv, "/api/v1/products/1/stock"); decode[ProductStock](t, body).Available != 17 {
+			t.Errorf("stock after checkout = %s, want 17 units", body)
+		}
+		if _, body := postForm(t, client, srv, "/checkout", url.Values{"idempotency_key": {"other"}}); !strings.Contains(body, "Your cart is empty.") {
+			t.Errorf("POST /checkout of the emptied cart: want it said empty")
+		}
+	})
+}
+
+func TestCartAPI(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		rec := serveShop(s, http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 1, "quantity": 2}`)
+		if got := decode[cart.Contents](t, rec.Body.String()); rec.Code != http.StatusOK || got.Quantity != 2 || got.Items[0].Title != "Believe as You List" {
+			t.Fatalf("POST items: status %d, cart %+v, want 2 units of Believe as You List", rec.Code, got)
+		}
+		serveShop(s, http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 2, "quantity": 1}`)
+		serveShop(s, http.MethodPut, "/api/v1/carts/alice/items/2", "", `{"quantity": 4}`)
+		rec = serveShop(s, http.MethodDelete, "/api/v1/carts/alice/items/1", "", "")
+		if got := decode[cart.Contents](t, rec.Body.String()); rec.Code != http.StatusOK || len(got.Items) != 1 || got.Quantity != 4 {
+			t.Errorf("DELETE item 1: status %d, cart %+v, want 4 units of product 2", rec.Code, got)
+		}
+
+		// A checkout retried with the same key serves the same order.
+		var first orders.Order
+		for i := 0; i < 2; i++ {
+			rec = serveShop(s, http.MethodPost, "/api/v1/carts/alice/checkout", "key-1", "")
+			got := decode[orders.Order](t, rec.Body.String())
+			if rec.Code != http.StatusCreated || got.Status != orders.Placed || rec.Header().Get("Location") != "/api/v1/orders/"+got.ID {
+				t.Fatalf("checkout: status %d, Location %q, order %+v, want it placed", rec.Code, rec.Header().Get("Location"), got)
+			}
+			if i == 0 {
+				first = got
+			} else if got.ID != first.ID || !got.Created.Equal(first.Created) {
+				t.Errorf("retried checkout = %+v, want %+v", got, first)
+			}
+		}
+		if code, body := serveGet(s, "/api/v1/orders/"+first.ID); code != http.StatusOK || decode[orders.Order](t, body).Quantity != 4 {
+			t.Errorf("GET order: status %d, body %s, want 4 units", code, body)
+		}
+		if code, body := serveGet(s, "/api/v1/carts/alice"); code != http.StatusOK || decode[cart.Contents](t, body).Quantity != 0 {
+			t.Errorf("GET cart after checkout: status %d, body %s, want it empty", code, body)
+		}
+
+		for _, test := range []struct {
+			method, target, key, body string
+			status                    int
+		}{
+			{http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 1000, "quantity": 1}`, http.StatusNotFound},
+			{http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 1, "quantity": 0}`, http.StatusBadRequest},
+			{http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 1, "units": 1}`, http.StatusBadRequest},
+			{http.MethodPut, "/api/v1/carts/alice/items/1", "", `{"quantity": 100}`, http.StatusBadRequest},
+			{http.MethodPost, "/api/v1/carts/alice/checkout", "key-2", "", http.StatusConflict},
+			{http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 1, "quantity": ` + strings.Repeat(" ", maxCartBody) + `1}`, http.StatusRequestEntityTooLarge},
+		} {
+			if rec := serveShop(s, test.method, test.target, test.key, test.body); rec.Code != test.status {
+				t.Errorf("%s %s %.40q: status %d, want %d", test.method, test.target, test.body, rec.Code, test.status)
+			}
+		}
+	})
+}
+
+func TestCheckoutCompensation(t *testing.T) {
+	fakeInventory := fakes.NewInventory(20)
+	fakeInventory.FailWith("Commit", errors.New("inventory is down"))
+	runner := weavertest.Local
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[inventory.Inventory](fakeInventory))
+	runner.Test(t, func(t *testing.T, s *Server) {
+		serveShop(s, http.MethodPost, "/api/v1/carts/alice/items", "", `{"product_id": 1, "quantity": 2}`)
+
+		// The order fails, and its reservation is released.
+		rec := serveShop(s, http.MethodPost, "/api/v1/carts/alice/checkout", "key", "")
+		if rec.Code != http.StatusConflict || rec.Header().Get("Location") != "/api/v1/orders/"+orders.OrderID("alice", "key") {
+			t.Fatalf("checkout: status %d, Location %q, want %d with the failed order", rec.Code, rec.Header().Get("Location"), http.StatusConflict)
+		}
+		if state, _ := fakeInventory.State(orders.OrderID("alice", "key")); state != inventory.Released {
+			t.Errorf("reservation %s, want it released", state)
+		}
+		if _, body := serveGet(s, "/orders/"+orders.OrderID("alice", "key")); !strings.Contains(body, "Your order failed") || !strings.Contains(body, "inventory is down") {
+			t.Errorf("the order page doesn't say the order failed and why")
+		}
+		if _, body := serveGet(s, "/api/v1/carts/alice"); decode[cart.Contents](t, body).Quantity != 2 {
+			t.Errorf("cart after the failed checkout = %s, want it kept", body)
+		}
+
+		// Once the inventory is back, a new key places the order.
+		fakeInventory.FailWith("Commit", nil)
+		if rec := serveShop(s, http.MethodPost, "/api/v1/carts/alice/checkout", "key-2", ""); rec.Code != http.StatusCreated {
+			t.Errorf("checkout with a new key: status %d, want %d", rec.Code, http.StatusCreated)
+		}
+		if state, _ := fakeInventory.State(orders.OrderID("alice", "key-2")); state != inventory.Committed {
+			t.Errorf("reservation %s, want it committed", state)
+		}
+	})
+}
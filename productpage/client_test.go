@@ -0,0 +1,222 @@
+package productpage
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/client/apiv1"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+)
+
+// TestClientTypes checks that the types of the client encode like the types
+// the handlers encode.
+func TestClientTypes(t *testing.T) {
+	for _, test := range []struct{ client, server any }{
+		{apiv1.Product{}, Product{}},
+		{apiv1.CatalogProduct{}, catalog.Product{}},
+		{apiv1.SearchResults{}, search.Results{}},
+		{apiv1.Recommendation{}, recommendations.Recommendation{}},
+		{apiv1.BookDetails{}, details.BookDetails{}},
+		{apiv1.Reviews{}, reviews.Response{}},
+		{apiv1.Ratings{}, ratings.RatingResponse{}},
+		{apiv1.ProductStock{}, ProductStock{}},
+		{apiv1.Price{}, pricing.Price{}},
+		{apiv1.Cart{}, cart.Contents{}},
+		{apiv1.Order{}, orders.Order{}},
+		{apiv1.Readiness{}, Readiness{}},
+		{apiv1.Problem{}, Problem{}},
+	} {
+		client, server := shapeOf(reflect.TypeOf(test.client)), shapeOf(reflect.TypeOf(test.server))
+		if !reflect.DeepEqual(client, server) {
+			t.Errorf("%T is encoded as %v, but %T as %v", test.client, client, test.server, server)
+		}
+	}
+	if apiv1.MaxStars != ratings.MaxStars {
+		t.Errorf("apiv1.MaxStars = %d, want %d", apiv1.MaxStars, ratings.MaxStars)
+	}
+	if apiv1.MaxQuantity != cart.MaxQuantity {
+		t.Errorf("apiv1.MaxQuantity = %d, want %d", apiv1.MaxQuantity, cart.MaxQuantity)
+	}
+}
+
+// shapeOf returns the JSON schema of t, with the references resolved and
+// without documentation.
+func shapeOf(t reflect.Type) any {
+	doc := &OpenAPI{Components: OpenAPIComponents{Schemas: map[string]*Schema{}}}
+	var expand func(s *Schema) any
+	expand = func(s *Schema) any {
+		switch {
+		case s.Ref != "":
+			return expand(doc.Components.Schemas[s.Ref[len("#/components/schemas/"):]])
+		case len(s.AllOf) > 0:
+			return expand(s.AllOf[0])
+		}
+		shape := map[string]any{"type": s.Type, "format": s.Format, "nullable": s.Nullable}
+		if s.Items != nil {
+			shape["items"] = expand(s.Items)
+		}
+		if s.AdditionalProperties != nil {
+			shape["values"] = expand(s.AdditionalProperties)
+		}
+		if s.Properties != nil {
+			properties := map[string]any{}
+			for name, p := range s.Properties {
+				properties[name] = expand(p)
+			}
+			required := append([]string(nil), s.Required...)
+			sort.Strings(required)
+			shape["properties"], shape["required"] = properties, required
+		}
+		return shape
+	}
+	return expand(doc.schema(t))
+}
+
+func TestClient(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+		c, err := apiv1.New(srv.URL, apiv1.WithToken("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+
+		products, err := c.Products(ctx)
+		if err != nil || len(products) == 0 || products[0].Title != "The Comedy of Errors" {
+			t.Errorf("Products() = %+v, %v, want The Comedy of Errors first", products, err)
+		}
+		book, err := c.Details(ctx, 1)
+		if err != nil || book.ID != 1 || book.Author != "William Shakespeare" {
+			t.Errorf("Details(1) = %+v, %v, want the details of product 1", book, err)
+		}
+		results, err := c.Search(ctx, "tempest", 1)
+		if err != nil || results.Total == 0 || len(results.Hits) != 1 || results.Hits[0].Title != "The Tempest" {
+			t.Errorf("Search(tempest, 1) = %+v, %v, want The Tempest", results, err)
+		}
+		// Which products are recommended depends on the ratings posted by
+		// the other tests.
+		recs, err := c.Recommendations(ctx, 1, 2)
+		if err != nil || len(recs) > 2 || (len(recs) > 0 && (recs[0].ProductID == 1 || recs[0].Title == "")) {
+			t.Errorf("Recommendations(1, 2) = %+v, %v, want at most two other products", recs, err)
+		}
+		bookReviews, err := c.Reviews(ctx, 1)
+		if err != nil || bookReviews.ID != "1" || len(bookReviews.Reviews) == 0 {
+			t.Errorf("Reviews(1) = %+v, %v, want the reviews of product 1", bookReviews, err)
+		}
+
+		// Product 8 is only used here, as ratings are posted to it.
+		want := apiv1.Ratings{ID: 8, Ratings: map[string]int{"Reviewer1": 2}}
+		posted, err := c.PostRatings(ctx, 8, want.Ratings)
+		if err != nil || !reflect.DeepEqual(posted, want) {
+			t.Errorf("PostRatings(8) = %+v, %v, want %+v", posted, err, want)
+		}
+		got, err := c.Ratings(ctx, 8)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("Ratings(8) after post = %+v, %v, want %+v", got, err, want)
+		}
+
+		// Invalid ratings are a client error.
+		var e *apiv1.Error
+		if _, err := c.PostRatings(ctx, 8, map[string]int{"Reviewer1": 9}); !errors.As(err, &e) || e.StatusCode != http.StatusBadRequest || e.Problem.RequestID == "" {
+			t.Errorf("PostRatings(8) with 9 stars: %v, want a 400 problem", err)
+		}
+		if _, err := c.Details(ctx, -1); !errors.As(err, &e) || e.StatusCode != http.StatusBadRequest {
+			t.Errorf("Details(-1): %v, want a 400 problem", err)
+		}
+
+		// A purchase: a cart is filled and checked out.
+		if _, err := c.AddToCart(ctx, "alice", 1, 3); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.AddToCart(ctx, "alice", 2, 1); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := c.SetCartItem(ctx, "alice", 1, 2); err != nil || got.Quantity != 3 {
+			t.Errorf("SetCartItem(1, 2) = %+v, %v, want 3 units", got, err)
+		}
+		if got, err := c.RemoveFromCart(ctx, "alice", 2); err != nil || len(got.Items) != 1 || got.Items[0].Title != "Believe as You List" {
+			t.Errorf("RemoveFromCart(2) = %+v, %v, want product 1 left", got, err)
+		}
+		order, err := c.Checkout(ctx, "alice", "key-1")
+		if err != nil || order.Status != "placed" || order.Quantity != 2 {
+			t.Errorf("Checkout() = %+v, %v, want a placed order of 2 units", order, err)
+		}
+		if got, err := c.Order(ctx, order.ID); err != nil || got.ID != order.ID || !got.Created.Equal(order.Created) {
+			t.Errorf("Order(%s) = %+v, %v, want %+v", order.ID, got, err, order)
+		}
+		if got, err := c.Cart(ctx, "alice"); err != nil || len(got.Items) != 0 {
+			t.Errorf("Cart() after checkout = %+v, %v, want it empty", got, err)
+		}
+		if stock, err := c.ProductStock(ctx, 1); err != nil || stock.Available != 18 {
+			t.Errorf("ProductStock(1) = %+v, %v, want 18 units", stock, err)
+		}
+		if _, err := c.Checkout(ctx, "alice", "key-2"); !errors.As(err, &e) || e.StatusCode != http.StatusConflict {
+			t.Errorf("Checkout() of an empty cart: %v, want a 409 problem", err)
+		}
+		if price, err := c.Price(ctx, 3, "eur"); err != nil || price.Currency != "EUR" || price.Discounted != "14.71" || price.DiscountPercent != 20 {
+			t.Errorf("Price(3, eur) = %+v, %v, want 14.71 EUR, 20%% off", price, err)
+		}
+		if _, err := c.Price(ctx, 3, "CHF"); !errors.As(err, &e) || e.StatusCode != http.StatusBadRequest {
+			t.Errorf("Price(3, CHF): %v, want a 400 problem", err)
+		}
+
+		readiness, err := c.Readiness(ctx)
+		if err != nil || !readiness.Ready() || len(readiness.Components) != 10 {
+			t.Errorf("Readiness() = %+v, %v, want ready with 10 components", readiness, err)
+		}
+	})
+}
+
+func TestClientCatalog(t *testing.T) {
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/ServiceWeaver/weaver/Main"]
+admin_token = "secret"
+`
+	runner.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+		c, err := apiv1.New(srv.URL, apiv1.WithToken("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := context.Background()
+
+		created, err := c.CreateProduct(ctx, apiv1.CatalogProduct{Title: "Venus and Adonis", Year: 1593})
+		if err != nil || created.ID != 100 {
+			t.Fatalf("CreateProduct() = %+v, %v, want product 100", created, err)
+		}
+		if got, err := c.SearchProducts(ctx, "venus adonis"); err != nil || len(got) != 1 || got[0].ID != 100 {
+			t.Errorf("SearchProducts() = %+v, %v, want product 100", got, err)
+		}
+		created.Pages = 40
+		if got, err := c.UpdateProduct(ctx, created); err != nil || got != created {
+			t.Errorf("UpdateProduct() = %+v, %v, want %+v", got, err, created)
+		}
+		if got, err := c.CatalogProduct(ctx, 100); err != nil || got != created {
+			t.Errorf("CatalogProduct(100) = %+v, %v, want %+v", got, err, created)
+		}
+		if err := c.DeleteProduct(ctx, 100); err != nil {
+			t.Errorf("DeleteProduct(100) = %v", err)
+		}
+		var e *apiv1.Error
+		if err := c.DeleteProduct(ctx, 100); !errors.As(err, &e) || e.StatusCode != http.StatusNotFound {
+			t.Errorf("DeleteProduct(100) twice: %v, want a 404 problem", err)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package productpage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+)
+
+// config is the product page's configuration, read from the
+// ["github.com/ServiceWeaver/weaver/Main"] section of the app config.
+type config struct {
+	logging.Config
+
+	// Compat serves the HTTP APIs of the original Istio Bookinfo services on
+	// the details, reviews and ratings listeners.
+	Compat bool `toml:"compat"`
+
+	// Remote replaces components with original Bookinfo services.
+	Remote remote.Config `toml:"remote"`
+
+	// AdminToken is the bearer token of the /api/v1/admin routes, which are
+	// disabled without one.
+	AdminToken string `toml:"admin_token"`
+}
+
+// compatListeners returns the compatibility listeners, by name.
+func (s *Server) compatListeners() map[string]net.Listener {
+	return map[string]net.Listener{
+		"details": s.compatDetails,
+		"reviews": s.compatReviews,
+		"ratings": s.compatRatings,
+	}
+}
+
+// compatHandlers returns the handlers of the compatibility listeners, by
+// name. Each serves the routes of the original service of the same name,
+// with the same JSON bodies, so tools and tutorials written for the original
+// Bookinfo work unchanged:
+//
+//	details: GET /details/{id}, GET /health
+//	reviews: GET /reviews/{id}, GET /health
+//	ratings: GET /ratings/{id}, POST /ratings/{id}, GET /health
+func (s *Server) compatHandlers() map[string]http.Handler {
+	details := http.NewServeMux()
+	details.Handle("GET /details/{id}", weaver.InstrumentHandler("compat-details", s.logged(http.HandlerFunc(s.compatDetailsHandler))))
+	details.Handle("GET /health", s.logged(compatHealth("Details is healthy")))
+
+	reviews := http.NewServeMux()
+	reviews.Handle("GET /reviews/{id}", weaver.InstrumentHandler("compat-reviews", s.logged(http.HandlerFunc(s.compatReviewsHandler))))
+	reviews.Handle("GET /health", s.logged(compatHealth("Reviews is healthy")))
+
+	ratings := http.NewServeMux()
+	ratings.Handle("GET /ratings/{id}", weaver.InstrumentHandler("compat-ratings", s.logged(http.HandlerFunc(s.compatRatingsHandler))))
+	ratings.Handle("POST /ratings/{id}", weaver.InstrumentHandler("compat-ratings-post", s.logged(http.HandlerFunc(s.compatPostRatingsHandler))))
+	ratings.Handle("GET /health", s.logged(http.HandlerFunc(s.compatRatingsHealthHandler)))
+
+	return map[string]http.Handler{"details": details, "reviews": reviews, "ratings": ratings}
+}
+
+// serveCompat serves the compatibility listeners until one fails, if they're
+// enabled, and closes them otherwise.
+func (s *Server) serveCompat(ctx context.Context) error {
+	listeners := s.compatListeners()
+	if !s.Config().Compat {
+		for _, l := range listeners {
+			l.Close()
+		}
+		return nil
+	}
+
+	handlers := s.compatHandlers()
+	errs := make(chan error, len(listeners))
+	for name, l := range listeners {
+		s.logger(ctx).Info("Bookinfo compatibility listener is up", "service", name, "address", l.Addr())
+		go func() {
+			errs <- fmt.Errorf("%s compatibility listener: %w", name, http.Serve(l, handlers[name]))
+		}()
+	}
+	return <-errs
+}
+
+// compatError writes an error the way the original services do.
+func compatError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// compatHealth returns a handler that reports a healthy service.
+func compatHealth(status string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": status})
+	})
+}
+
+// compatID returns the numeric product ID in the path of r, or writes the
+// error of the original services and returns false.
+func compatID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		compatError(w, http.StatusBadRequest, "please provide numeric product ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// compatDetailsHandler serves GET /details/{id}.
+func (s *Server) compatDetailsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := compatID(w, r)
+	if !ok {
+		return
+	}
+	book, err := s.bookDetails.GetBookDetails(r.Context(), id, nil)
+	var notFound catalog.NotFoundError
+	if errors.As(err, &notFound) {
+		compatError(w, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		s.logger(r.Context()).Error("Failed to get book details", "product_id", id, "err", err)
+		compatError(w, http.StatusInternalServerError, "could not fetch details")
+		return
+	}
+	writeJSON(w, http.StatusOK, book)
+}
+
+// compatReviewsHandler serves GET /reviews/{id}. Like the original, it
+// doesn't validate the product ID.
+func (s *Server) compatReviewsHandler(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	resp, err := s.bookReviews.BookReviewsByID(r.Context(), id)
+	if err != nil {
+		s.logger(r.Context()).Error("Failed to get book reviews", "product_id", id, "err", err)
+		compatError(w, http.StatusInternalServerError, "could not fetch reviews")
+		return
+	}
+	writeJSON(w, http.StatusOK, reviews.ToUpstream(resp))
+}
+
+// compatRatingsHandler serves GET /ratings/{id}.
+func (s *Server) compatRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := compatID(w, r)
+	if !ok {
+		return
+	}
+	resp, err := s.bookRatings.GetRatings(r.Context(), id)
+	if err != nil {
+		s.logger(r.Context()).Error("Failed to get ratings", "product_id", id, "err", err)
+		compatError(w, http.StatusInternalServerError, "could not connect to ratings database")
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// compatPostRatingsHandler serves POST /ratings/{id}.
+func (s *Server) compatPostRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRatingsBody))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		compatError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("ratings are limited to %d bytes", maxRatingsBody))
+		return
+	}
+	if err != nil {
+		compatError(w, http.StatusBadRequest, "please provide valid ratings JSON")
+		return
+	}
+	id := r.PathValue("id")
+	if _, _, err := ratings.ParseRatings(id, body); err != nil {
+		compatError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	resp, err := s.bookRatings.PostRatings(r.Context(), id, body)
+	if err != nil {
+		s.logger(r.Context()).Error("Failed to post ratings", "product_id", id, "err", err)
+		compatError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// compatRatingsHealthHandler serves GET /health of the ratings service, which
+// fails when the ratings component is unhealthy.
+func (s *Server) compatRatingsHealthHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := s.bookRatings.Health(r.Context())
+	if err != nil || !status.Healthy {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"status": "Ratings is not healthy"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "Ratings is healthy"})
+}
@@ -0,0 +1,159 @@
+package productpage
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/fakes"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+)
+
+// serveCompatRequest sends a request to the compatibility handler of service
+// and returns the status and decoded JSON body of the response.
+func serveCompatRequest(t *testing.T, s *Server, service, method, path, body string) (int, any) {
+	t.Helper()
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	rec := httptest.NewRecorder()
+	s.compatHandlers()[service].ServeHTTP(rec, httptest.NewRequest(method, path, r))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("%s %s: Content-Type %q, want application/json", method, path, ct)
+	}
+	return rec.Code, decode[any](t, rec.Body.String())
+}
+
+func TestCompatConfig(t *testing.T) {
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/ServiceWeaver/weaver/Main"]
+log_level = "debug"
+compat = true
+`
+	runner.Test(t, func(t *testing.T, s *Server) {
+		if !s.Config().Compat || s.level != slog.LevelDebug {
+			t.Errorf("config %+v, level %v; want compat and debug", s.Config(), s.level)
+		}
+	})
+}
+
+func TestCompatAPIs(t *testing.T) {
+	for _, test := range []struct {
+		service, method, path, body string
+		status                      int
+		want                        string // JSON body
+	}{
+		{
+			service: "details", method: http.MethodGet, path: "/details/1",
+			status: http.StatusOK,
+			want:   `{"id":1,"author":"William Shakespeare","year":1595,"type":"paperback","pages":200,"publisher":"PublisherA","language":"English","ISBN-10":"1234567890","ISBN-13":"123-1234567890"}`,
+		},
+		{
+			service: "details", method: http.MethodGet, path: "/details/1000",
+			status: http.StatusNotFound,
+			want:   `{"error":"product 1000 not found"}`,
+		},
+		{
+			service: "details", method: http.MethodGet, path: "/details/one",
+			status: http.StatusBadRequest,
+			want:   `{"error":"please provide numeric product ID"}`,
+		},
+		{
+			service: "details", method: http.MethodGet, path: "/health",
+			status: http.StatusOK,
+			want:   `{"status":"Details is healthy"}`,
+		},
+		{
+			service: "reviews", method: http.MethodGet, path: "/health",
+			status: http.StatusOK,
+			want:   `{"status":"Reviews is healthy"}`,
+		},
+		{
+			service: "ratings", method: http.MethodGet, path: "/ratings/1",
+			status: http.StatusOK,
+			want:   `{"id":1,"ratings":{"Reviewer1":5,"Reviewer2":4}}`,
+		},
+		{
+			service: "ratings", method: http.MethodGet, path: "/ratings/one",
+			status: http.StatusBadRequest,
+			want:   `{"error":"please provide numeric product ID"}`,
+		},
+		{
+			// Product 9 is only used here, as ratings are posted to it.
+			service: "ratings", method: http.MethodPost, path: "/ratings/9", body: `{"Reviewer1":1,"Reviewer2":0}`,
+			status: http.StatusOK,
+			want:   `{"id":9,"ratings":{"Reviewer1":1,"Reviewer2":0}}`,
+		},
+		{
+			service: "ratings", method: http.MethodPost, path: "/ratings/9", body: `{"Reviewer1":`,
+			status: http.StatusBadRequest,
+			want:   `{"error":"please provide valid ratings JSON"}`,
+		},
+		{
+			service: "ratings", method: http.MethodGet, path: "/health",
+			status: http.StatusOK,
+			want:   `{"status":"Ratings is healthy"}`,
+		},
+	} {
+		weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+			status, got := serveCompatRequest(t, s, test.service, test.method, test.path, test.body)
+			if status != test.status {
+				t.Errorf("%s %s: status %d, want %d", test.method, test.path, status, test.status)
+			}
+			if want := decode[any](t, test.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s %s: body %v, want %v", test.method, test.path, got, want)
+			}
+		})
+	}
+}
+
+func TestCompatReviews(t *testing.T) {
+	fakeRatings := fakes.NewRatings()
+	runner := weavertest.Local
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[ratings.Ratings](fakeRatings))
+	runner.Test(t, func(t *testing.T, s *Server) {
+		review := func(t *testing.T) map[string]any {
+			t.Helper()
+			status, got := serveCompatRequest(t, s, "reviews", http.MethodGet, "/reviews/1", "")
+			body, ok := got.(map[string]any)
+			if status != http.StatusOK || !ok || body["id"] != "1" {
+				t.Fatalf("GET /reviews/1: status %d, body %v", status, got)
+			}
+			for _, key := range []string{"podname", "clustername"} {
+				if _, ok := body[key]; !ok {
+					t.Errorf("GET /reviews/1: no %s", key)
+				}
+			}
+			return body["reviews"].([]any)[0].(map[string]any)
+		}
+
+		got := review(t)
+		want := map[string]any{
+			"reviewer": "Reviewer1",
+			"text":     "An extremely entertaining play by Shakespeare. The slapstick humour is refreshing!",
+			"rating":   map[string]any{"stars": 5.0, "color": "black"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("first review %v, want %v", got, want)
+		}
+
+		fakeRatings.FailWith("GetRatings", errors.New("ratings are down"))
+		if got := review(t)["rating"]; !reflect.DeepEqual(got, map[string]any{"error": reviews.RatingsUnavailable}) {
+			t.Errorf("rating with ratings down %v, want the error of the original", got)
+		}
+
+		fakeRatings.SetHealth(ratings.HealthStatus{Healthy: false})
+		if status, got := serveCompatRequest(t, s, "ratings", http.MethodGet, "/health", ""); status != http.StatusInternalServerError {
+			t.Errorf("GET /health of unhealthy ratings: status %d, body %v", status, got)
+		}
+	})
+}
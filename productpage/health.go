@@ -0,0 +1,130 @@
+package productpage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+)
+
+// probeTimeout bounds how long readiness waits for each downstream component.
+const probeTimeout = 2 * time.Second
+
+// ComponentHealth is the readiness result of a single downstream component.
+type ComponentHealth struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Healthy  *bool  `json:"healthy,omitempty"`
+	Database string `json:"database,omitempty"`
+}
+
+// Readiness is the JSON body returned by /readyz.
+type Readiness struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// healthzHandler reports liveness. It never calls other components, so a
+// slow or failing dependency doesn't get the product page restarted.
+func (s *Server) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyzHandler reports readiness by probing Catalog, Details, Reviews,
+// Ratings and Search.
+func (s *Server) readyzHandler(w http.ResponseWriter, r *http.Request) {
+	readiness := s.probeComponents(r.Context())
+
+	status := http.StatusOK
+	if readiness.Status != "ready" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, readiness)
+}
+
+// probeComponents calls the health method of every downstream component
+// concurrently and aggregates the results.
+func (s *Server) probeComponents(ctx context.Context) Readiness {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	probes := map[string]func(context.Context) ComponentHealth{
+		"catalog": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.catalog.Get().Health(ctx))
+		},
+		"details": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.bookDetails.Health(ctx))
+		},
+		"reviews": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.bookReviews.Health(ctx))
+		},
+		"search": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.search.Get().Health(ctx))
+		},
+		"recommendations": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.recommendations.Get().Health(ctx))
+		},
+		"cart": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.cart.Get().Health(ctx))
+		},
+		"inventory": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.inventory.Get().Health(ctx))
+		},
+		"orders": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.orders.Get().Health(ctx))
+		},
+		"pricing": func(ctx context.Context) ComponentHealth {
+			return errorHealth(s.pricing.Get().Health(ctx))
+		},
+		"ratings": func(ctx context.Context) ComponentHealth {
+			status, err := s.bookRatings.Health(ctx)
+			if err != nil {
+				return errorHealth(err)
+			}
+			h := ComponentHealth{Status: "ok", Healthy: &status.Healthy, Database: status.Database}
+			if !status.Healthy || status.Database == ratings.DatabaseUnreachable {
+				h.Status = "unhealthy"
+			}
+			return h
+		},
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	readiness := Readiness{Status: "ready", Components: map[string]ComponentHealth{}}
+	for name, probe := range probes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := probe(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			readiness.Components[name] = h
+			if h.Status != "ok" {
+				readiness.Status = "not ready"
+			}
+		}()
+	}
+	wg.Wait()
+	return readiness
+}
+
+// errorHealth converts the error returned by a health method into a result.
+func errorHealth(err error) ComponentHealth {
+	if err != nil {
+		return ComponentHealth{Status: "unavailable", Error: err.Error()}
+	}
+	return ComponentHealth{Status: "ok"}
+}
+
+// writeJSON writes v as a JSON response with the given status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(v)
+}
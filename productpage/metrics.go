@@ -0,0 +1,15 @@
+package productpage
+
+import "github.com/ServiceWeaver/weaver/metrics"
+
+// renderLabels label the product page renders. Component is the full name of
+// the product page component, as in Service Weaver's method metrics.
+type renderLabels struct {
+	Component string
+	Product   int
+}
+
+var pageRenders = metrics.NewCounterMap[renderLabels](
+	"bookinfo_productpage_renders",
+	"Product pages rendered, by product ID",
+)
@@ -0,0 +1,666 @@
+package productpage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+)
+
+// apiOperations are the operations of the /api/v1 routes, documented in the
+// OpenAPI document. The routes themselves are registered in routes; a test
+// checks that both agree.
+var apiOperations = []apiOperation{
+	{
+		Pattern: "GET /api/v1/products",
+		ID:      "listProducts",
+		Summary: "List the products",
+		Query: []Parameter{{
+			Name:        "q",
+			Description: "Words that the title, author or description of the listed products contain, ignoring case.",
+			Schema:      &Schema{Type: "string"},
+		}},
+		Response: []Product{},
+	},
+	{
+		Pattern: "GET /api/v1/search",
+		ID:      "search",
+		Summary: "Search the products, best matches first",
+		Query: []Parameter{
+			{
+				Name:        "q",
+				Required:    true,
+				Description: "Words that the title, author or description of the products match, ignoring case. A word matches the words it's equal to, a prefix of, or a typo or two away from, which rank lower in that order.",
+				Schema:      &Schema{Type: "string"},
+				Example:     "tempest",
+			},
+			{
+				Name:        "limit",
+				Description: "Maximum number of hits.",
+				Schema:      &Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(float64(maxSearchLimit)), Default: defaultSearchLimit},
+			},
+		},
+		Response: search.Results{},
+		Problems: map[int]string{
+			http.StatusBadRequest: "Missing query or invalid limit",
+			http.StatusBadGateway: "The search component failed",
+		},
+	},
+	{
+		Pattern:  "GET /api/v1/products/{id}",
+		ID:       "getProductDetails",
+		Summary:  "Get the book details of a product, with its price",
+		Response: ProductDetails{},
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern:  "GET /api/v1/products/{id}/reviews",
+		ID:       "getProductReviews",
+		Summary:  "Get the reviews of a product",
+		Response: reviews.Response{},
+	},
+	{
+		Pattern:  "GET /api/v1/products/{id}/ratings",
+		ID:       "getProductRatings",
+		Summary:  "Get the ratings of a product",
+		Response: ratings.RatingResponse{},
+	},
+	{
+		Pattern: "GET /api/v1/products/{id}/recommendations",
+		ID:      "getProductRecommendations",
+		Summary: "Get the products that the readers of a product also liked, most similar first",
+		Query: []Parameter{{
+			Name:        "n",
+			Description: "Maximum number of recommendations.",
+			Schema:      &Schema{Type: "integer", Minimum: ptr(1.0), Maximum: ptr(float64(maxRecommendations)), Default: defaultRecommendations},
+		}},
+		Response: []recommendations.Recommendation{},
+		Problems: map[int]string{http.StatusBadRequest: "Invalid product ID or n"},
+	},
+	{
+		Pattern:  "POST /api/v1/products/{id}/ratings",
+		ID:       "postProductRatings",
+		Summary:  "Set the ratings of a product, from 0 to 5 stars by reviewer",
+		Request:  map[string]int{},
+		Example:  `{"Reviewer1": 3}`,
+		Response: ratings.RatingResponse{},
+	},
+	{
+		Pattern:  "GET /api/v1/products/{id}/stock",
+		ID:       "getProductStock",
+		Summary:  "Get the units of a product that can be ordered",
+		Response: ProductStock{},
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern: "GET /api/v1/products/{id}/price",
+		ID:      "getProductPrice",
+		Summary: "Get the price of a product, with its discount",
+		Query: []Parameter{{
+			Name:        "currency",
+			Description: "ISO 4217 code of the currency of the price. Without it, the price is in the currency of the currency cookie, or else of the first language of the Accept-Language header with a region whose currency is supported, or else in USD.",
+			Schema:      &Schema{Type: "string"},
+			Example:     "EUR",
+		}},
+		Response: pricing.Price{},
+		Problems: map[int]string{
+			http.StatusBadRequest: "Invalid product ID, or invalid or unsupported currency",
+			http.StatusNotFound:   "No price for the product",
+			http.StatusBadGateway: "The pricing component failed",
+		},
+	},
+	{
+		Pattern:  "GET /api/v1/carts/{cart}",
+		ID:       "getCart",
+		Summary:  "Get the items in a cart; a new cart is empty",
+		Response: cart.Contents{},
+	},
+	{
+		Pattern:  "POST /api/v1/carts/{cart}/items",
+		ID:       "addCartItem",
+		Summary:  "Add units of a product to a cart",
+		Request:  CartItemRequest{},
+		Example:  `{"product_id": 7, "quantity": 1}`,
+		Response: cart.Contents{},
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern:  "DELETE /api/v1/carts/{cart}/items/{id}",
+		ID:       "deleteCartItem",
+		Summary:  "Remove a product from a cart",
+		Response: cart.Contents{},
+	},
+	{
+		Pattern:  "PUT /api/v1/carts/{cart}/items/{id}",
+		ID:       "setCartItem",
+		Summary:  "Set the units of a product in a cart; 0 removes it",
+		Request:  QuantityRequest{},
+		Example:  `{"quantity": 2}`,
+		Response: cart.Contents{},
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern: "POST /api/v1/carts/{cart}/checkout",
+		ID:      "checkout",
+		Summary: "Order the items in a cart and empty it",
+		Header: []Parameter{{
+			Name:        idempotencyKeyHeader,
+			Required:    true,
+			Description: "Key of the purchase, picked by the client, e.g. at random: 1 to 128 printable ASCII characters. Retrying a checkout with the same key returns the same order or problem, without ordering again.",
+			Schema:      &Schema{Type: "string"},
+			Example:     exampleIdempotencyKey,
+		}},
+		Status:   http.StatusCreated,
+		Response: orders.Order{},
+		Problems: map[int]string{
+			http.StatusBadRequest: "Invalid cart ID or missing or invalid idempotency key",
+			http.StatusConflict:   "The cart is empty, an item is out of stock, or the order failed; a failed order is recorded, and the Location header points to it",
+		},
+	},
+	{
+		Pattern:  "GET /api/v1/orders/{order}",
+		ID:       "getOrder",
+		Summary:  "Get an order",
+		Response: orders.Order{},
+		Problems: map[int]string{http.StatusNotFound: "No such order"},
+	},
+	{
+		Pattern:  "GET /api/v1/admin/products/{id}",
+		ID:       "getCatalogProduct",
+		Summary:  "Get a product of the catalog",
+		Admin:    true,
+		Response: catalog.Product{},
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern:  "POST /api/v1/admin/products",
+		ID:       "createCatalogProduct",
+		Summary:  "Add a product to the catalog, with the next free ID",
+		Admin:    true,
+		Request:  catalog.Product{},
+		Example:  exampleProduct,
+		Status:   http.StatusCreated,
+		Response: catalog.Product{},
+	},
+	{
+		Pattern:  "PUT /api/v1/admin/products/{id}",
+		ID:       "updateCatalogProduct",
+		Summary:  "Replace a product of the catalog",
+		Admin:    true,
+		Request:  catalog.Product{},
+		Example:  exampleProduct,
+		Response: catalog.Product{},
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern:  "DELETE /api/v1/admin/products/{id}",
+		ID:       "deleteCatalogProduct",
+		Summary:  "Delete a product from the catalog",
+		Admin:    true,
+		Status:   http.StatusNoContent,
+		Problems: map[int]string{http.StatusNotFound: "No such product"},
+	},
+	{
+		Pattern:  "GET /api/v1/topology",
+		ID:       "getTopology",
+		Summary:  "Get the live topology of the deployment",
+		Response: Topology{},
+	},
+	{
+		Pattern:  "GET /api/v1/openapi.json",
+		ID:       "getOpenAPI",
+		Summary:  "Get this OpenAPI document",
+		Response: map[string]any{},
+	},
+}
+
+// exampleProduct is an example body of the catalog admin operations.
+const exampleProduct = `{
+  "title": "Venus and Adonis",
+  "description_html": "<b>Venus and Adonis</b> is a narrative poem by William Shakespeare.",
+  "author": "William Shakespeare",
+  "year": 1593,
+  "type": "paperback",
+  "pages": 120,
+  "publisher": "PublisherA",
+  "language": "English",
+  "ISBN-10": "1234567890",
+  "ISBN-13": "123-1234567890"
+}`
+
+// Examples of the cart and order wildcards. The example order is the one
+// placed by the checkout of the example cart with the example key.
+const (
+	exampleCart           = "c0ffee"
+	exampleIdempotencyKey = "4d1c7e0a"
+)
+
+// pathParameters are the wildcards of the paths of the operations, by name.
+var pathParameters = map[string]pathParameter{
+	"id": {"product ID", Parameter{
+		Description: "Product ID, written without a sign or leading zeros.",
+		Schema:      &Schema{Type: "integer", Minimum: ptr(0.0)},
+	}},
+	"cart": {"cart ID", Parameter{
+		Description: "Cart ID, picked by the client, e.g. at random: 1 to 64 letters, digits, '_' and '-'.",
+		Schema:      &Schema{Type: "string"},
+		Example:     exampleCart,
+	}},
+	"order": {"", Parameter{
+		Description: "Order ID, from the checkout that placed the order.",
+		Schema:      &Schema{Type: "string"},
+		Example:     orders.OrderID(exampleCart, exampleIdempotencyKey),
+	}},
+}
+
+// pathParameter is a path parameter, and what its value is called in
+// problems, e.g. "product ID", if it's validated.
+type pathParameter struct {
+	Noun string
+	Parameter
+}
+
+// apiOperation is an operation of the API. Request and Response are values
+// of the types of its request body, if any, and successful response body, if
+// any.
+type apiOperation struct {
+	Pattern  string // as registered on the router, e.g. "GET /api/v1/products"
+	ID       string
+	Summary  string
+	Query    []Parameter // query parameters; In is implied
+	Header   []Parameter // header parameters; In is implied
+	Admin    bool        // requires the admin token
+	Request  any
+	Example  string // example request body
+	Status   int    // of the successful response; 200 if zero
+	Response any
+	Problems map[int]string // error statuses other than the common ones, with their descriptions
+}
+
+// schemaNames are the names of the types whose package-local names are too
+// generic for the document.
+var schemaNames = map[reflect.Type]string{
+	reflect.TypeFor[reviews.Response]():       "ProductReviews",
+	reflect.TypeFor[ratings.RatingResponse](): "ProductRatings",
+	reflect.TypeFor[catalog.Product]():        "CatalogProduct",
+	reflect.TypeFor[search.Results]():         "SearchResults",
+	reflect.TypeFor[search.Hit]():             "SearchHit",
+	reflect.TypeFor[cart.Contents]():          "Cart",
+	reflect.TypeFor[cart.Item]():              "CartItem",
+}
+
+// fieldNotes annotate fields of the schemas, keyed by "Schema.field".
+var fieldNotes = map[string]Schema{
+	"ProductReviews.podname": {
+		Description: "Host name of the reviews replica that served the request. It leaks deployment details and is kept for compatibility with the original Bookinfo only.",
+		Deprecated:  true,
+	},
+	"ProductReviews.clustername": {
+		Description: "Cluster of the reviews replica that served the request. It leaks deployment details and is kept for compatibility with the original Bookinfo only.",
+		Deprecated:  true,
+	},
+	"ProductReviews.id": {
+		Description: "Product ID, as a string.",
+	},
+	"Rating.stars": {
+		Description: "Stars from 0 to 5, or -1 if ratings are unavailable, in which case color holds the reason.",
+	},
+	"Product.description_html": {
+		Description: "Description of the product, in HTML.",
+	},
+	"CatalogProduct.id": {
+		Description: "Product ID. It's assigned when a product is added, and ignored in request bodies.",
+	},
+	"Recommendation.score": {
+		Description: "Similarity of the ratings of the two products, from 0 to 1; higher is more similar.",
+	},
+	"Recommendation.readers": {
+		Description: "Number of readers who rated both products.",
+	},
+	"Cart.quantity": {
+		Description: "Number of units of every item.",
+	},
+	"Order.quantity": {
+		Description: "Number of units of every item.",
+	},
+	"Order.status": {
+		Description: "placed, or failed if the items couldn't be sold, in which case they went back in stock and reason tells why.",
+	},
+	"ProductDetails.price": {
+		Description: "Price, in the currency of the currency cookie, or else of the Accept-Language header, as for getProductPrice; null if the product has no price or the pricing component failed.",
+	},
+	"EdgeView.rate": {
+		Description: "Calls per second since the previous topology served by the same product page replica, over the caller replicas found both times.",
+	},
+	"EdgeView.interval": {
+		Description: "Seconds since the previous topology, over which rate was computed; 0 for the first topology, whose rate is unknown.",
+	},
+	"Price.list": {
+		Description: "List price, as a decimal with the number of decimals of the currency, e.g. 12.99 for USD and 1938 for JPY.",
+	},
+	"Price.discounted": {
+		Description: "Price to pay, the list price less the discount, formatted like list.",
+	},
+	"Price.rate": {
+		Description: "Units of the currency per US dollar, the currency of the price list, that the price was converted at.",
+	},
+	"SearchResults.total": {
+		Description: "Number of matching products, of which hits are the best ones.",
+	},
+	"SearchHit.title_html": {
+		Description: "Title, in HTML, with the words matching the query in mark elements.",
+	},
+	"SearchHit.snippet_html": {
+		Description: "Excerpt of the description around the first word matching the query, in HTML, highlighted like title_html.",
+	},
+	"CatalogProduct.description_html": {
+		Description: "Description of the product, in HTML. It's shown as it is, so only admins can set it.",
+	},
+}
+
+// OpenAPI is an OpenAPI 3.0 document, limited to what the API uses.
+type OpenAPI struct {
+	OpenAPI    string                          `json:"openapi"`
+	Info       OpenAPIInfo                     `json:"info"`
+	Servers    []OpenAPIServer                 `json:"servers"`
+	Paths      map[string]map[string]Operation `json:"paths"`
+	Components OpenAPIComponents               `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way of authenticating requests.
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+// Operation is an operation of a path.
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+// Parameter is a parameter of an operation.
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+	Example     any     `json:"example,omitempty"`
+}
+
+// RequestBody is the request body of an operation, by media type.
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation, by media type.
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema  *Schema         `json:"schema"`
+	Example json.RawMessage `json:"example,omitempty"`
+}
+
+// Schema is an OpenAPI schema object.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+}
+
+// newOpenAPI returns the OpenAPI document of the API. The schemas are
+// derived from the Go types the handlers encode, so they can't drift apart.
+func newOpenAPI() *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: "3.0.3",
+		Info: OpenAPIInfo{
+			Title:       "Bookinfo API",
+			Description: "The products of the Bookinfo bookstore, with their details, reviews and ratings. Errors are RFC 7807 problem details.",
+			Version:     "v1",
+		},
+		Servers:    []OpenAPIServer{{URL: "/"}},
+		Paths:      map[string]map[string]Operation{},
+		Components: OpenAPIComponents{Schemas: map[string]*Schema{}},
+	}
+	problem := doc.schema(reflect.TypeFor[Problem]())
+	problemResponse := func(description string) Response {
+		return Response{Description: description, Content: map[string]MediaType{problemContentType: {Schema: problem}}}
+	}
+	for _, op := range apiOperations {
+		method, path, _ := strings.Cut(op.Pattern, " ")
+		status := op.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if op.Response != nil {
+			success.Content = map[string]MediaType{"application/json": {Schema: doc.schema(reflect.TypeOf(op.Response))}}
+		}
+		operation := Operation{
+			OperationID: op.ID,
+			Summary:     op.Summary,
+			Responses: map[string]Response{
+				strconv.Itoa(status): success,
+				"default":            problemResponse("Error"),
+			},
+		}
+		var invalid []string // what can be invalid
+		for _, name := range wildcards(path) {
+			p, ok := pathParameters[name]
+			if !ok {
+				panic(fmt.Sprintf("path %s has an undocumented wildcard %q", path, name))
+			}
+			p.Name, p.In, p.Required = name, "path", true
+			operation.Parameters = append(operation.Parameters, p.Parameter)
+			if p.Noun != "" {
+				invalid = append(invalid, p.Noun)
+			}
+		}
+		if len(operation.Parameters) > 0 {
+			operation.Responses["502"] = problemResponse("A component failed")
+		}
+		for _, p := range op.Query {
+			p.In = "query"
+			operation.Parameters = append(operation.Parameters, p)
+		}
+		for _, p := range op.Header {
+			p.In = "header"
+			operation.Parameters = append(operation.Parameters, p)
+		}
+		if op.Request != nil {
+			media := MediaType{Schema: doc.schema(reflect.TypeOf(op.Request))}
+			if op.Example != "" {
+				media.Example = json.RawMessage(op.Example)
+			}
+			operation.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": media}}
+			invalid = append(invalid, "request body")
+			operation.Responses["413"] = problemResponse("Request body too large")
+		}
+		if len(invalid) > 0 {
+			operation.Responses["400"] = problemResponse("Invalid " + list(invalid))
+		}
+		if op.Admin {
+			operation.Security = []map[string][]string{{"admin": {}}}
+			operation.Responses["401"] = problemResponse("Missing or wrong admin token")
+			operation.Responses["403"] = problemResponse("The admin API is disabled")
+			doc.Components.SecuritySchemes = map[string]SecurityScheme{"admin": {
+				Type:        "http",
+				Scheme:      "bearer",
+				Description: "The admin_token of the product page's config.",
+			}}
+		}
+		for status, description := range op.Problems {
+			operation.Responses[strconv.Itoa(status)] = problemResponse(description)
+		}
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]Operation{}
+		}
+		doc.Paths[path][strings.ToLower(method)] = operation
+	}
+	return doc
+}
+
+// wildcards returns the names of the wildcards of path, in order.
+func wildcards(path string) []string {
+	var names []string
+	for _, segment := range strings.Split(path, "/") {
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			names = append(names, strings.TrimSuffix(name, "}"))
+		}
+	}
+	return names
+}
+
+// list returns the words joined with commas and a final "or", e.g. "a, b or
+// c".
+func list(words []string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " or " + words[len(words)-1]
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// schema returns the schema of values of type t. Structs are added to the
+// components and referenced.
+func (doc *OpenAPI) schema(t reflect.Type) *Schema {
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == reflect.TypeFor[any]():
+		return &Schema{}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: doc.schema(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: doc.schema(t.Elem()), Nullable: true}
+	case reflect.Pointer:
+		s := *doc.schema(t.Elem())
+		s.Nullable = true
+		return &s
+	case reflect.Struct:
+		name := schemaName(t)
+		ref := &Schema{Ref: "#/components/schemas/" + name}
+		if _, ok := doc.Components.Schemas[name]; ok {
+			return ref
+		}
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		doc.Components.Schemas[name] = s // added first, for recursive types
+		doc.addFields(name, s, t)
+		return ref
+	}
+	panic(fmt.Sprintf("no schema for %v", t))
+}
+
+// addFields adds the JSON fields of struct type t to s, the schema name.
+func (doc *OpenAPI) addFields(name string, s *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || (!f.IsExported() && !f.Anonymous) || f.Type == reflect.TypeFor[weaver.AutoMarshal]() {
+			continue
+		}
+		field, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && field == "" && f.Type.Kind() == reflect.Struct {
+			doc.addFields(name, s, f.Type) // promoted fields
+			continue
+		}
+		if field == "" {
+			field = f.Name
+		}
+		fs := doc.schema(f.Type)
+		if note, ok := fieldNotes[name+"."+field]; ok {
+			if fs.Ref != "" {
+				// Siblings of $ref are ignored, so wrap it.
+				fs = &Schema{AllOf: []*Schema{fs}}
+			}
+			fs.Description, fs.Deprecated = note.Description, note.Deprecated
+		}
+		s.Properties[field] = fs
+		if !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, field)
+		}
+	}
+}
+
+// schemaName returns the name of the schema of struct type t.
+func schemaName(t reflect.Type) string {
+	if name, ok := schemaNames[t]; ok {
+		return name
+	}
+	return t.Name()
+}
+
+// openAPIHandler serves the OpenAPI document.
+func (s *Server) openAPIHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.openAPI)
+}
+
+// marshalOpenAPI returns the indented JSON of doc.
+func marshalOpenAPI(doc *OpenAPI) ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,216 @@
+package productpage
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestOpenAPIGolden(t *testing.T) {
+	got, err := marshalOpenAPI(newOpenAPI())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "openapi.json", got)
+}
+
+// TestOpenAPIRoutes checks that the /api routes registered on the router are
+// the documented ones.
+func TestOpenAPIRoutes(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		var registered, documented []string
+		for _, route := range s.routeTable.routes {
+			if _, path, _ := strings.Cut(route, " "); strings.HasPrefix(path, "/api/") {
+				registered = append(registered, route)
+			}
+		}
+		for _, op := range apiOperations {
+			documented = append(documented, op.Pattern)
+		}
+		sort.Strings(registered)
+		sort.Strings(documented)
+		if !slices.Equal(registered, documented) {
+			t.Errorf("registered API routes %q, documented %q", registered, documented)
+		}
+	})
+}
+
+// TestOpenAPIResponses checks that the responses of the handlers match the
+// schemas of the OpenAPI document.
+func TestOpenAPIResponses(t *testing.T) {
+	doc := newOpenAPI()
+	runner := weavertest.Local
+	runner.Config = `
+["github.com/ServiceWeaver/weaver/Main"]
+admin_token = "secret"
+`
+	runner.Test(t, func(t *testing.T, s *Server) {
+		served := httptest.NewRecorder()
+		s.handler.ServeHTTP(served, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+		if !bytes.Equal(served.Body.Bytes(), s.openAPI) {
+			t.Errorf("GET /api/v1/openapi.json doesn't serve the document")
+		}
+
+		// The operations run in order, so product 7 is deleted last, and
+		// the example cart is checked out before its order is asked for.
+		for _, apiOp := range apiOperations {
+			method, path, _ := strings.Cut(apiOp.Pattern, " ")
+			op := doc.Paths[path][strings.ToLower(method)]
+			status := apiOp.Status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			// Product 7 is only used here, as ratings are posted to it.
+			for _, test := range []struct {
+				id     string
+				status int
+			}{{"7", status}, {"one", http.StatusBadRequest}} {
+				if test.status == http.StatusBadRequest && !strings.Contains(path, "{id}") {
+					continue
+				}
+				target := strings.ReplaceAll(path, "{id}", test.id)
+				for name, p := range pathParameters {
+					if p.Example != nil {
+						target = strings.ReplaceAll(target, "{"+name+"}", fmt.Sprint(p.Example))
+					}
+				}
+				query := url.Values{}
+				for _, p := range apiOp.Query {
+					if p.Example != nil {
+						query.Set(p.Name, fmt.Sprint(p.Example))
+					}
+				}
+				if len(query) > 0 {
+					target += "?" + query.Encode()
+				}
+				var body io.Reader
+				if op.RequestBody != nil {
+					body = strings.NewReader(apiOp.Example)
+				}
+				req := httptest.NewRequest(method, target, body)
+				if apiOp.Admin {
+					req.Header.Set("Authorization", "Bearer secret")
+				}
+				for _, p := range apiOp.Header {
+					req.Header.Set(p.Name, fmt.Sprint(p.Example))
+				}
+				rec := httptest.NewRecorder()
+				s.handler.ServeHTTP(rec, req)
+				if rec.Code != test.status {
+					t.Errorf("%s %s: status %d, want %d", method, target, rec.Code, test.status)
+					continue
+				}
+				resp, ok := op.Responses[fmt.Sprint(test.status)]
+				if !ok {
+					t.Errorf("%s %s: status %d isn't documented", method, path, test.status)
+					continue
+				}
+				if len(resp.Content) == 0 {
+					if rec.Body.Len() != 0 {
+						t.Errorf("%s %s: body %q, want none", method, target, rec.Body)
+					}
+					continue
+				}
+				contentType := rec.Header().Get("Content-Type")
+				media, ok := resp.Content[contentType]
+				if !ok {
+					t.Errorf("%s %s: content type %q isn't documented", method, target, contentType)
+					continue
+				}
+				value := decode[any](t, rec.Body.String())
+				for _, err := range doc.validate(media.Schema, value, "body") {
+					t.Errorf("%s %s: %v", method, target, err)
+				}
+			}
+		}
+	})
+}
+
+// validate checks value, decoded from JSON, against schema and returns the
+// mismatches. Objects may only have the documented properties.
+func (doc *OpenAPI) validate(schema *Schema, value any, at string) []error {
+	if schema.Ref != "" {
+		return doc.validate(doc.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")], value, at)
+	}
+	if len(schema.AllOf) > 0 {
+		var errs []error
+		for _, s := range schema.AllOf {
+			errs = append(errs, doc.validate(s, value, at)...)
+		}
+		return errs
+	}
+	if value == nil {
+		if schema.Nullable || schema.Type == "" {
+			return nil
+		}
+		return []error{fmt.Errorf("%s is null, want %s", at, schema.Type)}
+	}
+	mismatch := func() []error {
+		return []error{fmt.Errorf("%s is %T, want %s", at, value, schema.Type)}
+	}
+	switch schema.Type {
+	case "":
+		return nil
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return mismatch()
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return mismatch()
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return mismatch()
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return mismatch()
+		}
+	case "array":
+		values, ok := value.([]any)
+		if !ok {
+			return mismatch()
+		}
+		var errs []error
+		for i, v := range values {
+			errs = append(errs, doc.validate(schema.Items, v, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return errs
+	case "object":
+		fields, ok := value.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		var errs []error
+		for _, name := range schema.Required {
+			if _, ok := fields[name]; !ok {
+				errs = append(errs, fmt.Errorf("%s.%s is missing", at, name))
+			}
+		}
+		for name, v := range fields {
+			s, ok := schema.Properties[name]
+			if !ok {
+				s = schema.AdditionalProperties
+			}
+			if s == nil {
+				errs = append(errs, fmt.Errorf("%s.%s isn't documented", at, name))
+				continue
+			}
+			errs = append(errs, doc.validate(s, v, at+"."+name)...)
+		}
+		return errs
+	default:
+		return []error{fmt.Errorf("%s: unknown type %q", at, schema.Type)}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package productpage
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+)
+
+// idempotencyKeyHeader carries the idempotency key of a checkout.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// OrderView is the data of the order.html template.
+type OrderView struct {
+	Nav   NavView
+	Order orders.Order
+}
+
+// checkoutPageHandler checks out the browser's cart with the idempotency_key
+// of the posted form, and redirects to the page of the order. The cart page
+// puts a new key in every form it serves, so that submitting a form twice
+// places a single order.
+func (s *Server) checkoutPageHandler(w http.ResponseWriter, r *http.Request) {
+	key := r.PostFormValue("idempotency_key")
+	if key == "" {
+		writeProblem(w, r, http.StatusBadRequest, "missing idempotency_key")
+		return
+	}
+	id := requestCart(r)
+	if id == "" {
+		s.renderCart(w, r, id, orders.EmptyCartError{})
+		return
+	}
+	order, err := s.orders.Get().Checkout(r.Context(), id, key)
+	// A failed order has a page of its own, which tells why.
+	if err != nil && !errors.As(err, new(orders.FailedError)) {
+		s.renderCart(w, r, id, err)
+		return
+	}
+	http.Redirect(w, r, "/orders/"+order.ID, http.StatusSeeOther)
+}
+
+// orderPageHandler serves the page of an order.
+func (s *Server) orderPageHandler(w http.ResponseWriter, r *http.Request) {
+	order, err := s.orders.Get().Get(r.Context(), r.PathValue("order"))
+	if err != nil {
+		s.shopProblem(w, r, "orders", err)
+		return
+	}
+	s.render(w, r, "order.html", OrderView{Nav: NavView{User: requestUser(r)}, Order: order})
+}
+
+// checkoutHandler orders the items of a cart, with the idempotency key in the
+// Idempotency-Key header. Retries with the same key serve the same order, or
+// the same problem if it failed.
+func (s *Server) checkoutHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathCartID(w, r)
+	if !ok {
+		return
+	}
+	key := r.Header.Get(idempotencyKeyHeader)
+	if key == "" {
+		writeProblem(w, r, http.StatusBadRequest, "missing "+idempotencyKeyHeader+" header")
+		return
+	}
+	order, err := s.orders.Get().Checkout(r.Context(), id, key)
+	if order.ID != "" {
+		w.Header().Set("Location", "/api/v1/orders/"+order.ID)
+	}
+	if err != nil {
+		s.shopProblem(w, r, "orders", err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newOrder(order))
+}
+
+// orderHandler serves an order.
+func (s *Server) orderHandler(w http.ResponseWriter, r *http.Request) {
+	order, err := s.orders.Get().Get(r.Context(), r.PathValue("order"))
+	if err != nil {
+		s.shopProblem(w, r, "orders", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newOrder(order))
+}
+
+// newOrder returns order as served by the API, with its items as a list even
+// if it has none.
+func newOrder(order orders.Order) orders.Order {
+	if order.Items == nil {
+		order.Items = []cart.Item{}
+	}
+	return order
+}
@@ -0,0 +1,111 @@
+package productpage
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"golang.org/x/text/currency"
+)
+
+const (
+	// currencyCookie holds the currency a browser chose to see prices in.
+	currencyCookie = "currency"
+	// currencyCookieAge is how long a browser keeps its choice.
+	currencyCookieAge = 365 * 24 * time.Hour
+)
+
+// parseCurrency returns the ISO 4217 code of a currency, in upper case.
+func parseCurrency(code string) (string, error) {
+	unit, err := currency.ParseISO(code)
+	if err != nil {
+		return "", fmt.Errorf("invalid currency %q", code)
+	}
+	return unit.String(), nil
+}
+
+// requestCurrencies returns the currencies r prefers prices in, most
+// preferred first: the one in its currency cookie, then the ones of the
+// languages of its Accept-Language header.
+func requestCurrencies(r *http.Request) []string {
+	var codes []string
+	if c, err := r.Cookie(currencyCookie); err == nil {
+		if code, err := parseCurrency(c.Value); err == nil {
+			codes = append(codes, code)
+		}
+	}
+	for _, code := range pricing.LanguageCurrencies(r.Header.Get("Accept-Language")) {
+		if !slices.Contains(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	return codes
+}
+
+// currencyHandler sets the currency cookie to the currency of the posted
+// form, and redirects back to the page the form was posted from.
+func (s *Server) currencyHandler(w http.ResponseWriter, r *http.Request) {
+	code, err := parseCurrency(r.PostFormValue("currency"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     currencyCookie,
+		Value:    code,
+		Path:     "/",
+		MaxAge:   int(currencyCookieAge.Seconds()),
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, referrerPath(r), http.StatusSeeOther)
+}
+
+// referrerPath returns the path and query of the Referer of r, if it's a page
+// of this server, and "/" otherwise. The path must start with a single "/":
+// browsers take "//host" and "/\host" to be another host.
+func referrerPath(r *http.Request) string {
+	u, err := url.Parse(r.Referer())
+	if err != nil || u.Host != r.Host || !strings.HasPrefix(u.Path, "/") ||
+		strings.HasPrefix(u.Path[1:], "/") || strings.HasPrefix(u.Path[1:], `\`) {
+		return "/"
+	}
+	return u.RequestURI()
+}
+
+// productPriceHandler serves the price of a product, in the currency query
+// parameter, or else in the currency the request prefers, see
+// requestCurrencies.
+func (s *Server) productPriceHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	currencies := requestCurrencies(r)
+	asked := r.URL.Query().Get("currency")
+	if asked != "" {
+		code, err := parseCurrency(asked)
+		if err != nil {
+			writeProblem(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		currencies = []string{code}
+	}
+	// The price depends on the preferences unless a currency is asked for.
+	w.Header().Set("Vary", "Accept-Language, Cookie")
+
+	price, err := s.pricing.Get().Price(r.Context(), id, currencies)
+	if err != nil {
+		s.componentProblem(w, r, "pricing", id, err)
+		return
+	}
+	if asked != "" && price.Currency != currencies[0] {
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("prices aren't quoted in %s", currencies[0]))
+		return
+	}
+	writeJSON(w, http.StatusOK, price)
+}
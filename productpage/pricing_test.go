@@ -0,0 +1,111 @@
+package productpage
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/fakes"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+)
+
+// servePrice serves a request for target with the given Accept-Language and
+// currency cookie, if any, and returns the recorded response.
+func servePrice(s *Server, target, language, cookie string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	if language != "" {
+		req.Header.Set("Accept-Language", language)
+	}
+	if cookie != "" {
+		req.AddCookie(&http.Cookie{Name: currencyCookie, Value: cookie})
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPriceCurrency(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		for _, test := range []struct {
+			target, language, cookie string
+			want                     string
+		}{
+			{"/api/v1/products/3/price", "", "", "USD"},
+			{"/api/v1/products/3/price", "ja, en;q=0.5", "", "JPY"},
+			{"/api/v1/products/3/price", "fr-CH, en-GB;q=0.8", "", "GBP"}, // no rate for CHF
+			{"/api/v1/products/3/price", "ja", "gbp", "GBP"},              // the cookie wins
+			{"/api/v1/products/3/price", "ja", "nonsense", "JPY"},
+			{"/api/v1/products/3/price?currency=INR", "ja", "GBP", "INR"}, // and the query over it
+		} {
+			rec := servePrice(s, test.target, test.language, test.cookie)
+			got := decode[pricing.Price](t, rec.Body.String())
+			if rec.Code != http.StatusOK || got.Currency != test.want {
+				t.Errorf("GET %s with Accept-Language %q and cookie %q: status %d, price %+v, want it in %s", test.target, test.language, test.cookie, rec.Code, got, test.want)
+			}
+			if vary := rec.Header().Get("Vary"); vary != "Accept-Language, Cookie" {
+				t.Errorf("GET %s: Vary %q, want Accept-Language, Cookie", test.target, vary)
+			}
+		}
+
+		// The product page shows the price in the chosen currency.
+		rec := servePrice(s, "/productpage?id=3", "", "EUR")
+		if body := rec.Body.String(); !strings.Contains(body, "14.71 EUR") || !strings.Contains(body, "18.39 EUR") || !strings.Contains(body, "<option selected>EUR</option>") {
+			t.Errorf("the product page doesn't show the price of 3 in EUR")
+		}
+	})
+}
+
+func TestCurrencyForm(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		for _, test := range []struct {
+			referer, want string
+		}{
+			{"http://example.com/productpage?id=3", "/productpage?id=3"},
+			{"http://elsewhere.com/productpage?id=3", "/"},
+			{"", "/"},
+			{"//elsewhere.com/productpage", "/"},
+			{"http://example.com//elsewhere.com/productpage", "/"},
+			{`http://example.com/\elsewhere.com/productpage`, "/"},
+			{"http://example.com/%2F/elsewhere.com", "/"},
+			{"http://example.com", "/"},
+		} {
+			req := httptest.NewRequest(http.MethodPost, "http://example.com/currency", strings.NewReader(url.Values{"currency": {"jpy"}}.Encode()))
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			req.Header.Set("Referer", test.referer)
+			rec := httptest.NewRecorder()
+			s.handler.ServeHTTP(rec, req)
+			if rec.Code != http.StatusSeeOther || rec.Header().Get("Location") != test.want {
+				t.Errorf("POST /currency from %q: status %d, Location %q, want %d to %s", test.referer, rec.Code, rec.Header().Get("Location"), http.StatusSeeOther, test.want)
+			}
+			if cookies := rec.Result().Cookies(); len(cookies) != 1 || cookies[0].Name != currencyCookie || cookies[0].Value != "JPY" {
+				t.Errorf("POST /currency: cookies %v, want %s=JPY", cookies, currencyCookie)
+			}
+		}
+	})
+}
+
+func TestPricingDown(t *testing.T) {
+	fakePricing := fakes.NewPricing()
+	fakePricing.FailWith(fakes.AnyMethod, errors.New("pricing is down"))
+	runner := weavertest.Local
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[pricing.Pricing](fakePricing))
+	runner.Test(t, func(t *testing.T, s *Server) {
+		if code, body := serveGet(s, "/api/v1/products/3/price"); code != http.StatusBadGateway || !strings.Contains(body, "pricing is down") {
+			t.Errorf("GET price with pricing down: status %d, body %s, want %d", code, body, http.StatusBadGateway)
+		}
+		// The product details and page are served without the price.
+		if code, body := serveGet(s, "/api/v1/products/3"); code != http.StatusOK || decode[ProductDetails](t, body).Price != nil {
+			t.Errorf("GET /api/v1/products/3 with pricing down: status %d, body %s, want %d with a null price", code, body, http.StatusOK)
+		}
+		if code, body := serveGet(s, "/productpage?id=3"); code != http.StatusOK || strings.Contains(body, `action="/currency"`) {
+			t.Errorf("GET /productpage with pricing down: status %d, want %d without the price", code, http.StatusOK)
+		}
+		if code, body := serveGet(s, "/readyz"); code != http.StatusServiceUnavailable || !strings.Contains(body, "pricing is down") {
+			t.Errorf("GET /readyz with pricing down: status %d, body %s, want %d", code, body, http.StatusServiceUnavailable)
+		}
+	})
+}
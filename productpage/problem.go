@@ -0,0 +1,42 @@
+package productpage
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+)
+
+// problemContentType is the media type of problem details (RFC 7807).
+const problemContentType = "application/problem+json"
+
+// Problem is the body of every error response, in the problem details format
+// of RFC 7807. Problems don't have a type of their own yet, so Type is always
+// "about:blank" and Title the status text.
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`   // path of the request
+	RequestID string `json:"request_id,omitempty"` // for matching the logs
+}
+
+// writeProblem writes a problem details response with the given status and
+// detail for the request r.
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	problem := Problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: logging.RequestID(r.Context()),
+	}
+	w.Header().Set("Content-Type", problemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.Encode(problem)
+}
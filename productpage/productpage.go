@@ -1,38 +1,65 @@
 package productpage
 
 import (
-	"bytes"
 	"context"
 	"embed"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
+	"log/slog"
 	"net/http"
 	"strconv"
-	"strings"
-	"log"
 
 	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
 	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
 	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
 	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
 )
 
 //go:embed static/* templates/*
 var embeddedFiles embed.FS
 
-//go:embed data/products.json
-var productsJSON []byte
-
 type Server struct {
 	weaver.Implements[weaver.Main]
+	weaver.WithConfig[config]
 	handler     http.Handler
 	productpage weaver.Listener
-	details     weaver.Ref[details.Details]
-	reviews     weaver.Ref[reviews.Reviews]
-	ratings     weaver.Ref[ratings.Ratings]
-	templates   *template.Template
+
+	// Listeners of the original Bookinfo APIs, see compatHandlers.
+	compatDetails weaver.Listener `weaver:"details"`
+	compatReviews weaver.Listener `weaver:"reviews"`
+	compatRatings weaver.Listener `weaver:"ratings"`
+
+	catalog         weaver.Ref[catalog.Catalog]
+	details         weaver.Ref[details.Details]
+	reviews         weaver.Ref[reviews.Reviews]
+	ratings         weaver.Ref[ratings.Ratings]
+	search          weaver.Ref[search.Search]
+	recommendations weaver.Ref[recommendations.Recommendations]
+	cart            weaver.Ref[cart.Cart]
+	inventory       weaver.Ref[inventory.Inventory]
+	orders          weaver.Ref[orders.Orders]
+	pricing         weaver.Ref[pricing.Pricing]
+	bookDetails     details.Details // details, or the remote service replacing it
+	bookReviews     reviews.Reviews // reviews, or the remote service replacing it
+	bookRatings     ratings.Ratings // ratings, or the remote service replacing it
+	templates       *template.Template
+	callSamples     callSamples // the call counts of the last topology, for rates
+	routeTable      *routeTable // the registered routes
+	openAPI         []byte      // the OpenAPI document of the API
+	name            string      // full component name, used as a metric label
+	level           slog.Level  // minimum level logged
 }
 
 // Product represents a product.
@@ -42,14 +69,39 @@ type Product struct {
 	DescriptionHtml template.HTML `json:"description_html"`
 }
 
-// Service represents a service with children.
-type Service struct {
-	Name     string    `json:"name"`
-	Children []Service `json:"children"`
+// ProductDetails is the book details of a product, with its price.
+type ProductDetails struct {
+	details.BookDetails
+	Price *pricing.Price `json:"price"` // nil if the product has no price or pricing failed
 }
 
-// Serve initializes the product page service.
-func Serve(ctx context.Context, s *Server) error {
+// newProduct returns the product page's view of a catalog product. The
+// description is trusted as HTML, since only admins can set it.
+func newProduct(p catalog.Product) Product {
+	return Product{ID: p.ID, Title: p.Title, DescriptionHtml: template.HTML(p.DescriptionHTML)}
+}
+
+// Init loads the templates and sets up the routes. It runs
+// before Serve, and before the server is handed to tests.
+func (s *Server) Init(context.Context) error {
+	level, err := s.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	s.level = level
+
+	// Pick the components or the remote services that replace them.
+	remotes := s.Config().Remote
+	if s.bookDetails, err = remote.Or(remotes.Details, s.details.Get(), details.NewRemote); err != nil {
+		return err
+	}
+	if s.bookReviews, err = remote.Or(remotes.Reviews, s.reviews.Get(), reviews.NewRemote); err != nil {
+		return err
+	}
+	if s.bookRatings, err = remote.Or(remotes.Ratings, s.ratings.Get(), ratings.NewRemote); err != nil {
+		return err
+	}
+
 	// Set up static file serving.
 	staticHTML, err := fs.Sub(embeddedFiles, "static")
 	if err != nil {
@@ -62,102 +114,105 @@ func Serve(ctx context.Context, s *Server) error {
 		return fmt.Errorf("failed to load templates: %w", err)
 	}
 
-	// Set up routing
-	r := http.NewServeMux()
-
-	r.Handle("/", weaver.InstrumentHandler("index", http.HandlerFunc(s.indexHandler)))
-	r.Handle("/health", weaver.InstrumentHandler("health", http.HandlerFunc(s.healthHandler)))
-	r.Handle("/productpage", weaver.InstrumentHandler("productpage-reviews-details", http.HandlerFunc(s.productPageHandler)))
-	r.Handle("/api/v1/products", weaver.InstrumentHandler("products", http.HandlerFunc(s.productsHandler)))
-	r.Handle("/api/v1/products/{id}", weaver.InstrumentHandler("product", http.HandlerFunc(s.productHandler)))
-	r.Handle("/api/v1/products/{id}/reviews", weaver.InstrumentHandler("product-reviews", http.HandlerFunc(s.productReviewsHandler)))
-	r.Handle("/api/v1/products/{id}/ratings", weaver.InstrumentHandler("product-ratings", http.HandlerFunc(s.productRatingsHandler)))
-
-	// Static content não precisa de tracing, pode manter normal:
-	r.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.FS(staticHTML))))
+	// Document the API.
+	s.openAPI, err = marshalOpenAPI(newOpenAPI())
+	if err != nil {
+		return fmt.Errorf("failed to generate the OpenAPI document: %w", err)
+	}
 
-	// Set handler and log initialization.
-	s.handler = r
-	s.Logger(ctx).Debug("ProductPage service is up", "address", s.productpage)
+	s.routeTable = s.routes(staticHTML)
+	s.handler = s.routeTable.handler(s.logged)
+	s.name = topology.Name[weaver.Main]()
+	return nil
+}
 
+// Serve serves the product page on its listener.
+func Serve(ctx context.Context, s *Server) error {
+	s.logger(ctx).Info("ProductPage service is up", "address", s.productpage)
 
-	// Serve requests on the Service Weaver listener.
-	return http.Serve(s.productpage, s.handler)
+	errs := make(chan error, 2)
+	go func() { errs <- s.serveCompat(ctx) }()
+	go func() { errs <- http.Serve(s.productpage, s.handler) }()
+	// serveCompat returns nil right away if it's disabled.
+	if err := <-errs; err != nil {
+		return err
+	}
+	return <-errs
 }
 
-// indexHandler serves the index page with the service table.
+// indexHandler serves the index page with the live service topology.
 func (s *Server) indexHandler(w http.ResponseWriter, r *http.Request) {
-	// Create the services structure for rendering.
-	services := Service{
-		Name: "ProductPage",
-		Children: []Service{
-			{Name: "Details", Children: nil},
-			{Name: "Reviews", Children: []Service{
-				{Name: "Ratings", Children: nil},
-			}},
-		},
-	}
-
-	// Convert the services structure to an HTML table.
-	table := jsonToHTMLTable(services)
-
-	// Set the content type to HTML.
-	w.Header().Set("Content-Type", "text/html")
-
-	// Execute the template, passing in the service table.
-	if err := s.templates.ExecuteTemplate(w, "index.html", map[string]interface{}{
-		"serviceTable": template.HTML(table),
-	}); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-	}
+	// Probe the components for the topology of the running deployment.
+	s.render(w, r, "index.html", IndexView{Topology: s.buildTopology(r.Context())})
 }
 
+// productPageHandler serves the page of the product in the id query
+// parameter, or the example page, which shows product 0 with the details and
+// reviews of product 1, as the original Bookinfo does.
 func (s *Server) productPageHandler(w http.ResponseWriter, r *http.Request) {
 	productID := 1 // ID de produto padrão
+	catalogID := 0
 	ctx := r.Context()
+	if id := r.URL.Query().Get("id"); id != "" {
+		var err error
+		if productID, err = parseProductID(id); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+		catalogID = productID
+	}
 
-	// Obtendo os detalhes do livro
-	bookDetails, err := s.details.Get().GetBookDetails(ctx, productID, nil)
+	// Obtenção do produto
+	product, err := s.catalog.Get().Get(ctx, catalogID)
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get book details: %v", err), http.StatusInternalServerError)
+		s.componentProblem(w, r, "catalog", catalogID, err)
 		return
 	}
+	view := ProductPageView{Nav: NavView{User: requestUser(r)}, Product: newProduct(product)}
 
-	fmt.Println(bookDetails)
+	// Obtendo os detalhes do livro
+	bookDetails, err := s.bookDetails.GetBookDetails(ctx, productID, nil)
+	if err != nil {
+		s.logger(ctx).Error("Failed to get book details", "product_id", productID, "err", err)
+		view.Details.Error = detailsUnavailable
+	} else {
+		s.logger(ctx).Debug("Got book details", "product_id", productID, "details", bookDetails)
+		view.Details.Book = bookDetails
+	}
 
 	// Obtendo as avaliações do livro
-	reviewsResponse, err := s.reviews.Get().BookReviewsByID(ctx, fmt.Sprintf("%d", productID))
+	reviewsResponse, err := s.bookReviews.BookReviewsByID(ctx, fmt.Sprintf("%d", productID))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get book reviews: %v", err), http.StatusInternalServerError)
-		return
+		s.logger(ctx).Error("Failed to get book reviews", "product_id", productID, "err", err)
+		view.Reviews.Error = reviewsUnavailable
+	} else {
+		view.Reviews = newReviewsView(reviewsResponse)
 	}
 
-	// Processa as avaliações e cria StarsSlice e EmptyStarsSlice
-	processedReviews := processReviewsWithStarsSlice(reviewsResponse.Reviews)
-
-	// Obtenção de produto exemplo
-	products := getProducts()
-	product := products[0]
-
-	// Preparando os dados para passar ao template
-	data := map[string]interface{}{
-		"detailsStatus": http.StatusOK,
-		"reviewsStatus": http.StatusOK,
-		"product":       product,
-		"details":       bookDetails,
-		"reviews":       processedReviews, // Reviews processados
-		"user":          "",               // Usuário não implementado
-		"rating": map[string]interface{}{
-			"stars": makeSeq(4), // Exemplo de valor de rating
-			"color": "yellow",
-		},
+	// Obtendo as recomendações; sem elas, a seção não é mostrada
+	recs, err := s.recommendations.Get().Recommend(ctx, catalogID, defaultRecommendations)
+	if err != nil {
+		s.logger(ctx).Error("Failed to get recommendations", "product_id", catalogID, "err", err)
+	}
+	view.Recommendations = recs
+
+	// Obtendo o preço, na moeda preferida; sem ele, o preço não é mostrado
+	price, err := s.pricing.Get().Price(ctx, catalogID, requestCurrencies(r))
+	switch {
+	case errors.As(err, new(pricing.NotFoundError)):
+		// Produtos novos do catálogo ainda não têm preço.
+	case err != nil:
+		s.logger(ctx).Error("Failed to get the price", "product_id", catalogID, "err", err)
+	default:
+		view.Price = &price
+		if view.Currencies, err = s.pricing.Get().Currencies(ctx); err != nil {
+			s.logger(ctx).Error("Failed to get the currencies", "err", err)
+		}
 	}
-
-	w.Header().Set("Content-Type", "text/html")
 
 	// Renderizando o template productpage.html com os dados
-	if err := s.templates.ExecuteTemplate(w, "productpage.html", data); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if s.render(w, r, "productpage.html", view) {
+		pageRenders.Get(renderLabels{Component: s.name, Product: productID}).Inc()
 	}
 }
 
@@ -170,199 +225,137 @@ func makeSeq(n int) []int {
 	return seq
 }
 
-// jsonToHTMLTable converts a Service structure to an HTML table.
-func jsonToHTMLTable(service Service) string {
-	html := "<table class='table table-condensed table-bordered table-hover'>"
-	html += "<thead><tr><th>Name</th><th>Endpoint</th><th>Children</th></tr></thead>"
-	html += "<tbody>"
-	html += buildHTMLTableRow(service)
-	html += "</tbody></table>"
-	return html
-}
-
-// buildHTMLTableRow builds HTML rows for a service and its children.
-func buildHTMLTableRow(service Service) string {
-	row := "<tr>"
-	row += fmt.Sprintf("<td>%s</td>", service.Name)
-	row += "<td>Interno</td>" // Use "Interno" instead of the endpoint
-
-	if len(service.Children) > 0 {
-		row += "<td><table>"
-		for _, child := range service.Children {
-			row += buildHTMLTableRow(child)
-		}
-		row += "</table></td>"
+// productsHandler serves the products, or the ones matching the q query
+// parameter.
+func (s *Server) productsHandler(w http.ResponseWriter, r *http.Request) {
+	var products []catalog.Product
+	var err error
+	if q := r.URL.Query().Get("q"); q != "" {
+		products, err = s.catalog.Get().Search(r.Context(), q)
 	} else {
-		row += "<td>None</td>"
+		products, err = s.catalog.Get().List(r.Context())
 	}
-	row += "</tr>"
-	return row
-}
-
-func getProducts() []Product {
-	var products []Product
-	if err := json.Unmarshal(productsJSON, &products); err != nil {
-		log.Fatalf("Failed to parse embedded products: %v", err)
-	}
-	return products
-}
-
-func processReviewsWithStarsSlice(reviews []reviews.Review) []map[string]interface{} {
-	var processedReviews []map[string]interface{}
-
-	for _, review := range reviews {
-		stars := review.Rating.Stars
-
-		// Criando slices de estrelas cheias e vazias
-		starsSlice := make([]int, stars)
-		emptyStarsSlice := make([]int, 5-stars) // Assume-se que a escala é de 5 estrelas
-
-		// Cria um mapa para passar ao template
-		reviewMap := map[string]interface{}{
-			"Reviewer":        review.Reviewer,
-			"Text":            review.Text,
-			"Rating":          review.Rating,
-			"StarsSlice":      starsSlice,
-			"EmptyStarsSlice": emptyStarsSlice,
-			"Color":           review.Rating.Color,
-		}
-
-		processedReviews = append(processedReviews, reviewMap)
-	}
-
-	return processedReviews
-}
-
-func (s *Server) productsHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-
-	products := getProducts()
-
-	var buf bytes.Buffer
-	enc := json.NewEncoder(&buf)
-	enc.SetEscapeHTML(false)
-
-	err := enc.Encode(products)
 	if err != nil {
-		http.Error(w, "Error encoding JSON", http.StatusInternalServerError)
+		s.logger(r.Context()).Error("Component call failed", "component", "catalog", "err", err)
+		writeProblem(w, r, http.StatusBadGateway, fmt.Sprintf("failed to list the products: %v", err))
 		return
 	}
-
-	w.Write(buf.Bytes())
+	view := make([]Product, len(products))
+	for i, p := range products {
+		view[i] = newProduct(p)
+	}
+	writeJSON(w, http.StatusOK, view)
 }
 
+// productHandler serves the details of a product, with its price in the
+// currency the request prefers, see requestCurrencies. The details are served
+// without the price if the pricing component fails.
 func (s *Server) productHandler(w http.ResponseWriter, r *http.Request) {
-	// Extrai o `id` do produto da URL usando `r.URL.Path`
-	pathParts := strings.Split(r.URL.Path, "/")
-
-	// Verifica se a URL tem pelo menos 5 partes para corresponder ao formato `/api/v1/products/{id}`
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid product URL", http.StatusBadRequest)
-		return
-	}
-
-	// O `productID` está na quinta parte da URL (índice 4)
-	productID := pathParts[4]
-
-	// Converte o productID para um número inteiro
-	id, err := strconv.Atoi(productID)
-	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+	id, ok := pathProductID(w, r)
+	if !ok {
 		return
 	}
+	ctx := r.Context()
 
 	// Chamada direta ao método `GetBookDetails` do componente `details`
-	details, err := s.details.Get().GetBookDetails(r.Context(), id, nil)
+	details, err := s.bookDetails.GetBookDetails(ctx, id, nil)
 	if err != nil {
-		http.Error(w, "Failed to fetch product details", http.StatusInternalServerError)
+		s.componentProblem(w, r, "details", id, err)
 		return
 	}
-
-	// Serializa `details` para JSON
-	response, err := json.Marshal(details)
-	if err != nil {
-		http.Error(w, "Failed to marshal product details", http.StatusInternalServerError)
-		return
+	view := ProductDetails{BookDetails: details}
+
+	// Obtendo o preço, na moeda preferida; sem ele, price é null
+	w.Header().Set("Vary", "Accept-Language, Cookie")
+	price, err := s.pricing.Get().Price(ctx, id, requestCurrencies(r))
+	switch {
+	case errors.As(err, new(pricing.NotFoundError)):
+		// Produtos novos do catálogo ainda não têm preço.
+	case err != nil:
+		s.logger(ctx).Error("Failed to get the price", "product_id", id, "err", err)
+	default:
+		view.Price = &price
 	}
-
-	// Envia a resposta JSON ao cliente
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(response)
+	writeJSON(w, http.StatusOK, view)
 }
 
+// productReviewsHandler serves the reviews of a product.
 func (s *Server) productReviewsHandler(w http.ResponseWriter, r *http.Request) {
-	// Extrai o `id` do produto da URL usando `r.URL.Path`
-	pathParts := strings.Split(r.URL.Path, "/")
-
-	// Verifica se a URL tem pelo menos 5 partes para corresponder ao formato `/api/v1/products/{id}/reviews`
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid product URL", http.StatusBadRequest)
+	id, ok := pathProductID(w, r)
+	if !ok {
 		return
 	}
 
-	// O `productID` está na quinta parte da URL (índice 4)
-	productID := pathParts[4]
-
 	// Chamada direta ao método `BookReviewsByID` do componente `reviews`
-	reviewsResponse, err := s.reviews.Get().BookReviewsByID(r.Context(), productID)
+	reviewsResponse, err := s.bookReviews.BookReviewsByID(r.Context(), strconv.Itoa(id))
 	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get book reviews: %v", err), http.StatusInternalServerError)
+		s.componentProblem(w, r, "reviews", id, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, reviewsResponse)
+}
 
-	// Serializa `reviewsResponse` para JSON
-	response, err := json.Marshal(reviewsResponse)
-	if err != nil {
-		http.Error(w, "Failed to marshal product reviews", http.StatusInternalServerError)
+// productRatingsHandler serves the ratings of a product.
+func (s *Server) productRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
 		return
 	}
 
-	// Envia a resposta JSON ao cliente
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(response)
+	// Chamada direta ao método `GetRatings` do componente `ratings`
+	ratingsResponse, err := s.bookRatings.GetRatings(r.Context(), id)
+	if err != nil {
+		s.componentProblem(w, r, "ratings", id, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, ratingsResponse)
 }
 
-func (s *Server) productRatingsHandler(w http.ResponseWriter, r *http.Request) {
-	// Extrai o `id` do produto da URL usando `r.URL.Path`
-	pathParts := strings.Split(r.URL.Path, "/")
+// maxRatingsBody bounds the size of posted ratings.
+const maxRatingsBody = 64 << 10
 
-	// Verifica se a URL tem pelo menos 5 partes para corresponder ao formato `/api/v1/products/{id}/ratings`
-	if len(pathParts) < 5 {
-		http.Error(w, "Invalid product URL", http.StatusBadRequest)
+// postRatingsHandler sets the ratings of a product to the posted JSON object
+// of reviewer names to stars, and serves the stored ratings.
+func (s *Server) postRatingsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
 		return
 	}
-
-	// O `productID` está na quinta parte da URL (índice 4)
-	productID := pathParts[4]
-
-	// Converte o `productID` para um número inteiro
-	id, err := strconv.Atoi(productID)
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRatingsBody))
 	if err != nil {
-		http.Error(w, "Invalid product ID", http.StatusBadRequest)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeProblem(w, r, http.StatusRequestEntityTooLarge, fmt.Sprintf("ratings are limited to %d bytes", maxRatingsBody))
+			return
+		}
+		writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("failed to read the ratings: %v", err))
 		return
 	}
-
-	// Chamada direta ao método `GetRatings` do componente `ratings`
-	ratingsResponse, err := s.ratings.Get().GetRatings(r.Context(), id)
-	if err != nil {
-		http.Error(w, fmt.Sprintf("Failed to get product ratings: %v", err), http.StatusInternalServerError)
+	// Invalid ratings are rejected here, so that only component failures
+	// result in a 502.
+	if _, _, err := ratings.ParseRatings(strconv.Itoa(id), body); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	// Serializa `ratingsResponse` para JSON
-	response, err := json.Marshal(ratingsResponse)
+	ratingsResponse, err := s.bookRatings.PostRatings(r.Context(), strconv.Itoa(id), body)
 	if err != nil {
-		http.Error(w, "Failed to marshal product ratings", http.StatusInternalServerError)
+		s.componentProblem(w, r, "ratings", id, err)
 		return
 	}
+	writeJSON(w, http.StatusOK, ratingsResponse)
+}
 
-	// Envia a resposta JSON ao cliente
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	w.Write(response)
+// componentProblem logs that the call to component for product id failed
+// with err, and writes a 404 problem if the product doesn't exist, or has no
+// price, and a 502 problem otherwise.
+func (s *Server) componentProblem(w http.ResponseWriter, r *http.Request, component string, id int, err error) {
+	if errors.As(err, new(catalog.NotFoundError)) || errors.As(err, new(pricing.NotFoundError)) {
+		writeProblem(w, r, http.StatusNotFound, err.Error())
+		return
+	}
+	s.logger(r.Context()).Error("Component call failed", "component", component, "product_id", id, "err", err)
+	writeProblem(w, r, http.StatusBadGateway, fmt.Sprintf("failed to get the %s of product %d: %v", component, id, err))
 }
 
 func (s *Server) healthHandler(w http.ResponseWriter, r *http.Request) {
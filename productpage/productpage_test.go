@@ -0,0 +1,626 @@
+package productpage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/quick"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/fakes"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+)
+
+// get sends a GET request for path to srv and returns the response and its
+// body.
+func get(t *testing.T, srv *httptest.Server, path string) (*http.Response, string) {
+	t.Helper()
+	resp, err := srv.Client().Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+// decode decodes the JSON body of a response into a value of type T.
+func decode[T any](t *testing.T, body string) T {
+	t.Helper()
+	var v T
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		t.Fatalf("invalid JSON %q: %v", body, err)
+	}
+	return v
+}
+
+func TestRoutes(t *testing.T) {
+	for _, test := range []struct {
+		path        string
+		status      int
+		contentType string
+		contains    []string                 // substrings of the body
+		check       func(*testing.T, string) // checks the body
+	}{
+		{
+			path:        "/",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"simple bookstore application", "catalog.Catalog", "details.Details", "reviews.Reviews", "ratings.Ratings"},
+		},
+		{
+			path:     "/health",
+			status:   http.StatusOK,
+			contains: []string{"Product page is healthy"},
+		},
+		{
+			path:        "/healthz",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			contains:    []string{`"status":"ok"`},
+		},
+		{
+			path:        "/readyz",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[Readiness](t, body)
+				if got.Status != "ready" || len(got.Components) != 10 {
+					t.Errorf("readiness = %+v, want ready with 10 components", got)
+				}
+			},
+		},
+		{
+			path:        "/productpage",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"The Comedy of Errors", "PublisherA", "1234567890", "An extremely entertaining play by Shakespeare"},
+		},
+		{
+			path:        "/productpage?id=8",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"The Tempest", `name="q"`},
+		},
+		{
+			path:        "/books",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"The Comedy of Errors", `href="/productpage?id=0"`, "William Shakespeare", "page 1 of 5", `href="/books?page=2"`},
+		},
+		{
+			path:        "/books?page=5",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"page 5 of 5", `href="/books?page=4"`},
+		},
+		{
+			// Which products are recommended depends on the ratings
+			// posted by the other tests; TestRecommendationsFollowRatings
+			// checks them.
+			path:        "/api/v1/products/3/recommendations?n=2",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[[]recommendations.Recommendation](t, body)
+				if len(got) > 2 || (len(got) > 0 && got[0].ProductID == 3) {
+					t.Errorf("recommendations = %+v, want at most 2 other products", got)
+				}
+			},
+		},
+		{
+			path:        "/api/v1/products/1000/recommendations",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			contains:    []string{"[]"},
+		},
+		{
+			path:        "/search?q=tempest",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{`value="tempest"`, "The <mark>Tempest</mark>", `href="/productpage?id=8"`},
+		},
+		{
+			path:        "/api/v1/search?q=tmpest&limit=1",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[search.Results](t, body)
+				if len(got.Hits) != 1 || got.Hits[0].ProductID != 8 || got.Hits[0].TitleHTML != "The <mark>Tempest</mark>" {
+					t.Errorf("results = %+v, want a hit for The Tempest", got)
+				}
+			},
+		},
+		{
+			path:        "/api/v1/topology",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[Topology](t, body)
+				if len(got.Components) != 11 {
+					t.Errorf("got %d components, want 11", len(got.Components))
+				}
+				for _, c := range got.Components {
+					if len(c.Replicas) == 0 || c.Error != "" {
+						t.Errorf("component %s: %d replicas, error %q", c.Name, len(c.Replicas), c.Error)
+					}
+				}
+			},
+		},
+		{
+			path:        "/api/v1/products",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[[]Product](t, body)
+				if len(got) == 0 || got[0].ID != 0 || got[0].Title != "The Comedy of Errors" {
+					t.Errorf("products = %+v, want The Comedy of Errors first", got)
+				}
+			},
+		},
+		{
+			path:        "/api/v1/products/1",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[ProductDetails](t, body)
+				if got.ID != 1 || got.Author != "William Shakespeare" || got.Price == nil || got.Price.Currency != "USD" {
+					t.Errorf("details = %+v, want the details of product 1 with its price in USD", got)
+				}
+			},
+		},
+		{
+			path:   "/api/v1/products/one",
+			status: http.StatusBadRequest,
+		},
+		{
+			path:   "/api/v1/products/-1",
+			status: http.StatusBadRequest,
+		},
+		{
+			path:   "/api/v1/products/one/reviews",
+			status: http.StatusBadRequest,
+		},
+		{
+			path:        "/api/v1/products/1/reviews",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[reviews.Response](t, body)
+				if got.ID != "1" || len(got.Reviews) != 10 || got.Reviews[0].Rating.Stars != 5 {
+					t.Errorf("reviews = %+v, want 10 rated reviews of product 1", got)
+				}
+			},
+		},
+		{
+			path:        "/api/v1/products/1/ratings",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				got := decode[ratings.RatingResponse](t, body)
+				if got.ID != 1 || got.Ratings["Reviewer1"] != 5 || got.Ratings["Reviewer2"] != 4 {
+					t.Errorf("ratings = %+v, want the ratings of product 1", got)
+				}
+			},
+		},
+		{
+			path:   "/api/v1/products/one/ratings",
+			status: http.StatusBadRequest,
+		},
+		{
+			path:        "/cart",
+			status:      http.StatusOK,
+			contentType: "text/html",
+			contains:    []string{"Your cart is empty."},
+		},
+		{
+			path:        "/api/v1/products/1/stock",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				if got := decode[ProductStock](t, body); got.ProductID != 1 || got.Available != 20 {
+					t.Errorf("stock = %+v, want 20 units of product 1", got)
+				}
+			},
+		},
+		{
+			path:        "/api/v1/products/3/price?currency=eur",
+			status:      http.StatusOK,
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				if got := decode[pricing.Price](t, body); got.Currency != "EUR" || got.List != "18.39" || got.Discounted != "14.71" {
+					t.Errorf("price = %+v, want 18.39 EUR less 20%%", got)
+				}
+			},
+		},
+		{
+			path:        "/static/img/izzy.png",
+			status:      http.StatusOK,
+			contentType: "image/png",
+		},
+	} {
+		for _, runner := range weavertest.AllRunners() {
+			runner.Name = runner.Name + strings.ReplaceAll(test.path, "/", "_")
+			runner.Test(t, func(t *testing.T, s *Server) {
+				srv := httptest.NewServer(s.handler)
+				defer srv.Close()
+
+				resp, body := get(t, srv, test.path)
+				if resp.StatusCode != test.status {
+					t.Fatalf("GET %s: status %d, want %d; body %q", test.path, resp.StatusCode, test.status, body)
+				}
+				if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, test.contentType) {
+					t.Errorf("GET %s: Content-Type %q, want %q", test.path, ct, test.contentType)
+				}
+				if resp.Header.Get(requestIDHeader) == "" {
+					t.Errorf("GET %s: no %s header", test.path, requestIDHeader)
+				}
+				for _, want := range test.contains {
+					if !strings.Contains(body, want) {
+						t.Errorf("GET %s: body doesn't contain %q", test.path, want)
+					}
+				}
+				if test.check != nil {
+					test.check(t, body)
+				}
+			})
+		}
+	}
+}
+
+func TestProblems(t *testing.T) {
+	for _, test := range []struct {
+		method, path string
+		status       int
+		allow        string // the Allow header
+		detail       string // substring of the detail
+	}{
+		{http.MethodGet, "/api/v1/products/one", http.StatusBadRequest, "", `invalid product ID "one"`},
+		{http.MethodGet, "/api/v1/products/01/ratings", http.StatusBadRequest, "", `invalid product ID "01"`},
+		{http.MethodGet, "/api/v1/products/1/sales", http.StatusNotFound, "", "no route"},
+		{http.MethodGet, "/api/v1/search", http.StatusBadRequest, "", "missing query parameter q"},
+		{http.MethodGet, "/api/v1/search?q=tempest&limit=51", http.StatusBadRequest, "", `invalid limit "51"`},
+		{http.MethodGet, "/books?page=0", http.StatusBadRequest, "", `invalid page "0"`},
+		{http.MethodGet, "/books?page=01", http.StatusBadRequest, "", `invalid page "01"`},
+		{http.MethodGet, "/books?page=6", http.StatusNotFound, "", "page 6 not found; there are 5"},
+		{http.MethodGet, "/api/v1/products/1/recommendations?n=21", http.StatusBadRequest, "", `invalid n "21"`},
+		{http.MethodGet, "/productpage?id=01", http.StatusBadRequest, "", `invalid product ID "01"`},
+		{http.MethodGet, "/productpage?id=1000", http.StatusNotFound, "", "product 1000 not found"},
+		{http.MethodGet, "/api/v1/carts/a.b", http.StatusBadRequest, "", "invalid cart"},
+		{http.MethodPost, "/api/v1/carts/alice/checkout", http.StatusBadRequest, "", "missing Idempotency-Key header"},
+		{http.MethodGet, "/api/v1/orders/0123456789abcdef", http.StatusNotFound, "", `order "0123456789abcdef" not found`},
+		{http.MethodGet, "/api/v1/products/1000/stock", http.StatusNotFound, "", "product 1000 not found"},
+		{http.MethodGet, "/api/v1/products/1000/price", http.StatusNotFound, "", "no price for product 1000"},
+		{http.MethodGet, "/api/v1/products/1/price?currency=euro", http.StatusBadRequest, "", `invalid currency "euro"`},
+		{http.MethodGet, "/api/v1/products/1/price?currency=CHF", http.StatusBadRequest, "", "prices aren't quoted in CHF"},
+		{http.MethodPost, "/currency", http.StatusBadRequest, "", `invalid currency ""`},
+		{http.MethodPost, "/checkout", http.StatusBadRequest, "", "missing idempotency_key"},
+		{http.MethodGet, "/orders/0123456789abcdef", http.StatusNotFound, "", `order "0123456789abcdef" not found`},
+		{http.MethodGet, "/nowhere", http.StatusNotFound, "", "no route"},
+		{http.MethodPost, "/", http.StatusMethodNotAllowed, "GET, HEAD", "POST is not allowed"},
+		{http.MethodPost, "/productpage", http.StatusMethodNotAllowed, "GET, HEAD", "POST is not allowed"},
+		{http.MethodDelete, "/api/v1/products/1", http.StatusMethodNotAllowed, "GET, HEAD", "DELETE is not allowed"},
+		{http.MethodPut, "/api/v1/products/1/ratings", http.StatusMethodNotAllowed, "GET, HEAD, POST", "PUT is not allowed"},
+		{http.MethodPost, "/static/img/izzy.png", http.StatusMethodNotAllowed, "GET, HEAD", "POST is not allowed"},
+	} {
+		weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+			rec := httptest.NewRecorder()
+			s.handler.ServeHTTP(rec, httptest.NewRequest(test.method, test.path, nil))
+			if rec.Code != test.status {
+				t.Fatalf("%s %s: status %d, want %d; body %q", test.method, test.path, rec.Code, test.status, rec.Body)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != problemContentType {
+				t.Errorf("%s %s: Content-Type %q, want %q", test.method, test.path, ct, problemContentType)
+			}
+			if allow := rec.Header().Get("Allow"); allow != test.allow {
+				t.Errorf("%s %s: Allow %q, want %q", test.method, test.path, allow, test.allow)
+			}
+			got := decode[Problem](t, rec.Body.String())
+			path, _, _ := strings.Cut(test.path, "?")
+			if got.Type != "about:blank" || got.Status != test.status || got.Title != http.StatusText(test.status) || got.Instance != path {
+				t.Errorf("%s %s: problem %+v, want status %d for %s", test.method, test.path, got, test.status, path)
+			}
+			if !strings.Contains(got.Detail, test.detail) {
+				t.Errorf("%s %s: detail %q doesn't contain %q", test.method, test.path, got.Detail, test.detail)
+			}
+			if got.RequestID == "" || got.RequestID != rec.Header().Get(requestIDHeader) {
+				t.Errorf("%s %s: request ID %q, want the %s header", test.method, test.path, got.RequestID, requestIDHeader)
+			}
+		})
+	}
+}
+
+func TestComponentProblem(t *testing.T) {
+	fakeRatings := fakes.NewRatings()
+	fakeRatings.FailWith("GetRatings", errors.New("ratings are down"))
+	runner := weavertest.Local
+	runner.Fakes = append(runner.Fakes, weavertest.Fake[ratings.Ratings](fakeRatings))
+	runner.Test(t, func(t *testing.T, s *Server) {
+		rec := httptest.NewRecorder()
+		s.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/products/1/ratings", nil))
+		if rec.Code != http.StatusBadGateway {
+			t.Fatalf("status %d, want %d; body %q", rec.Code, http.StatusBadGateway, rec.Body)
+		}
+		if got := decode[Problem](t, rec.Body.String()); !strings.Contains(got.Detail, "ratings are down") {
+			t.Errorf("detail %q doesn't contain the ratings error", got.Detail)
+		}
+	})
+}
+
+func TestRequestIDIsKept(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/healthz", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(requestIDHeader, "test-request")
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get(requestIDHeader); got != "test-request" {
+			t.Errorf("%s = %q, want %q", requestIDHeader, got, "test-request")
+		}
+	})
+}
+
+func TestRatingsDisabled(t *testing.T) {
+	// ENABLE_RATINGS is read by the reviews package when it's initialized,
+	// so it only takes effect in the reviews process of the Multi runner.
+	t.Setenv("ENABLE_RATINGS", "false")
+	weavertest.Multi.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+
+		resp, body := get(t, srv, "/api/v1/products/1/reviews")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET reviews: status %d; body %q", resp.StatusCode, body)
+		}
+		for _, review := range decode[reviews.Response](t, body).Reviews {
+			if review.Rating != (reviews.Rating{}) {
+				t.Errorf("%s: rating = %+v, want none", review.Reviewer, review.Rating)
+			}
+		}
+
+		resp, body = get(t, srv, "/productpage")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /productpage: status %d; body %q", resp.StatusCode, body)
+		}
+		if !strings.Contains(body, "An extremely entertaining play by Shakespeare") {
+			t.Errorf("GET /productpage: reviews missing from the page")
+		}
+	})
+}
+
+func TestWithFakes(t *testing.T) {
+	fakeDetails := fakes.NewDetails()
+	fakeDetails.SetBook(details.BookDetails{ID: 1, Author: "Ben Jonson", Publisher: "PublisherB"})
+	fakeReviews := fakes.NewReviews()
+	fakeCatalog := fakes.NewCatalog()
+	fakeCatalog.SetProduct(catalog.Product{ID: 0, Title: "The Alchemist"})
+	fakeSearch := fakes.NewSearch()
+	fakeRatings := fakes.NewRatings()
+	fakeRecommendations := fakes.NewRecommendations()
+	// The readers of product 0 liked 1 alike and 2 the other way round.
+	fakeRecommendations.SetRatings(0, map[string]int{"Alice": 5, "Bob": 2})
+	fakeRecommendations.SetRatings(1, map[string]int{"Alice": 5, "Bob": 2})
+	fakeRecommendations.SetRatings(2, map[string]int{"Alice": 1, "Bob": 4})
+	runner := weavertest.Local
+	runner.Fakes = append(runner.Fakes,
+		weavertest.Fake[catalog.Catalog](fakeCatalog),
+		weavertest.Fake[ratings.Ratings](fakeRatings),
+		weavertest.Fake[recommendations.Recommendations](fakeRecommendations),
+		weavertest.Fake[search.Search](fakeSearch),
+		weavertest.Fake[details.Details](fakeDetails),
+		weavertest.Fake[reviews.Reviews](fakeReviews))
+	runner.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+
+		// The product page shows the scripted product, book and reviews.
+		resp, body := get(t, srv, "/productpage")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /productpage: status %d; body %q", resp.StatusCode, body)
+		}
+		if !strings.Contains(body, "PublisherB") || !strings.Contains(body, "The Alchemist") {
+			t.Errorf("GET /productpage: scripted product or details missing")
+		}
+		if calls := fakeDetails.Calls("GetBookDetails"); len(calls) != 1 || calls[0].Args[0] != 1 {
+			t.Errorf("GetBookDetails calls = %+v, want one call for product 1", calls)
+		}
+		if calls := fakeRecommendations.Calls("Recommend"); len(calls) != 1 || calls[0].Args[0] != 0 || !strings.Contains(body, `href="/productpage?id=1"`) {
+			t.Errorf("Recommend calls = %+v, want one call for product 0 and a link to product 1", calls)
+		}
+
+		// A page of books takes one batch call to details, one to reviews
+		// and one to ratings.
+		resp, body = get(t, srv, "/books")
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, "Ben Jonson") {
+			t.Errorf("GET /books: status %d, want %d and the scripted author", resp.StatusCode, http.StatusOK)
+		}
+		if calls := fakeDetails.Calls("GetBookDetailsBatch"); len(calls) != 1 || len(calls[0].Args[0].([]int)) != booksPerPage {
+			t.Errorf("GetBookDetailsBatch calls = %+v, want one call for %d products", calls, booksPerPage)
+		}
+		if calls := fakeRatings.Calls("GetRatings", "GetRatingsBatch"); len(calls) != 1 || calls[0].Method != "GetRatingsBatch" || len(calls[0].Args[0].([]int)) != booksPerPage {
+			t.Errorf("ratings calls = %+v, want one GetRatingsBatch call for %d products", calls, booksPerPage)
+		}
+		if calls := fakeReviews.Calls("ReviewCounts"); len(calls) != 1 || len(calls[0].Args[0].([]int)) != booksPerPage {
+			t.Errorf("ReviewCounts calls = %+v, want one call for %d products", calls, booksPerPage)
+		}
+
+		// A failing details component makes the product page unready.
+		fakeDetails.FailWith("Health", errors.New("details are down"))
+		resp, body = get(t, srv, "/readyz")
+		if resp.StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("GET /readyz: status %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+		}
+		if got := decode[Readiness](t, body).Components["details"]; got.Status != "unavailable" || got.Error != "details are down" {
+			t.Errorf("details readiness = %+v, want unavailable", got)
+		}
+
+		// Without the catalog, there's no product to show.
+		fakeCatalog.FailWith(fakes.AnyMethod, errors.New("catalog is down"))
+		for _, path := range []string{"/productpage", "/api/v1/products"} {
+			if resp, _ := get(t, srv, path); resp.StatusCode != http.StatusBadGateway {
+				t.Errorf("GET %s with the catalog down: status %d, want %d", path, resp.StatusCode, http.StatusBadGateway)
+			}
+		}
+		fakeCatalog.FailWith(fakes.AnyMethod, nil)
+
+		// Without search, the API fails and the search page says so.
+		fakeSearch.FailWith("Search", errors.New("search is down"))
+		if resp, _ := get(t, srv, "/api/v1/search?q=tempest"); resp.StatusCode != http.StatusBadGateway {
+			t.Errorf("GET /api/v1/search with search down: status %d, want %d", resp.StatusCode, http.StatusBadGateway)
+		}
+		if resp, body := get(t, srv, "/search?q=tempest"); resp.StatusCode != http.StatusOK || !strings.Contains(body, searchUnavailable) {
+			t.Errorf("GET /search with search down: status %d, want %d and %q", resp.StatusCode, http.StatusOK, searchUnavailable)
+		}
+
+		// Without recommendations, the API fails and the product page leaves
+		// them out.
+		fakeRecommendations.FailWith("Recommend", errors.New("recommendations are down"))
+		if resp, _ := get(t, srv, "/api/v1/products/1/recommendations"); resp.StatusCode != http.StatusBadGateway {
+			t.Errorf("GET /api/v1/products/1/recommendations with recommendations down: status %d, want %d", resp.StatusCode, http.StatusBadGateway)
+		}
+		if resp, body := get(t, srv, "/productpage"); resp.StatusCode != http.StatusOK || strings.Contains(body, "You might also like") {
+			t.Errorf("GET /productpage with recommendations down: status %d, want %d without recommendations", resp.StatusCode, http.StatusOK)
+		}
+		fakeRecommendations.FailWith("Recommend", nil)
+
+		// Without reviews, the product page says so.
+		fakeReviews.FailWith("BookReviewsByID", errors.New("reviews are down"))
+		resp, body = get(t, srv, "/productpage")
+		if resp.StatusCode != http.StatusOK || !strings.Contains(body, reviewsUnavailable) {
+			t.Errorf("GET /productpage with reviews down: status %d, want %d and %q", resp.StatusCode, http.StatusOK, reviewsUnavailable)
+		}
+	})
+}
+
+func FuzzParseProductID(f *testing.F) {
+	for _, seed := range []string{"0", "1", "42", "", "one", "-1", "+1", "01", " 1", "1/ratings", "9223372036854775807", "9223372036854775808"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		id, err := parseProductID(s)
+		if err != nil {
+			return
+		}
+		if id < 0 {
+			t.Errorf("parseProductID(%q) = %d, want a non-negative ID", s, id)
+		}
+		if got := strconv.Itoa(id); got != s {
+			t.Errorf("parseProductID(%q) = %d, which is written %q", s, id, got)
+		}
+	})
+}
+
+func TestRecommendationsFollowRatings(t *testing.T) {
+	// Posted ratings are kept by the ratings replica that got them, and the
+	// Multi runner balances routed calls over more than one.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Config = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations"]
+refresh_interval = "10ms"
+`
+		runner.Test(t, func(t *testing.T, s *Server) {
+			srv := httptest.NewServer(s.handler)
+			defer srv.Close()
+
+			// Products 90 to 92 are only rated here, by readers of their
+			// own: the readers of 90 liked 91 too, and disliked 92.
+			for id, body := range map[int]string{
+				90: `{"Alice": 5, "Bob": 4}`,
+				91: `{"Alice": 5, "Bob": 5}`,
+				92: `{"Alice": 2, "Bob": 1}`,
+			} {
+				resp, err := srv.Client().Post(fmt.Sprintf("%s/api/v1/products/%d/ratings", srv.URL, id), "application/json", strings.NewReader(body))
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+				if resp.StatusCode != http.StatusOK {
+					t.Fatalf("POST ratings of product %d: status %d, want %d", id, resp.StatusCode, http.StatusOK)
+				}
+			}
+
+			var got []recommendations.Recommendation
+			for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+				_, body := get(t, srv, "/api/v1/products/90/recommendations")
+				got = decode[[]recommendations.Recommendation](t, body)
+				if len(got) == 1 || time.Now().After(deadline) {
+					break
+				}
+			}
+			if len(got) != 1 || got[0].ProductID != 91 || got[0].Readers != 2 {
+				t.Fatalf("recommendations of product 90 = %+v, want product 91", got)
+			}
+			_, body := get(t, srv, "/productpage?id=90")
+			if !strings.Contains(body, "You might also like") || !strings.Contains(body, `href="/productpage?id=91"`) || strings.Contains(body, `href="/productpage?id=92"`) {
+				t.Errorf("GET /productpage?id=90: want product 91 under You might also like, and not 92")
+			}
+		})
+	}
+}
+
+// TestProductIDRoundTrip checks that the product API serves any valid product
+// ID under its own ID, or a 404 for the details of products missing from the
+// catalog.
+func TestProductIDRoundTrip(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		roundTrip := func(n uint32) bool {
+			id := int(n)
+			if _, err := parseProductID(strconv.Itoa(id)); err != nil {
+				t.Logf("parseProductID(%d): %v", id, err)
+				return false
+			}
+			_, err := s.catalog.Get().Get(context.Background(), id)
+			inCatalog := err == nil
+			for _, path := range []string{"/api/v1/products/%d", "/api/v1/products/%d/ratings"} {
+				path := fmt.Sprintf(path, id)
+				rec := httptest.NewRecorder()
+				s.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+				if !inCatalog && path == fmt.Sprintf("/api/v1/products/%d", id) {
+					if rec.Code != http.StatusNotFound {
+						t.Logf("GET %s: status %d, want %d", path, rec.Code, http.StatusNotFound)
+						return false
+					}
+					continue
+				}
+				if rec.Code != http.StatusOK {
+					t.Logf("GET %s: status %d; body %q", path, rec.Code, rec.Body)
+					return false
+				}
+				var got struct{ ID int }
+				if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got.ID != id {
+					t.Logf("GET %s: body %q, want product %d", path, rec.Body, id)
+					return false
+				}
+			}
+			return true
+		}
+		if err := quick.Check(roundTrip, nil); err != nil {
+			t.Error(err)
+		}
+	})
+}
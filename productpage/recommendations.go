@@ -0,0 +1,43 @@
+package productpage
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+)
+
+// Number of recommendations shown on the product page and served by the
+// API, unless asked for another, and the most the API serves.
+const (
+	defaultRecommendations = 4
+	maxRecommendations     = 20
+)
+
+// recommendationsHandler serves the products recommended for a product, most
+// similar first, at most n of them.
+func (s *Server) recommendationsHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathProductID(w, r)
+	if !ok {
+		return
+	}
+	n := defaultRecommendations
+	if v := r.URL.Query().Get("n"); v != "" {
+		var err error
+		if n, err = strconv.Atoi(v); err != nil || n < 1 || n > maxRecommendations {
+			writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid n %q; want 1 to %d", v, maxRecommendations))
+			return
+		}
+	}
+
+	recs, err := s.recommendations.Get().Recommend(r.Context(), id, n)
+	if err != nil {
+		s.componentProblem(w, r, "recommendations", id, err)
+		return
+	}
+	if recs == nil {
+		recs = []recommendations.Recommendation{}
+	}
+	writeJSON(w, http.StatusOK, recs)
+}
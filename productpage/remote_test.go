@@ -0,0 +1,125 @@
+package productpage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote/stubs"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+)
+
+// TestRemote replaces every component with a stub of the original service.
+func TestRemote(t *testing.T) {
+	detailsStub, reviewsStub, ratingsStub := stubs.NewDetails(), stubs.NewReviews(), stubs.NewRatings()
+	var urls []any
+	for _, stub := range []*stubs.Stub{detailsStub, reviewsStub, ratingsStub} {
+		srv := httptest.NewServer(stub)
+		defer srv.Close()
+		urls = append(urls, srv.URL)
+	}
+
+	runner := weavertest.Local
+	runner.Config = fmt.Sprintf(`
+["github.com/ServiceWeaver/weaver/Main"]
+remote.details = %q
+remote.reviews = %q
+remote.ratings = %q
+`, urls...)
+	runner.Test(t, func(t *testing.T, s *Server) {
+		srv := httptest.NewServer(s.handler)
+		defer srv.Close()
+
+		_, body := get(t, srv, "/api/v1/products/1/reviews")
+		if got := decode[reviews.Response](t, body); got.PodName != "stub" || len(got.Reviews) != 2 {
+			t.Errorf("reviews = %+v, want the stub's", got)
+		}
+		if _, body := get(t, srv, "/api/v1/products/1"); decode[details.BookDetails](t, body).Author != "William Shakespeare" {
+			t.Errorf("details = %s, want the stub's", body)
+		}
+
+		ratingsStub.FailWith(http.StatusInternalServerError)
+		resp, body := get(t, srv, "/readyz")
+		readiness := decode[Readiness](t, body)
+		if resp.StatusCode != http.StatusServiceUnavailable || readiness.Components["ratings"].Status != "unhealthy" {
+			t.Errorf("readiness with the ratings stub failing: %d %s, want ratings unhealthy", resp.StatusCode, body)
+		}
+		if resp, _ := get(t, srv, "/api/v1/products/1/ratings"); resp.StatusCode != http.StatusBadGateway {
+			t.Errorf("ratings with the ratings stub failing: %d, want %d", resp.StatusCode, http.StatusBadGateway)
+		}
+	})
+}
+
+// TestRemoteCompat checks that the remote adapters, called against the
+// compatibility listeners, return what the components do.
+func TestRemoteCompat(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, s *Server) {
+		handlers := s.compatHandlers()
+		urls := map[string]string{}
+		for name, h := range handlers {
+			srv := httptest.NewServer(h)
+			defer srv.Close()
+			urls[name] = srv.URL
+		}
+		ctx := context.Background()
+
+		d, err := details.NewRemote(urls["details"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotBook, err := d.GetBookDetails(ctx, 1, nil)
+		wantBook, _ := s.bookDetails.GetBookDetails(ctx, 1, nil)
+		if err != nil || !reflect.DeepEqual(gotBook, wantBook) {
+			t.Errorf("remote GetBookDetails(1) = %+v, %v, want %+v", gotBook, err, wantBook)
+		}
+		if _, err := d.GetBookDetails(ctx, 1000, nil); !errors.As(err, new(catalog.NotFoundError)) {
+			t.Errorf("remote GetBookDetails(1000) = %v, want a catalog.NotFoundError", err)
+		}
+		gotBooks, err := d.GetBookDetailsBatch(ctx, []int{1, 1000})
+		wantBooks, _ := s.bookDetails.GetBookDetailsBatch(ctx, []int{1, 1000})
+		if err != nil || !reflect.DeepEqual(gotBooks, wantBooks) {
+			t.Errorf("remote GetBookDetailsBatch(1, 1000) = %+v, %v, want %+v", gotBooks, err, wantBooks)
+		}
+
+		r, err := reviews.NewRemote(urls["reviews"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotReviews, err := r.BookReviewsByID(ctx, "1")
+		wantReviews, _ := s.bookReviews.BookReviewsByID(ctx, "1")
+		if err != nil || !reflect.DeepEqual(gotReviews, wantReviews) {
+			t.Errorf("remote BookReviewsByID(1) = %+v, %v, want %+v", gotReviews, err, wantReviews)
+		}
+		gotCounts, err := r.ReviewCounts(ctx, []int{1, 2})
+		wantCounts, _ := s.bookReviews.ReviewCounts(ctx, []int{1, 2})
+		if err != nil || !reflect.DeepEqual(gotCounts, wantCounts) {
+			t.Errorf("remote ReviewCounts(1, 2) = %+v, %v, want %+v", gotCounts, err, wantCounts)
+		}
+
+		rt, err := ratings.NewRemote(urls["ratings"])
+		if err != nil {
+			t.Fatal(err)
+		}
+		gotRatings, err := rt.GetRatings(ctx, 1)
+		wantRatings, _ := s.bookRatings.GetRatings(ctx, 1)
+		if err != nil || !reflect.DeepEqual(gotRatings, wantRatings) {
+			t.Errorf("remote GetRatings(1) = %+v, %v, want %+v", gotRatings, err, wantRatings)
+		}
+		gotBatch, err := rt.GetRatingsBatch(ctx, []int{1, 2})
+		wantBatch, _ := s.bookRatings.GetRatingsBatch(ctx, []int{1, 2})
+		if err != nil || !reflect.DeepEqual(gotBatch, wantBatch) {
+			t.Errorf("remote GetRatingsBatch(1, 2) = %+v, %v, want %+v", gotBatch, err, wantBatch)
+		}
+		if status, err := rt.Health(ctx); err != nil || !status.Healthy {
+			t.Errorf("remote Health() = %+v, %v, want healthy", status, err)
+		}
+	})
+}
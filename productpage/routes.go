@@ -0,0 +1,129 @@
+package productpage
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ServiceWeaver/weaver"
+)
+
+// routes registers every product page route. Routes are method-qualified;
+// other methods on a route's path get a 405 problem, and paths without routes
+// a 404 problem.
+func (s *Server) routes(static fs.FS) *routeTable {
+	t := newRouteTable()
+	t.handle("GET /{$}", weaver.InstrumentHandler("index", s.logged(http.HandlerFunc(s.indexHandler))))
+	t.handle("GET /health", weaver.InstrumentHandler("health", s.logged(http.HandlerFunc(s.healthHandler))))
+	t.handle("GET /healthz", weaver.InstrumentHandler("healthz", s.logged(http.HandlerFunc(s.healthzHandler))))
+	t.handle("GET /readyz", weaver.InstrumentHandler("readyz", s.logged(http.HandlerFunc(s.readyzHandler))))
+	t.handle("GET /productpage", weaver.InstrumentHandler("productpage-reviews-details", s.logged(http.HandlerFunc(s.productPageHandler))))
+	t.handle("GET /books", weaver.InstrumentHandler("books", s.logged(http.HandlerFunc(s.booksHandler))))
+	t.handle("GET /search", weaver.InstrumentHandler("search-page", s.logged(http.HandlerFunc(s.searchPageHandler))))
+	t.handle("GET /cart", weaver.InstrumentHandler("cart-page", s.logged(http.HandlerFunc(s.cartPageHandler))))
+	t.handle("POST /cart/items", weaver.InstrumentHandler("cart-page-add", s.logged(http.HandlerFunc(s.cartPageAddHandler))))
+	t.handle("POST /cart/items/{id}", weaver.InstrumentHandler("cart-page-set", s.logged(http.HandlerFunc(s.cartPageSetHandler))))
+	t.handle("POST /checkout", weaver.InstrumentHandler("checkout-page", s.logged(http.HandlerFunc(s.checkoutPageHandler))))
+	t.handle("GET /orders/{order}", weaver.InstrumentHandler("order-page", s.logged(http.HandlerFunc(s.orderPageHandler))))
+	t.handle("POST /currency", weaver.InstrumentHandler("currency-page", s.logged(http.HandlerFunc(s.currencyHandler))))
+	t.handle("GET /api/v1/openapi.json", weaver.InstrumentHandler("openapi", s.logged(http.HandlerFunc(s.openAPIHandler))))
+	t.handle("GET /api/v1/topology", weaver.InstrumentHandler("topology", s.logged(http.HandlerFunc(s.topologyHandler))))
+	t.handle("GET /api/v1/products", weaver.InstrumentHandler("products", s.logged(http.HandlerFunc(s.productsHandler))))
+	t.handle("GET /api/v1/search", weaver.InstrumentHandler("search", s.logged(http.HandlerFunc(s.searchHandler))))
+	t.handle("GET /api/v1/products/{id}", weaver.InstrumentHandler("product", s.logged(http.HandlerFunc(s.productHandler))))
+	t.handle("GET /api/v1/products/{id}/reviews", weaver.InstrumentHandler("product-reviews", s.logged(http.HandlerFunc(s.productReviewsHandler))))
+	t.handle("GET /api/v1/products/{id}/ratings", weaver.InstrumentHandler("product-ratings", s.logged(http.HandlerFunc(s.productRatingsHandler))))
+	t.handle("GET /api/v1/products/{id}/recommendations", weaver.InstrumentHandler("product-recommendations", s.logged(http.HandlerFunc(s.recommendationsHandler))))
+	t.handle("POST /api/v1/products/{id}/ratings", weaver.InstrumentHandler("product-ratings-post", s.logged(http.HandlerFunc(s.postRatingsHandler))))
+	t.handle("GET /api/v1/products/{id}/stock", weaver.InstrumentHandler("product-stock", s.logged(http.HandlerFunc(s.productStockHandler))))
+	t.handle("GET /api/v1/products/{id}/price", weaver.InstrumentHandler("product-price", s.logged(http.HandlerFunc(s.productPriceHandler))))
+	t.handle("GET /api/v1/carts/{cart}", weaver.InstrumentHandler("cart", s.logged(http.HandlerFunc(s.cartHandler))))
+	t.handle("POST /api/v1/carts/{cart}/items", weaver.InstrumentHandler("cart-item-add", s.logged(http.HandlerFunc(s.addCartItemHandler))))
+	t.handle("PUT /api/v1/carts/{cart}/items/{id}", weaver.InstrumentHandler("cart-item-set", s.logged(http.HandlerFunc(s.setCartItemHandler))))
+	t.handle("DELETE /api/v1/carts/{cart}/items/{id}", weaver.InstrumentHandler("cart-item-delete", s.logged(http.HandlerFunc(s.deleteCartItemHandler))))
+	t.handle("POST /api/v1/carts/{cart}/checkout", weaver.InstrumentHandler("checkout", s.logged(http.HandlerFunc(s.checkoutHandler))))
+	t.handle("GET /api/v1/orders/{order}", weaver.InstrumentHandler("order", s.logged(http.HandlerFunc(s.orderHandler))))
+	t.handle("GET /api/v1/admin/products/{id}", weaver.InstrumentHandler("admin-product", s.logged(s.requireAdmin(http.HandlerFunc(s.adminProductHandler)))))
+	t.handle("POST /api/v1/admin/products", weaver.InstrumentHandler("admin-product-create", s.logged(s.requireAdmin(http.HandlerFunc(s.createProductHandler)))))
+	t.handle("PUT /api/v1/admin/products/{id}", weaver.InstrumentHandler("admin-product-update", s.logged(s.requireAdmin(http.HandlerFunc(s.updateProductHandler)))))
+	t.handle("DELETE /api/v1/admin/products/{id}", weaver.InstrumentHandler("admin-product-delete", s.logged(s.requireAdmin(http.HandlerFunc(s.deleteProductHandler)))))
+
+	// Static content isn't instrumented.
+	t.handle("GET /static/", s.logged(http.StripPrefix("/static/", http.FileServer(http.FS(static)))))
+
+	return t
+}
+
+// routeTable registers method-qualified routes on a ServeMux and records the
+// methods allowed on every path, for answering the others with a 405.
+type routeTable struct {
+	mux     *http.ServeMux
+	methods map[string][]string // allowed methods, by path pattern
+	paths   []string            // path patterns, in registration order
+	routes  []string            // the patterns registered with handle
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{mux: http.NewServeMux(), methods: map[string][]string{}}
+}
+
+// handle registers h for pattern, which must be of the form "METHOD /path".
+// As in ServeMux, a GET route serves HEAD requests too.
+func (t *routeTable) handle(pattern string, h http.Handler) {
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		panic(fmt.Sprintf("route %q has no method", pattern))
+	}
+	t.mux.Handle(pattern, h)
+	t.routes = append(t.routes, pattern)
+	if _, ok := t.methods[path]; !ok {
+		t.paths = append(t.paths, path)
+	}
+	t.methods[path] = append(t.methods[path], method)
+	if method == http.MethodGet {
+		t.methods[path] = append(t.methods[path], http.MethodHead)
+	}
+}
+
+// handler registers the 405 and 404 handlers and returns the mux. wrap wraps
+// them, e.g. for logging.
+func (t *routeTable) handler(wrap func(http.Handler) http.Handler) http.Handler {
+	for _, path := range t.paths {
+		allowed := strings.Join(slices.Compact(t.methods[path]), ", ")
+		t.mux.Handle(path, wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", allowed)
+			writeProblem(w, r, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not allowed on %s; use %s", r.Method, r.URL.Path, allowed))
+		})))
+	}
+	if _, ok := t.methods["/"]; !ok {
+		t.mux.Handle("/", wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			writeProblem(w, r, http.StatusNotFound, fmt.Sprintf("no route for %s", r.URL.Path))
+		})))
+	}
+	return t.mux
+}
+
+// pathProductID returns the product ID in the {id} wildcard of r's path. If
+// the ID is invalid, it writes a 400 problem and returns false.
+func pathProductID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := parseProductID(r.PathValue("id"))
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, err.Error())
+		return 0, false
+	}
+	return id, true
+}
+
+// parseProductID parses a product ID from a URL. IDs are non-negative
+// decimal integers written without a sign or leading zeros, so every product
+// has a single URL.
+func parseProductID(s string) (int, error) {
+	id, err := strconv.Atoi(s)
+	if err != nil || id < 0 || strconv.Itoa(id) != s {
+		return 0, fmt.Errorf("invalid product ID %q", s)
+	}
+	return id, nil
+}
@@ -0,0 +1,95 @@
+package productpage
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+)
+
+// Number of hits served by the search API, unless asked for another, and
+// the most it serves.
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// searchUnavailable is shown on the search page when search fails.
+const searchUnavailable = "Sorry, search is currently unavailable."
+
+// SearchView is the data of the search.html template.
+type SearchView struct {
+	Nav   NavView // with the query in the search box
+	Total int     // the number of matching products
+	Hits  []SearchHitView
+	Error string // set if the search failed
+}
+
+// SearchHitView is a product matching a search.
+type SearchHitView struct {
+	ProductID int
+	Title     template.HTML // highlighted
+	Author    string
+	Snippet   template.HTML // highlighted
+}
+
+// newSearchView returns the view of the results of a search. The highlighted
+// title and snippet are trusted as HTML, since the search component escapes
+// the text around the <mark> elements it adds.
+func newSearchView(nav NavView, results search.Results) SearchView {
+	view := SearchView{Nav: nav, Total: results.Total}
+	for _, hit := range results.Hits {
+		view.Hits = append(view.Hits, SearchHitView{
+			ProductID: hit.ProductID,
+			Title:     template.HTML(hit.TitleHTML),
+			Author:    hit.Author,
+			Snippet:   template.HTML(hit.SnippetHTML),
+		})
+	}
+	return view
+}
+
+// searchHandler serves the products matching the q query parameter, best
+// first, at most limit of them.
+func (s *Server) searchHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	if q == "" {
+		writeProblem(w, r, http.StatusBadRequest, "missing query parameter q")
+		return
+	}
+	limit := defaultSearchLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		var err error
+		if limit, err = strconv.Atoi(l); err != nil || limit < 1 || limit > maxSearchLimit {
+			writeProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid limit %q; want 1 to %d", l, maxSearchLimit))
+			return
+		}
+	}
+
+	results, err := s.search.Get().Search(r.Context(), q, limit)
+	if err != nil {
+		s.logger(r.Context()).Error("Component call failed", "component", "search", "err", err)
+		writeProblem(w, r, http.StatusBadGateway, fmt.Sprintf("failed to search the products: %v", err))
+		return
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// searchPageHandler serves the search page, with the products matching the
+// q query parameter, if any.
+func (s *Server) searchPageHandler(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query().Get("q")
+	view := SearchView{Nav: NavView{User: requestUser(r), Query: q}}
+	if q != "" {
+		results, err := s.search.Get().Search(r.Context(), q, maxSearchLimit)
+		if err != nil {
+			s.logger(r.Context()).Error("Component call failed", "component", "search", "err", err)
+			view.Error = searchUnavailable
+		} else {
+			view = newSearchView(view.Nav, results)
+		}
+	}
+	s.render(w, r, "search.html", view)
+}
@@ -0,0 +1,187 @@
+// Renders the OpenAPI document served at /api/v1/openapi.json, and lets
+// every operation be tried out from the page.
+"use strict";
+
+const specURL = "/api/v1/openapi.json";
+
+// el creates an element with the given attributes and children.
+function el(tag, attrs, ...children) {
+  const e = document.createElement(tag);
+  for (const [k, v] of Object.entries(attrs || {})) {
+    e.setAttribute(k, v);
+  }
+  for (const child of children) {
+    e.append(child);
+  }
+  return e;
+}
+
+// refName returns the schema name of a "#/components/schemas/Name" reference.
+function refName(ref) {
+  return ref.substring(ref.lastIndexOf("/") + 1);
+}
+
+// typeOf returns a node describing the type of a schema.
+function typeOf(schema) {
+  if (!schema) {
+    return "";
+  }
+  if (schema.$ref) {
+    const name = refName(schema.$ref);
+    return el("a", {href: "#schema-" + name}, name);
+  }
+  if (schema.allOf) {
+    return typeOf(schema.allOf[0]);
+  }
+  const nullable = schema.nullable ? " | null" : "";
+  switch (schema.type) {
+    case "array":
+      return el("span", {}, "array of ", typeOf(schema.items), nullable);
+    case "object":
+      if (schema.additionalProperties) {
+        return el("span", {}, "map of ", typeOf(schema.additionalProperties), nullable);
+      }
+      return "object" + nullable;
+    case undefined:
+      return "any";
+  }
+  return schema.type + (schema.format ? " (" + schema.format + ")" : "") + nullable;
+}
+
+// renderOperation renders an operation, with a form to try it out.
+function renderOperation(method, path, op) {
+  const params = op.parameters || [];
+  const inputs = {};
+  const form = el("form", {});
+  for (const p of params) {
+    const attrs = {name: p.name};
+    if (p.required) {
+      attrs.required = "";
+    }
+    if (p.example !== undefined) {
+      attrs.value = String(p.example);
+    } else if (p.in === "path") {
+      attrs.value = "1";
+    }
+    inputs[p.name] = el("input", attrs);
+    form.append(el("label", {}, p.name + " ", inputs[p.name]), " ");
+  }
+  let token;
+  if (op.security) {
+    token = el("input", {name: "token", type: "password", autocomplete: "off"});
+    form.append(el("label", {}, "admin token ", token), " ");
+  }
+  let body;
+  if (op.requestBody) {
+    const example = op.requestBody.content["application/json"].example;
+    body = el("textarea", {name: "body", rows: "6"});
+    body.value = example === undefined ? "" : JSON.stringify(example, null, 2);
+    form.append(el("label", {class: "body"}, "body", body));
+  }
+  const output = el("pre", {hidden: ""});
+  form.append(el("button", {type: "submit"}, "Try it"));
+  form.addEventListener("submit", async (event) => {
+    event.preventDefault();
+    let url = path;
+    const query = new URLSearchParams();
+    const init = {method: method.toUpperCase(), headers: {}};
+    for (const p of params) {
+      if (p.in === "path") {
+        url = url.replace("{" + p.name + "}", encodeURIComponent(inputs[p.name].value));
+      } else if (inputs[p.name].value === "") {
+        // Left out.
+      } else if (p.in === "header") {
+        init.headers[p.name] = inputs[p.name].value;
+      } else {
+        query.set(p.name, inputs[p.name].value);
+      }
+    }
+    if (query.size > 0) {
+      url += "?" + query;
+    }
+    if (token && token.value !== "") {
+      init.headers["Authorization"] = "Bearer " + token.value;
+    }
+    if (body) {
+      init.headers["Content-Type"] = "application/json";
+      init.body = body.value;
+    }
+    output.hidden = false;
+    output.textContent = init.method + " " + url + "\n\n";
+    try {
+      const resp = await fetch(url, init);
+      let text = await resp.text();
+      try {
+        text = JSON.stringify(JSON.parse(text), null, 2);
+      } catch (e) {
+        // Not JSON: shown as it is.
+      }
+      output.textContent += resp.status + " " + resp.statusText + "\n" + text;
+    } catch (e) {
+      output.textContent += "Request failed: " + e;
+    }
+  });
+
+  const responses = el("table", {}, el("tr", {}, el("th", {}, "Status"), el("th", {}, "Description"), el("th", {}, "Body")));
+  for (const [status, resp] of Object.entries(op.responses)) {
+    const media = Object.entries(resp.content || {})[0];
+    responses.append(el("tr", {},
+      el("td", {class: "status"}, status),
+      el("td", {}, resp.description),
+      el("td", {}, media ? el("span", {}, typeOf(media[1].schema), " (" + media[0] + ")") : "")));
+  }
+
+  const section = el("div", {class: "operation", id: op.operationId},
+    el("h3", {}, el("span", {class: "method"}, method), el("span", {class: "path"}, path)),
+    el("p", {}, op.summary));
+  for (const p of params) {
+    section.append(el("p", {}, el("code", {}, p.name), " (" + p.in + (p.required ? ", required" : "") + ", ", typeOf(p.schema), "): " + (p.description || "")));
+  }
+  section.append(responses, form, output);
+  return section;
+}
+
+// renderSchema renders an object schema as a table of its properties.
+function renderSchema(name, schema) {
+  const required = new Set(schema.required || []);
+  const table = el("table", {}, el("tr", {}, el("th", {}, "Field"), el("th", {}, "Type"), el("th", {}, "Description")));
+  for (const [field, prop] of Object.entries(schema.properties || {})) {
+    const description = el("td", {}, prop.description || "");
+    if (prop.deprecated) {
+      description.prepend(el("span", {class: "deprecated"}, "Deprecated. "));
+    }
+    table.append(el("tr", {},
+      el("td", {}, el("code", {}, field), required.has(field) ? "" : " (optional)"),
+      el("td", {}, typeOf(prop)),
+      description));
+  }
+  return el("div", {id: "schema-" + name}, el("h3", {}, name), table);
+}
+
+async function main() {
+  const operations = document.getElementById("operations");
+  let spec;
+  try {
+    const resp = await fetch(specURL);
+    spec = await resp.json();
+  } catch (e) {
+    operations.textContent = "Failed to load " + specURL + ": " + e;
+    return;
+  }
+  document.title = spec.info.title;
+  document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+  document.getElementById("description").textContent = spec.info.description;
+
+  operations.replaceChildren(el("h2", {}, "Operations"));
+  for (const [path, methods] of Object.entries(spec.paths)) {
+    for (const [method, op] of Object.entries(methods)) {
+      operations.append(renderOperation(method, path, op));
+    }
+  }
+  const schemas = document.getElementById("schemas");
+  for (const [name, schema] of Object.entries(spec.components.schemas)) {
+    schemas.append(renderSchema(name, schema));
+  }
+}
+
+main();
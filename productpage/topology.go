@@ -0,0 +1,297 @@
+package productpage
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/inventory"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Service Weaver balances calls across replicas, so the product page finds
+// the replicas of a component by asking it to describe itself repeatedly.
+// Probes are sent in rounds of at least minProbeRound calls, and at least
+// twice as many calls as replicas found so far; discovery stops after a
+// round that finds no new replica, or after maxProbes calls.
+const (
+	minProbeRound = 4
+	maxProbes     = 256
+)
+
+// Topology is the live component graph rendered on the index page and
+// served by /api/v1/topology.
+type Topology struct {
+	Groups     []GroupView     `json:"groups"`
+	Components []ComponentView `json:"components"`
+	Edges      []EdgeView      `json:"edges"`
+}
+
+// GroupView is a colocation group: the components that share processes.
+type GroupView struct {
+	Name       string   `json:"name"`
+	Components []string `json:"components"`
+	Processes  int      `json:"processes"`
+}
+
+// ComponentView is a component together with the replicas that were found.
+type ComponentView struct {
+	Name     string             `json:"name"`
+	Short    string             `json:"short"`
+	Group    string             `json:"group"`
+	Replicas []topology.Replica `json:"replicas"`
+	Error    string             `json:"error,omitempty"`
+}
+
+// EdgeView is a static dependency between two components and the rate at
+// which the caller's replicas have been calling the callee.
+type EdgeView struct {
+	Caller string  `json:"caller"`
+	Callee string  `json:"callee"`
+	Remote bool    `json:"remote"`
+	Calls  float64 `json:"calls"`
+	// Rate is the calls per second since the previous topology, over the
+	// replicas found both times, and Interval the seconds between the two.
+	// Interval is 0, and Rate unknown, for the first topology.
+	Rate     float64 `json:"rate"`
+	Interval float64 `json:"interval"`
+}
+
+// callSamples are the call counts of the last topology built, from which
+// the next one computes the current call rates.
+type callSamples struct {
+	mu     sync.Mutex
+	at     time.Time          // when the counts were taken; zero if never
+	counts map[string]float64 // by caller process and callee, see callKey
+}
+
+// callKey returns the key of the calls from replica r to callee.
+func callKey(r topology.Replica, callee string) string {
+	return r.Process() + " " + callee
+}
+
+// describers returns, for every component the product page knows how to
+// probe, a function that describes one of its replicas.
+func (s *Server) describers() map[string]func(context.Context) (topology.Replica, error) {
+	return map[string]func(context.Context) (topology.Replica, error){
+		topology.Name[weaver.Main](): func(context.Context) (topology.Replica, error) {
+			return topology.Self[weaver.Main](), nil
+		},
+		topology.Name[catalog.Catalog](): func(ctx context.Context) (topology.Replica, error) {
+			return s.catalog.Get().Describe(ctx)
+		},
+		topology.Name[search.Search](): func(ctx context.Context) (topology.Replica, error) {
+			return s.search.Get().Describe(ctx)
+		},
+		topology.Name[recommendations.Recommendations](): func(ctx context.Context) (topology.Replica, error) {
+			return s.recommendations.Get().Describe(ctx)
+		},
+		topology.Name[cart.Cart](): func(ctx context.Context) (topology.Replica, error) {
+			return s.cart.Get().Describe(ctx)
+		},
+		topology.Name[inventory.Inventory](): func(ctx context.Context) (topology.Replica, error) {
+			return s.inventory.Get().Describe(ctx)
+		},
+		topology.Name[orders.Orders](): func(ctx context.Context) (topology.Replica, error) {
+			return s.orders.Get().Describe(ctx)
+		},
+		topology.Name[pricing.Pricing](): func(ctx context.Context) (topology.Replica, error) {
+			return s.pricing.Get().Describe(ctx)
+		},
+		topology.Name[details.Details](): func(ctx context.Context) (topology.Replica, error) {
+			return s.bookDetails.Describe(ctx)
+		},
+		topology.Name[reviews.Reviews](): func(ctx context.Context) (topology.Replica, error) {
+			return s.bookReviews.Describe(ctx)
+		},
+		topology.Name[ratings.Ratings](): func(ctx context.Context) (topology.Replica, error) {
+			return s.bookRatings.Describe(ctx)
+		},
+	}
+}
+
+// buildTopology probes every registered component and assembles the graph.
+func (s *Server) buildTopology(ctx context.Context) Topology {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	names, edges := topology.Graph()
+	describers := s.describers()
+
+	var wg sync.WaitGroup
+	components := make([]ComponentView, len(names))
+	for i, name := range names {
+		components[i] = ComponentView{Name: name, Short: logging.ShortenComponent(name)}
+		describe, ok := describers[name]
+		if !ok {
+			components[i].Error = "component does not support Describe"
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			replicas, err := discoverReplicas(ctx, describe)
+			components[i].Replicas = replicas
+			if err != nil {
+				components[i].Error = err.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.callSamples.mu.Lock()
+	defer s.callSamples.mu.Unlock()
+	now := time.Now()
+	var interval time.Duration
+	if !s.callSamples.at.IsZero() {
+		interval = now.Sub(s.callSamples.at)
+	}
+	views, counts := edgeRates(components, edges, s.callSamples.counts, interval)
+	s.callSamples.at, s.callSamples.counts = now, counts
+	return Topology{
+		Groups:     groupComponents(components),
+		Components: components,
+		Edges:      views,
+	}
+}
+
+// discoverReplicas probes a component in rounds until a round finds no
+// replica that wasn't already known. It returns the replicas found, sorted by
+// process, and the last probe error, if any.
+func discoverReplicas(ctx context.Context, describe func(context.Context) (topology.Replica, error)) ([]topology.Replica, error) {
+	var mu sync.Mutex
+	var lastErr error
+	seen := map[string]topology.Replica{}
+	for probes := 0; probes < maxProbes && ctx.Err() == nil; {
+		round := max(minProbeRound, 2*len(seen))
+		round = min(round, maxProbes-probes)
+		probes += round
+
+		found := false
+		var wg sync.WaitGroup
+		for range round {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				replica, err := describe(ctx)
+				mu.Lock()
+				defer mu.Unlock()
+				if err != nil {
+					lastErr = err
+					return
+				}
+				if _, ok := seen[replica.Process()]; !ok {
+					seen[replica.Process()] = replica
+					found = true
+				}
+			}()
+		}
+		wg.Wait()
+		if !found {
+			break
+		}
+	}
+
+	replicas := make([]topology.Replica, 0, len(seen))
+	for _, r := range seen {
+		replicas = append(replicas, r)
+	}
+	sort.Slice(replicas, func(i, j int) bool { return replicas[i].Process() < replicas[j].Process() })
+	if len(replicas) > 0 {
+		// Some probes succeeded; a failed one doesn't hide the replicas.
+		return replicas, nil
+	}
+	return replicas, lastErr
+}
+
+// groupComponents assigns components to colocation groups. Components whose
+// replicas run in exactly the same set of processes are colocated.
+func groupComponents(components []ComponentView) []GroupView {
+	byProcesses := map[string][]int{}
+	var keys []string
+	for i, c := range components {
+		var procs []string
+		for _, r := range c.Replicas {
+			procs = append(procs, r.Process())
+		}
+		sort.Strings(procs)
+		key := strings.Join(procs, ",")
+		if _, ok := byProcesses[key]; !ok {
+			keys = append(keys, key)
+		}
+		byProcesses[key] = append(byProcesses[key], i)
+	}
+
+	var groups []GroupView
+	for _, key := range keys {
+		var members []string
+		for _, i := range byProcesses[key] {
+			members = append(members, components[i].Name)
+		}
+		group := GroupView{Name: topology.GroupName(members), Components: members}
+		if key == "" {
+			group.Name = "unknown"
+		} else {
+			group.Processes = strings.Count(key, ",") + 1
+		}
+		for _, i := range byProcesses[key] {
+			components[i].Group = group.Name
+		}
+		groups = append(groups, group)
+	}
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Name < groups[j].Name })
+	return groups
+}
+
+// edgeRates computes, for every static edge, the calls made along it by the
+// caller's replicas, and their rate since prev, the counts taken interval
+// ago, or never if interval is 0. It returns the edges and the counts of now, keyed by callKey. A
+// replica missing from prev, or whose count went down, isn't in the rate.
+func edgeRates(components []ComponentView, edges []topology.Edge, prev map[string]float64, interval time.Duration) ([]EdgeView, map[string]float64) {
+	byName := map[string]ComponentView{}
+	for _, c := range components {
+		byName[c.Name] = c
+	}
+
+	counts := map[string]float64{}
+	var views []EdgeView
+	for _, e := range edges {
+		view := EdgeView{Caller: logging.ShortenComponent(e.Caller), Callee: logging.ShortenComponent(e.Callee), Interval: interval.Seconds()}
+		for _, r := range byName[e.Caller].Replicas {
+			var count float64 // over the callee's methods
+			for _, call := range r.Calls {
+				if call.Component == e.Callee {
+					count += call.Count
+					view.Remote = view.Remote || call.Remote
+				}
+			}
+			view.Calls += count
+			key := callKey(r, e.Callee)
+			counts[key] = count
+			if before, ok := prev[key]; ok && count >= before && interval > 0 {
+				view.Rate += (count - before) / interval.Seconds()
+			}
+		}
+		views = append(views, view)
+	}
+	return views, counts
+}
+
+// topologyHandler serves the live topology as JSON.
+func (s *Server) topologyHandler(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.buildTopology(r.Context()))
+}
@@ -0,0 +1,55 @@
+package productpage
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+func TestDiscoverReplicas(t *testing.T) {
+	// A component with more replicas than a single round of probes, served
+	// round robin like Service Weaver balances unrouted calls.
+	const replicas = 10
+	var next atomic.Int64
+	describe := func(context.Context) (topology.Replica, error) {
+		pid := int(next.Add(1) % replicas)
+		return topology.Replica{Component: "c", Host: "node", PID: pid}, nil
+	}
+
+	got, err := discoverReplicas(context.Background(), describe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != replicas {
+		t.Fatalf("found %d replicas, want %d", len(got), replicas)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Process() >= got[i].Process() {
+			t.Errorf("replicas not sorted by process: %q before %q", got[i-1].Process(), got[i].Process())
+		}
+	}
+}
+
+func TestEdgeRates(t *testing.T) {
+	replica := func(pid int, count float64) topology.Replica {
+		return topology.Replica{Component: "a.A", Host: "node", PID: pid, Calls: []topology.Call{{Component: "b.B", Count: count}}}
+	}
+	edges := []topology.Edge{{Caller: "a.A", Callee: "b.B"}}
+	components := []ComponentView{{Name: "a.A", Replicas: []topology.Replica{replica(1, 100), replica(2, 50)}}}
+
+	views, counts := edgeRates(components, edges, nil, 0)
+	if len(views) != 1 || views[0].Calls != 150 || views[0].Rate != 0 || views[0].Interval != 0 {
+		t.Fatalf("first edgeRates() = %+v, want 150 calls and no rate", views)
+	}
+
+	// Ten seconds later, replica 1 made 20 more calls, replica 2 restarted
+	// and replica 3 started: only replica 1 is in the rate.
+	components[0].Replicas = []topology.Replica{replica(1, 120), replica(2, 10), replica(3, 500)}
+	views, _ = edgeRates(components, edges, counts, 10*time.Second)
+	if len(views) != 1 || views[0].Calls != 630 || views[0].Rate != 2 || views[0].Interval != 10 {
+		t.Errorf("edgeRates() 10s later = %+v, want 630 calls at 2 calls/s", views)
+	}
+}
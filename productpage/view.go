@@ -0,0 +1,124 @@
+package productpage
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+)
+
+// maxStars is the number of stars of the rating scale.
+const maxStars = 5
+
+// Messages shown when a component can't be reached, as in the original
+// Bookinfo product page.
+const (
+	detailsUnavailable = "Sorry, product details are currently unavailable for this book."
+	reviewsUnavailable = "Sorry, product reviews are currently unavailable for this book."
+)
+
+// IndexView is the data of the index.html template.
+type IndexView struct {
+	Topology Topology
+}
+
+// NavView is the data of the navigation bar, the nav template.
+type NavView struct {
+	User  string // signed in user, empty if none
+	Query string // in the search box
+}
+
+// ProductPageView is the data of the productpage.html template.
+type ProductPageView struct {
+	Nav     NavView
+	Product Product
+	Details DetailsView
+	Reviews ReviewsView
+	// Recommendations are shown as "You might also like"; none if they
+	// couldn't be fetched.
+	Recommendations []recommendations.Recommendation
+	// Price is shown under the title, with a choice of Currencies; nil if
+	// it couldn't be fetched.
+	Price      *pricing.Price
+	Currencies []string
+}
+
+// DetailsView is the book details section of the product page.
+type DetailsView struct {
+	Book  details.BookDetails
+	Error string // set if the details couldn't be fetched
+}
+
+// ReviewsView is the reviews section of the product page.
+type ReviewsView struct {
+	Reviews     []ReviewView
+	PodName     string // where the reviews were served
+	ClusterName string
+	Error       string // set if the reviews couldn't be fetched
+}
+
+// ReviewView is a single review. Stars and EmptyStars have one element per
+// full and empty star, for the template to range over.
+type ReviewView struct {
+	Reviewer    string
+	Text        string
+	Rated       bool   // the review has a rating to show
+	RatingError string // set if ratings were enabled but unavailable
+	Color       string // color of the stars
+	Stars       []int
+	EmptyStars  []int
+}
+
+// newReviewsView returns the reviews section for a reviews response.
+func newReviewsView(resp reviews.Response) ReviewsView {
+	view := ReviewsView{PodName: resp.PodName, ClusterName: resp.ClusterName}
+	for _, review := range resp.Reviews {
+		view.Reviews = append(view.Reviews, newReviewView(review))
+	}
+	return view
+}
+
+// newReviewView returns the view of a review. The reviews component rates
+// reviews with -1 stars, and the reason in the color, when ratings are
+// unavailable, and leaves the rating empty when ratings are disabled. Other
+// star counts are clamped to the rating scale.
+func newReviewView(review reviews.Review) ReviewView {
+	view := ReviewView{Reviewer: review.Reviewer, Text: review.Text}
+	switch rating := review.Rating; {
+	case rating == reviews.Rating{}:
+		// Ratings are disabled.
+	case rating.Stars < 0:
+		view.RatingError = rating.Color
+	default:
+		stars := min(rating.Stars, maxStars)
+		view.Rated = true
+		view.Color = rating.Color
+		view.Stars = makeSeq(stars)
+		view.EmptyStars = makeSeq(maxStars - stars)
+	}
+	return view
+}
+
+// render executes the template name with data and reports whether it
+// succeeded. The page is only written once it's fully rendered, so a template
+// error results in a clean error response instead of a truncated page.
+func (s *Server) render(w http.ResponseWriter, r *http.Request, name string, data any) bool {
+	return s.renderStatus(w, r, http.StatusOK, name, data)
+}
+
+// renderStatus is like render, but the page is served with the given status.
+func (s *Server) renderStatus(w http.ResponseWriter, r *http.Request, status int, name string, data any) bool {
+	var buf bytes.Buffer
+	if err := s.templates.ExecuteTemplate(&buf, name, data); err != nil {
+		s.logger(r.Context()).Error("Failed to render template", "template", name, "err", err)
+		writeProblem(w, r, http.StatusInternalServerError, "failed to render the page")
+		return false
+	}
+	w.Header().Set("Content-Type", "text/html")
+	w.WriteHeader(status)
+	w.Write(buf.Bytes())
+	return true
+}
@@ -0,0 +1,318 @@
+package productpage
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/cart"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/fakes"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/orders"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/pricing"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/search"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata/golden")
+
+// checkGolden compares got with the golden file name, or updates the file
+// if -update is set.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", "golden", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		gotPath := filepath.Join(t.TempDir(), name)
+		os.WriteFile(gotPath, got, 0644)
+		t.Errorf("%s doesn't match the golden file; got written to %s, run go test -update to accept it", name, gotPath)
+	}
+}
+
+func TestNewReviewView(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		rating reviews.Rating
+		want   ReviewView
+	}{
+		{
+			name: "ratings disabled",
+			want: ReviewView{},
+		},
+		{
+			name:   "ratings unavailable",
+			rating: reviews.Rating{Stars: -1, Color: "Ratings service is unavailable"},
+			want:   ReviewView{RatingError: "Ratings service is unavailable"},
+		},
+		{
+			name:   "no stars",
+			rating: reviews.Rating{Stars: 0, Color: "black"},
+			want:   ReviewView{Rated: true, Color: "black", Stars: []int{}, EmptyStars: []int{0, 1, 2, 3, 4}},
+		},
+		{
+			name:   "three stars",
+			rating: reviews.Rating{Stars: 3, Color: "red"},
+			want:   ReviewView{Rated: true, Color: "red", Stars: []int{0, 1, 2}, EmptyStars: []int{0, 1}},
+		},
+		{
+			name:   "above the scale",
+			rating: reviews.Rating{Stars: 7, Color: "black"},
+			want:   ReviewView{Rated: true, Color: "black", Stars: []int{0, 1, 2, 3, 4}, EmptyStars: []int{}},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			got := newReviewView(reviews.Review{Rating: test.rating})
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("newReviewView(%+v) = %+v, want %+v", test.rating, got, test.want)
+			}
+		})
+	}
+}
+
+// reviewsWith returns two reviews rated r1 and r2.
+func reviewsWith(r1, r2 reviews.Rating) []reviews.Review {
+	return []reviews.Review{
+		{Reviewer: "Reviewer1", Text: "An extremely entertaining play by Shakespeare. The slapstick humour is refreshing!", Rating: r1},
+		{Reviewer: "Reviewer2", Text: "Absolutely fun and entertaining. The play lacks thematic depth when compared to other plays by Shakespeare.", Rating: r2},
+	}
+}
+
+func TestProductPageGolden(t *testing.T) {
+	unavailable := reviews.Rating{Stars: -1, Color: "Ratings service is unavailable"}
+	for _, test := range []struct {
+		name       string
+		target     string // "/productpage" if empty
+		user       string
+		language   string // Accept-Language
+		setup      func(*fakes.Details, *fakes.Reviews)
+		pricingErr error
+	}{
+		{name: "normal"},
+		{name: "signed-in", user: "jason"},
+		{name: "discounted-in-euros", target: "/productpage?id=1", language: "de-DE"},
+		{name: "pricing-error", pricingErr: errors.New("pricing is down")},
+		{
+			name: "no-ratings",
+			setup: func(_ *fakes.Details, r *fakes.Reviews) {
+				r.SetReviews("1", reviewsWith(reviews.Rating{}, reviews.Rating{}))
+			},
+		},
+		{
+			name: "ratings-unavailable",
+			setup: func(_ *fakes.Details, r *fakes.Reviews) {
+				r.SetReviews("1", reviewsWith(unavailable, unavailable))
+			},
+		},
+		{
+			name: "stars-out-of-range",
+			setup: func(_ *fakes.Details, r *fakes.Reviews) {
+				r.SetReviews("1", reviewsWith(reviews.Rating{Stars: 9, Color: "red"}, reviews.Rating{Stars: 0, Color: "red"}))
+			},
+		},
+		{
+			name: "details-error",
+			setup: func(d *fakes.Details, _ *fakes.Reviews) {
+				d.FailWith("GetBookDetails", errors.New("details are down"))
+			},
+		},
+		{
+			name: "reviews-error",
+			setup: func(_ *fakes.Details, r *fakes.Reviews) {
+				r.FailWith("BookReviewsByID", errors.New("reviews are down"))
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fakeDetails, fakeReviews, fakePricing := fakes.NewDetails(), fakes.NewReviews(), fakes.NewPricing()
+			// The readers of product 0 liked 2 alike, 5 somewhat and 7
+			// the other way round.
+			fakeRecommendations := fakes.NewRecommendations()
+			fakeRecommendations.SetRatings(0, map[string]int{"Alice": 5, "Bob": 4, "Carol": 2})
+			fakeRecommendations.SetRatings(2, map[string]int{"Alice": 5, "Bob": 5, "Carol": 1})
+			fakeRecommendations.SetRatings(5, map[string]int{"Alice": 4, "Bob": 4, "Carol": 3})
+			fakeRecommendations.SetRatings(7, map[string]int{"Alice": 1, "Bob": 2, "Carol": 5})
+			if test.setup != nil {
+				test.setup(fakeDetails, fakeReviews)
+			}
+			fakePricing.FailWith("Price", test.pricingErr)
+			runner := weavertest.Local
+			runner.Fakes = append(runner.Fakes,
+				weavertest.Fake[details.Details](fakeDetails),
+				weavertest.Fake[reviews.Reviews](fakeReviews),
+				weavertest.Fake[pricing.Pricing](fakePricing),
+				weavertest.Fake[recommendations.Recommendations](fakeRecommendations))
+			runner.Test(t, func(t *testing.T, s *Server) {
+				target := test.target
+				if target == "" {
+					target = "/productpage"
+				}
+				req := httptest.NewRequest(http.MethodGet, target, nil)
+				if test.user != "" {
+					req.AddCookie(&http.Cookie{Name: userCookie, Value: test.user})
+				}
+				if test.language != "" {
+					req.Header.Set("Accept-Language", test.language)
+				}
+				rec := httptest.NewRecorder()
+				s.handler.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status %d, want %d; body %q", rec.Code, http.StatusOK, rec.Body)
+				}
+				checkGolden(t, "productpage-"+test.name+".html", rec.Body.Bytes())
+			})
+		})
+	}
+}
+
+func TestSearchPageGolden(t *testing.T) {
+	for _, test := range []struct {
+		name  string
+		query string
+		err   error // of the search component
+	}{
+		{name: "results", query: "shakespeare tragedy"},
+		{name: "no-query"},
+		{name: "no-results", query: "xyzzy"},
+		{name: "error", query: "tempest", err: errors.New("search is down")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fakeSearch := fakes.NewSearch()
+			fakeSearch.FailWith("Search", test.err)
+			runner := weavertest.Local
+			runner.Fakes = append(runner.Fakes, weavertest.Fake[search.Search](fakeSearch))
+			runner.Test(t, func(t *testing.T, s *Server) {
+				rec := httptest.NewRecorder()
+				s.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/search?q="+url.QueryEscape(test.query), nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status %d, want %d; body %q", rec.Code, http.StatusOK, rec.Body)
+				}
+				checkGolden(t, "search-"+test.name+".html", rec.Body.Bytes())
+			})
+		})
+	}
+}
+
+func TestBooksPageGolden(t *testing.T) {
+	for _, test := range []struct {
+		name                               string
+		path                               string
+		detailsErr, ratingsErr, reviewsErr error
+	}{
+		{name: "first", path: "/books"},
+		{name: "last", path: "/books?page=5"},
+		{name: "details-error", path: "/books", detailsErr: errors.New("details are down")},
+		{name: "ratings-error", path: "/books", ratingsErr: errors.New("ratings are down")},
+		{name: "reviews-error", path: "/books", reviewsErr: errors.New("reviews are down")},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			fakeDetails, fakeRatings, fakeReviews := fakes.NewDetails(), fakes.NewRatings(), fakes.NewReviews()
+			fakeDetails.FailWith("GetBookDetailsBatch", test.detailsErr)
+			fakeRatings.FailWith("GetRatingsBatch", test.ratingsErr)
+			fakeRatings.SetRatings(1, map[string]int{"Reviewer1": 5, "Reviewer2": 2})
+			fakeReviews.FailWith("ReviewCounts", test.reviewsErr)
+			runner := weavertest.Local
+			runner.Fakes = append(runner.Fakes,
+				weavertest.Fake[details.Details](fakeDetails),
+				weavertest.Fake[ratings.Ratings](fakeRatings),
+				weavertest.Fake[reviews.Reviews](fakeReviews))
+			runner.Test(t, func(t *testing.T, s *Server) {
+				rec := httptest.NewRecorder()
+				s.handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, test.path, nil))
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status %d, want %d; body %q", rec.Code, http.StatusOK, rec.Body)
+				}
+				checkGolden(t, "books-"+test.name+".html", rec.Body.Bytes())
+			})
+		})
+	}
+}
+
+func TestIndexGolden(t *testing.T) {
+	// The index page shows the live topology, so it's rendered from a fixed
+	// view rather than through the handler.
+	templates, err := template.ParseFS(embeddedFiles, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	replica := func(component, address string, pid int) topology.Replica {
+		return topology.Replica{Component: component, Host: "node-1", Address: address, PID: pid, Started: started}
+	}
+	view := IndexView{Topology: Topology{
+		Groups: []GroupView{
+			{Name: "pp", Components: []string{"weaver.Main"}, Processes: 1},
+			{Name: "details-ratings-reviews", Components: []string{"details.Details", "ratings.Ratings", "reviews.Reviews"}, Processes: 2},
+		},
+		Components: []ComponentView{
+			{Name: "weaver.Main", Short: "pp", Group: "pp", Replicas: []topology.Replica{replica("weaver.Main", "10.0.0.1:12345", 10)}},
+			{Name: "details.Details", Short: "details", Group: "details-ratings-reviews", Replicas: []topology.Replica{replica("details.Details", "10.0.0.2:9000", 20), replica("details.Details", "10.0.0.3:9000", 30)}},
+			{Name: "reviews.Reviews", Short: "reviews", Group: "details-ratings-reviews", Replicas: []topology.Replica{replica("reviews.Reviews", "10.0.0.2:9000", 20)}},
+			{Name: "ratings.Ratings", Short: "ratings", Group: "details-ratings-reviews", Error: "ratings are down"},
+		},
+		Edges: []EdgeView{
+			{Caller: "weaver.Main", Callee: "details.Details", Remote: true, Calls: 120, Rate: 2, Interval: 30},
+			{Caller: "reviews.Reviews", Callee: "ratings.Ratings", Calls: 60}, // not sampled before
+		},
+	}}
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "index.html", view); err != nil {
+		t.Fatal(err)
+	}
+	checkGolden(t, "index.html", buf.Bytes())
+}
+
+func TestCartPagesGolden(t *testing.T) {
+	// The cart page has a random idempotency key and orders have the time
+	// they were placed, so they're rendered from fixed views rather than
+	// through the handlers.
+	templates, err := template.ParseFS(embeddedFiles, "templates/*.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	items := []cart.Item{
+		{ProductID: 0, Title: "The Comedy of Errors", Quantity: 2},
+		{ProductID: 2, Title: "Hamlet", Quantity: 1},
+	}
+	contents := cart.Contents{ID: "c0ffee", Items: items, Quantity: 3}
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, test := range []struct {
+		name, template string
+		view           any
+	}{
+		{"cart.html", "cart.html", CartView{Cart: contents, Key: "4d1c7e0a"}},
+		{"cart-empty.html", "cart.html", CartView{Nav: NavView{User: "jason"}, Key: "4d1c7e0a"}},
+		{"cart-out-of-stock.html", "cart.html", CartView{Cart: contents, Key: "4d1c7e0a", Error: "Sorry, only 1 left of Hamlet."}},
+		{"order-placed.html", "order.html", OrderView{Order: orders.Order{ID: "0123456789abcdef", CartID: "c0ffee", Items: items, Quantity: 3, Status: orders.Placed, Created: created}}},
+		{"order-failed.html", "order.html", OrderView{Order: orders.Order{ID: "0123456789abcdef", CartID: "c0ffee", Items: items, Quantity: 3, Status: orders.Failed, Reason: "failed to commit the reservation: inventory is down", Created: created}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := templates.ExecuteTemplate(&buf, test.template, test.view); err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, test.name, buf.Bytes())
+		})
+	}
+}
@@ -16,7 +16,7 @@ func init() {
 		Name:      "github.com/ServiceWeaver/weaver/Main",
 		Iface:     reflect.TypeOf((*weaver.Main)(nil)).Elem(),
 		Impl:      reflect.TypeOf(Server{}),
-		Listeners: []string{"productpage"},
+		Listeners: []string{"details", "productpage", "ratings", "reviews"},
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
 			return main_local_stub{impl: impl.(weaver.Main), tracer: tracer}
 		},
@@ -27,7 +27,7 @@ func init() {
 		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
 			return main_reflect_stub{caller: caller}
 		},
-		RefData: "⟦d8b12067:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details⟧\n⟦8404e27b:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews⟧\n⟦685fa2bc:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings⟧\n⟦3f83a94b:wEaVeRlIsTeNeRs:github.com/ServiceWeaver/weaver/Main→productpage⟧\n",
+		RefData: "⟦d06c5d12:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog⟧\n⟦d8b12067:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/details/Details⟧\n⟦8404e27b:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews⟧\n⟦685fa2bc:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings⟧\n⟦b6c91e3c:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search⟧\n⟦06aa1d1a:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations⟧\n⟦f614f02c:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/cart/Cart⟧\n⟦b632c851:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/inventory/Inventory⟧\n⟦9f3ca51a:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/orders/Orders⟧\n⟦c9a9e739:wEaVeReDgE:github.com/ServiceWeaver/weaver/Main→github.com/camilamedeir0s/bookinfo-serviceweaver/pricing/Pricing⟧\n⟦e586c1a1:wEaVeRlIsTeNeRs:github.com/ServiceWeaver/weaver/Main→details,productpage,ratings,reviews⟧\n",
 	})
 }
 
@@ -0,0 +1,46 @@
+package ratings
+
+import "github.com/ServiceWeaver/weaver/metrics"
+
+// componentLabels rotulam as métricas com o nome completo do componente
+// Ratings, como nas métricas de métodos do Service Weaver.
+type componentLabels struct {
+	Component string
+}
+
+// postedLabels rotulam os ratings postados pelo número de estrelas.
+type postedLabels struct {
+	Component string
+	Stars     int
+}
+
+var (
+	ratingsPosted = metrics.NewCounterMap[postedLabels](
+		"bookinfo_ratings_posted",
+		"Ratings posted, by number of stars",
+	)
+	chaosUnavailable = metrics.NewGaugeMap[componentLabels](
+		"bookinfo_ratings_unavailable",
+		"1 while the v-unavailable or v-unhealthy versions make GetRatings fail",
+	)
+	chaosUnhealthy = metrics.NewGaugeMap[componentLabels](
+		"bookinfo_ratings_unhealthy",
+		"1 while the v-unhealthy version reports the component as unhealthy",
+	)
+)
+
+// setChaos alterna o estado de caos e atualiza as métricas correspondentes.
+func (r *ratings) setChaos(isUnavailable, isUnhealthy bool) {
+	unavailable.Store(isUnavailable)
+	healthy.Store(!isUnhealthy)
+	labels := componentLabels{Component: r.name}
+	chaosUnavailable.Get(labels).Set(boolGauge(isUnavailable))
+	chaosUnhealthy.Get(labels).Set(boolGauge(isUnhealthy))
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -5,12 +5,16 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -18,8 +22,9 @@ import (
 
 var (
 	userAddedRatings = make(map[int]map[string]int) // in-memory ratings
-	unavailable      = false
-	healthy          = true
+	userAddedMu      sync.Mutex                     // guarda userAddedRatings
+	unavailable      atomic.Bool
+	healthy          atomic.Bool
 	db               *sql.DB
 	mongoClient      *mongo.Client
 )
@@ -31,22 +36,55 @@ type RatingResponse struct {
 	Ratings map[string]int `json:"ratings"`
 }
 
+// HealthStatus descreve o estado do serviço de ratings.
+type HealthStatus struct {
+	weaver.AutoMarshal
+	Healthy  bool   `json:"healthy"`
+	Database string `json:"database"`
+}
+
+// Estados possíveis da conexão com o banco de dados.
+const (
+	DatabaseOK            = "ok"
+	DatabaseUnreachable   = "unreachable"
+	DatabaseNotConfigured = "not configured"
+	DatabaseUnknown       = "unknown" // o serviço original não informa o banco
+)
+
 type Ratings interface {
 	GetRatings(ctx context.Context, productId int) (RatingResponse, error)
+	// GetRatingsBatch retorna os ratings de vários produtos de um mesmo
+	// shard, por ID, numa única chamada; veja ShardSize. Para produtos de
+	// shards diferentes, use GetMany.
+	GetRatingsBatch(ctx context.Context, productIds []int) (map[int]RatingResponse, error)
 	PostRatings(ctx context.Context, productIdStr string, requestBody []byte) (RatingResponse, error)
+	Health(ctx context.Context) (HealthStatus, error)
+	Describe(ctx context.Context) (topology.Replica, error)
 }
 
 type ratings struct {
 	weaver.Implements[Ratings]
+	weaver.WithConfig[logging.Config]
+	weaver.WithRouter[router]
+	name  string     // nome completo do componente, usado nas métricas
+	level slog.Level // nível mínimo dos logs
 }
 
 // Função de inicialização para lidar com as variáveis de ambiente e configurar banco de dados
 func (r *ratings) Init(ctx context.Context) error {
+	r.name = topology.Name[Ratings]()
+	level, err := r.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	r.level = level
+	r.setChaos(false, false)
+
 	if os.Getenv("SERVICE_VERSION") == "v-unavailable" {
 		// make the service unavailable once in 60 seconds
 		go func() {
 			for {
-				unavailable = !unavailable
+				r.setChaos(!unavailable.Load(), false)
 				time.Sleep(60 * time.Second)
 			}
 		}()
@@ -56,8 +94,7 @@ func (r *ratings) Init(ctx context.Context) error {
 		// make the service unhealthy every 15 minutes
 		go func() {
 			for {
-				healthy = !healthy
-				unavailable = !unavailable
+				r.setChaos(!unavailable.Load(), healthy.Load())
 				time.Sleep(15 * time.Minute)
 			}
 		}()
@@ -67,7 +104,6 @@ func (r *ratings) Init(ctx context.Context) error {
 	if os.Getenv("SERVICE_VERSION") == "v2" {
 		dbType := os.Getenv("DB_TYPE")
 		if dbType == "mysql" {
-			var err error
 			host := os.Getenv("MYSQL_DB_HOST")
 			port := os.Getenv("MYSQL_DB_PORT")
 			user := os.Getenv("MYSQL_DB_USER")
@@ -76,18 +112,25 @@ func (r *ratings) Init(ctx context.Context) error {
 
 			db, err = sql.Open("mysql", dsn)
 			if err != nil {
-				log.Fatal("Could not connect to MySQL database:", err)
+				return fmt.Errorf("could not connect to MySQL database: %w", err)
 			}
 		}
 	}
 
+	r.logger(ctx).Info("Ratings component initialized", "version", os.Getenv("SERVICE_VERSION"), "db_type", os.Getenv("DB_TYPE"))
 	return nil
 }
 
+// logger retorna o logger do componente para a requisição em ctx.
+func (r *ratings) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, r.Logger(ctx), r.level)
+}
+
 // Função para obter ratings
 func (r *ratings) GetRatings(ctx context.Context, productId int) (RatingResponse, error) {
 	if os.Getenv("SERVICE_VERSION") == "v-unavailable" || os.Getenv("SERVICE_VERSION") == "v-unhealthy" {
-		if unavailable {
+		if unavailable.Load() {
+			r.logger(ctx).Warn("Ratings unavailable", "product_id", productId)
 			return RatingResponse{}, fmt.Errorf("service unavailable")
 		}
 	}
@@ -129,13 +172,15 @@ func (r *ratings) GetRatings(ctx context.Context, productId int) (RatingResponse
 		} else { // Conectar ao MongoDB
 			var err error
 			mongoURL := os.Getenv("MONGO_DB_URL")
+			logger := r.logger(ctx)
 
 			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 			defer cancel()
 
 			mongoClient, err = mongo.Connect(ctx, options.Client().ApplyURI(mongoURL))
 			if err != nil {
-				log.Fatal("Could not connect to MongoDB:", err)
+				logger.Error("Could not connect to MongoDB", "product_id", productId, "err", err)
+				return RatingResponse{}, fmt.Errorf("could not connect to ratings database")
 			}
 
 			collection := mongoClient.Database("test").Collection("ratings")
@@ -180,32 +225,121 @@ func (r *ratings) GetRatings(ctx context.Context, productId int) (RatingResponse
 	}
 }
 
-// Função para postar ratings
-func (r *ratings) PostRatings(ctx context.Context, productIdStr string, requestBody []byte) (RatingResponse, error) {
-	productId, err := strconv.Atoi(productIdStr)
+// GetRatingsBatch obtém os ratings de cada produto como GetRatings, numa
+// única chamada ao componente. Os produtos precisam ser do mesmo shard, cuja
+// réplica guarda as avaliações postadas para eles.
+func (r *ratings) GetRatingsBatch(ctx context.Context, productIds []int) (map[int]RatingResponse, error) {
+	if err := checkShard(productIds); err != nil {
+		return nil, err
+	}
+	responses := map[int]RatingResponse{}
+	for _, id := range productIds {
+		resp, err := r.GetRatings(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		responses[id] = resp
+	}
+	return responses, nil
+}
+
+// Health reporta o flag healthy e a conectividade com o banco de dados.
+func (r *ratings) Health(ctx context.Context) (HealthStatus, error) {
+	return HealthStatus{
+		Healthy:  healthy.Load(),
+		Database: checkDatabase(ctx),
+	}, nil
+}
+
+// Describe retorna a réplica do componente Ratings que atendeu a chamada.
+func (r *ratings) Describe(ctx context.Context) (topology.Replica, error) {
+	return topology.Self[Ratings](), nil
+}
+
+// checkDatabase verifica o banco de dados usado pela versão v2.
+func checkDatabase(ctx context.Context) string {
+	if os.Getenv("SERVICE_VERSION") != "v2" {
+		return DatabaseNotConfigured
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+
+	if os.Getenv("DB_TYPE") == "mysql" {
+		if db == nil || db.PingContext(ctx) != nil {
+			return DatabaseUnreachable
+		}
+		return DatabaseOK
+	}
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(os.Getenv("MONGO_DB_URL")))
 	if err != nil {
-		return RatingResponse{}, fmt.Errorf("please provide numeric product ID")
+		return DatabaseUnreachable
 	}
+	defer client.Disconnect(context.Background())
+	if err := client.Ping(ctx, nil); err != nil {
+		return DatabaseUnreachable
+	}
+	return DatabaseOK
+}
 
-	var ratings map[string]int
-	if err := json.Unmarshal(requestBody, &ratings); err != nil {
-		return RatingResponse{}, fmt.Errorf("please provide valid ratings JSON")
+// Função para postar ratings
+func (r *ratings) PostRatings(ctx context.Context, productIdStr string, requestBody []byte) (RatingResponse, error) {
+	productId, ratings, err := ParseRatings(productIdStr, requestBody)
+	if err != nil {
+		return RatingResponse{}, err
 	}
 
 	if os.Getenv("SERVICE_VERSION") == "v2" {
 		return RatingResponse{}, fmt.Errorf("Post not implemented for database backed ratings")
 	}
 
+	for _, stars := range ratings {
+		ratingsPosted.Get(postedLabels{Component: r.name, Stars: stars}).Inc()
+	}
+	r.logger(ctx).Info("Ratings posted", "product_id", productId, "ratings", len(ratings))
+
 	// Processar avaliações localmente e retornar o resultado
 	return putLocalReviews(productId, ratings), nil
 }
 
+// MaxStars é o número máximo de estrelas de uma avaliação.
+const MaxStars = 5
+
+// ParseRatings valida os argumentos de PostRatings e retorna o ID do produto e
+// as avaliações postadas, um mapa de revisor para estrelas, de 0 a MaxStars.
+// Estrelas fora da escala são rejeitadas, já que os reviews usam estrelas
+// negativas para indicar que as avaliações estão indisponíveis. O ID do
+// produto é um número sem sinal, sem zeros à esquerda, como os IDs aceitos
+// pela página de produto.
+func ParseRatings(productIdStr string, requestBody []byte) (int, map[string]int, error) {
+	productId, err := strconv.Atoi(productIdStr)
+	if err != nil || productId < 0 || strconv.Itoa(productId) != productIdStr {
+		return 0, nil, fmt.Errorf("please provide numeric product ID")
+	}
+
+	var ratings map[string]int
+	if err := json.Unmarshal(requestBody, &ratings); err != nil || ratings == nil {
+		return 0, nil, fmt.Errorf("please provide valid ratings JSON")
+	}
+	for reviewer, stars := range ratings {
+		if stars < 0 || stars > MaxStars {
+			return 0, nil, fmt.Errorf("rating of %q must be between 0 and %d stars", reviewer, MaxStars)
+		}
+	}
+	return productId, ratings, nil
+}
+
 func putLocalReviews(productId int, ratings map[string]int) RatingResponse {
+	userAddedMu.Lock()
 	userAddedRatings[productId] = ratings
+	userAddedMu.Unlock()
 	return getLocalReviews(productId)
 }
 
 func getLocalReviews(productId int) RatingResponse {
+	userAddedMu.Lock()
+	defer userAddedMu.Unlock()
 	if val, ok := userAddedRatings[productId]; ok {
 		return RatingResponse{ID: productId, Ratings: val}
 	}
@@ -0,0 +1,281 @@
+package ratings
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+	"testing/quick"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+)
+
+func TestGetRatings(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Ratings) {
+			got, err := r.GetRatings(context.Background(), 1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := RatingResponse{ID: 1, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetRatings(1) = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestGetRatingsBatch(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Ratings) {
+			ctx := context.Background()
+			got, err := r.GetRatingsBatch(ctx, []int{1, 2})
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[int]RatingResponse{
+				1: {ID: 1, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}},
+				2: {ID: 2, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetRatingsBatch(1, 2) = %+v, want %+v", got, want)
+			}
+			if got, err := r.GetRatingsBatch(ctx, []int{1, ShardSize}); err == nil {
+				t.Errorf("GetRatingsBatch(1, %d) = %+v, want an error for products of different shards", ShardSize, got)
+			}
+		})
+	}
+}
+
+func TestGetMany(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Ratings) {
+			ids := []int{1, 2, 1, ShardSize + 3}
+			got, err := GetMany(context.Background(), r, ids)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := map[int]RatingResponse{
+				1:             {ID: 1, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}},
+				2:             {ID: 2, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}},
+				ShardSize + 3: {ID: ShardSize + 3, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}},
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetMany(%v) = %+v, want %+v", ids, got, want)
+			}
+		})
+	}
+}
+
+// shardRatings é um Ratings cujo GetRatingsBatch falha para o shard bad e
+// conta as chamadas.
+type shardRatings struct {
+	Ratings
+	bad   int
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *shardRatings) GetRatingsBatch(ctx context.Context, productIds []int) (map[int]RatingResponse, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+	if err := checkShard(productIds); err != nil {
+		return nil, err
+	}
+	if Shard(productIds[0]) == s.bad {
+		return nil, errors.New("ratings are down")
+	}
+	responses := map[int]RatingResponse{}
+	for _, id := range productIds {
+		responses[id] = RatingResponse{ID: id}
+	}
+	return responses, nil
+}
+
+func TestGetManyShards(t *testing.T) {
+	shards := 3 * maxParallel
+	ids := make([]int, shards*ShardSize)
+	for i := range ids {
+		ids[i] = i
+	}
+	r := &shardRatings{bad: -1}
+	if got, err := GetMany(context.Background(), r, ids); err != nil || len(got) != len(ids) {
+		t.Errorf("GetMany(%d products) = %d ratings, %v, want %d", len(ids), len(got), err, len(ids))
+	}
+	if r.calls != shards {
+		t.Errorf("GetMany(%d products) made %d calls, want one per shard, %d", len(ids), r.calls, shards)
+	}
+
+	got, err := GetMany(context.Background(), &shardRatings{bad: maxParallel}, ids)
+	if err == nil || got != nil {
+		t.Errorf("GetMany() with a failing shard = %+v, %v, want an error", got, err)
+	}
+}
+
+func TestPostThenGetRatings(t *testing.T) {
+	// Posted ratings are kept in the memory of the replica that got them.
+	// The router sends the calls of a product to the same replica when the
+	// deployer assigns routes, as "weaver multi" does, but the Multi runner
+	// balances routed calls over its two replicas, so it isn't tested here.
+	for i, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, r Ratings) {
+			ctx := context.Background()
+			// Every runner posts to its own product, as the Local runner
+			// shares the ratings of the test process.
+			id := 100 + i
+			want := RatingResponse{ID: id, Ratings: map[string]int{"Reviewer1": 1, "Reviewer2": 3}}
+
+			posted, err := r.PostRatings(ctx, strconv.Itoa(id), []byte(`{"Reviewer1": 1, "Reviewer2": 3}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(posted, want) {
+				t.Errorf("PostRatings(%d) = %+v, want %+v", id, posted, want)
+			}
+
+			got, err := r.GetRatings(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetRatings(%d) after post = %+v, want %+v", id, got, want)
+			}
+		})
+	}
+}
+
+func TestPostRatingsErrors(t *testing.T) {
+	for _, test := range []struct {
+		name, id, body string
+	}{
+		{"non numeric ID", "one", `{"Reviewer1": 1}`},
+		{"invalid JSON", "1", `{"Reviewer1":`},
+		{"non numeric stars", "1", `{"Reviewer1": "five"}`},
+		{"null ratings", "1", `null`},
+		{"negative stars", "1", `{"Reviewer1": -1}`},
+		{"too many stars", "1", `{"Reviewer1": 6}`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			weavertest.Local.Test(t, func(t *testing.T, r Ratings) {
+				if _, err := r.PostRatings(context.Background(), test.id, []byte(test.body)); err == nil {
+					t.Errorf("PostRatings(%q, %q) succeeded, want error", test.id, test.body)
+				}
+			})
+		})
+	}
+}
+
+// TestPostGetRoundTrip checks that any valid ratings posted for a product are
+// returned by GetRatings, through a remote call too.
+func TestPostGetRoundTrip(t *testing.T) {
+	for i, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, r Ratings) {
+			ctx := context.Background()
+			roundTrip := func(n uint16, posted map[string]uint8) bool {
+				// Products of their own, see TestPostThenGetRatings.
+				id := 1000 + 100000*i + int(n)
+				want := RatingResponse{ID: id, Ratings: map[string]int{}}
+				for reviewer, stars := range posted {
+					want.Ratings[reviewer] = int(stars) % (MaxStars + 1)
+				}
+				body, err := json.Marshal(want.Ratings)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if _, err := r.PostRatings(ctx, strconv.Itoa(id), body); err != nil {
+					t.Logf("PostRatings(%d, %s): %v", id, body, err)
+					return false
+				}
+				got, err := r.GetRatings(ctx, id)
+				if err != nil {
+					t.Logf("GetRatings(%d): %v", id, err)
+					return false
+				}
+				if got.ID != want.ID || !maps.Equal(got.Ratings, want.Ratings) {
+					t.Logf("GetRatings(%d) after posting %s = %+v, want %+v", id, body, got, want)
+					return false
+				}
+				return true
+			}
+			if err := quick.Check(roundTrip, nil); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestParseRatingsProductID(t *testing.T) {
+	body := []byte(`{"Reviewer1": 5}`)
+	for _, s := range []string{"0", "1", "42"} {
+		if id, _, err := ParseRatings(s, body); err != nil || strconv.Itoa(id) != s {
+			t.Errorf("ParseRatings(%q) = %d, %v; want %s", s, id, err, s)
+		}
+	}
+	for _, s := range []string{"", "-3", "+3", "-0", "03", " 3", "3.0", "one", "99999999999999999999"} {
+		if id, _, err := ParseRatings(s, body); err == nil {
+			t.Errorf("ParseRatings(%q) = %d, want error", s, id)
+		}
+	}
+}
+
+func FuzzParseRatings(f *testing.F) {
+	f.Add("1", []byte(`{"Reviewer1": 5, "Reviewer2": 4}`))
+	f.Add("0", []byte(`{}`))
+	f.Add("-7", []byte(`{"": 0}`))
+	f.Add("+3", []byte(`{"Reviewer1": 1, "Reviewer1": 2}`))
+	f.Add("one", []byte(`{"Reviewer1": "five"}`))
+	f.Add("99999999999999999999", []byte(`null`))
+	f.Add("1", []byte(`{"Reviewer1": 6}`))
+	f.Add("1", []byte(`{"Reviewer1": 1e3}`))
+	f.Fuzz(func(t *testing.T, productIdStr string, body []byte) {
+		id, ratings, err := ParseRatings(productIdStr, body)
+		if err != nil {
+			return
+		}
+		if id < 0 || strconv.Itoa(id) != productIdStr {
+			t.Errorf("ParseRatings(%q): ID = %d, want the unsigned decimal ID", productIdStr, id)
+		}
+		if ratings == nil {
+			t.Fatalf("ParseRatings(%q): nil ratings", body)
+		}
+		for reviewer, stars := range ratings {
+			if stars < 0 || stars > MaxStars {
+				t.Errorf("ParseRatings(%q): %q has %d stars", body, reviewer, stars)
+			}
+		}
+
+		// The accepted ratings, encoded again, are accepted as they are.
+		again, err := json.Marshal(ratings)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, reparsed, err := ParseRatings(strconv.Itoa(id), again)
+		if err != nil {
+			t.Fatalf("ParseRatings(%q) failed on accepted ratings %q: %v", again, body, err)
+		}
+		if !maps.Equal(reparsed, ratings) {
+			t.Errorf("ParseRatings(%q) = %v, want %v", again, reparsed, ratings)
+		}
+	})
+}
+
+func TestHealth(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Ratings) {
+			got, err := r.Health(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := HealthStatus{Healthy: true, Database: DatabaseNotConfigured}
+			if got != want {
+				t.Errorf("Health() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package ratings
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"golang.org/x/sync/errgroup"
+)
+
+// remoteRatings chama um serviço de ratings do Bookinfo original, que atende
+// GET e POST /ratings/{id} e GET /health.
+type remoteRatings struct {
+	client *remote.Client
+}
+
+var _ Ratings = (*remoteRatings)(nil)
+
+// NewRemote retorna um Ratings que chama o serviço de ratings original em
+// baseURL, por exemplo "http://ratings:9080".
+func NewRemote(baseURL string) (Ratings, error) {
+	client, err := remote.NewClient(topology.Name[Ratings](), baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteRatings{client: client}, nil
+}
+
+// GetRatings implementa Ratings.
+func (r *remoteRatings) GetRatings(ctx context.Context, productId int) (RatingResponse, error) {
+	var resp RatingResponse
+	err := r.client.Call(ctx, "GetRatings", http.MethodGet, "/ratings/"+strconv.Itoa(productId), nil, nil, &resp)
+	return resp, err
+}
+
+// GetRatingsBatch implementa Ratings. O serviço original não tem uma API em
+// lote, então os ratings são pedidos um produto por chamada, em paralelo, no
+// máximo maxParallel por vez.
+func (r *remoteRatings) GetRatingsBatch(ctx context.Context, productIds []int) (map[int]RatingResponse, error) {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallel)
+	var mu sync.Mutex // protege responses
+	responses := map[int]RatingResponse{}
+	requested := map[int]bool{}
+	for _, id := range productIds {
+		if requested[id] {
+			continue
+		}
+		requested[id] = true
+		g.Go(func() error {
+			resp, err := r.GetRatings(ctx, id)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			responses[id] = resp
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
+
+// PostRatings implementa Ratings. Os argumentos são validados antes do envio,
+// como no componente.
+func (r *remoteRatings) PostRatings(ctx context.Context, productIdStr string, requestBody []byte) (RatingResponse, error) {
+	productId, _, err := ParseRatings(productIdStr, requestBody)
+	if err != nil {
+		return RatingResponse{}, err
+	}
+	var resp RatingResponse
+	err = r.client.Call(ctx, "PostRatings", http.MethodPost, "/ratings/"+strconv.Itoa(productId), requestBody, nil, &resp)
+	return resp, err
+}
+
+// Health implementa Ratings. O serviço original responde 500 quando não está
+// saudável e não informa o estado do banco.
+func (r *remoteRatings) Health(ctx context.Context) (HealthStatus, error) {
+	err := r.client.Call(ctx, "Health", http.MethodGet, "/health", nil, nil, nil)
+	var e *remote.Error
+	if errors.As(err, &e) && e.StatusCode == http.StatusInternalServerError {
+		return HealthStatus{Healthy: false, Database: DatabaseUnknown}, nil
+	}
+	if err != nil {
+		return HealthStatus{}, err
+	}
+	return HealthStatus{Healthy: true, Database: DatabaseUnknown}, nil
+}
+
+// Describe implementa Ratings.
+func (r *remoteRatings) Describe(context.Context) (topology.Replica, error) {
+	return r.client.Describe(), nil
+}
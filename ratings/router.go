@@ -0,0 +1,106 @@
+package ratings
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ShardSize é o número de produtos de IDs consecutivos de um shard. As
+// chamadas de um produto vão para a réplica do seu shard, já que as
+// avaliações postadas ficam em memória, e um GetRatingsBatch pede os ratings
+// de um shard inteiro numa única chamada. Uma página de /books, com o mesmo
+// número de livros, costuma ser um único shard.
+const ShardSize = 20
+
+// Shard retorna o shard do produto productId.
+func Shard(productId int) int {
+	if productId < 0 {
+		return -1 // IDs negativos são rejeitados; qualquer réplica serve
+	}
+	return productId / ShardSize
+}
+
+// router envia as chamadas de um produto sempre para a réplica do seu shard.
+// Assim um GetRatings enxerga o que um PostRatings anterior gravou, mesmo com
+// várias réplicas.
+type router struct{}
+
+func (router) GetRatings(_ context.Context, productId int) int {
+	return Shard(productId)
+}
+
+func (router) GetRatingsBatch(_ context.Context, productIds []int) int {
+	if len(productIds) == 0 {
+		return 0
+	}
+	return Shard(productIds[0])
+}
+
+func (router) PostRatings(_ context.Context, productIdStr string, _ []byte) int {
+	// IDs inválidos são rejeitados por PostRatings; qualquer réplica serve.
+	productId, _ := strconv.Atoi(productIdStr)
+	return Shard(productId)
+}
+
+// checkShard retorna um erro se os produtos productIds não forem todos do
+// mesmo shard.
+func checkShard(productIds []int) error {
+	for _, id := range productIds {
+		if Shard(id) != Shard(productIds[0]) {
+			return fmt.Errorf("products %d and %d are in different shards", productIds[0], id)
+		}
+	}
+	return nil
+}
+
+// maxParallel é o número máximo de chamadas GetRatingsBatch em andamento num
+// GetMany, e de chamadas ao serviço original num GetRatingsBatch remoto.
+const maxParallel = 8
+
+// GetMany obtém os ratings de vários produtos, por ID, com uma chamada
+// GetRatingsBatch por shard, que o router envia à réplica do shard. As
+// chamadas são feitas em paralelo, no máximo maxParallel por vez. Se alguma
+// falhar, as demais são canceladas e o primeiro erro é retornado.
+func GetMany(ctx context.Context, r Ratings, productIds []int) (map[int]RatingResponse, error) {
+	var shards []int
+	byShard := map[int][]int{}
+	requested := map[int]bool{}
+	for _, id := range productIds {
+		if requested[id] {
+			continue
+		}
+		requested[id] = true
+		shard := Shard(id)
+		if _, ok := byShard[shard]; !ok {
+			shards = append(shards, shard)
+		}
+		byShard[shard] = append(byShard[shard], id)
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(maxParallel)
+	var mu sync.Mutex // protege responses
+	responses := map[int]RatingResponse{}
+	for _, shard := range shards {
+		g.Go(func() error {
+			batch, err := r.GetRatingsBatch(ctx, byShard[shard])
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for id, resp := range batch {
+				responses[id] = resp
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return responses, nil
+}
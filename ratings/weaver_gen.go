@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"reflect"
@@ -16,14 +17,15 @@ import (
 
 func init() {
 	codegen.Register(codegen.Registration{
-		Name:  "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings",
-		Iface: reflect.TypeOf((*Ratings)(nil)).Elem(),
-		Impl:  reflect.TypeOf(ratings{}),
+		Name:   "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings",
+		Iface:  reflect.TypeOf((*Ratings)(nil)).Elem(),
+		Impl:   reflect.TypeOf(ratings{}),
+		Routed: true,
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return ratings_local_stub{impl: impl.(Ratings), tracer: tracer, getRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "GetRatings", Remote: false, Generated: true}), postRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "PostRatings", Remote: false, Generated: true})}
+			return ratings_local_stub{impl: impl.(Ratings), tracer: tracer, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "Describe", Remote: false, Generated: true}), getRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "GetRatings", Remote: false, Generated: true}), getRatingsBatchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "GetRatingsBatch", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "Health", Remote: false, Generated: true}), postRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "PostRatings", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return ratings_client_stub{stub: stub, getRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "GetRatings", Remote: true, Generated: true}), postRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "PostRatings", Remote: true, Generated: true})}
+			return ratings_client_stub{stub: stub, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "Describe", Remote: true, Generated: true}), getRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "GetRatings", Remote: true, Generated: true}), getRatingsBatchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "GetRatingsBatch", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "Health", Remote: true, Generated: true}), postRatingsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings", Method: "PostRatings", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return ratings_server_stub{impl: impl.(Ratings), addLoad: addLoad}
@@ -39,20 +41,60 @@ func init() {
 var _ weaver.InstanceOf[Ratings] = (*ratings)(nil)
 
 // weaver.Router checks.
-var _ weaver.Unrouted = (*ratings)(nil)
+var _ weaver.RoutedBy[router] = (*ratings)(nil)
+
+// Component "ratings", router "router" checks.
+type __ratings_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate struct {
+	router
+	__ratings_router_embedding
+}
+
+type __ratings_router_embedding struct{}
+
+func (__ratings_router_embedding) Describe() {}
+func (__ratings_router_embedding) Health()   {}
+
+var _ func(_ context.Context, productId int) int = (&router{}).GetRatings                              // routed
+var _ func(_ context.Context, productIds []int) int = (&router{}).GetRatingsBatch                      // routed
+var _ func(_ context.Context, productIdStr string, _ []byte) int = (&router{}).PostRatings             // routed
+var _ = (&__ratings_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Describe // unrouted
+var _ = (&__ratings_router_if_youre_seeing_this_you_probably_forgot_to_run_weaver_generate{}).Health   // unrouted
 
 // Local stub implementations.
 
 type ratings_local_stub struct {
-	impl               Ratings
-	tracer             trace.Tracer
-	getRatingsMetrics  *codegen.MethodMetrics
-	postRatingsMetrics *codegen.MethodMetrics
+	impl                   Ratings
+	tracer                 trace.Tracer
+	describeMetrics        *codegen.MethodMetrics
+	getRatingsMetrics      *codegen.MethodMetrics
+	getRatingsBatchMetrics *codegen.MethodMetrics
+	healthMetrics          *codegen.MethodMetrics
+	postRatingsMetrics     *codegen.MethodMetrics
 }
 
 // Check that ratings_local_stub implements the Ratings interface.
 var _ Ratings = (*ratings_local_stub)(nil)
 
+func (s ratings_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "ratings.Ratings.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
 func (s ratings_local_stub) GetRatings(ctx context.Context, a0 int) (r0 RatingResponse, err error) {
 	// Update metrics.
 	begin := s.getRatingsMetrics.Begin()
@@ -73,6 +115,46 @@ func (s ratings_local_stub) GetRatings(ctx context.Context, a0 int) (r0 RatingRe
 	return s.impl.GetRatings(ctx, a0)
 }
 
+func (s ratings_local_stub) GetRatingsBatch(ctx context.Context, a0 []int) (r0 map[int]RatingResponse, err error) {
+	// Update metrics.
+	begin := s.getRatingsBatchMetrics.Begin()
+	defer func() { s.getRatingsBatchMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "ratings.Ratings.GetRatingsBatch", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.GetRatingsBatch(ctx, a0)
+}
+
+func (s ratings_local_stub) Health(ctx context.Context) (r0 HealthStatus, err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "ratings.Ratings.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
 func (s ratings_local_stub) PostRatings(ctx context.Context, a0 string, a1 []byte) (r0 RatingResponse, err error) {
 	// Update metrics.
 	begin := s.postRatingsMetrics.Begin()
@@ -96,14 +178,64 @@ func (s ratings_local_stub) PostRatings(ctx context.Context, a0 string, a1 []byt
 // Client stub implementations.
 
 type ratings_client_stub struct {
-	stub               codegen.Stub
-	getRatingsMetrics  *codegen.MethodMetrics
-	postRatingsMetrics *codegen.MethodMetrics
+	stub                   codegen.Stub
+	describeMetrics        *codegen.MethodMetrics
+	getRatingsMetrics      *codegen.MethodMetrics
+	getRatingsBatchMetrics *codegen.MethodMetrics
+	healthMetrics          *codegen.MethodMetrics
+	postRatingsMetrics     *codegen.MethodMetrics
 }
 
 // Check that ratings_client_stub implements the Ratings interface.
 var _ Ratings = (*ratings_client_stub)(nil)
 
+func (s ratings_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "ratings.Ratings.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
 func (s ratings_client_stub) GetRatings(ctx context.Context, a0 int) (r0 RatingResponse, err error) {
 	// Update metrics.
 	var requestBytes, replyBytes int
@@ -141,12 +273,121 @@ func (s ratings_client_stub) GetRatings(ctx context.Context, a0 int) (r0 RatingR
 
 	// Encode arguments.
 	enc.Int(a0)
-	var shardKey uint64
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashRatings(r.GetRatings(ctx, a0))
 
 	// Call the remote method.
 	requestBytes = len(enc.Data())
 	var results []byte
-	results, err = s.stub.Run(ctx, 0, enc.Data(), shardKey)
+	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s ratings_client_stub) GetRatingsBatch(ctx context.Context, a0 []int) (r0 map[int]RatingResponse, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.getRatingsBatchMetrics.Begin()
+	defer func() { s.getRatingsBatchMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "ratings.Ratings.GetRatingsBatch", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 8))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_int_7c8c8866(enc, a0)
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashRatings(r.GetRatingsBatch(ctx, a0))
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_map_int_RatingResponse_36a2419b(dec)
+	err = dec.Error()
+	return
+}
+
+func (s ratings_client_stub) Health(ctx context.Context) (r0 HealthStatus, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "ratings.Ratings.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, nil, shardKey)
 	replyBytes = len(results)
 	if err != nil {
 		err = errors.Join(weaver.RemoteCallError, err)
@@ -199,12 +440,15 @@ func (s ratings_client_stub) PostRatings(ctx context.Context, a0 string, a1 []by
 	// Encode arguments.
 	enc.String(a0)
 	serviceweaver_enc_slice_byte_87461245(enc, a1)
-	var shardKey uint64
+
+	// Set the shardKey.
+	var r router
+	shardKey := _hashRatings(r.PostRatings(ctx, a0, a1))
 
 	// Call the remote method.
 	requestBytes = len(enc.Data())
 	var results []byte
-	results, err = s.stub.Run(ctx, 1, enc.Data(), shardKey)
+	results, err = s.stub.Run(ctx, 4, enc.Data(), shardKey)
 	replyBytes = len(results)
 	if err != nil {
 		err = errors.Join(weaver.RemoteCallError, err)
@@ -254,8 +498,14 @@ var _ codegen.Server = (*ratings_server_stub)(nil)
 // GetStubFn implements the codegen.Server interface.
 func (s ratings_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
 	switch method {
+	case "Describe":
+		return s.describe
 	case "GetRatings":
 		return s.getRatings
+	case "GetRatingsBatch":
+		return s.getRatingsBatch
+	case "Health":
+		return s.health
 	case "PostRatings":
 		return s.postRatings
 	default:
@@ -263,6 +513,26 @@ func (s ratings_server_stub) GetStubFn(method string) func(ctx context.Context,
 	}
 }
 
+func (s ratings_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 func (s ratings_server_stub) getRatings(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
@@ -275,6 +545,8 @@ func (s ratings_server_stub) getRatings(ctx context.Context, args []byte) (res [
 	dec := codegen.NewDecoder(args)
 	var a0 int
 	a0 = dec.Int()
+	var r router
+	s.addLoad(_hashRatings(r.GetRatings(ctx, a0)), 1.0)
 
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
@@ -288,6 +560,53 @@ func (s ratings_server_stub) getRatings(ctx context.Context, args []byte) (res [
 	return enc.Data(), nil
 }
 
+func (s ratings_server_stub) getRatingsBatch(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []int
+	a0 = serviceweaver_dec_slice_int_7c8c8866(dec)
+	var r router
+	s.addLoad(_hashRatings(r.GetRatingsBatch(ctx, a0)), 1.0)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.GetRatingsBatch(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_map_int_RatingResponse_36a2419b(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s ratings_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 func (s ratings_server_stub) postRatings(ctx context.Context, args []byte) (res []byte, err error) {
 	// Catch and return any panics detected during encoding/decoding/rpc.
 	defer func() {
@@ -302,6 +621,8 @@ func (s ratings_server_stub) postRatings(ctx context.Context, args []byte) (res
 	a0 = dec.String()
 	var a1 []byte
 	a1 = serviceweaver_dec_slice_byte_87461245(dec)
+	var r router
+	s.addLoad(_hashRatings(r.PostRatings(ctx, a0, a1)), 1.0)
 
 	// TODO(rgrandl): The deferred function above will recover from panics in the
 	// user code: fix this.
@@ -324,11 +645,26 @@ type ratings_reflect_stub struct {
 // Check that ratings_reflect_stub implements the Ratings interface.
 var _ Ratings = (*ratings_reflect_stub)(nil)
 
+func (s ratings_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
 func (s ratings_reflect_stub) GetRatings(ctx context.Context, a0 int) (r0 RatingResponse, err error) {
 	err = s.caller("GetRatings", ctx, []any{a0}, []any{&r0})
 	return
 }
 
+func (s ratings_reflect_stub) GetRatingsBatch(ctx context.Context, a0 []int) (r0 map[int]RatingResponse, err error) {
+	err = s.caller("GetRatingsBatch", ctx, []any{a0}, []any{&r0})
+	return
+}
+
+func (s ratings_reflect_stub) Health(ctx context.Context) (r0 HealthStatus, err error) {
+	err = s.caller("Health", ctx, []any{}, []any{&r0})
+	return
+}
+
 func (s ratings_reflect_stub) PostRatings(ctx context.Context, a0 string, a1 []byte) (r0 RatingResponse, err error) {
 	err = s.caller("PostRatings", ctx, []any{a0, a1}, []any{&r0})
 	return
@@ -336,6 +672,32 @@ func (s ratings_reflect_stub) PostRatings(ctx context.Context, a0 string, a1 []b
 
 // AutoMarshal implementations.
 
+var _ codegen.AutoMarshal = (*HealthStatus)(nil)
+
+type __is_HealthStatus[T ~struct {
+	weaver.AutoMarshal
+	Healthy  bool   "json:\"healthy\""
+	Database string "json:\"database\""
+}] struct{}
+
+var _ __is_HealthStatus[HealthStatus]
+
+func (x *HealthStatus) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("HealthStatus.WeaverMarshal: nil receiver"))
+	}
+	enc.Bool(x.Healthy)
+	enc.String(x.Database)
+}
+
+func (x *HealthStatus) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("HealthStatus.WeaverUnmarshal: nil receiver"))
+	}
+	x.Healthy = dec.Bool()
+	x.Database = dec.String()
+}
+
 var _ codegen.AutoMarshal = (*RatingResponse)(nil)
 
 type __is_RatingResponse[T ~struct {
@@ -390,8 +752,75 @@ func serviceweaver_dec_map_string_int_c20ee031(dec *codegen.Decoder) map[string]
 	return res
 }
 
+// Router methods.
+
+// _hashRatings returns a 64 bit hash of the provided value.
+func _hashRatings(r int) uint64 {
+	var h codegen.Hasher
+	h.WriteInt(int(r))
+	return h.Sum64()
+}
+
+// _orderedCodeRatings returns an order-preserving serialization of the provided value.
+func _orderedCodeRatings(r int) codegen.OrderedCode {
+	var enc codegen.OrderedEncoder
+	enc.WriteInt(int(r))
+	return enc.Encode()
+}
+
 // Encoding/decoding implementations.
 
+func serviceweaver_enc_slice_int_7c8c8866(enc *codegen.Encoder, arg []int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Int(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]int, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Int()
+	}
+	return res
+}
+
+func serviceweaver_enc_map_int_RatingResponse_36a2419b(enc *codegen.Encoder, arg map[int]RatingResponse) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for k, v := range arg {
+		enc.Int(k)
+		(v).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_map_int_RatingResponse_36a2419b(dec *codegen.Decoder) map[int]RatingResponse {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make(map[int]RatingResponse, n)
+	var k int
+	var v RatingResponse
+	for i := 0; i < n; i++ {
+		k = dec.Int()
+		(&v).WeaverUnmarshal(dec)
+		res[k] = v
+	}
+	return res
+}
+
 func serviceweaver_enc_slice_byte_87461245(enc *codegen.Encoder, arg []byte) {
 	if arg == nil {
 		enc.Len(-1)
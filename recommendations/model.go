@@ -0,0 +1,125 @@
+package recommendations
+
+import (
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+// maxNeighbors is the number of most similar products a model keeps for
+// every product, and so the most Recommend returns.
+const maxNeighbors = 50
+
+// Model holds the products most similar to every product, by the ratings
+// their readers gave them.
+type Model struct {
+	neighbors map[int][]Recommendation // by product ID, best first
+}
+
+// NewModel computes the model of products from ratings, the stars given by
+// every reader to the products, by product ID. Ratings of products that
+// aren't among products are left out. A rating of 0 stars is a rating, the
+// strongest dislike, like it is for ratings.ParseRatings and the reviews.
+//
+// Two products are as similar as the adjusted cosine of their ratings: the
+// cosine of their vectors of stars, one element per reader who rated both,
+// less the mean stars of the reader. Centering on the reader's mean makes a
+// reader who rates everything 5 stars count as much as one who rates
+// everything 2, and compares how each reader liked the products relative to
+// the rest, so products are similar when the readers who rated both liked
+// or disliked them alike. Only products more similar than 0 are kept, so
+// products with no reader in common, or rated alike only by readers who
+// give every product the same stars, aren't similar.
+func NewModel(products []catalog.Product, ratings map[int]map[string]int) *Model {
+	// The rated products and, per reader, their stars.
+	var rated []catalog.Product
+	type rating struct {
+		product int // index in rated
+		stars   float64
+	}
+	byReader := map[string][]rating{}
+	for _, p := range products {
+		if len(ratings[p.ID]) == 0 {
+			continue
+		}
+		for reader, stars := range ratings[p.ID] {
+			byReader[reader] = append(byReader[reader], rating{len(rated), float64(stars)})
+		}
+		rated = append(rated, p)
+	}
+
+	// The dot products of the centered stars of every pair of rated
+	// products, the squared norms of the first product's stars, and their
+	// common readers.
+	n := len(rated)
+	dots := make([]float64, n*n)
+	norms := make([]float64, n*n)
+	common := make([]int, n*n)
+	for _, rs := range byReader {
+		var mean float64
+		for _, r := range rs {
+			mean += r.stars
+		}
+		mean /= float64(len(rs))
+		for _, a := range rs {
+			for _, b := range rs {
+				if a.product != b.product {
+					dots[a.product*n+b.product] += (a.stars - mean) * (b.stars - mean)
+					norms[a.product*n+b.product] += (a.stars - mean) * (a.stars - mean)
+					common[a.product*n+b.product]++
+				}
+			}
+		}
+	}
+
+	m := &Model{neighbors: map[int][]Recommendation{}}
+	for i, p := range rated {
+		var recs []Recommendation
+		for j, q := range rated {
+			// The norm of q's stars over the common readers is the one of
+			// the pair (j, i).
+			norm := math.Sqrt(norms[i*n+j] * norms[j*n+i])
+			if common[i*n+j] == 0 || norm == 0 {
+				continue
+			}
+			score := math.Round(dots[i*n+j]/norm*1000) / 1000
+			if score <= 0 {
+				continue
+			}
+			recs = append(recs, Recommendation{
+				ProductID: q.ID,
+				Title:     q.Title,
+				Author:    q.Author,
+				Score:     score,
+				Readers:   common[i*n+j],
+			})
+		}
+		sort.Slice(recs, func(a, b int) bool {
+			if recs[a].Score != recs[b].Score {
+				return recs[a].Score > recs[b].Score
+			}
+			if recs[a].Readers != recs[b].Readers {
+				return recs[a].Readers > recs[b].Readers
+			}
+			return recs[a].ProductID < recs[b].ProductID
+		})
+		if len(recs) > 0 {
+			m.neighbors[p.ID] = recs[:min(len(recs), maxNeighbors)]
+		}
+	}
+	return m
+}
+
+// Len returns the number of products with recommendations.
+func (m *Model) Len() int {
+	return len(m.neighbors)
+}
+
+// Recommend returns the n products most similar to the product with the
+// given ID, best first, or fewer if there aren't as many.
+func (m *Model) Recommend(id, n int) []Recommendation {
+	recs := m.neighbors[id]
+	return slices.Clone(recs[:max(0, min(n, len(recs)))])
+}
@@ -0,0 +1,164 @@
+package recommendations
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+var testProducts = []catalog.Product{
+	{ID: 1, Title: "The Tempest", Author: "William Shakespeare"},
+	{ID: 2, Title: "Hamlet", Author: "William Shakespeare"},
+	{ID: 3, Title: "Volpone", Author: "Ben Jonson"},
+	{ID: 4, Title: "The Alchemist", Author: "Ben Jonson"},
+	{ID: 5, Title: "Doctor Faustus", Author: "Christopher Marlowe"},
+	{ID: 6, Title: "Tamburlaine", Author: "Christopher Marlowe"},
+}
+
+var testRatings = map[int]map[string]int{
+	1: {"alice": 5, "bob": 4, "carol": 1},
+	2: {"alice": 5, "bob": 4, "carol": 1}, // rated like 1
+	// Liked less than 1 by alice and bob, and more by carol, relative to
+	// the other products they rated, though its stars are as high.
+	3: {"alice": 4, "bob": 3, "carol": 5},
+	4: {"alice": 4, "carol": 2, "dave": 5}, // dave rated nothing else
+	// 5 isn't rated.
+	6: {"alice": 0}, // disliked, which lowers alice's mean
+	9: {"alice": 5}, // not in the catalog
+}
+
+// ids returns the product IDs of recs.
+func ids(recs []Recommendation) string {
+	var ids []int
+	for _, rec := range recs {
+		ids = append(ids, rec.ProductID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestModelRecommend(t *testing.T) {
+	m := NewModel(testProducts, testRatings)
+	for _, test := range []struct {
+		id, n int
+		want  string
+	}{
+		{1, 10, "[2 4]"}, // most similar first; 3 is dissimilar
+		{1, 1, "[2]"},
+		{1, 0, "[]"},
+		{1, -1, "[]"},
+		{3, 10, "[]"},    // dissimilar to every product
+		{4, 10, "[1 2]"}, // equally similar, by ID
+		{5, 10, "[]"},    // no ratings
+		{6, 10, "[]"},    // only disliked
+		{9, 10, "[]"},    // not in the catalog
+		{7, 10, "[]"},
+	} {
+		if got := ids(m.Recommend(test.id, test.n)); got != test.want {
+			t.Errorf("Recommend(%d, %d) = %s, want %s", test.id, test.n, got, test.want)
+		}
+	}
+	if got, want := m.Len(), 3; got != want {
+		t.Errorf("Len() = %d, want %d", got, want)
+	}
+}
+
+func TestModelScores(t *testing.T) {
+	m := NewModel(testProducts, testRatings)
+	want := []Recommendation{
+		{ProductID: 2, Title: "Hamlet", Author: "William Shakespeare", Score: 1, Readers: 3},
+		{ProductID: 4, Title: "The Alchemist", Author: "Ben Jonson", Score: 0.986, Readers: 2},
+	}
+	got := m.Recommend(1, 10)
+	if len(got) != len(want) {
+		t.Fatalf("Recommend(1, 10) = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Recommend(1, 10)[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	// The model isn't changed through the recommendations it returns.
+	got[0].Score = 0
+	if again := m.Recommend(1, 1); again[0].Score != 1 {
+		t.Errorf("Recommend(1, 1) after changing a result = %+v, want a score of 1", again)
+	}
+}
+
+func TestModelNeighbors(t *testing.T) {
+	var products []catalog.Product
+	ratings := map[int]map[string]int{}
+	for id := range 3 * maxNeighbors {
+		// The even products are liked alike, and so similar to product 0.
+		stars := 1
+		if id%2 == 0 {
+			stars = 5
+		}
+		products = append(products, catalog.Product{ID: id})
+		ratings[id] = map[string]int{"alice": stars, "bob": stars}
+	}
+	m := NewModel(products, ratings)
+	if got := len(m.Recommend(0, 3*maxNeighbors)); got != maxNeighbors {
+		t.Errorf("got %d recommendations, want at most %d", got, maxNeighbors)
+	}
+}
+
+func TestModelCentersOnReaders(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		ratings map[int]map[string]int
+		want    string
+	}{
+		{
+			// A reader who gives every product the same stars tells
+			// nothing about which are alike.
+			name: "same stars",
+			ratings: map[int]map[string]int{
+				1: {"alice": 5, "bob": 2},
+				2: {"alice": 5, "bob": 2},
+				3: {"alice": 5, "bob": 2},
+			},
+			want: "[]",
+		},
+		{
+			// bob rates lower than alice, but both liked 2 more than 3.
+			name: "harsh reader",
+			ratings: map[int]map[string]int{
+				1: {"alice": 5, "bob": 3},
+				2: {"alice": 5, "bob": 3},
+				3: {"alice": 3, "bob": 1},
+			},
+			want: "[2]",
+		},
+		{
+			// The readers disagree on 2 and agree on 3.
+			name: "disagreement",
+			ratings: map[int]map[string]int{
+				1: {"alice": 5, "bob": 5, "carol": 1},
+				2: {"alice": 5, "bob": 1, "carol": 5},
+				3: {"alice": 5, "bob": 4, "carol": 1},
+				4: {"alice": 1, "bob": 2, "carol": 5},
+			},
+			want: "[3]",
+		},
+		{
+			// alice disliked 4 with no stars, so she liked 2 more than
+			// most of what she rated, like bob did; without the rating of
+			// 4, 2 would be below her mean and dissimilar to 1.
+			name: "no stars",
+			ratings: map[int]map[string]int{
+				1: {"alice": 5, "bob": 4},
+				2: {"alice": 3, "bob": 4},
+				3: {"bob": 2},
+				4: {"alice": 0},
+			},
+			want: "[2]",
+		},
+	} {
+		m := NewModel(testProducts, test.ratings)
+		if got := ids(m.Recommend(1, 10)); got != test.want {
+			t.Errorf("%s: Recommend(1, 10) = %s, want %s", test.name, got, test.want)
+		}
+	}
+}
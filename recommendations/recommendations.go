@@ -0,0 +1,186 @@
+// Package recommendations implements the Recommendations component, which
+// suggests the products that the readers who liked a product also liked.
+//
+// It compares the products by the ratings their readers gave them, an
+// item-item collaborative filter, and keeps the products most similar to
+// every product in a model. The model is computed from the products of the
+// catalog and their ratings in the background, so there are no
+// recommendations until the first one is. It's computed when the component
+// starts and then every refresh interval, so recommendations are served from
+// memory and follow new ratings within an interval:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations"]
+//	refresh_interval = "30s"
+package recommendations
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+const (
+	// defaultRefreshInterval is how often the model is recomputed when the
+	// config doesn't say.
+	defaultRefreshInterval = time.Minute
+
+	// refreshTimeout bounds a background recomputation of the model.
+	refreshTimeout = 30 * time.Second
+)
+
+// Recommendation is a product recommended for another.
+type Recommendation struct {
+	weaver.AutoMarshal
+	ProductID int    `json:"product_id"`
+	Title     string `json:"title"`
+	Author    string `json:"author"`
+	// Score is the similarity of the ratings of the two products, their
+	// adjusted cosine, above 0 and at most 1; higher is more similar.
+	Score float64 `json:"score"`
+	// Readers is the number of readers who rated both products.
+	Readers int `json:"readers"`
+}
+
+// Recommendations recommends products.
+type Recommendations interface {
+	// Recommend returns up to n products that the readers of the product
+	// with the given ID also liked, most similar first. Products without
+	// ratings, or that aren't in the catalog, have none, and so does every
+	// product until the first model is computed.
+	Recommend(ctx context.Context, productID int, n int) ([]Recommendation, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// config is the configuration of the recommendations component, read from
+// its section of the app config.
+type config struct {
+	logging.Config
+
+	// RefreshInterval is how often the model is recomputed, as a Go
+	// duration, e.g. "30s". It's a minute by default.
+	RefreshInterval string `toml:"refresh_interval"`
+}
+
+type recommendations struct {
+	weaver.Implements[Recommendations]
+	weaver.WithConfig[config]
+	catalog  weaver.Ref[catalog.Catalog]
+	ratings  weaver.Ref[ratings.Ratings]
+	level    slog.Level    // minimum level logged
+	interval time.Duration // between recomputations of the model
+	done     chan struct{} // closed by Shutdown
+
+	model atomic.Pointer[Model] // the last one computed; nil before
+}
+
+// Init starts recomputing the model in the background.
+func (r *recommendations) Init(ctx context.Context) error {
+	level, err := r.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	r.level = level
+
+	r.interval = defaultRefreshInterval
+	if s := r.Config().RefreshInterval; s != "" {
+		if r.interval, err = time.ParseDuration(s); err != nil || r.interval <= 0 {
+			return fmt.Errorf("invalid refresh_interval %q", s)
+		}
+	}
+	r.done = make(chan struct{})
+	go r.refreshEvery(r.interval)
+	r.logger(ctx).Info("Recommendations component initialized", "refresh_interval", r.interval)
+	return nil
+}
+
+// Shutdown stops the background recomputations.
+func (r *recommendations) Shutdown(context.Context) error {
+	close(r.done)
+	return nil
+}
+
+// logger returns the component's logger for the request in ctx.
+func (r *recommendations) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, r.Logger(ctx), r.level)
+}
+
+func (r *recommendations) Recommend(ctx context.Context, productID int, n int) ([]Recommendation, error) {
+	m := r.model.Load()
+	if m == nil {
+		// The first model isn't computed yet, or failed to be. Computing it
+		// here would keep the request waiting for every rating, so there are
+		// no recommendations until the background refresh succeeds.
+		return nil, nil
+	}
+	return m.Recommend(productID, n), nil
+}
+
+// refreshEvery computes the model right away, and then again every
+// interval until Shutdown. If a computation fails, the last model keeps
+// serving.
+func (r *recommendations) refreshEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+		if _, err := r.compute(ctx); err != nil {
+			r.logger(ctx).Warn("Failed to recompute the recommendations; serving the last ones", "err", err)
+		}
+		cancel()
+
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// compute computes a model from the products of the catalog and their
+// ratings, and serves it. Only refreshEvery calls it, so computations never
+// overlap.
+func (r *recommendations) compute(ctx context.Context) (*Model, error) {
+	start := time.Now()
+	products, err := r.catalog.Get().List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the products: %w", err)
+	}
+	ids := make([]int, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	responses, err := ratings.GetMany(ctx, r.ratings.Get(), ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the ratings: %w", err)
+	}
+	stars := make(map[int]map[string]int, len(responses))
+	for id, resp := range responses {
+		stars[id] = resp.Ratings
+	}
+
+	m := NewModel(products, stars)
+	r.model.Store(m)
+	r.logger(ctx).Debug("Computed the recommendations", "products", len(products), "recommended", m.Len(), "elapsed", time.Since(start))
+	return m, nil
+}
+
+// Health reports whether the recommendations component is able to serve
+// requests.
+func (r *recommendations) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the recommendations component serving the
+// call.
+func (r *recommendations) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Recommendations](), nil
+}
@@ -0,0 +1,75 @@
+package recommendations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+)
+
+func TestRecommend(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Recommendations) {
+			// Every product starts with the same ratings by the same
+			// readers, who so tell nothing about which products are alike.
+			got, err := r.Recommend(context.Background(), 0, 3)
+			if err != nil || len(got) != 0 {
+				t.Errorf("Recommend(0, 3) = %+v, %v, want none", got, err)
+			}
+			if got, err := r.Recommend(context.Background(), 1000, 3); err != nil || len(got) != 0 {
+				t.Errorf("Recommend(1000, 3) = %+v, %v, want none", got, err)
+			}
+		})
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	// Posted ratings are kept by the ratings replica that got them, and the
+	// Multi runner has more than one.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Config = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations"]
+refresh_interval = "10ms"
+`
+		runner.Test(t, func(t *testing.T, r Recommendations, rt ratings.Ratings) {
+			ctx := context.Background()
+			if _, err := r.Recommend(ctx, 5, 3); err != nil {
+				t.Fatal(err)
+			}
+
+			// The readers of 5 liked 6 too, and disliked 7.
+			for id, body := range map[string]string{
+				"5": `{"Alice": 5, "Bob": 4}`,
+				"6": `{"Alice": 5, "Bob": 5}`,
+				"7": `{"Alice": 2, "Bob": 1}`,
+			} {
+				if _, err := rt.PostRatings(ctx, id, []byte(body)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var got []Recommendation
+			for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+				var err error
+				if got, err = r.Recommend(ctx, 5, 3); err != nil {
+					t.Fatal(err)
+				}
+				if ids(got) == "[6]" && got[0].Readers == 2 && got[0].Title != "" {
+					return
+				}
+			}
+			t.Errorf("Recommend(5, 3) after posting ratings = %+v, want product 6", got)
+		})
+	}
+}
+
+func TestRecommendBeforeFirstModel(t *testing.T) {
+	// Without a model, Recommend returns none rather than computing one,
+	// which would need the catalog and ratings this one doesn't have.
+	var r recommendations
+	got, err := r.Recommend(context.Background(), 1, 3)
+	if err != nil || len(got) != 0 {
+		t.Errorf("Recommend(1, 3) before the first model = %+v, %v, want none", got, err)
+	}
+}
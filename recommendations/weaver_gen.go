@@ -0,0 +1,478 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package recommendations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:  "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations",
+		Iface: reflect.TypeOf((*Recommendations)(nil)).Elem(),
+		Impl:  reflect.TypeOf(recommendations{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return recommendations_local_stub{impl: impl.(Recommendations), tracer: tracer, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations", Method: "Describe", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations", Method: "Health", Remote: false, Generated: true}), recommendMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations", Method: "Recommend", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return recommendations_client_stub{stub: stub, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations", Method: "Describe", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations", Method: "Health", Remote: true, Generated: true}), recommendMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations", Method: "Recommend", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return recommendations_server_stub{impl: impl.(Recommendations), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return recommendations_reflect_stub{caller: caller}
+		},
+		RefData: "⟦e2279aa6:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations→github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog⟧\n⟦a6303c9b:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/recommendations/Recommendations→github.com/camilamedeir0s/bookinfo-serviceweaver/ratings/Ratings⟧\n",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Recommendations] = (*recommendations)(nil)
+
+// weaver.Router checks.
+var _ weaver.Unrouted = (*recommendations)(nil)
+
+// Local stub implementations.
+
+type recommendations_local_stub struct {
+	impl             Recommendations
+	tracer           trace.Tracer
+	describeMetrics  *codegen.MethodMetrics
+	healthMetrics    *codegen.MethodMetrics
+	recommendMetrics *codegen.MethodMetrics
+}
+
+// Check that recommendations_local_stub implements the Recommendations interface.
+var _ Recommendations = (*recommendations_local_stub)(nil)
+
+func (s recommendations_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "recommendations.Recommendations.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s recommendations_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "recommendations.Recommendations.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s recommendations_local_stub) Recommend(ctx context.Context, a0 int, a1 int) (r0 []Recommendation, err error) {
+	// Update metrics.
+	begin := s.recommendMetrics.Begin()
+	defer func() { s.recommendMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "recommendations.Recommendations.Recommend", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Recommend(ctx, a0, a1)
+}
+
+// Client stub implementations.
+
+type recommendations_client_stub struct {
+	stub             codegen.Stub
+	describeMetrics  *codegen.MethodMetrics
+	healthMetrics    *codegen.MethodMetrics
+	recommendMetrics *codegen.MethodMetrics
+}
+
+// Check that recommendations_client_stub implements the Recommendations interface.
+var _ Recommendations = (*recommendations_client_stub)(nil)
+
+func (s recommendations_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "recommendations.Recommendations.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s recommendations_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "recommendations.Recommendations.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s recommendations_client_stub) Recommend(ctx context.Context, a0 int, a1 int) (r0 []Recommendation, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.recommendMetrics.Begin()
+	defer func() { s.recommendMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "recommendations.Recommendations.Recommend", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += 8
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.Int(a0)
+	enc.Int(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_slice_Recommendation_e749d0b4(dec)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type recommendations_server_stub struct {
+	impl    Recommendations
+	addLoad func(key uint64, load float64)
+}
+
+// Check that recommendations_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*recommendations_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s recommendations_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Describe":
+		return s.describe
+	case "Health":
+		return s.health
+	case "Recommend":
+		return s.recommend
+	default:
+		return nil
+	}
+}
+
+func (s recommendations_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s recommendations_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s recommendations_server_stub) recommend(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 int
+	a0 = dec.Int()
+	var a1 int
+	a1 = dec.Int()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Recommend(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_slice_Recommendation_e749d0b4(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type recommendations_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that recommendations_reflect_stub implements the Recommendations interface.
+var _ Recommendations = (*recommendations_reflect_stub)(nil)
+
+func (s recommendations_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s recommendations_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s recommendations_reflect_stub) Recommend(ctx context.Context, a0 int, a1 int) (r0 []Recommendation, err error) {
+	err = s.caller("Recommend", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*Recommendation)(nil)
+
+type __is_Recommendation[T ~struct {
+	weaver.AutoMarshal
+	ProductID int     "json:\"product_id\""
+	Title     string  "json:\"title\""
+	Author    string  "json:\"author\""
+	Score     float64 "json:\"score\""
+	Readers   int     "json:\"readers\""
+}] struct{}
+
+var _ __is_Recommendation[Recommendation]
+
+func (x *Recommendation) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Recommendation.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+	enc.String(x.Title)
+	enc.String(x.Author)
+	enc.Float64(x.Score)
+	enc.Int(x.Readers)
+}
+
+func (x *Recommendation) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Recommendation.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+	x.Title = dec.String()
+	x.Author = dec.String()
+	x.Score = dec.Float64()
+	x.Readers = dec.Int()
+}
+
+// Encoding/decoding implementations.
+
+func serviceweaver_enc_slice_Recommendation_e749d0b4(enc *codegen.Encoder, arg []Recommendation) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Recommendation_e749d0b4(dec *codegen.Decoder) []Recommendation {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]Recommendation, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
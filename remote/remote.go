@@ -0,0 +1,187 @@
+// Package remote lets Bookinfo components be replaced by the services of the
+// original Istio Bookinfo, called over HTTP with the original JSON.
+//
+// Every component package has a NewRemote adapter that implements the
+// component interface with a Client of this package. Config selects, per
+// caller, which components are reached through their adapters:
+//
+//	["github.com/ServiceWeaver/weaver/Main"]
+//	remote.ratings = "http://localhost:9083"
+//
+// makes the product page call the ratings service at that address instead
+// of the ratings component, while every other call stays a Service Weaver
+// call. Comparing the bookinfo_remote_latency_ms metric of the adapters with
+// Service Weaver's method latency measures plain HTTP against Weaver RPC for
+// the same call.
+//
+// Package stubs serves the original APIs from memory, for tests and local
+// runs.
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/metrics"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Config selects the components a caller reaches over HTTP. It is read from
+// the remote table of the caller's section of the app config:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews"]
+//	remote.ratings = "http://ratings:9080"
+//
+// Each field is the base URL of an original Bookinfo service; an empty one
+// keeps the Service Weaver component.
+type Config struct {
+	Details string `toml:"details"`
+	Reviews string `toml:"reviews"`
+	Ratings string `toml:"ratings"`
+}
+
+// Or returns local if baseURL is empty, and the adapter newRemote returns
+// for baseURL otherwise.
+func Or[T any](baseURL string, local T, newRemote func(baseURL string) (T, error)) (T, error) {
+	if baseURL == "" {
+		return local, nil
+	}
+	return newRemote(baseURL)
+}
+
+// Error is a response of a remote service with an error status.
+type Error struct {
+	Method     string // HTTP method
+	URL        string
+	StatusCode int
+	Message    string // the error in the body, if any
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("%s %s: %s", e.Method, e.URL, http.StatusText(e.StatusCode))
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	return msg
+}
+
+// latencyLabels label the calls of the adapters. Component is the full name
+// of the component an adapter replaces and Method the method called, as in
+// Service Weaver's method metrics.
+type latencyLabels struct {
+	Component string
+	Method    string
+	Error     bool
+}
+
+var latency = metrics.NewHistogramMap[latencyLabels](
+	"bookinfo_remote_latency_ms",
+	"Latency of the calls to remote Bookinfo services, in milliseconds",
+	[]float64{0.5, 1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500},
+)
+
+// Limits of the calls to remote services, which may hang or misbehave like
+// any other HTTP server.
+const (
+	callTimeout      = 10 * time.Second // for a whole call, reading the body included
+	maxResponseBytes = 4 << 20          // of a response body
+)
+
+// httpClient is shared by the clients, so they share connections.
+var httpClient = &http.Client{Timeout: callTimeout}
+
+// Client calls a remote service in place of a component.
+type Client struct {
+	component string       // full name of the replaced component
+	base      string       // base URL, without a trailing slash
+	host      string       // host[:port] of the base URL
+	http      *http.Client // sends the requests
+}
+
+// NewClient returns a client of the service at baseURL, which replaces the
+// named component.
+func NewClient(component, baseURL string) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q of the remote %s: want http(s)://host[:port]", baseURL, component)
+	}
+	return &Client{component: component, base: strings.TrimSuffix(baseURL, "/"), host: u.Host, http: httpClient}, nil
+}
+
+// Describe describes the remote service as a replica of the component it
+// replaces. The replica has the service's host, and no process or calls the
+// product page could see.
+func (c *Client) Describe() topology.Replica {
+	return topology.Replica{Component: c.component, Host: c.host, Address: c.host}
+}
+
+// Call sends a request for the component method named method and decodes the
+// JSON response into out, unless out is nil. A non-nil body is sent as JSON,
+// and the request ID in ctx as the X-Request-Id header, which the original
+// services propagate. Responses with an error status return an *Error. Calls
+// time out after callTimeout, and responses larger than maxResponseBytes
+// are errors.
+func (c *Client) Call(ctx context.Context, method, httpMethod, path string, body []byte, header map[string]string, out any) error {
+	start := time.Now()
+	err := c.do(ctx, httpMethod, path, body, header, out)
+	latency.Get(latencyLabels{Component: c.component, Method: method, Error: err != nil}).Put(float64(time.Since(start).Microseconds()) / 1000)
+	return err
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body []byte, header map[string]string, out any) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.base+path, r)
+	if err != nil {
+		return err
+	}
+	for key, value := range header {
+		req.Header.Set(key, value)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if id := logging.RequestID(ctx); id != "" {
+		req.Header.Set("X-Request-Id", id)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes+1))
+	if err != nil {
+		return err
+	}
+	if len(data) > maxResponseBytes {
+		return fmt.Errorf("%s %s: response larger than %d bytes", method, req.URL, maxResponseBytes)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		e := &Error{Method: method, URL: req.URL.String(), StatusCode: resp.StatusCode}
+		var problem struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &problem) == nil {
+			e.Message = problem.Error
+		}
+		return e
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: invalid response: %w", method, req.URL, err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCall(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id": 1}`))
+	})
+	mux.HandleFunc("GET /missing", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error": "product not found"}`))
+	})
+	mux.HandleFunc("GET /large", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`"` + strings.Repeat("x", maxResponseBytes) + `"`))
+	})
+	mux.HandleFunc("GET /hang", func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	c, err := NewClient("details", server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.http = &http.Client{Timeout: 100 * time.Millisecond}
+	ctx := context.Background()
+
+	var out struct{ ID int }
+	if err := c.Call(ctx, "Get", http.MethodGet, "/ok", nil, nil, &out); err != nil || out.ID != 1 {
+		t.Errorf("Call(/ok) = %+v, %v; want ID 1", out, err)
+	}
+
+	var e *Error
+	if err := c.Call(ctx, "Get", http.MethodGet, "/missing", nil, nil, nil); !errors.As(err, &e) || e.StatusCode != http.StatusNotFound || e.Message != "product not found" {
+		t.Errorf("Call(/missing) = %v, want a 404 *Error", err)
+	}
+
+	var s string
+	if err := c.Call(ctx, "Get", http.MethodGet, "/large", nil, nil, &s); err == nil || !strings.Contains(err.Error(), "larger than") {
+		t.Errorf("Call(/large) = %v, want a too large error", err)
+	}
+
+	start := time.Now()
+	if err := c.Call(ctx, "Get", http.MethodGet, "/hang", nil, nil, nil); err == nil {
+		t.Errorf("Call(/hang) succeeded, want a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Call(/hang) took %v, want it to time out", elapsed)
+	}
+}
@@ -0,0 +1,198 @@
+// Package stubs serves the APIs of the original Istio Bookinfo services from
+// memory, to test the remote adapters and run them locally without the
+// original services:
+//
+//	srv := httptest.NewServer(stubs.NewRatings())
+//	r, err := ratings.NewRemote(srv.URL)
+//
+// The stubs answer like the originals, with fixed data: the details of the
+// same book for every product, two reviews rated 5 and 4 stars, and ratings
+// that can be posted.
+package stubs
+
+import (
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+)
+
+// Stub is a stub of an original Bookinfo service.
+type Stub struct {
+	mux *http.ServeMux
+
+	mu      sync.Mutex
+	status  int           // status of every response, if non-zero
+	delay   time.Duration // added to every response
+	header  http.Header   // of the last request
+	ratings map[int]map[string]int
+}
+
+// newStub returns a stub serving GET /health with the given status.
+func newStub(health string) *Stub {
+	s := &Stub{mux: http.NewServeMux(), ratings: map[int]map[string]int{}}
+	s.mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": health})
+	})
+	return s
+}
+
+// NewDetails returns a stub of the details service, which serves
+// GET /details/{id} and GET /health.
+func NewDetails() *Stub {
+	s := newStub("Details is healthy")
+	s.mux.HandleFunc("GET /details/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := productID(w, r)
+		if !ok {
+			return
+		}
+		writeJSON(w, http.StatusOK, details.BookDetails{
+			ID:        id,
+			Author:    "William Shakespeare",
+			Year:      1595,
+			Type:      "paperback",
+			Pages:     200,
+			Publisher: "PublisherA",
+			Language:  "English",
+			ISBN10:    "1234567890",
+			ISBN13:    "123-1234567890",
+		})
+	})
+	return s
+}
+
+// NewReviews returns a stub of the reviews service, which serves
+// GET /reviews/{id} and GET /health.
+func NewReviews() *Stub {
+	s := newStub("Reviews is healthy")
+	s.mux.HandleFunc("GET /reviews/{id}", func(w http.ResponseWriter, r *http.Request) {
+		five, four := 5, 4
+		writeJSON(w, http.StatusOK, reviews.UpstreamResponse{
+			ID:          r.PathValue("id"),
+			PodName:     "stub",
+			ClusterName: "stub",
+			Reviews: []reviews.UpstreamReview{
+				{
+					Reviewer: "Reviewer1",
+					Text:     "An extremely entertaining play by Shakespeare. The slapstick humour is refreshing!",
+					Rating:   &reviews.UpstreamRating{Stars: &five, Color: "black"},
+				},
+				{
+					Reviewer: "Reviewer2",
+					Text:     "Absolutely fun and entertaining. The play lacks thematic depth when compared to other plays by Shakespeare.",
+					Rating:   &reviews.UpstreamRating{Stars: &four, Color: "black"},
+				},
+			},
+		})
+	})
+	return s
+}
+
+// NewRatings returns a stub of the ratings service, which serves
+// GET /ratings/{id}, POST /ratings/{id} and GET /health. Every product is
+// rated 5 stars by Reviewer1 and 4 by Reviewer2 until ratings are posted.
+func NewRatings() *Stub {
+	s := newStub("Ratings is healthy")
+	s.mux.HandleFunc("GET /ratings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		id, ok := productID(w, r)
+		if !ok {
+			return
+		}
+		s.mu.Lock()
+		stored, ok := s.ratings[id]
+		s.mu.Unlock()
+		if !ok {
+			stored = map[string]int{"Reviewer1": 5, "Reviewer2": 4}
+		}
+		writeJSON(w, http.StatusOK, ratings.RatingResponse{ID: id, Ratings: stored})
+	})
+	s.mux.HandleFunc("POST /ratings/{id}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "please provide valid ratings JSON")
+			return
+		}
+		id, posted, err := ratings.ParseRatings(r.PathValue("id"), body)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		s.mu.Lock()
+		s.ratings[id] = maps.Clone(posted)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, ratings.RatingResponse{ID: id, Ratings: posted})
+	})
+	return s
+}
+
+// FailWith makes every response, health checks included, an error with the
+// given status. A zero status makes the stub answer again.
+func (s *Stub) FailWith(status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.status = status
+}
+
+// SetDelay delays every response by d.
+func (s *Stub) SetDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// Header returns the header of the last request served.
+func (s *Stub) Header() http.Header {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.header
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Stub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	status, delay := s.status, s.delay
+	s.header = r.Header.Clone()
+	s.mu.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+	}
+	if status != 0 {
+		writeError(w, status, http.StatusText(status))
+		return
+	}
+	s.mux.ServeHTTP(w, r)
+}
+
+// productID returns the numeric product ID in the path of r, or writes the
+// error of the original services and returns false.
+func productID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "please provide numeric product ID")
+		return 0, false
+	}
+	return id, true
+}
+
+// writeError writes an error the way the original services do.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
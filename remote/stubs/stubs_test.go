@@ -0,0 +1,143 @@
+package stubs_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/details"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote/stubs"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/reviews"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// serve serves stub until the test ends and returns its URL.
+func serve(t *testing.T, stub *stubs.Stub) string {
+	t.Helper()
+	srv := httptest.NewServer(stub)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestDetails(t *testing.T) {
+	stub := stubs.NewDetails()
+	d, err := details.NewRemote(serve(t, stub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := logging.NewContext(context.Background(), "req-1", "")
+
+	book, err := d.GetBookDetails(ctx, 3, map[string]string{"X-B3-Traceid": "abc"})
+	if err != nil || book.ID != 3 || book.Author != "William Shakespeare" || book.ISBN13 != "123-1234567890" {
+		t.Errorf("GetBookDetails(3) = %+v, %v, want the stub's book", book, err)
+	}
+	if got := stub.Header(); got.Get("X-Request-Id") != "req-1" || got.Get("X-B3-Traceid") != "abc" {
+		t.Errorf("headers sent = %v, want the request ID and the given headers", got)
+	}
+	if err := d.Health(ctx); err != nil {
+		t.Errorf("Health() = %v", err)
+	}
+	replica, err := d.Describe(ctx)
+	if err != nil || replica.Component != topology.Name[details.Details]() || !strings.HasPrefix(replica.Host, "127.0.0.1:") {
+		t.Errorf("Describe() = %+v, %v, want the stub's host", replica, err)
+	}
+
+	stub.FailWith(http.StatusServiceUnavailable)
+	var e *remote.Error
+	if _, err := d.GetBookDetails(ctx, 3, nil); !errors.As(err, &e) || e.StatusCode != http.StatusServiceUnavailable || e.Message != "Service Unavailable" {
+		t.Errorf("GetBookDetails(3) of a failing stub: %v, want a 503 *remote.Error", err)
+	}
+	if err := d.Health(ctx); err == nil {
+		t.Error("Health() of a failing stub succeeded")
+	}
+}
+
+func TestReviews(t *testing.T) {
+	stub := stubs.NewReviews()
+	r, err := reviews.NewRemote(serve(t, stub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := r.BookReviewsByID(context.Background(), "2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := reviews.Response{ID: "2", PodName: "stub", ClusterName: "stub", Reviews: []reviews.Review{
+		{Reviewer: "Reviewer1", Text: "An extremely entertaining play by Shakespeare. The slapstick humour is refreshing!", Rating: reviews.Rating{Stars: 5, Color: "black"}},
+		{Reviewer: "Reviewer2", Text: "Absolutely fun and entertaining. The play lacks thematic depth when compared to other plays by Shakespeare.", Rating: reviews.Rating{Stars: 4, Color: "black"}},
+	}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BookReviewsByID(2) = %+v, want %+v", got, want)
+	}
+}
+
+func TestRatings(t *testing.T) {
+	stub := stubs.NewRatings()
+	r, err := ratings.NewRemote(serve(t, stub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	got, err := r.GetRatings(ctx, 1)
+	if want := (ratings.RatingResponse{ID: 1, Ratings: map[string]int{"Reviewer1": 5, "Reviewer2": 4}}); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRatings(1) = %+v, %v, want %+v", got, err, want)
+	}
+	want := ratings.RatingResponse{ID: 1, Ratings: map[string]int{"Reviewer1": 0}}
+	if got, err := r.PostRatings(ctx, "1", []byte(`{"Reviewer1": 0}`)); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("PostRatings(1) = %+v, %v, want %+v", got, err, want)
+	}
+	if got, err := r.GetRatings(ctx, 1); err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetRatings(1) after post = %+v, %v, want %+v", got, err, want)
+	}
+	// A batch asks for the products concurrently: 16 calls of 50ms each
+	// take about 100ms, at 8 at a time.
+	stub.SetDelay(50 * time.Millisecond)
+	start := time.Now()
+	batch, err := r.GetRatingsBatch(ctx, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 2})
+	if err != nil || len(batch) != 16 || !reflect.DeepEqual(batch[1], want) || batch[16].Ratings["Reviewer2"] != 4 {
+		t.Errorf("GetRatingsBatch(1..16) = %+v, %v, want 16 ratings", batch, err)
+	}
+	if elapsed := time.Since(start); elapsed > 400*time.Millisecond {
+		t.Errorf("GetRatingsBatch(1..16) took %v, want the calls made concurrently", elapsed)
+	}
+	stub.SetDelay(0)
+	// Invalid ratings aren't sent.
+	if _, err := r.PostRatings(ctx, "1", []byte(`{"Reviewer1": 9}`)); err == nil || errors.As(err, new(*remote.Error)) {
+		t.Errorf("PostRatings(1) with 9 stars: %v, want a validation error", err)
+	}
+
+	if status, err := r.Health(ctx); err != nil || !status.Healthy {
+		t.Errorf("Health() = %+v, %v, want healthy", status, err)
+	}
+	stub.FailWith(http.StatusInternalServerError)
+	if status, err := r.Health(ctx); err != nil || status.Healthy {
+		t.Errorf("Health() of a failing stub = %+v, %v, want unhealthy", status, err)
+	}
+	if _, err := r.GetRatings(ctx, 1); err == nil {
+		t.Error("GetRatings(1) of a failing stub succeeded")
+	}
+	if batch, err := r.GetRatingsBatch(ctx, []int{1, 2}); err == nil {
+		t.Errorf("GetRatingsBatch(1, 2) of a failing stub = %+v, want an error", batch)
+	}
+}
+
+func TestNewRemote(t *testing.T) {
+	for _, base := range []string{"", "ratings:9080", "ftp://ratings", "http://"} {
+		if _, err := ratings.NewRemote(base); err == nil {
+			t.Errorf("NewRemote(%q) succeeded, want error", base)
+		}
+	}
+	local := ratings.Ratings(nil)
+	if got, err := remote.Or("", local, ratings.NewRemote); err != nil || got != local {
+		t.Errorf("Or without a URL = %v, %v, want the local component", got, err)
+	}
+}
@@ -0,0 +1,27 @@
+package reviews
+
+import "github.com/ServiceWeaver/weaver/metrics"
+
+// reviewLabels rotulam as reviews retornadas. Component é o nome completo do
+// componente Reviews, como nas métricas de métodos do Service Weaver, e Rated
+// indica se a review foi retornada com estrelas.
+type reviewLabels struct {
+	Component string
+	Rated     bool
+}
+
+// fallbackLabels rotulam as respostas servidas sem ratings.
+type fallbackLabels struct {
+	Component string
+}
+
+var (
+	reviewsReturned = metrics.NewCounterMap[reviewLabels](
+		"bookinfo_reviews_returned",
+		"Reviews returned, by whether they carry a rating",
+	)
+	ratingsFallbacks = metrics.NewCounterMap[fallbackLabels](
+		"bookinfo_ratings_fallbacks",
+		"Reviews responses served without stars because ratings could not be fetched",
+	)
+)
@@ -0,0 +1,62 @@
+package reviews
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// remoteReviews chama um serviço de reviews do Bookinfo original, que atende
+// GET /reviews/{id} e GET /health.
+type remoteReviews struct {
+	client *remote.Client
+}
+
+var _ Reviews = (*remoteReviews)(nil)
+
+// NewRemote retorna um Reviews que chama o serviço de reviews original em
+// baseURL, por exemplo "http://reviews:9080".
+func NewRemote(baseURL string) (Reviews, error) {
+	client, err := remote.NewClient(topology.Name[Reviews](), baseURL)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteReviews{client: client}, nil
+}
+
+// BookReviewsByID implementa Reviews.
+func (r *remoteReviews) BookReviewsByID(ctx context.Context, productId string) (Response, error) {
+	var upstream UpstreamResponse
+	if err := r.client.Call(ctx, "BookReviewsByID", http.MethodGet, "/reviews/"+url.PathEscape(productId), nil, nil, &upstream); err != nil {
+		return Response{}, err
+	}
+	return upstream.Response(), nil
+}
+
+// ReviewCounts implementa Reviews. O serviço original não tem uma API em
+// lote, então as reviews são pedidas um produto por vez.
+func (r *remoteReviews) ReviewCounts(ctx context.Context, productIds []int) (map[int]int, error) {
+	counts := map[int]int{}
+	for _, id := range productIds {
+		resp, err := r.BookReviewsByID(ctx, strconv.Itoa(id))
+		if err != nil {
+			return nil, err
+		}
+		counts[id] = len(resp.Reviews)
+	}
+	return counts, nil
+}
+
+// Health implementa Reviews.
+func (r *remoteReviews) Health(ctx context.Context) error {
+	return r.client.Call(ctx, "Health", http.MethodGet, "/health", nil, nil, nil)
+}
+
+// Describe implementa Reviews.
+func (r *remoteReviews) Describe(context.Context) (topology.Replica, error) {
+	return r.client.Describe(), nil
+}
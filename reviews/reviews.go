@@ -3,11 +3,15 @@ package reviews
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 
 	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
 	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/remote"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
 )
 
 type Review struct {
@@ -23,6 +27,13 @@ type Rating struct {
 	Color string `json:"color"`
 }
 
+// Rated indica se a avaliação tem estrelas, de 0 em diante. O valor zero de
+// Rating, sem estrelas nem cor, é o de ratings desabilitados, e -1 estrela o
+// de ratings indisponíveis.
+func (r Rating) Rated() bool {
+	return r != Rating{} && r.Stars >= 0
+}
+
 type Response struct {
 	weaver.AutoMarshal
 	ID          string   `json:"id"`
@@ -41,11 +52,49 @@ var (
 // Definição do componente Reviews
 type Reviews interface {
 	BookReviewsByID(ctx context.Context, productId string) (Response, error)
+	// ReviewCounts retorna o número de reviews de vários produtos, por ID,
+	// numa única chamada.
+	ReviewCounts(ctx context.Context, productIds []int) (map[int]int, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// config é a configuração do componente Reviews, lida da sua seção da
+// configuração da aplicação. Remote.Ratings troca o componente Ratings por um
+// serviço de ratings original; os outros campos de Remote não se aplicam.
+type config struct {
+	logging.Config
+	Remote remote.Config `toml:"remote"`
 }
 
 type reviews struct {
 	weaver.Implements[Reviews]
+	weaver.WithConfig[config]
 	ratingsComponent weaver.Ref[ratings.Ratings] // Referência ao componente Ratings
+	ratingsService   ratings.Ratings             // o componente Ratings ou o serviço remoto que o substitui
+	name             string                      // nome completo do componente, usado nas métricas
+	level            slog.Level                  // nível mínimo dos logs
+}
+
+// Init guarda o nome do componente, usado como rótulo das métricas, e o
+// nível dos logs, e escolhe entre o componente Ratings e o serviço remoto.
+func (r *reviews) Init(context.Context) error {
+	r.name = topology.Name[Reviews]()
+	level, err := r.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	r.level = level
+	if r.Config().Remote.Details != "" || r.Config().Remote.Reviews != "" {
+		return fmt.Errorf("reviews only calls ratings, so only remote.ratings can be set")
+	}
+	r.ratingsService, err = remote.Or(r.Config().Remote.Ratings, r.ratingsComponent.Get(), ratings.NewRemote)
+	return err
+}
+
+// logger retorna o logger do componente para a requisição em ctx.
+func (r *reviews) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, r.Logger(ctx), r.level)
 }
 
 // Função principal para buscar reviews por ID de produto
@@ -65,16 +114,41 @@ func (r *reviews) BookReviewsByID(ctx context.Context, productId string) (Respon
 				starsReviewer2 = reviewer2
 			}
 		} else {
-			// Log de erro ou tratamento de erro pode ser adicionado aqui
-			fmt.Printf("Error getting ratings: %v\n", err)
+			r.logger(ctx).Warn("Could not get ratings, serving reviews without stars", "product_id", productId, "err", err)
+			ratingsFallbacks.Get(fallbackLabels{Component: r.name}).Inc()
 		}
 	}
 
 	// Gera a resposta final com as reviews e os ratings
 	response := r.getJsonResponse(productId, starsReviewer1, starsReviewer2)
+	r.logger(ctx).Debug("Serving reviews", "product_id", productId, "reviews", len(response.Reviews))
+	for _, review := range response.Reviews {
+		reviewsReturned.Get(reviewLabels{Component: r.name, Rated: review.Rating.Rated()}).Inc()
+	}
 	return response, nil
 }
 
+// ReviewCounts conta as reviews de cada produto. Os ratings não mudam o
+// número de reviews, então não são pedidos.
+func (r *reviews) ReviewCounts(ctx context.Context, productIds []int) (map[int]int, error) {
+	counts := map[int]int{}
+	for _, id := range productIds {
+		counts[id] = len(r.getJsonResponse(strconv.Itoa(id), -1, -1).Reviews)
+	}
+	return counts, nil
+}
+
+// Health indica se o componente Reviews está pronto para atender requisições.
+// O estado do componente Ratings é verificado separadamente.
+func (r *reviews) Health(ctx context.Context) error {
+	return nil
+}
+
+// Describe retorna a réplica do componente Reviews que atendeu a chamada.
+func (r *reviews) Describe(ctx context.Context) (topology.Replica, error) {
+	return topology.Self[Reviews](), nil
+}
+
 func (r *reviews) getRatings(ctx context.Context, productId string) (ratings.RatingResponse, error) {
 	// Converte productId de string para int
 	productIdInt, err := strconv.Atoi(productId)
@@ -82,9 +156,8 @@ func (r *reviews) getRatings(ctx context.Context, productId string) (ratings.Rat
 		return ratings.RatingResponse{}, fmt.Errorf("invalid product ID: %w", err)
 	}
 
-	// Chama o componente Ratings diretamente com o productId convertido
-	ratingsComponent := r.ratingsComponent.Get()
-	ratingResponse, err := ratingsComponent.GetRatings(ctx, productIdInt)
+	// Chama o componente Ratings, ou o serviço remoto, com o productId convertido
+	ratingResponse, err := r.ratingsService.GetRatings(ctx, productIdInt)
 	if err != nil {
 		return ratings.RatingResponse{}, fmt.Errorf("error getting ratings: %w", err)
 	}
@@ -0,0 +1,224 @@
+package reviews
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/ratings"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// stars returns the stars of every review.
+func stars(reviews []Review) []int {
+	s := make([]int, len(reviews))
+	for i, review := range reviews {
+		s[i] = review.Rating.Stars
+	}
+	return s
+}
+
+// alternate returns ten stars, alternating between a and b, as given to the
+// reviews of Reviewer1 and Reviewer2.
+func alternate(a, b int) []int {
+	s := make([]int, 10)
+	for i := range s {
+		if i%2 == 0 {
+			s[i] = a
+		} else {
+			s[i] = b
+		}
+	}
+	return s
+}
+
+func TestReviewsWithRatings(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Reviews) {
+			got, err := r.BookReviewsByID(context.Background(), "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.ID != "1" {
+				t.Errorf("ID = %q, want %q", got.ID, "1")
+			}
+			if want := alternate(5, 4); !slices.Equal(stars(got.Reviews), want) {
+				t.Errorf("stars = %v, want %v", stars(got.Reviews), want)
+			}
+			for _, review := range got.Reviews {
+				if review.Rating.Color != starColor {
+					t.Errorf("%s: color = %q, want %q", review.Reviewer, review.Rating.Color, starColor)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewsSeePostedRatings(t *testing.T) {
+	// Reviews and ratings share a process in the Local and RPC runners; see
+	// TestPostThenGetRatings in the ratings package.
+	for i, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Test(t, func(t *testing.T, r Reviews, rt ratings.Ratings) {
+			ctx := context.Background()
+			id := []string{"200", "201"}[i]
+			if _, err := rt.PostRatings(ctx, id, []byte(`{"Reviewer1": 2, "Reviewer2": 1}`)); err != nil {
+				t.Fatal(err)
+			}
+			got, err := r.BookReviewsByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := alternate(2, 1); !slices.Equal(stars(got.Reviews), want) {
+				t.Errorf("stars = %v, want %v", stars(got.Reviews), want)
+			}
+		})
+	}
+}
+
+// failingRatings is a Ratings whose GetRatings always fails.
+type failingRatings struct{ ratings.Ratings }
+
+func (failingRatings) GetRatings(context.Context, int) (ratings.RatingResponse, error) {
+	return ratings.RatingResponse{}, errors.New("ratings are down")
+}
+
+func (failingRatings) Describe(context.Context) (topology.Replica, error) {
+	return topology.Replica{}, nil
+}
+
+func TestReviewsWithoutRatings(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Fakes = append(runner.Fakes, weavertest.Fake[ratings.Ratings](failingRatings{}))
+		runner.Test(t, func(t *testing.T, r Reviews) {
+			got, err := r.BookReviewsByID(context.Background(), "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := alternate(-1, -1); !slices.Equal(stars(got.Reviews), want) {
+				t.Errorf("stars = %v, want %v", stars(got.Reviews), want)
+			}
+			for _, review := range got.Reviews {
+				if review.Rating.Color != "Ratings service is unavailable" {
+					t.Errorf("%s: color = %q, want the unavailable message", review.Reviewer, review.Rating.Color)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewsInvalidProductID(t *testing.T) {
+	weavertest.Local.Test(t, func(t *testing.T, r Reviews) {
+		got, err := r.BookReviewsByID(context.Background(), "not-a-number")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := alternate(-1, -1); !slices.Equal(stars(got.Reviews), want) {
+			t.Errorf("stars = %v, want %v", stars(got.Reviews), want)
+		}
+	})
+}
+
+func TestRatingsDisabled(t *testing.T) {
+	// ENABLE_RATINGS is read when the package is initialized. The variable
+	// reaches the processes started by the Multi runner; the test process
+	// needs ratingsEnabled set directly.
+	t.Setenv("ENABLE_RATINGS", "false")
+	enabled := ratingsEnabled
+	ratingsEnabled = false
+	t.Cleanup(func() { ratingsEnabled = enabled })
+
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, r Reviews) {
+			got, err := r.BookReviewsByID(context.Background(), "1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Reviews) != 10 {
+				t.Fatalf("got %d reviews, want 10", len(got.Reviews))
+			}
+			for _, review := range got.Reviews {
+				if review.Rating != (Rating{}) {
+					t.Errorf("%s: rating = %+v, want none", review.Reviewer, review.Rating)
+				}
+			}
+		})
+	}
+}
+
+func TestRated(t *testing.T) {
+	for _, test := range []struct {
+		rating Rating
+		want   bool
+	}{
+		{Rating{Stars: 5, Color: "black"}, true},
+		{Rating{Stars: 0, Color: "black"}, true}, // uma avaliação válida de 0 estrelas
+		{Rating{Stars: 0, Color: ""}, false},     // ratings desabilitados
+		{Rating{Stars: -1, Color: "Ratings service is unavailable"}, false},
+	} {
+		if got := test.rating.Rated(); got != test.want {
+			t.Errorf("%+v.Rated() = %v, want %v", test.rating, got, test.want)
+		}
+	}
+}
+
+func TestReviewsWithRemoteRatings(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ratings/1" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1, "ratings": {"Reviewer1": 3, "Reviewer2": 2}}`))
+	}))
+	defer srv.Close()
+
+	runner := weavertest.Local
+	runner.Config = fmt.Sprintf(`
+["github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews"]
+remote.ratings = %q
+`, srv.URL)
+	runner.Test(t, func(t *testing.T, r Reviews) {
+		got, err := r.BookReviewsByID(context.Background(), "1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := alternate(3, 2); !slices.Equal(stars(got.Reviews), want) {
+			t.Errorf("stars = %v, want %v from the remote ratings", stars(got.Reviews), want)
+		}
+	})
+}
+
+func TestUpstreamRoundTrip(t *testing.T) {
+	for _, rating := range []Rating{
+		{Stars: 0, Color: "black"},
+		{Stars: 5, Color: "red"},
+		{}, // ratings desabilitados
+		{Stars: -1, Color: RatingsUnavailable},
+	} {
+		resp := Response{ID: "1", PodName: "pod", ClusterName: "cluster", Reviews: []Review{{Reviewer: "Reviewer1", Text: "Fun", Rating: rating}}}
+		data, err := json.Marshal(ToUpstream(resp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var upstream UpstreamResponse
+		if err := json.Unmarshal(data, &upstream); err != nil {
+			t.Fatal(err)
+		}
+		if got := upstream.Response(); !reflect.DeepEqual(got, resp) {
+			t.Errorf("%s decoded as %+v, want %+v", data, got, resp)
+		}
+	}
+
+	// O serviço original omite o rating quando os ratings estão desabilitados.
+	data, _ := json.Marshal(ToUpstream(Response{ID: "1", Reviews: []Review{{Reviewer: "Reviewer1", Text: "Fun"}}}))
+	if want := `{"id":"1","podname":"","clustername":"","reviews":[{"reviewer":"Reviewer1","text":"Fun"}]}`; string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
@@ -0,0 +1,67 @@
+package reviews
+
+// RatingsUnavailable é o erro que o serviço de reviews original retorna no
+// lugar do rating quando o serviço de ratings não responde.
+const RatingsUnavailable = "Ratings service is currently unavailable"
+
+// UpstreamResponse é o corpo de GET /reviews/{id} do serviço de reviews
+// original.
+type UpstreamResponse struct {
+	ID          string           `json:"id"`
+	PodName     string           `json:"podname"`
+	ClusterName string           `json:"clustername"`
+	Reviews     []UpstreamReview `json:"reviews"`
+}
+
+// UpstreamReview é uma review do serviço original. Rating é nil quando os
+// ratings estão desabilitados.
+type UpstreamReview struct {
+	Reviewer string          `json:"reviewer"`
+	Text     string          `json:"text"`
+	Rating   *UpstreamRating `json:"rating,omitempty"`
+}
+
+// UpstreamRating é o rating de uma review do serviço original: as estrelas e
+// a cor, ou o erro do serviço de ratings.
+type UpstreamRating struct {
+	Stars *int   `json:"stars,omitempty"`
+	Color string `json:"color,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// ToUpstream converte uma resposta do componente para a do serviço original.
+func ToUpstream(resp Response) UpstreamResponse {
+	upstream := UpstreamResponse{ID: resp.ID, PodName: resp.PodName, ClusterName: resp.ClusterName, Reviews: []UpstreamReview{}}
+	for _, review := range resp.Reviews {
+		u := UpstreamReview{Reviewer: review.Reviewer, Text: review.Text}
+		switch rating := review.Rating; {
+		case rating == Rating{}:
+			// Ratings desabilitados.
+		case rating.Stars < 0:
+			u.Rating = &UpstreamRating{Error: RatingsUnavailable}
+		default:
+			u.Rating = &UpstreamRating{Stars: &rating.Stars, Color: rating.Color}
+		}
+		upstream.Reviews = append(upstream.Reviews, u)
+	}
+	return upstream
+}
+
+// Response converte a resposta do serviço original para a do componente. O
+// erro do serviço de ratings vira um rating de -1 estrela com o erro no lugar
+// da cor, como o componente faz.
+func (u UpstreamResponse) Response() Response {
+	resp := Response{ID: u.ID, PodName: u.PodName, ClusterName: u.ClusterName, Reviews: []Review{}}
+	for _, upstream := range u.Reviews {
+		review := Review{Reviewer: upstream.Reviewer, Text: upstream.Text}
+		switch rating := upstream.Rating; {
+		case rating == nil:
+		case rating.Stars == nil:
+			review.Rating = Rating{Stars: -1, Color: rating.Error}
+		default:
+			review.Rating = Rating{Stars: *rating.Stars, Color: rating.Color}
+		}
+		resp.Reviews = append(resp.Reviews, review)
+	}
+	return resp
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"github.com/ServiceWeaver/weaver"
 	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
 	"go.opentelemetry.io/otel/codes"
 	"go.opentelemetry.io/otel/trace"
 	"reflect"
@@ -20,10 +21,10 @@ func init() {
 		Iface: reflect.TypeOf((*Reviews)(nil)).Elem(),
 		Impl:  reflect.TypeOf(reviews{}),
 		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
-			return reviews_local_stub{impl: impl.(Reviews), tracer: tracer, bookReviewsByIDMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "BookReviewsByID", Remote: false, Generated: true})}
+			return reviews_local_stub{impl: impl.(Reviews), tracer: tracer, bookReviewsByIDMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "BookReviewsByID", Remote: false, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "Describe", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "Health", Remote: false, Generated: true}), reviewCountsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "ReviewCounts", Remote: false, Generated: true})}
 		},
 		ClientStubFn: func(stub codegen.Stub, caller string) any {
-			return reviews_client_stub{stub: stub, bookReviewsByIDMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "BookReviewsByID", Remote: true, Generated: true})}
+			return reviews_client_stub{stub: stub, bookReviewsByIDMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "BookReviewsByID", Remote: true, Generated: true}), describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "Describe", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "Health", Remote: true, Generated: true}), reviewCountsMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/reviews/Reviews", Method: "ReviewCounts", Remote: true, Generated: true})}
 		},
 		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
 			return reviews_server_stub{impl: impl.(Reviews), addLoad: addLoad}
@@ -47,6 +48,9 @@ type reviews_local_stub struct {
 	impl                   Reviews
 	tracer                 trace.Tracer
 	bookReviewsByIDMetrics *codegen.MethodMetrics
+	describeMetrics        *codegen.MethodMetrics
+	healthMetrics          *codegen.MethodMetrics
+	reviewCountsMetrics    *codegen.MethodMetrics
 }
 
 // Check that reviews_local_stub implements the Reviews interface.
@@ -72,11 +76,74 @@ func (s reviews_local_stub) BookReviewsByID(ctx context.Context, a0 string) (r0
 	return s.impl.BookReviewsByID(ctx, a0)
 }
 
+func (s reviews_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "reviews.Reviews.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s reviews_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "reviews.Reviews.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s reviews_local_stub) ReviewCounts(ctx context.Context, a0 []int) (r0 map[int]int, err error) {
+	// Update metrics.
+	begin := s.reviewCountsMetrics.Begin()
+	defer func() { s.reviewCountsMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "reviews.Reviews.ReviewCounts", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.ReviewCounts(ctx, a0)
+}
+
 // Client stub implementations.
 
 type reviews_client_stub struct {
 	stub                   codegen.Stub
 	bookReviewsByIDMetrics *codegen.MethodMetrics
+	describeMetrics        *codegen.MethodMetrics
+	healthMetrics          *codegen.MethodMetrics
+	reviewCountsMetrics    *codegen.MethodMetrics
 }
 
 // Check that reviews_client_stub implements the Reviews interface.
@@ -138,6 +205,155 @@ func (s reviews_client_stub) BookReviewsByID(ctx context.Context, a0 string) (r0
 	return
 }
 
+func (s reviews_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "reviews.Reviews.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s reviews_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "reviews.Reviews.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s reviews_client_stub) ReviewCounts(ctx context.Context, a0 []int) (r0 map[int]int, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.reviewCountsMetrics.Begin()
+	defer func() { s.reviewCountsMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "reviews.Reviews.ReviewCounts", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + (len(a0) * 8))
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	serviceweaver_enc_slice_int_7c8c8866(enc, a0)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 3, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	r0 = serviceweaver_dec_map_int_int_61995a5d(dec)
+	err = dec.Error()
+	return
+}
+
 // Note that "weaver generate" will always generate the error message below.
 // Everything is okay. The error message is only relevant if you see it when
 // you run "go build" or "go run".
@@ -176,6 +392,12 @@ func (s reviews_server_stub) GetStubFn(method string) func(ctx context.Context,
 	switch method {
 	case "BookReviewsByID":
 		return s.bookReviewsByID
+	case "Describe":
+		return s.describe
+	case "Health":
+		return s.health
+	case "ReviewCounts":
+		return s.reviewCounts
 	default:
 		return nil
 	}
@@ -206,6 +428,70 @@ func (s reviews_server_stub) bookReviewsByID(ctx context.Context, args []byte) (
 	return enc.Data(), nil
 }
 
+func (s reviews_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s reviews_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s reviews_server_stub) reviewCounts(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 []int
+	a0 = serviceweaver_dec_slice_int_7c8c8866(dec)
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.ReviewCounts(ctx, a0)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	serviceweaver_enc_map_int_int_61995a5d(enc, r0)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
 // Reflect stub implementations.
 
 type reviews_reflect_stub struct {
@@ -220,6 +506,21 @@ func (s reviews_reflect_stub) BookReviewsByID(ctx context.Context, a0 string) (r
 	return
 }
 
+func (s reviews_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s reviews_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s reviews_reflect_stub) ReviewCounts(ctx context.Context, a0 []int) (r0 map[int]int, err error) {
+	err = s.caller("ReviewCounts", ctx, []any{a0}, []any{&r0})
+	return
+}
+
 // AutoMarshal implementations.
 
 var _ codegen.AutoMarshal = (*Rating)(nil)
@@ -331,3 +632,56 @@ func (x *Review) WeaverUnmarshal(dec *codegen.Decoder) {
 	x.Text = dec.String()
 	(&x.Rating).WeaverUnmarshal(dec)
 }
+
+// Encoding/decoding implementations.
+
+func serviceweaver_enc_slice_int_7c8c8866(enc *codegen.Encoder, arg []int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		enc.Int(arg[i])
+	}
+}
+
+func serviceweaver_dec_slice_int_7c8c8866(dec *codegen.Decoder) []int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]int, n)
+	for i := 0; i < n; i++ {
+		res[i] = dec.Int()
+	}
+	return res
+}
+
+func serviceweaver_enc_map_int_int_61995a5d(enc *codegen.Encoder, arg map[int]int) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for k, v := range arg {
+		enc.Int(k)
+		enc.Int(v)
+	}
+}
+
+func serviceweaver_dec_map_int_int_61995a5d(dec *codegen.Decoder) map[int]int {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make(map[int]int, n)
+	var k int
+	var v int
+	for i := 0; i < n; i++ {
+		k = dec.Int()
+		v = dec.Int()
+		res[k] = v
+	}
+	return res
+}
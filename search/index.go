@@ -0,0 +1,420 @@
+package search
+
+import (
+	"html"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+// Fields of a product that are indexed, and their weights in the score: a
+// word of the title counts three times as much as one of the description.
+const (
+	fieldTitle = iota
+	fieldAuthor
+	fieldDescription
+	numFields
+)
+
+var fieldWeights = [numFields]float64{fieldTitle: 3, fieldAuthor: 2, fieldDescription: 1}
+
+// Weights of the terms matched by a word of a query, relative to the word
+// itself.
+const (
+	prefixWeight = 0.8 // the word is a prefix of the term
+	typoWeight   = 0.6 // the word is one typo away from the term
+	typosWeight  = 0.4 // the word is two typos away from the term
+)
+
+// BM25 parameters.
+const (
+	k1 = 1.2
+	b  = 0.75
+)
+
+// snippetWords is the number of words of the description in a snippet.
+const snippetWords = 24
+
+// Index is an inverted index of products. It's safe for concurrent use.
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[int]*document
+	postings map[string]map[int]*posting // by term, then product ID
+	terms    []string                    // the terms, sorted; nil if stale
+	length   float64                     // total weighted length of the documents
+}
+
+// document is an indexed product.
+type document struct {
+	product     catalog.Product
+	description string  // plain text
+	length      float64 // weighted number of terms
+	terms       []string
+}
+
+// posting holds the occurrences of a term in a document.
+type posting struct {
+	tf [numFields]int // occurrences by field
+}
+
+// weightedTF returns the weighted number of occurrences of the term.
+func (p *posting) weightedTF() float64 {
+	var tf float64
+	for f, n := range p.tf {
+		tf += fieldWeights[f] * float64(n)
+	}
+	return tf
+}
+
+// NewIndex returns an index of products.
+func NewIndex(products ...catalog.Product) *Index {
+	ix := &Index{docs: map[int]*document{}, postings: map[string]map[int]*posting{}}
+	for _, p := range products {
+		ix.Put(p)
+	}
+	return ix
+}
+
+// Len returns the number of indexed products.
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Put adds p to the index, replacing the product with the same ID.
+func (ix *Index) Put(p catalog.Product) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.deleteLocked(p.ID)
+
+	doc := &document{product: p, description: p.DescriptionText()}
+	for f, text := range [numFields]string{p.Title, p.Author, doc.description} {
+		for _, tok := range tokenize(text) {
+			doc.length += fieldWeights[f]
+			byDoc := ix.postings[tok.term]
+			if byDoc == nil {
+				byDoc = map[int]*posting{}
+				ix.postings[tok.term] = byDoc
+				ix.terms = nil
+			}
+			post := byDoc[p.ID]
+			if post == nil {
+				post = &posting{}
+				byDoc[p.ID] = post
+				doc.terms = append(doc.terms, tok.term)
+			}
+			post.tf[f]++
+		}
+	}
+	ix.docs[p.ID] = doc
+	ix.length += doc.length
+}
+
+// Delete removes product id from the index, if it's there.
+func (ix *Index) Delete(id int) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.deleteLocked(id)
+}
+
+// Reset replaces the indexed products with products.
+func (ix *Index) Reset(products []catalog.Product) {
+	fresh := NewIndex(products...)
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.docs, ix.postings, ix.terms, ix.length = fresh.docs, fresh.postings, nil, fresh.length
+}
+
+// deleteLocked removes product id from the index.
+//
+// REQUIRES: ix.mu is held.
+func (ix *Index) deleteLocked(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for _, term := range doc.terms {
+		delete(ix.postings[term], id)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.terms = nil
+		}
+	}
+	ix.length -= doc.length
+	delete(ix.docs, id)
+}
+
+// Search returns the products matching every word of query, best first, and
+// the first limit of them as hits. A word matches the terms it's equal to, a
+// prefix of, or a typo or two away from, which score less in that order.
+// Products with the same score are ordered by ID.
+func (ix *Index) Search(query string, limit int) Results {
+	results := Results{Query: query, Hits: []Hit{}}
+	words := uniqueTerms(tokenize(query))
+	if len(words) == 0 {
+		return results
+	}
+
+	ix.mu.RLock()
+	for ix.terms == nil {
+		ix.mu.RUnlock()
+		ix.mu.Lock()
+		ix.sortTermsLocked()
+		ix.mu.Unlock()
+		ix.mu.RLock()
+	}
+	defer ix.mu.RUnlock()
+
+	// Score the documents matching every word.
+	n := float64(len(ix.docs))
+	avgLength := ix.length / max(n, 1)
+	scores := map[int]float64{}
+	matched := map[int]map[string]bool{} // the terms matched, by document
+	for i, word := range words {
+		best := map[int]float64{} // the best score of the word, by document
+		for term, weight := range ix.expand(word) {
+			byDoc := ix.postings[term]
+			idf := math.Log(1 + (n-float64(len(byDoc))+0.5)/(float64(len(byDoc))+0.5))
+			for id, post := range byDoc {
+				if i > 0 && matched[id] == nil {
+					continue // a previous word didn't match
+				}
+				tf := post.weightedTF()
+				score := weight * idf * tf * (k1 + 1) / (tf + k1*(1-b+b*ix.docs[id].length/avgLength))
+				best[id] = max(best[id], score)
+				if matched[id] == nil {
+					matched[id] = map[string]bool{}
+				}
+				matched[id][term] = true
+			}
+		}
+		for id := range matched {
+			if _, ok := best[id]; !ok {
+				delete(matched, id)
+				delete(scores, id)
+				continue
+			}
+			scores[id] += best[id]
+		}
+	}
+
+	ids := make([]int, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(a, b int) int {
+		if scores[a] != scores[b] {
+			if scores[a] > scores[b] {
+				return -1
+			}
+			return 1
+		}
+		return a - b
+	})
+	results.Total = len(ids)
+	if limit >= 0 && len(ids) > limit {
+		ids = ids[:limit]
+	}
+	for _, id := range ids {
+		doc := ix.docs[id]
+		results.Hits = append(results.Hits, Hit{
+			ProductID:   id,
+			Title:       doc.product.Title,
+			Author:      doc.product.Author,
+			Score:       math.Round(scores[id]*1000) / 1000,
+			TitleHTML:   highlight(doc.product.Title, matched[id]),
+			SnippetHTML: snippet(doc.description, matched[id]),
+		})
+	}
+	return results
+}
+
+// sortTermsLocked sorts the terms, for matching prefixes.
+//
+// REQUIRES: ix.mu is held for writing.
+func (ix *Index) sortTermsLocked() {
+	ix.terms = make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		ix.terms = append(ix.terms, term)
+	}
+	sort.Strings(ix.terms)
+}
+
+// expand returns the indexed terms a word of a query matches, with their
+// weights.
+//
+// REQUIRES: ix.mu is held, and ix.terms is up to date.
+func (ix *Index) expand(word string) map[string]float64 {
+	terms := map[string]float64{}
+	if _, ok := ix.postings[word]; ok {
+		terms[word] = 1
+	}
+	if utf8.RuneCountInString(word) >= 2 {
+		for i := sort.SearchStrings(ix.terms, word); i < len(ix.terms) && strings.HasPrefix(ix.terms[i], word); i++ {
+			if ix.terms[i] != word {
+				terms[ix.terms[i]] = prefixWeight
+			}
+		}
+	}
+	typos := maxTypos(word)
+	if typos == 0 {
+		return terms
+	}
+	runes := []rune(word)
+	for _, term := range ix.terms {
+		if _, ok := terms[term]; ok {
+			continue
+		}
+		t := []rune(term)
+		if abs(len(t)-len(runes)) > typos {
+			continue
+		}
+		switch distance(runes, t) {
+		case 1:
+			terms[term] = typoWeight
+		case 2:
+			if typos >= 2 {
+				terms[term] = typosWeight
+			}
+		}
+	}
+	return terms
+}
+
+// maxTypos returns the number of typos tolerated in a word of a query: none
+// in short words, which would match too many terms.
+func maxTypos(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	}
+	return 0
+}
+
+// distance returns the optimal string alignment distance between a and b:
+// the number of insertions, deletions, substitutions and transpositions of
+// adjacent characters that turn one into the other.
+func distance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// token is a word of a text: its term, and its position in the text.
+type token struct {
+	term       string // the word, in lower case
+	start, end int    // byte offsets of the word in the text
+}
+
+// tokenize splits text into words, which are runs of letters and digits.
+func tokenize(text string) []token {
+	var tokens []token
+	start := -1
+	for i, r := range text {
+		word := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case word && start < 0:
+			start = i
+		case !word && start >= 0:
+			tokens = append(tokens, token{strings.ToLower(text[start:i]), start, i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{strings.ToLower(text[start:]), start, len(text)})
+	}
+	return tokens
+}
+
+// uniqueTerms returns the terms of tokens, without repeats.
+func uniqueTerms(tokens []token) []string {
+	var terms []string
+	for _, tok := range tokens {
+		if !slices.Contains(terms, tok.term) {
+			terms = append(terms, tok.term)
+		}
+	}
+	return terms
+}
+
+// highlight returns text as HTML, with the words whose terms are in matched
+// in <mark> elements.
+func highlight(text string, matched map[string]bool) string {
+	return highlightRange(text, tokenize(text), matched, 0, len(text))
+}
+
+// highlightRange is highlight for text[start:end], whose tokens are tokens.
+func highlightRange(text string, tokens []token, matched map[string]bool, start, end int) string {
+	var sb strings.Builder
+	last := start
+	for _, tok := range tokens {
+		if !matched[tok.term] {
+			continue
+		}
+		sb.WriteString(html.EscapeString(text[last:tok.start]))
+		sb.WriteString("<mark>")
+		sb.WriteString(html.EscapeString(text[tok.start:tok.end]))
+		sb.WriteString("</mark>")
+		last = tok.end
+	}
+	sb.WriteString(html.EscapeString(text[last:end]))
+	return sb.String()
+}
+
+// snippet returns an excerpt of text around its first matched word as HTML,
+// highlighted, or its beginning if no word matched.
+func snippet(text string, matched map[string]bool) string {
+	tokens := tokenize(text)
+	if len(tokens) == 0 {
+		return ""
+	}
+	first := slices.IndexFunc(tokens, func(tok token) bool { return matched[tok.term] })
+	// Start a few words before the first match, so it has some context.
+	from := max(0, first-snippetWords/4)
+	to := min(len(tokens), from+snippetWords)
+	from = max(0, to-snippetWords)
+
+	start, end := 0, len(text)
+	prefix, suffix := "", ""
+	if from > 0 {
+		start, prefix = tokens[from].start, "…"
+	}
+	if to < len(tokens) {
+		end, suffix = tokens[to-1].end, "…"
+	}
+	return prefix + highlightRange(text, tokens[from:to], matched, start, end) + suffix
+}
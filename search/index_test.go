@@ -0,0 +1,129 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+var testProducts = []catalog.Product{
+	{ID: 1, Title: "The Tempest", Author: "William Shakespeare", DescriptionHTML: "A play about a <b>storm</b> on an island."},
+	{ID: 2, Title: "Tempest Tales", Author: "Anonymous", DescriptionHTML: "Stories of the sea."},
+	{ID: 3, Title: "Hamlet", Author: "William Shakespeare", DescriptionHTML: "A tempest of revenge in Denmark."},
+	{ID: 4, Title: "Volpone", Author: "Ben Jonson", DescriptionHTML: "A comedy & a satire."},
+}
+
+// ids returns the product IDs of the hits of r.
+func ids(r Results) string {
+	var ids []int
+	for _, hit := range r.Hits {
+		ids = append(ids, hit.ProductID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestIndexSearch(t *testing.T) {
+	ix := NewIndex(testProducts...)
+	for _, test := range []struct {
+		query string
+		want  string
+	}{
+		{"tempest", "[2 1 3]"},           // shorter titles first, then descriptions
+		{"TEMPEST shakespeare", "[1 3]"}, // every word must match
+		{"temp", "[2 1 3]"},              // prefixes
+		{"tempets", "[2 1 3]"},           // a transposition
+		{"shakespaere hamlet", "[3]"},
+		{"storm", "[1]"}, // the description, without its tags
+		{"b", "[]"},      // not the tags
+		{"xyz", "[]"},
+		{"  ", "[]"},
+		{"sae", "[]"}, // a typo, but too short a word
+		{"jonson volpone", "[4]"},
+	} {
+		if got := ids(ix.Search(test.query, -1)); got != test.want {
+			t.Errorf("Search(%q) = %v, want %v", test.query, got, test.want)
+		}
+	}
+}
+
+func TestIndexRanking(t *testing.T) {
+	ix := NewIndex(testProducts...)
+	exact, prefix, typo := ix.Search("tempest", 1), ix.Search("tempes", 1), ix.Search("tempset", 1)
+	if !(exact.Hits[0].Score > prefix.Hits[0].Score && prefix.Hits[0].Score > typo.Hits[0].Score) {
+		t.Errorf("scores of exact, prefix and typo matches = %v, %v, %v, want decreasing", exact.Hits[0].Score, prefix.Hits[0].Score, typo.Hits[0].Score)
+	}
+
+	r := ix.Search("tempest", 2)
+	if r.Total != 3 || len(r.Hits) != 2 || r.Query != "tempest" {
+		t.Errorf("Search(tempest, 2) = total %d, %d hits, want 3 and 2", r.Total, len(r.Hits))
+	}
+	if r := ix.Search("xyz", 10); r.Hits == nil {
+		t.Error("Search(xyz) hits = nil, want an empty slice")
+	}
+}
+
+func TestIndexHighlight(t *testing.T) {
+	ix := NewIndex(testProducts...)
+	hit := ix.Search("tempest", -1).Hits[2]
+	if hit.ProductID != 3 || hit.TitleHTML != "Hamlet" || hit.SnippetHTML != "A <mark>tempest</mark> of revenge in Denmark." {
+		t.Errorf("hit = %+v, want product 3 with tempest highlighted in the snippet", hit)
+	}
+	if hit := ix.Search("comedy", -1).Hits[0]; hit.SnippetHTML != "A <mark>comedy</mark> &amp; a satire." {
+		t.Errorf("snippet = %q, want it highlighted and escaped", hit.SnippetHTML)
+	}
+	if hit := ix.Search("temp", -1).Hits[0]; hit.TitleHTML != "<mark>Tempest</mark> Tales" {
+		t.Errorf("title of a prefix match = %q, want the whole word highlighted", hit.TitleHTML)
+	}
+
+	long := strings.Repeat("word ", 40) + "needle " + strings.Repeat("word ", 40)
+	ix.Put(catalog.Product{ID: 5, Title: "Haystack", DescriptionHTML: long})
+	got := ix.Search("needle", 1).Hits[0].SnippetHTML
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>needle</mark>") || len(tokenize(got)) != snippetWords+2 {
+		t.Errorf("snippet = %q, want %d words around the match between ellipses", got, snippetWords)
+	}
+}
+
+func TestIndexUpdates(t *testing.T) {
+	ix := NewIndex(testProducts...)
+	ix.Put(catalog.Product{ID: 2, Title: "Sea Stories"})
+	if got := ids(ix.Search("tempest", -1)); got != "[1 3]" {
+		t.Errorf("after replacing product 2: Search(tempest) = %v, want [1 3]", got)
+	}
+	ix.Delete(1)
+	ix.Delete(1)
+	if got := ids(ix.Search("tempest", -1)); got != "[3]" {
+		t.Errorf("after deleting product 1: Search(tempest) = %v, want [3]", got)
+	}
+	if got := ids(ix.Search("temp", -1)); got != "[3]" {
+		t.Errorf("after deleting product 1: Search(temp) = %v, want [3]", got)
+	}
+	if got := ids(ix.Search("sea", -1)); got != "[2]" {
+		t.Errorf("Search(sea) = %v, want [2]", got)
+	}
+
+	ix.Reset(testProducts[3:])
+	if ix.Len() != 1 || ids(ix.Search("tempest", -1)) != "[]" || ids(ix.Search("volpone", -1)) != "[4]" {
+		t.Errorf("after Reset: %d products, want only product 4", ix.Len())
+	}
+}
+
+func TestDistance(t *testing.T) {
+	for _, test := range []struct {
+		a, b string
+		want int
+	}{
+		{"tempest", "tempest", 0},
+		{"tempest", "tempst", 1},
+		{"tempest", "tmepest", 1},
+		{"tempest", "tempesta", 1},
+		{"tempest", "tampist", 2},
+		{"ca", "abc", 3},
+		{"", "abc", 3},
+	} {
+		if got := distance([]rune(test.a), []rune(test.b)); got != test.want {
+			t.Errorf("distance(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
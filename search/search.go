@@ -0,0 +1,198 @@
+// Package search implements the Search component, a full-text search of the
+// products of the catalog.
+//
+// It keeps an inverted index of the titles, authors and descriptions of the
+// products. In the background, every sync interval, it asks the catalog for
+// the changes made since the previous sync and applies them to the index, so
+// the results are at most an interval staler than the catalog, and the index
+// is only rebuilt from scratch when the catalog can't tell what changed,
+// e.g. after it restarted. Searches don't wait for syncs, except the ones
+// before the index was first filled. The interval is set in the component's
+// config:
+//
+//	["github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search"]
+//	sync_interval = "500ms"
+package search
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/logging"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+)
+
+// Hit is a product matching a search.
+type Hit struct {
+	weaver.AutoMarshal
+	ProductID int     `json:"product_id"`
+	Title     string  `json:"title"`
+	Author    string  `json:"author"`
+	Score     float64 `json:"score"` // relevance; higher is better
+	// TitleHTML is the title as HTML, with the words matching the query in
+	// <mark> elements.
+	TitleHTML string `json:"title_html"`
+	// SnippetHTML is an excerpt of the description around the first word
+	// matching the query, as HTML highlighted like TitleHTML.
+	SnippetHTML string `json:"snippet_html"`
+}
+
+// Results are the results of a search.
+type Results struct {
+	weaver.AutoMarshal
+	Query string `json:"query"`
+	Total int    `json:"total"` // the number of matching products
+	Hits  []Hit  `json:"hits"`  // the best ones, best first
+}
+
+// Search searches the products of the catalog.
+type Search interface {
+	// Search returns the products whose title, author or description match
+	// every word of query, and the best limit of them as hits. Words match
+	// the words of the products they're equal to, a prefix of, or a typo or
+	// two away from, which rank lower in that order.
+	Search(ctx context.Context, query string, limit int) (Results, error)
+	Health(ctx context.Context) error
+	Describe(ctx context.Context) (topology.Replica, error)
+}
+
+// defaultSyncInterval is how often the index is synced when the config
+// doesn't say.
+const defaultSyncInterval = time.Second
+
+// config is the configuration of the search component, read from its
+// section of the app config.
+type config struct {
+	logging.Config
+
+	// SyncInterval is how often the index is synced with the catalog, as a
+	// Go duration, e.g. "500ms". It's a second by default.
+	SyncInterval string `toml:"sync_interval"`
+}
+
+type search struct {
+	weaver.Implements[Search]
+	weaver.WithConfig[config]
+	catalog weaver.Ref[catalog.Catalog]
+	index   *Index
+	level   slog.Level    // minimum level logged
+	done    chan struct{} // closed by Shutdown
+
+	mu      sync.Mutex  // held while syncing the index
+	version int64       // of the catalog, as of the last sync; 0 before it
+	filled  atomic.Bool // whether a sync succeeded
+}
+
+// Init creates the index and starts syncing it in the background.
+func (s *search) Init(ctx context.Context) error {
+	level, err := s.Config().ParseLevel()
+	if err != nil {
+		return err
+	}
+	s.level = level
+	interval := defaultSyncInterval
+	if v := s.Config().SyncInterval; v != "" {
+		if interval, err = time.ParseDuration(v); err != nil || interval <= 0 {
+			return fmt.Errorf("invalid sync_interval %q", v)
+		}
+	}
+	s.index = NewIndex()
+	s.done = make(chan struct{})
+	go s.syncEvery(interval)
+	s.logger(ctx).Info("Search component initialized", "sync_interval", interval)
+	return nil
+}
+
+// Shutdown stops the background syncs.
+func (s *search) Shutdown(context.Context) error {
+	close(s.done)
+	return nil
+}
+
+// logger returns the component's logger for the request in ctx.
+func (s *search) logger(ctx context.Context) *slog.Logger {
+	return logging.Logger(ctx, s.Logger(ctx), s.level)
+}
+
+func (s *search) Search(ctx context.Context, query string, limit int) (Results, error) {
+	if !s.filled.Load() {
+		if err := s.fill(ctx); err != nil {
+			return Results{}, err
+		}
+	}
+	return s.index.Search(query, limit), nil
+}
+
+// fill syncs the index, unless a sync already filled it.
+func (s *search) fill(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.filled.Load() {
+		return nil
+	}
+	return s.syncLocked(ctx)
+}
+
+// syncEvery syncs the index right away and then every interval, until
+// Shutdown. If a sync fails, the index is left as it is.
+func (s *search) syncEvery(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		ctx := context.Background()
+		s.mu.Lock()
+		err := s.syncLocked(ctx)
+		s.mu.Unlock()
+		if err != nil {
+			s.logger(ctx).Warn("Failed to get the catalog changes; searching a stale index", "err", err)
+		}
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncLocked applies the changes made to the catalog to the index. Searches
+// don't hold s.mu, so they go on while the catalog is called.
+//
+// REQUIRES: s.mu is held.
+func (s *search) syncLocked(ctx context.Context) error {
+	changes, err := s.catalog.Get().Changes(ctx, s.version)
+	if err != nil {
+		return err
+	}
+	switch {
+	case changes.Reset:
+		s.index.Reset(changes.Products)
+		s.logger(ctx).Info("Rebuilt the search index", "products", len(changes.Products))
+	case len(changes.Products)+len(changes.Deleted) > 0:
+		for _, p := range changes.Products {
+			s.index.Put(p)
+		}
+		for _, id := range changes.Deleted {
+			s.index.Delete(id)
+		}
+		s.logger(ctx).Debug("Updated the search index", "updated", len(changes.Products), "deleted", len(changes.Deleted))
+	}
+	s.version = changes.Version
+	s.filled.Store(true)
+	return nil
+}
+
+// Health reports whether the search component is able to serve requests.
+func (s *search) Health(context.Context) error {
+	return nil
+}
+
+// Describe reports the replica of the search component serving the call.
+func (s *search) Describe(context.Context) (topology.Replica, error) {
+	return topology.Self[Search](), nil
+}
@@ -0,0 +1,80 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ServiceWeaver/weaver/weavertest"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/catalog"
+)
+
+func TestSearch(t *testing.T) {
+	for _, runner := range weavertest.AllRunners() {
+		runner.Test(t, func(t *testing.T, s Search) {
+			got, err := s.Search(context.Background(), "comedy errors", 5)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.Hits) == 0 || got.Hits[0].ProductID != 0 || got.Hits[0].TitleHTML != "The <mark>Comedy</mark> of <mark>Errors</mark>" {
+				t.Errorf("Search(comedy errors) = %+v, want The Comedy of Errors first", got)
+			}
+		})
+	}
+}
+
+func TestCatalogChanges(t *testing.T) {
+	// The Multi runner has more than one replica of the catalog, each with a
+	// memory store of its own, and balances routed calls over them.
+	for _, runner := range []weavertest.Runner{weavertest.Local, weavertest.RPC} {
+		runner.Config = `
+["github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search"]
+sync_interval = "10ms"
+`
+		runner.Test(t, func(t *testing.T, s Search, c catalog.Catalog) {
+			ctx := context.Background()
+			if got, err := s.Search(ctx, "venus adonis", 5); err != nil || got.Total != 0 {
+				t.Fatalf("Search(venus adonis) = %+v, %v, want no hits", got, err)
+			}
+
+			created, err := c.Create(ctx, catalog.Product{Title: "Venus and Adonis", Author: "William Shakespeare"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := eventually(t, s, "venus adonis", func(r Results) bool { return ids(r) == "[100]" }); ids(got) != "[100]" {
+				t.Errorf("Search(venus adonis) after Create = %+v, want product 100", got)
+			}
+
+			if err := c.Delete(ctx, created.ID); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Delete(ctx, 0); err != nil {
+				t.Fatal(err)
+			}
+			if got := eventually(t, s, "venus", func(r Results) bool { return r.Total == 0 }); got.Total != 0 {
+				t.Errorf("Search(venus) after Delete = %+v, want no hits", got)
+			}
+			if got := eventually(t, s, "comedy errors", func(r Results) bool { return r.Total == 0 }); got.Total != 0 {
+				t.Errorf("Search(comedy errors) after deleting product 0 = %+v, want no hits", got)
+			}
+		})
+	}
+}
+
+// eventually searches for query until the results are ok, or a few seconds
+// pass, and returns the last results. The index is synced in the
+// background, so it takes a sync interval to see a change to the catalog.
+func eventually(t *testing.T, s Search, query string, ok func(Results) bool) Results {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		got, err := s.Search(context.Background(), query, 5)
+		if err != nil {
+			t.Fatalf("Search(%s): %v", query, err)
+		}
+		if ok(got) || time.Now().After(deadline) {
+			return got
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,508 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package search
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/camilamedeir0s/bookinfo-serviceweaver/topology"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"reflect"
+)
+
+func init() {
+	codegen.Register(codegen.Registration{
+		Name:  "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search",
+		Iface: reflect.TypeOf((*Search)(nil)).Elem(),
+		Impl:  reflect.TypeOf(search{}),
+		LocalStubFn: func(impl any, caller string, tracer trace.Tracer) any {
+			return search_local_stub{impl: impl.(Search), tracer: tracer, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search", Method: "Describe", Remote: false, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search", Method: "Health", Remote: false, Generated: true}), searchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search", Method: "Search", Remote: false, Generated: true})}
+		},
+		ClientStubFn: func(stub codegen.Stub, caller string) any {
+			return search_client_stub{stub: stub, describeMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search", Method: "Describe", Remote: true, Generated: true}), healthMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search", Method: "Health", Remote: true, Generated: true}), searchMetrics: codegen.MethodMetricsFor(codegen.MethodLabels{Caller: caller, Component: "github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search", Method: "Search", Remote: true, Generated: true})}
+		},
+		ServerStubFn: func(impl any, addLoad func(uint64, float64)) codegen.Server {
+			return search_server_stub{impl: impl.(Search), addLoad: addLoad}
+		},
+		ReflectStubFn: func(caller func(string, context.Context, []any, []any) error) any {
+			return search_reflect_stub{caller: caller}
+		},
+		RefData: "⟦6979b14c:wEaVeReDgE:github.com/camilamedeir0s/bookinfo-serviceweaver/search/Search→github.com/camilamedeir0s/bookinfo-serviceweaver/catalog/Catalog⟧\n",
+	})
+}
+
+// weaver.InstanceOf checks.
+var _ weaver.InstanceOf[Search] = (*search)(nil)
+
+// weaver.Router checks.
+var _ weaver.Unrouted = (*search)(nil)
+
+// Local stub implementations.
+
+type search_local_stub struct {
+	impl            Search
+	tracer          trace.Tracer
+	describeMetrics *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+	searchMetrics   *codegen.MethodMetrics
+}
+
+// Check that search_local_stub implements the Search interface.
+var _ Search = (*search_local_stub)(nil)
+
+func (s search_local_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "search.Search.Describe", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Describe(ctx)
+}
+
+func (s search_local_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "search.Search.Health", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Health(ctx)
+}
+
+func (s search_local_stub) Search(ctx context.Context, a0 string, a1 int) (r0 Results, err error) {
+	// Update metrics.
+	begin := s.searchMetrics.Begin()
+	defer func() { s.searchMetrics.End(begin, err != nil, 0, 0) }()
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.tracer.Start(ctx, "search.Search.Search", trace.WithSpanKind(trace.SpanKindInternal))
+		defer func() {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}()
+	}
+
+	return s.impl.Search(ctx, a0, a1)
+}
+
+// Client stub implementations.
+
+type search_client_stub struct {
+	stub            codegen.Stub
+	describeMetrics *codegen.MethodMetrics
+	healthMetrics   *codegen.MethodMetrics
+	searchMetrics   *codegen.MethodMetrics
+}
+
+// Check that search_client_stub implements the Search interface.
+var _ Search = (*search_client_stub)(nil)
+
+func (s search_client_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.describeMetrics.Begin()
+	defer func() { s.describeMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "search.Search.Describe", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 0, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+func (s search_client_stub) Health(ctx context.Context) (err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.healthMetrics.Begin()
+	defer func() { s.healthMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "search.Search.Health", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	var shardKey uint64
+
+	// Call the remote method.
+	var results []byte
+	results, err = s.stub.Run(ctx, 1, nil, shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	err = dec.Error()
+	return
+}
+
+func (s search_client_stub) Search(ctx context.Context, a0 string, a1 int) (r0 Results, err error) {
+	// Update metrics.
+	var requestBytes, replyBytes int
+	begin := s.searchMetrics.Begin()
+	defer func() { s.searchMetrics.End(begin, err != nil, requestBytes, replyBytes) }()
+
+	span := trace.SpanFromContext(ctx)
+	if span.SpanContext().IsValid() {
+		// Create a child span for this method.
+		ctx, span = s.stub.Tracer().Start(ctx, "search.Search.Search", trace.WithSpanKind(trace.SpanKindClient))
+	}
+
+	defer func() {
+		// Catch and return any panics detected during encoding/decoding/rpc.
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+			if err != nil {
+				err = errors.Join(weaver.RemoteCallError, err)
+			}
+		}
+
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+
+	}()
+
+	// Preallocate a buffer of the right size.
+	size := 0
+	size += (4 + len(a0))
+	size += 8
+	enc := codegen.NewEncoder()
+	enc.Reset(size)
+
+	// Encode arguments.
+	enc.String(a0)
+	enc.Int(a1)
+	var shardKey uint64
+
+	// Call the remote method.
+	requestBytes = len(enc.Data())
+	var results []byte
+	results, err = s.stub.Run(ctx, 2, enc.Data(), shardKey)
+	replyBytes = len(results)
+	if err != nil {
+		err = errors.Join(weaver.RemoteCallError, err)
+		return
+	}
+
+	// Decode the results.
+	dec := codegen.NewDecoder(results)
+	(&r0).WeaverUnmarshal(dec)
+	err = dec.Error()
+	return
+}
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+type search_server_stub struct {
+	impl    Search
+	addLoad func(key uint64, load float64)
+}
+
+// Check that search_server_stub implements the codegen.Server interface.
+var _ codegen.Server = (*search_server_stub)(nil)
+
+// GetStubFn implements the codegen.Server interface.
+func (s search_server_stub) GetStubFn(method string) func(ctx context.Context, args []byte) ([]byte, error) {
+	switch method {
+	case "Describe":
+		return s.describe
+	case "Health":
+		return s.health
+	case "Search":
+		return s.search
+	default:
+		return nil
+	}
+}
+
+func (s search_server_stub) describe(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Describe(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s search_server_stub) health(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	appErr := s.impl.Health(ctx)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+func (s search_server_stub) search(ctx context.Context, args []byte) (res []byte, err error) {
+	// Catch and return any panics detected during encoding/decoding/rpc.
+	defer func() {
+		if err == nil {
+			err = codegen.CatchPanics(recover())
+		}
+	}()
+
+	// Decode arguments.
+	dec := codegen.NewDecoder(args)
+	var a0 string
+	a0 = dec.String()
+	var a1 int
+	a1 = dec.Int()
+
+	// TODO(rgrandl): The deferred function above will recover from panics in the
+	// user code: fix this.
+	// Call the local method.
+	r0, appErr := s.impl.Search(ctx, a0, a1)
+
+	// Encode the results.
+	enc := codegen.NewEncoder()
+	(r0).WeaverMarshal(enc)
+	enc.Error(appErr)
+	return enc.Data(), nil
+}
+
+// Reflect stub implementations.
+
+type search_reflect_stub struct {
+	caller func(string, context.Context, []any, []any) error
+}
+
+// Check that search_reflect_stub implements the Search interface.
+var _ Search = (*search_reflect_stub)(nil)
+
+func (s search_reflect_stub) Describe(ctx context.Context) (r0 topology.Replica, err error) {
+	err = s.caller("Describe", ctx, []any{}, []any{&r0})
+	return
+}
+
+func (s search_reflect_stub) Health(ctx context.Context) (err error) {
+	err = s.caller("Health", ctx, []any{}, []any{})
+	return
+}
+
+func (s search_reflect_stub) Search(ctx context.Context, a0 string, a1 int) (r0 Results, err error) {
+	err = s.caller("Search", ctx, []any{a0, a1}, []any{&r0})
+	return
+}
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*Hit)(nil)
+
+type __is_Hit[T ~struct {
+	weaver.AutoMarshal
+	ProductID   int     "json:\"product_id\""
+	Title       string  "json:\"title\""
+	Author      string  "json:\"author\""
+	Score       float64 "json:\"score\""
+	TitleHTML   string  "json:\"title_html\""
+	SnippetHTML string  "json:\"snippet_html\""
+}] struct{}
+
+var _ __is_Hit[Hit]
+
+func (x *Hit) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Hit.WeaverMarshal: nil receiver"))
+	}
+	enc.Int(x.ProductID)
+	enc.String(x.Title)
+	enc.String(x.Author)
+	enc.Float64(x.Score)
+	enc.String(x.TitleHTML)
+	enc.String(x.SnippetHTML)
+}
+
+func (x *Hit) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Hit.WeaverUnmarshal: nil receiver"))
+	}
+	x.ProductID = dec.Int()
+	x.Title = dec.String()
+	x.Author = dec.String()
+	x.Score = dec.Float64()
+	x.TitleHTML = dec.String()
+	x.SnippetHTML = dec.String()
+}
+
+var _ codegen.AutoMarshal = (*Results)(nil)
+
+type __is_Results[T ~struct {
+	weaver.AutoMarshal
+	Query string "json:\"query\""
+	Total int    "json:\"total\""
+	Hits  []Hit  "json:\"hits\""
+}] struct{}
+
+var _ __is_Results[Results]
+
+func (x *Results) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Results.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Query)
+	enc.Int(x.Total)
+	serviceweaver_enc_slice_Hit_d6cdf29c(enc, x.Hits)
+}
+
+func (x *Results) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Results.WeaverUnmarshal: nil receiver"))
+	}
+	x.Query = dec.String()
+	x.Total = dec.Int()
+	x.Hits = serviceweaver_dec_slice_Hit_d6cdf29c(dec)
+}
+
+func serviceweaver_enc_slice_Hit_d6cdf29c(enc *codegen.Encoder, arg []Hit) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Hit_d6cdf29c(dec *codegen.Decoder) []Hit {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]Hit, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}
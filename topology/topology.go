@@ -0,0 +1,190 @@
+// Package topology describes where Bookinfo components run.
+//
+// Every component exposes a Describe method that returns a Replica built by
+// Self. The product page probes those methods and merges the replies with the
+// static component graph registered by "weaver generate" to render the live
+// topology, so the page reflects whichever placement is deployed.
+package topology
+
+import (
+	"net"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"github.com/ServiceWeaver/weaver/runtime/metrics"
+)
+
+// methodCountMetric is the counter Service Weaver updates on every component
+// method call. It is recorded in the caller's process.
+const methodCountMetric = "serviceweaver_method_count"
+
+// probeMethods are the methods the product page calls to inspect components.
+// They are left out of call counts so that looking at the topology doesn't
+// change it.
+var probeMethods = map[string]bool{"Describe": true, "Health": true}
+
+// started is the time this process started serving.
+var started = time.Now()
+
+// Replica describes a single replica of a component, as seen from the
+// process that hosts it.
+type Replica struct {
+	weaver.AutoMarshal
+	Component string    `json:"component"`
+	Host      string    `json:"host"`
+	Address   string    `json:"address"`
+	PID       int       `json:"pid"`
+	Started   time.Time `json:"started"`
+	Calls     []Call    `json:"calls"`
+}
+
+// Call counts the calls a replica has made to a method of another component.
+type Call struct {
+	weaver.AutoMarshal
+	Component string  `json:"component"`
+	Method    string  `json:"method"`
+	Remote    bool    `json:"remote"`
+	Count     float64 `json:"count"`
+}
+
+// Process returns a key identifying the process that hosts the replica.
+// Replicas with the same key are colocated.
+func (r Replica) Process() string {
+	return r.Host + "/" + strconv.Itoa(r.PID)
+}
+
+// Self describes the replica of component T running in this process.
+func Self[T any]() Replica {
+	name := Name[T]()
+	host, _ := os.Hostname()
+	return Replica{
+		Component: name,
+		Host:      host,
+		Address:   address(),
+		PID:       os.Getpid(),
+		Started:   started,
+		Calls:     calls(name),
+	}
+}
+
+// Name returns the full name of component T, as registered by
+// "weaver generate".
+func Name[T any]() string {
+	iface := reflect.TypeOf((*T)(nil)).Elem()
+	for _, reg := range codegen.Registered() {
+		if reg.Iface == iface {
+			return reg.Name
+		}
+	}
+	return iface.PkgPath() + "/" + iface.Name()
+}
+
+// ShortName returns the lower-case name used for a component in placement
+// files, e.g. "details" for ".../details/Details" and "pp" for weaver.Main.
+func ShortName(component string) string {
+	if component == "github.com/ServiceWeaver/weaver/Main" {
+		return "pp"
+	}
+	return strings.ToLower(component[strings.LastIndex(component, "/")+1:])
+}
+
+// GroupName returns the name of a colocation group holding the given
+// components: their short names joined by "-", with "pp" first.
+func GroupName(components []string) string {
+	var names []string
+	pp := false
+	for _, c := range components {
+		if name := ShortName(c); name == "pp" {
+			pp = true
+		} else {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	if pp {
+		names = append([]string{"pp"}, names...)
+	}
+	return strings.Join(names, "-")
+}
+
+// Edge is a static dependency between two components.
+type Edge struct {
+	Caller string
+	Callee string
+}
+
+// Graph returns the names of all registered application components and the
+// edges between them, both sorted. Service Weaver's internal components are
+// left out.
+func Graph() ([]string, []Edge) {
+	var names []string
+	var edges []Edge
+	for _, reg := range codegen.Registered() {
+		if internal(reg.Name) {
+			continue
+		}
+		names = append(names, reg.Name)
+		for _, e := range codegen.ExtractEdges([]byte(reg.RefData)) {
+			edges = append(edges, Edge{Caller: e[0], Callee: e[1]})
+		}
+	}
+	sort.Strings(names)
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Caller != edges[j].Caller {
+			return edges[i].Caller < edges[j].Caller
+		}
+		return edges[i].Callee < edges[j].Callee
+	})
+	return names, edges
+}
+
+// internal reports whether the named component is one of Service Weaver's
+// own control components rather than part of the application.
+func internal(component string) bool {
+	return strings.HasPrefix(component, "github.com/ServiceWeaver/weaver/") &&
+		component != "github.com/ServiceWeaver/weaver/Main"
+}
+
+// calls returns the method call counts recorded in this process for calls
+// made by the named component.
+func calls(caller string) []Call {
+	var result []Call
+	for _, m := range metrics.Snapshot() {
+		if m.Name != methodCountMetric || m.Labels["caller"] != caller || m.Value == 0 || probeMethods[m.Labels["method"]] {
+			continue
+		}
+		result = append(result, Call{
+			Component: m.Labels["component"],
+			Method:    m.Labels["method"],
+			Remote:    m.Labels["remote"] == "true",
+			Count:     m.Value,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Component != result[j].Component {
+			return result[i].Component < result[j].Component
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// address returns the first non-loopback IPv4 address of this host.
+func address() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return ""
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && !ipnet.IP.IsLoopback() && ipnet.IP.To4() != nil {
+			return ipnet.IP.String()
+		}
+	}
+	return "127.0.0.1"
+}
@@ -0,0 +1,141 @@
+// Code generated by "weaver generate". DO NOT EDIT.
+//go:build !ignoreWeaverGen
+
+package topology
+
+import (
+	"fmt"
+	"github.com/ServiceWeaver/weaver"
+	"github.com/ServiceWeaver/weaver/runtime/codegen"
+	"time"
+)
+
+// weaver.InstanceOf checks.
+
+// weaver.Router checks.
+
+// Local stub implementations.
+
+// Client stub implementations.
+
+// Note that "weaver generate" will always generate the error message below.
+// Everything is okay. The error message is only relevant if you see it when
+// you run "go build" or "go run".
+var _ codegen.LatestVersion = codegen.Version[[0][24]struct{}](`
+
+ERROR: You generated this file with 'weaver generate' v0.24.3 (codegen
+version v0.24.0). The generated code is incompatible with the version of the
+github.com/ServiceWeaver/weaver module that you're using. The weaver module
+version can be found in your go.mod file or by running the following command.
+
+    go list -m github.com/ServiceWeaver/weaver
+
+We recommend updating the weaver module and the 'weaver generate' command by
+running the following.
+
+    go get github.com/ServiceWeaver/weaver@latest
+    go install github.com/ServiceWeaver/weaver/cmd/weaver@latest
+
+Then, re-run 'weaver generate' and re-build your code. If the problem persists,
+please file an issue at https://github.com/ServiceWeaver/weaver/issues.
+
+`)
+
+// Server stub implementations.
+
+// Reflect stub implementations.
+
+// AutoMarshal implementations.
+
+var _ codegen.AutoMarshal = (*Call)(nil)
+
+type __is_Call[T ~struct {
+	weaver.AutoMarshal
+	Component string  "json:\"component\""
+	Method    string  "json:\"method\""
+	Remote    bool    "json:\"remote\""
+	Count     float64 "json:\"count\""
+}] struct{}
+
+var _ __is_Call[Call]
+
+func (x *Call) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Call.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Component)
+	enc.String(x.Method)
+	enc.Bool(x.Remote)
+	enc.Float64(x.Count)
+}
+
+func (x *Call) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Call.WeaverUnmarshal: nil receiver"))
+	}
+	x.Component = dec.String()
+	x.Method = dec.String()
+	x.Remote = dec.Bool()
+	x.Count = dec.Float64()
+}
+
+var _ codegen.AutoMarshal = (*Replica)(nil)
+
+type __is_Replica[T ~struct {
+	weaver.AutoMarshal
+	Component string    "json:\"component\""
+	Host      string    "json:\"host\""
+	Address   string    "json:\"address\""
+	PID       int       "json:\"pid\""
+	Started   time.Time "json:\"started\""
+	Calls     []Call    "json:\"calls\""
+}] struct{}
+
+var _ __is_Replica[Replica]
+
+func (x *Replica) WeaverMarshal(enc *codegen.Encoder) {
+	if x == nil {
+		panic(fmt.Errorf("Replica.WeaverMarshal: nil receiver"))
+	}
+	enc.String(x.Component)
+	enc.String(x.Host)
+	enc.String(x.Address)
+	enc.Int(x.PID)
+	enc.EncodeBinaryMarshaler(&x.Started)
+	serviceweaver_enc_slice_Call_55a08b39(enc, x.Calls)
+}
+
+func (x *Replica) WeaverUnmarshal(dec *codegen.Decoder) {
+	if x == nil {
+		panic(fmt.Errorf("Replica.WeaverUnmarshal: nil receiver"))
+	}
+	x.Component = dec.String()
+	x.Host = dec.String()
+	x.Address = dec.String()
+	x.PID = dec.Int()
+	dec.DecodeBinaryUnmarshaler(&x.Started)
+	x.Calls = serviceweaver_dec_slice_Call_55a08b39(dec)
+}
+
+func serviceweaver_enc_slice_Call_55a08b39(enc *codegen.Encoder, arg []Call) {
+	if arg == nil {
+		enc.Len(-1)
+		return
+	}
+	enc.Len(len(arg))
+	for i := 0; i < len(arg); i++ {
+		(arg[i]).WeaverMarshal(enc)
+	}
+}
+
+func serviceweaver_dec_slice_Call_55a08b39(dec *codegen.Decoder) []Call {
+	n := dec.Len()
+	if n == -1 {
+		return nil
+	}
+	res := make([]Call, n)
+	for i := 0; i < n; i++ {
+		(&res[i]).WeaverUnmarshal(dec)
+	}
+	return res
+}